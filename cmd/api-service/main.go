@@ -4,30 +4,99 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/internal/api-service/metadata"
 	repo "github.com/danilovid/linkkeeper/internal/api-service/repository"
+	"github.com/danilovid/linkkeeper/internal/api-service/syndication"
 	"github.com/danilovid/linkkeeper/internal/api-service/transport/http"
 	"github.com/danilovid/linkkeeper/internal/api-service/usecase"
+	"github.com/danilovid/linkkeeper/internal/api-service/users"
+	"github.com/danilovid/linkkeeper/pkg/auth"
 	"github.com/danilovid/linkkeeper/pkg/config"
 	"github.com/danilovid/linkkeeper/pkg/database/postgresql"
 	"github.com/danilovid/linkkeeper/pkg/httpclient"
 	"github.com/danilovid/linkkeeper/pkg/logger"
 )
 
-const shutdownTimeout = 5 * time.Second
+const (
+	shutdownTimeout    = 5 * time.Second
+	userServiceTimeout = 5 * time.Second
+	// tokenCacheTTL is how long a resolved API token is trusted without
+	// asking user-service again, and so how long a revoked token keeps working.
+	tokenCacheTTL = 30 * time.Second
+)
 
 func main() {
 	cfg := config.New()
 
 	logger.Init()
 
-	db := postgresql.New(cfg.PostgresDSN, &repo.LinkModel{})
+	db := postgresql.New(
+		cfg.PostgresDSN,
+		&repo.LinkModel{}, &repo.LinkViewModel{}, &repo.TagModel{}, &repo.LinkTagModel{},
+		&repo.ImportJobModel{}, &repo.FeedTokenModel{}, &repo.SubscriptionModel{}, &repo.SubscriptionEntryModel{},
+	)
 	linkRepo := repo.NewLinkRepo(db)
-	linkSvc := usecase.NewLinkService(linkRepo)
+	classifier := usecase.NewRuleClassifier()
+	enricher := usecase.NewEnricher(linkRepo, metadata.NewFetcher(cfg.MetadataTimeout), classifier, usecase.EnricherConfig{
+		Attempts: cfg.MetadataAttempts,
+		Timeout:  cfg.MetadataTimeout,
+	})
+	enrichCtx, stopEnriching := context.WithCancel(context.Background())
+	enricher.Start(enrichCtx)
+	linkSvc := usecase.NewLinkService(
+		linkRepo,
+		usecase.WithEnricher(enricher),
+		usecase.WithClassifier(classifier),
+		usecase.WithCanonicalizer(usecase.NewCanonicalizer(canonicalRules(cfg.StripURLParams))),
+	)
+	tagSvc := usecase.NewTagService(repo.NewTagRepo(db))
+	importer := usecase.NewImporter(linkSvc, repo.NewImportJobRepo(db), usecase.ImporterConfig{})
+	importer.Start(context.Background())
+	serviceKeys, err := auth.ParseKeys(cfg.ServiceKeys)
+	if err != nil {
+		logger.L().Fatal().Err(err).Msg("invalid SERVICE_KEYS")
+	}
+	if len(serviceKeys) == 0 {
+		logger.L().Warn().Msg("SERVICE_KEYS is not set, requests between services are not signed")
+	}
+	var (
+		profiles apiservice.ProfileSource
+		tokens   auth.TokenResolver
+	)
+	if cfg.UserServiceURL != "" {
+		userClient := users.NewClient(cfg.UserServiceURL, userServiceTimeout, auth.NewSigner(serviceKeys))
+		profiles = userClient
+		tokens = auth.NewCachedResolver(userClient, tokenCacheTTL)
+	} else {
+		logger.L().Warn().Msg("USER_SERVICE_URL is not set, API tokens are rejected")
+	}
+	backupSvc := usecase.NewBackupService(linkSvc, profiles)
+	feedSvc := usecase.NewFeedTokenService(repo.NewFeedTokenRepo(db))
+	subscriptionSvc := usecase.NewSubscriptionService(
+		linkSvc,
+		repo.NewSubscriptionRepo(db),
+		syndication.NewFetcher(cfg.MetadataTimeout),
+		usecase.SubscriptionConfig{RefreshInterval: cfg.FeedRefresh},
+	)
+	subscriptionSvc.Start(context.Background())
 
-	httpSrv := http.NewServer(linkSvc)
+	httpSrv := http.NewServer(
+		linkSvc,
+		tagSvc,
+		http.WithAdminToken(cfg.AdminToken),
+		http.WithTokenResolver(tokens),
+		http.WithServiceVerifier(auth.NewSignatureVerifier(serviceKeys)),
+		http.WithAuthRequired(cfg.AuthRequired),
+		http.WithImports(importer),
+		http.WithBackups(backupSvc),
+		http.WithFeeds(feedSvc),
+		http.WithSubscriptions(subscriptionSvc),
+	)
 	srv := httpclient.New(cfg.HTTPAddr, httpSrv.Handler(), nil)
 
 	go func() {
@@ -41,9 +110,24 @@ func main() {
 		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer cancel()
 		_ = srv.Shutdown(ctx)
+		importer.Stop()
+		subscriptionSvc.Stop()
+		stopEnriching()
+		enricher.Stop()
 	})
 }
 
+// canonicalRules extends the default URL canonicalization rules with extra query parameters to strip.
+func canonicalRules(extraParams string) usecase.CanonicalRules {
+	rules := usecase.DefaultCanonicalRules()
+	for _, param := range strings.Split(extraParams, ",") {
+		if param = strings.ToLower(strings.TrimSpace(param)); param != "" {
+			rules.StripParams = append(rules.StripParams, param)
+		}
+	}
+	return rules
+}
+
 func waitForShutdown(fn func()) {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/danilovid/linkkeeper/internal/bot-service/bot"
+	"github.com/danilovid/linkkeeper/pkg/httpclient"
 	"github.com/danilovid/linkkeeper/pkg/logger"
 )
 
-const defaultTimeout = 10 * time.Second
+const (
+	defaultTimeout  = 10 * time.Second
+	shutdownTimeout = 5 * time.Second
+)
 
 func main() {
 	logger.Init()
@@ -19,6 +28,10 @@ func main() {
 		APIBaseURL:     os.Getenv("API_BASE_URL"),
 		UserServiceURL: os.Getenv("USER_SERVICE_URL"),
 		Timeout:        readTimeout(),
+		ServiceKeys:    os.Getenv("SERVICE_KEYS"),
+		HTTPAddr:       os.Getenv("HTTP_ADDR"),
+		WebhookURL:     os.Getenv("WEBHOOK_URL"),
+		WebhookSecret:  os.Getenv("WEBHOOK_SECRET"),
 	}
 
 	w, err := bot.NewWrapper(&cfg)
@@ -26,10 +39,32 @@ func main() {
 		logger.L().Fatal().Err(err).Msg("init bot")
 	}
 
-	logger.L().Info().Msg("bot started")
-	if err := w.Start(); err != nil {
-		logger.L().Fatal().Err(err).Msg("bot stopped")
-	}
+	srv := httpclient.New(cfg.HTTPAddr, w.Handler(), nil)
+	go func() {
+		logger.L().Info().Str("addr", cfg.HTTPAddr).Msg("bot-service listening")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.L().Fatal().Err(err).Msg("http server")
+		}
+	}()
+
+	go func() {
+		if cfg.WebhookURL != "" {
+			logger.L().Info().Msg("bot started, receiving updates by webhook")
+		} else {
+			logger.L().Info().Msg("bot started, long polling for updates")
+		}
+		if err := w.Start(); err != nil {
+			logger.L().Fatal().Err(err).Msg("bot stopped")
+		}
+	}()
+
+	waitForShutdown(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		// Stop taking updates from Telegram before the bot stops handling them.
+		_ = srv.Shutdown(ctx)
+		w.Stop()
+	})
 }
 
 func readTimeout() time.Duration {
@@ -43,3 +78,10 @@ func readTimeout() time.Duration {
 	}
 	return time.Duration(seconds) * time.Second
 }
+
+func waitForShutdown(fn func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	<-ch
+	fn()
+}
@@ -2,14 +2,17 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	repo "github.com/danilovid/linkkeeper/internal/user-service/repository"
+	"github.com/danilovid/linkkeeper/internal/user-service/telegram"
 	"github.com/danilovid/linkkeeper/internal/user-service/transport/http"
 	"github.com/danilovid/linkkeeper/internal/user-service/usecase"
+	"github.com/danilovid/linkkeeper/pkg/auth"
 	"github.com/danilovid/linkkeeper/pkg/config"
 	"github.com/danilovid/linkkeeper/pkg/database/postgresql"
 	"github.com/danilovid/linkkeeper/pkg/httpclient"
@@ -20,16 +23,51 @@ import (
 
 const shutdownTimeout = 5 * time.Second
 
+// loginMaxAge is how old Telegram login data may be when it is exchanged for a session.
+const loginMaxAge = 24 * time.Hour
+
 func main() {
 	cfg := config.New()
 
 	logger.Init()
 
-	db := postgresql.New(cfg.PostgresDSN, &userservice.UserModel{})
+	db := postgresql.New(cfg.PostgresDSN, &userservice.UserModel{}, &userservice.APITokenModel{},
+		&userservice.DigestModel{}, &userservice.ReminderModel{}, &userservice.SettingsModel{})
 	userRepo := repo.NewUserRepo(db)
 	userSvc := usecase.NewUserService(userRepo)
+	tokenRepo := repo.NewTokenRepo(db)
+	scheduleRepo := repo.NewScheduleRepo(db)
+	settingsRepo := repo.NewSettingsRepo(db)
+	tokenSvc := usecase.NewTokenService(tokenRepo, userRepo)
+	scheduleSvc := usecase.NewScheduleService(scheduleRepo, userRepo)
+	settingsSvc := usecase.NewSettingsService(settingsRepo, userRepo, scheduleSvc)
+	accountSvc := usecase.NewAccountService(userRepo, tokenRepo, scheduleRepo, settingsRepo)
+
+	serviceKeys, err := auth.ParseKeys(cfg.ServiceKeys)
+	if err != nil {
+		logger.L().Fatal().Err(err).Msg("invalid SERVICE_KEYS")
+	}
+	if len(serviceKeys) == 0 {
+		logger.L().Warn().Msg("SERVICE_KEYS is not set, any caller may register users")
+	}
+
+	opts := []http.Option{
+		http.WithTokens(tokenSvc),
+		http.WithSchedules(scheduleSvc),
+		http.WithSettings(settingsSvc),
+		http.WithAccounts(accountSvc),
+		http.WithServiceVerifier(auth.NewSignatureVerifier(serviceKeys)),
+		http.WithAuthRequired(cfg.AuthRequired),
+	}
+	if cfg.TelegramToken != "" {
+		sessionSvc := usecase.NewSessionService(userSvc, telegram.NewVerifier(cfg.TelegramToken, loginMaxAge), usecase.SessionConfig{
+			Secret: sessionSecret(cfg.SessionSecret),
+			TTL:    cfg.SessionTTL,
+		})
+		opts = append(opts, http.WithSessions(sessionSvc))
+	}
 
-	httpSrv := http.NewServer(userSvc)
+	httpSrv := http.NewServer(userSvc, opts...)
 	srv := httpclient.New(cfg.HTTPAddr, httpSrv.Handler(), nil)
 
 	go func() {
@@ -46,6 +84,18 @@ func main() {
 	})
 }
 
+func sessionSecret(secret string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+	logger.L().Warn().Msg("SESSION_SECRET is not set, sessions will not survive a restart")
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		logger.L().Fatal().Err(err).Msg("generate session secret")
+	}
+	return random
+}
+
 func waitForShutdown(fn func()) {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
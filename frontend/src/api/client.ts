@@ -1,11 +1,22 @@
-import { API_BASE_URL } from '../config';
-import { Link, CreateLinkInput, UpdateLinkInput, ViewStats } from '../types';
+import { API_BASE_URL, API_TOKEN, USER_ID } from '../config';
+import { Link, LinkPage, ListLinksOptions, CreateLinkInput, UpdateLinkInput, ViewStats } from '../types';
 
 class ApiClient {
   private baseUrl: string;
+  private token: string;
+  private userId: string;
 
-  constructor(baseUrl: string) {
+  constructor(baseUrl: string, token: string, userId: string) {
     this.baseUrl = baseUrl;
+    this.token = token;
+    this.userId = userId;
+  }
+
+  private authHeaders(): Record<string, string> {
+    if (this.token) {
+      return { Authorization: `Bearer ${this.token}` };
+    }
+    return this.userId ? { 'X-User-ID': this.userId } : {};
   }
 
   private async request<T>(
@@ -17,6 +28,7 @@ class ApiClient {
       ...options,
       headers: {
         'Content-Type': 'application/json',
+        ...this.authHeaders(),
         ...options?.headers,
       },
     });
@@ -44,8 +56,15 @@ class ApiClient {
     return this.request<Link>(`/links/${id}`);
   }
 
-  async listLinks(limit: number = 50, offset: number = 0): Promise<Link[]> {
-    return this.request<Link[]>(`/links?limit=${limit}&offset=${offset}`);
+  async listLinks(options: ListLinksOptions = {}): Promise<LinkPage> {
+    const params = new URLSearchParams();
+    Object.entries(options).forEach(([key, value]) => {
+      if (value !== undefined && value !== '') {
+        params.set(key, String(value));
+      }
+    });
+    const query = params.toString();
+    return this.request<LinkPage>(`/links${query ? `?${query}` : ''}`);
   }
 
   async getRandomLink(resource?: string): Promise<Link> {
@@ -77,4 +96,4 @@ class ApiClient {
   }
 }
 
-export const apiClient = new ApiClient(API_BASE_URL);
+export const apiClient = new ApiClient(API_BASE_URL, API_TOKEN, USER_ID);
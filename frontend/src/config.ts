@@ -1,3 +1,11 @@
 // API configuration
 // Change this to match your backend URL
 export const API_BASE_URL = process.env.EXPO_PUBLIC_API_URL || 'http://localhost:8080/api/v1';
+
+// Personal API token (issued with the bot's /token command) the app
+// authenticates with. The API rejects link requests without a user.
+export const API_TOKEN = process.env.EXPO_PUBLIC_API_TOKEN || '';
+
+// Id of the user (as issued by the User Service) whose links the app shows
+// when no API token is set. Only accepted by an API that trusts its network.
+export const USER_ID = process.env.EXPO_PUBLIC_USER_ID || '';
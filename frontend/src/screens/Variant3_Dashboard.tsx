@@ -34,7 +34,7 @@ export default function Variant3_Dashboard() {
   const loadLinks = async () => {
     try {
       setLoading(true);
-      const data = await apiClient.listLinks(50, 0);
+      const { links: data } = await apiClient.listLinks({ limit: 50 });
       setLinks(data);
     } catch (error) {
       Alert.alert('Error', `Failed to load links: ${error}`);
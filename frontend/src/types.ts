@@ -8,6 +8,21 @@ export interface Link {
   updated_at: string;
 }
 
+export type LinkSort = 'created' | 'updated' | 'views' | 'viewed' | 'title';
+
+export interface ListLinksOptions {
+  limit?: number;
+  sort?: LinkSort;
+  order?: 'asc' | 'desc';
+  cursor?: string;
+}
+
+export interface LinkPage {
+  links: Link[];
+  next_cursor: string;  // empty on the last page
+  total: number;
+}
+
 export interface CreateLinkInput {
   url: string;
   resource?: string;
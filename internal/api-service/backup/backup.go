@@ -0,0 +1,94 @@
+// Package backup reads and writes LinkKeeper account backups: a versioned
+// JSON document holding the user's profile and every link with its tags and
+// view events.
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Version is the archive schema version written by this package. Bump it on
+// any change that older readers would misread, and teach Read to upgrade the
+// older versions it still accepts.
+const Version = 1
+
+// ErrUnsupportedVersion is returned by Read for archives it cannot interpret.
+var ErrUnsupportedVersion = errors.New("unsupported backup version")
+
+// Archive is a whole backup. Links are ordered oldest first.
+type Archive struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	User       *User     `json:"user,omitempty"`
+	Links      []Link    `json:"links"`
+}
+
+// User is the profile of the account the backup was taken from.
+type User struct {
+	ID         string    `json:"id"`
+	TelegramID int64     `json:"telegram_id,omitempty"`
+	Username   string    `json:"username,omitempty"`
+	FirstName  string    `json:"first_name,omitempty"`
+	LastName   string    `json:"last_name,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Link struct {
+	ID           string     `json:"id"`
+	URL          string     `json:"url"`
+	CanonicalURL string     `json:"canonical_url,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	Resource     string     `json:"resource,omitempty"`
+	Tags         []string   `json:"tags"`
+	Source       string     `json:"source,omitempty"`
+	Metadata     *Metadata  `json:"metadata,omitempty"`
+	Status       string     `json:"status"`
+	ReadingAt    *time.Time `json:"reading_at,omitempty"`
+	DoneAt       *time.Time `json:"done_at,omitempty"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+	Views        int64      `json:"views"`
+	ViewedAt     *time.Time `json:"viewed_at,omitempty"`
+	ViewEvents   []View     `json:"view_events"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Metadata is the fetched page metadata of a link.
+type Metadata struct {
+	Description  string     `json:"description,omitempty"`
+	SiteName     string     `json:"site_name,omitempty"`
+	ImageURL     string     `json:"image_url,omitempty"`
+	CanonicalURL string     `json:"canonical_url,omitempty"`
+	Language     string     `json:"language,omitempty"`
+	FaviconURL   string     `json:"favicon_url,omitempty"`
+	Type         string     `json:"type,omitempty"`
+	ContentType  string     `json:"content_type,omitempty"`
+	FetchedAt    *time.Time `json:"fetched_at,omitempty"`
+}
+
+// View is a single recorded view of a link.
+type View struct {
+	ID       string    `json:"id"`
+	ViewedAt time.Time `json:"viewed_at"`
+}
+
+// Read decodes an archive and checks that its version is one this package
+// understands.
+func Read(r io.Reader) (Archive, error) {
+	var a Archive
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return Archive{}, err
+	}
+	switch {
+	case a.Version == 0:
+		return Archive{}, fmt.Errorf("%w: version is missing", ErrUnsupportedVersion)
+	case a.Version > Version:
+		return Archive{}, fmt.Errorf("%w: %d is newer than %d", ErrUnsupportedVersion, a.Version, Version)
+	}
+	return a, nil
+}
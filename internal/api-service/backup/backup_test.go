@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_RoundTrip(t *testing.T) {
+	exportedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	viewedAt := exportedAt.Add(-time.Hour)
+	user := &User{ID: "u1", TelegramID: 42, Username: "reader", CreatedAt: exportedAt.AddDate(-1, 0, 0)}
+	links := []Link{
+		{
+			ID:         "l1",
+			URL:        "https://go.dev/",
+			Title:      "Go",
+			Tags:       []string{"golang"},
+			Metadata:   &Metadata{SiteName: "Go"},
+			Status:     "done",
+			Views:      1,
+			ViewedAt:   &viewedAt,
+			ViewEvents: []View{{ID: "v1", ViewedAt: viewedAt}},
+			CreatedAt:  exportedAt.AddDate(0, -1, 0),
+			UpdatedAt:  exportedAt,
+		},
+		{ID: "l2", URL: "https://example.com/", Status: "unread", CreatedAt: exportedAt, UpdatedAt: exportedAt},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, exportedAt, user)
+	for _, link := range links {
+		require.NoError(t, w.Write(link))
+	}
+	require.NoError(t, w.Close())
+
+	a, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, Version, a.Version)
+	assert.Equal(t, exportedAt, a.ExportedAt)
+	assert.Equal(t, user, a.User)
+	links[1].Tags, links[1].ViewEvents = []string{}, []View{}
+	assert.Equal(t, links, a.Links)
+}
+
+func TestWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewWriter(&buf, time.Unix(0, 0).UTC(), nil).Close())
+
+	a, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Nil(t, a.User)
+	assert.Empty(t, a.Links)
+}
+
+func TestRead_Version(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"links":[]}`))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	_, err = Read(strings.NewReader(`{"version":2,"links":[]}`))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	_, err = Read(strings.NewReader(`{"version":1,"links":[`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Writer streams an archive one link at a time, so that a large account is
+// never held in memory. The output decodes as a single Archive.
+type Writer struct {
+	w          *bufio.Writer
+	exportedAt time.Time
+	user       *User
+	started    bool
+	links      int
+}
+
+// NewWriter returns a Writer for an archive of user's links; user may be nil.
+func NewWriter(w io.Writer, exportedAt time.Time, user *User) *Writer {
+	return &Writer{w: bufio.NewWriter(w), exportedAt: exportedAt, user: user}
+}
+
+// Write adds a link to the archive.
+func (w *Writer) Write(link Link) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if link.Tags == nil {
+		link.Tags = []string{}
+	}
+	if link.ViewEvents == nil {
+		link.ViewEvents = []View{}
+	}
+	data, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+	if w.links > 0 {
+		if err := w.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	w.links++
+	if err := w.w.WriteByte('\n'); err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+// Close finishes the archive and flushes it. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString("\n]}\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// Flush writes buffered links to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// start writes everything up to the opening bracket of the links.
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	head, err := json.Marshal(struct {
+		Version    int       `json:"version"`
+		ExportedAt time.Time `json:"exported_at"`
+		User       *User     `json:"user,omitempty"`
+	}{Version, w.exportedAt, w.user})
+	if err != nil {
+		return err
+	}
+	// Reopen the header object to append the links array to it.
+	head = append(head[:len(head)-1], `,"links":[`...)
+	_, err = w.w.Write(head)
+	return err
+}
@@ -0,0 +1,396 @@
+// Package bookmarks reads and writes the Netscape bookmark file format that
+// browsers use to import and export bookmarks.
+package bookmarks
+
+import (
+	"bufio"
+	"errors"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTokenSize bounds a single tag or text run, so that a malformed file
+// cannot make the reader buffer the whole input.
+const maxTokenSize = 1 << 20
+
+// ErrTokenTooLarge is returned when a tag or text run exceeds maxTokenSize.
+var ErrTokenTooLarge = errors.New("bookmarks: token too large")
+
+// Bookmark is a single bookmarked page. Folders holds the names of the
+// folders it was filed under, outermost first. Section is the last <H1>
+// heading before it; Pocket groups its export under "Unread" and "Read Archive".
+type Bookmark struct {
+	URL          string
+	Title        string
+	Description  string
+	Section      string
+	Folders      []string
+	Tags         []string
+	AddDate      time.Time
+	LastModified time.Time
+}
+
+// Reader reads bookmarks one at a time from a bookmark file.
+type Reader struct {
+	r *bufio.Reader
+	// folders is the stack of open folders; skipped folders are kept as "".
+	folders []string
+	// heading is the folder named by the last <H3>, waiting for its <DL>.
+	heading *string
+	section string
+	pending *token
+}
+
+type token struct {
+	text string
+	tag  string
+	// closing is set for end tags such as </A>.
+	closing bool
+	attrs   map[string]string
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next bookmark, or io.EOF when there are no more.
+// Anything that is not a bookmark or a folder is skipped.
+func (r *Reader) Next() (Bookmark, error) {
+	for {
+		t, err := r.next()
+		if err != nil {
+			return Bookmark{}, err
+		}
+		switch {
+		case t.tag == "h1" && !t.closing:
+			if r.section, err = r.textUntil("h1"); err != nil {
+				return Bookmark{}, err
+			}
+		case t.tag == "h3" && !t.closing:
+			name, err := r.textUntil("h3")
+			if err != nil {
+				return Bookmark{}, err
+			}
+			// The browser's own top-level folders are not worth a tag.
+			if t.attrs["personal_toolbar_folder"] != "" || t.attrs["unfiled_bookmarks_folder"] != "" {
+				name = ""
+			}
+			r.heading = &name
+		case t.tag == "dl" && !t.closing:
+			name := ""
+			if r.heading != nil {
+				name = *r.heading
+				r.heading = nil
+			}
+			r.folders = append(r.folders, name)
+		case t.tag == "dl" && t.closing:
+			if len(r.folders) > 0 {
+				r.folders = r.folders[:len(r.folders)-1]
+			}
+		case t.tag == "a" && !t.closing:
+			return r.bookmark(t)
+		}
+	}
+}
+
+func (r *Reader) bookmark(a token) (Bookmark, error) {
+	title, err := r.textUntil("a")
+	if err != nil {
+		return Bookmark{}, err
+	}
+	b := Bookmark{
+		URL:          strings.TrimSpace(a.attrs["href"]),
+		Title:        title,
+		Section:      r.section,
+		AddDate:      parseDate(firstNonEmpty(a.attrs["add_date"], a.attrs["time_added"])),
+		LastModified: parseDate(a.attrs["last_modified"]),
+	}
+	for _, folder := range r.folders {
+		if folder != "" {
+			b.Folders = append(b.Folders, folder)
+		}
+	}
+	for _, tag := range strings.Split(a.attrs["tags"], ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			b.Tags = append(b.Tags, tag)
+		}
+	}
+
+	// A <DD> right after the link holds its description.
+	t, err := r.next()
+	for err == nil && t.tag == "" && strings.TrimSpace(t.text) == "" {
+		t, err = r.next()
+	}
+	if errors.Is(err, io.EOF) {
+		return b, nil
+	}
+	if err != nil {
+		return Bookmark{}, err
+	}
+	if t.tag != "dd" || t.closing {
+		r.pending = &t
+		return b, nil
+	}
+	description, err := r.rawTextUntilTag()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Bookmark{}, err
+	}
+	b.Description = cleanLines(description)
+	return b, nil
+}
+
+// rawTextUntilTag collects text up to the next tag, which is left for next.
+func (r *Reader) rawTextUntilTag() (string, error) {
+	var b strings.Builder
+	for {
+		t, err := r.next()
+		if err != nil {
+			return b.String(), err
+		}
+		if t.tag != "" {
+			r.pending = &t
+			return b.String(), nil
+		}
+		b.WriteString(t.text)
+	}
+}
+
+// textUntil collects text up to the closing tag of name. A structural tag
+// also ends the text and is left for next.
+func (r *Reader) textUntil(name string) (string, error) {
+	var b strings.Builder
+	for {
+		t, err := r.next()
+		if err != nil {
+			return cleanText(b.String()), err
+		}
+		if t.tag == "" {
+			b.WriteString(t.text)
+			continue
+		}
+		if t.tag == name && t.closing {
+			return cleanText(b.String()), nil
+		}
+		if isStructural(t.tag) {
+			r.pending = &t
+			return cleanText(b.String()), nil
+		}
+	}
+}
+
+// isStructural reports whether a tag ends an unterminated title, so that a
+// missing </A> does not swallow the rest of the file.
+func isStructural(tag string) bool {
+	switch tag {
+	case "dt", "dl", "dd", "h1", "h3", "a", "li":
+		return true
+	}
+	return false
+}
+
+func (r *Reader) next() (token, error) {
+	if r.pending != nil {
+		t := *r.pending
+		r.pending = nil
+		return t, nil
+	}
+	for {
+		c, err := r.r.ReadByte()
+		if err != nil {
+			return token{}, err
+		}
+		if c != '<' {
+			if err := r.r.UnreadByte(); err != nil {
+				return token{}, err
+			}
+			text, err := r.readUntil('<')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return token{}, err
+			}
+			if err == nil {
+				_ = r.r.UnreadByte()
+				text = text[:len(text)-1]
+			}
+			return token{text: html.UnescapeString(text)}, nil
+		}
+		raw, err := r.readTag()
+		if err != nil {
+			return token{}, err
+		}
+		if t, ok := parseTag(raw); ok {
+			return t, nil
+		}
+	}
+}
+
+// readUntil reads up to and including delim, like bufio.Reader.ReadString,
+// but gives up once maxTokenSize bytes have been read.
+func (r *Reader) readUntil(delim byte) (string, error) {
+	var b strings.Builder
+	for {
+		chunk, err := r.r.ReadSlice(delim)
+		b.Write(chunk)
+		if b.Len() > maxTokenSize {
+			return "", ErrTokenTooLarge
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return b.String(), err
+		}
+	}
+}
+
+// readTag reads the rest of a tag after its '<', up to the '>' that is not
+// inside a quoted attribute value. Comments are read up to "-->".
+func (r *Reader) readTag() (string, error) {
+	var b strings.Builder
+	var quote byte
+	for {
+		c, err := r.r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if b.Len() > maxTokenSize {
+			return "", ErrTokenTooLarge
+		}
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if !strings.HasPrefix(b.String(), "!") {
+				quote = c
+			}
+		case c == '>':
+			s := b.String()
+			if !strings.HasPrefix(s, "!--") || strings.HasSuffix(s, "--") {
+				return s, nil
+			}
+		}
+		b.WriteByte(c)
+	}
+}
+
+// parseTag parses the inside of a tag. It reports false for comments,
+// doctypes and anything else that is not an element.
+func parseTag(s string) (token, bool) {
+	t := token{attrs: map[string]string{}}
+	p := 0
+	if p < len(s) && s[p] == '/' {
+		t.closing = true
+		p++
+	}
+	start := p
+	for p < len(s) && isNameByte(s[p]) {
+		p++
+	}
+	if p == start {
+		return token{}, false
+	}
+	t.tag = strings.ToLower(s[start:p])
+
+	for p < len(s) {
+		for p < len(s) && (isSpace(s[p]) || s[p] == '/') {
+			p++
+		}
+		start := p
+		for p < len(s) && !isSpace(s[p]) && s[p] != '=' && s[p] != '/' {
+			p++
+		}
+		name := strings.ToLower(s[start:p])
+		for p < len(s) && isSpace(s[p]) {
+			p++
+		}
+		value := ""
+		if p < len(s) && s[p] == '=' {
+			p++
+			for p < len(s) && isSpace(s[p]) {
+				p++
+			}
+			if p < len(s) && (s[p] == '"' || s[p] == '\'') {
+				quote := s[p]
+				end := strings.IndexByte(s[p+1:], quote)
+				if end < 0 {
+					end = len(s) - p - 1
+				}
+				value = s[p+1 : p+1+end]
+				p = min(len(s), p+end+2)
+			} else {
+				start := p
+				for p < len(s) && !isSpace(s[p]) {
+					p++
+				}
+				value = s[start:p]
+			}
+		}
+		if _, seen := t.attrs[name]; name != "" && !seen {
+			t.attrs[name] = html.UnescapeString(value)
+		}
+	}
+	return t, true
+}
+
+// parseDate reads a Unix timestamp. Browsers write seconds, but some tools
+// write milliseconds or microseconds.
+func parseDate(raw string) time.Time {
+	n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	switch {
+	case n > 1e14:
+		return time.UnixMicro(n).UTC()
+	case n > 1e11:
+		return time.UnixMilli(n).UTC()
+	default:
+		return time.Unix(n, 0).UTC()
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// cleanLines tidies a multi-line description: spaces are collapsed within
+// lines and blank lines only separate paragraphs.
+func cleanLines(s string) string {
+	var paragraphs []string
+	var current []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = cleanText(line); line != "" {
+			current = append(current, line)
+			continue
+		}
+		if len(current) > 0 {
+			paragraphs = append(paragraphs, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, strings.Join(current, "\n"))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == ':'
+}
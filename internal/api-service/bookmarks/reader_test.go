@@ -0,0 +1,121 @@
+package bookmarks
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, doc string) []Bookmark {
+	r := NewReader(strings.NewReader(doc))
+	var out []Bookmark
+	for {
+		b, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		require.NoError(t, err)
+		out = append(out, b)
+	}
+}
+
+func TestReader_Chrome(t *testing.T) {
+	doc := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1600000000" PERSONAL_TOOLBAR_FOLDER="true">Bookmarks bar</H3>
+    <DL><p>
+        <DT><A HREF="https://go.dev/" ADD_DATE="1600000100">The Go <b>Programming</b> Language</A>
+        <DT><H3 ADD_DATE="1600000200">Research</H3>
+        <DL><p>
+            <DT><H3>Papers &amp; Notes</H3>
+            <DL><p>
+                <DT><A HREF="https://arxiv.org/abs/1706.03762?a=1&amp;b=2" ADD_DATE="1600000300" ICON="data:image/png;base64,iVBOR>w0">Attention</A>
+                <DD>Transformers,
+                   the original paper.
+
+                And a second paragraph.
+            </DL><p>
+        </DL><p>
+        <DT><A HREF="javascript:alert('>')">Bookmarklet</A>
+    </DL><p>
+    <DT><A HREF="https://example.com/">Example
+</DL><p>`
+
+	got := readAll(t, doc)
+
+	require.Len(t, got, 4)
+	assert.Equal(t, Bookmark{
+		URL:     "https://go.dev/",
+		Title:   "The Go Programming Language",
+		Section: "Bookmarks",
+		AddDate: time.Unix(1600000100, 0).UTC(),
+	}, got[0])
+	assert.Equal(t, Bookmark{
+		URL:         "https://arxiv.org/abs/1706.03762?a=1&b=2",
+		Title:       "Attention",
+		Description: "Transformers,\nthe original paper.\n\nAnd a second paragraph.",
+		Section:     "Bookmarks",
+		Folders:     []string{"Research", "Papers & Notes"},
+		AddDate:     time.Unix(1600000300, 0).UTC(),
+	}, got[1])
+	assert.Equal(t, "javascript:alert('>')", got[2].URL)
+	assert.Equal(t, "https://example.com/", got[3].URL)
+	assert.Equal(t, "Example", got[3].Title)
+	assert.Empty(t, got[3].Folders)
+}
+
+func TestReader_FirefoxTags(t *testing.T) {
+	doc := `<DL><p>
+<DT><H3 UNFILED_BOOKMARKS_FOLDER="true">Other Bookmarks</H3>
+<DL><p>
+<DT><A HREF="https://example.com" ADD_DATE="1700000000000" LAST_MODIFIED="1700000000000000" TAGS="go, tools,">Tools</A>
+</DL><p>
+</DL>`
+
+	got := readAll(t, doc)
+
+	require.Len(t, got, 1)
+	assert.Empty(t, got[0].Folders)
+	assert.Equal(t, []string{"go", "tools"}, got[0].Tags)
+	assert.Equal(t, time.UnixMilli(1700000000000).UTC(), got[0].AddDate)
+	assert.Equal(t, time.UnixMicro(1700000000000000).UTC(), got[0].LastModified)
+}
+
+func TestReader_Pocket(t *testing.T) {
+	doc := `<!DOCTYPE html>
+<html><head><title>Pocket Export</title></head><body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/a" time_added="1600000000" tags="go,tools">A</a></li>
+</ul>
+<h1>Read Archive</h1>
+<ul>
+<li><a href="https://example.com/b" time_added="1600000001" tags="">B</a></li>
+</ul>
+</body></html>`
+
+	got := readAll(t, doc)
+
+	require.Len(t, got, 2)
+	assert.Equal(t, "Unread", got[0].Section)
+	assert.Equal(t, []string{"go", "tools"}, got[0].Tags)
+	assert.Equal(t, time.Unix(1600000000, 0).UTC(), got[0].AddDate)
+	assert.Equal(t, "Read Archive", got[1].Section)
+	assert.Empty(t, got[1].Tags)
+}
+
+func TestReader_NotABookmarkFile(t *testing.T) {
+	assert.Empty(t, readAll(t, "just some text, no tags at all"))
+	assert.Empty(t, readAll(t, ""))
+}
@@ -0,0 +1,106 @@
+package bookmarks
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const header = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+`
+
+const footer = "</DL><p>\n"
+
+// Writer writes a flat bookmark file. Folders are not written; tags go into
+// the TAGS attribute, which Firefox and Reader both read back as tags.
+type Writer struct {
+	w       *bufio.Writer
+	started bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Write adds a bookmark to the file.
+func (w *Writer) Write(b Bookmark) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	title := b.Title
+	if title == "" {
+		title = b.URL
+	}
+	var line strings.Builder
+	line.WriteString(`    <DT><A HREF="`)
+	line.WriteString(html.EscapeString(b.URL))
+	line.WriteString(`"`)
+	writeDate(&line, "ADD_DATE", b.AddDate)
+	writeDate(&line, "LAST_MODIFIED", b.LastModified)
+	if len(b.Tags) > 0 {
+		line.WriteString(` TAGS="`)
+		line.WriteString(html.EscapeString(strings.Join(b.Tags, ",")))
+		line.WriteString(`"`)
+	}
+	line.WriteString(">")
+	line.WriteString(html.EscapeString(singleLine(title)))
+	line.WriteString("</A>\n")
+	if b.Description != "" {
+		line.WriteString("    <DD>")
+		line.WriteString(html.EscapeString(strings.TrimSpace(b.Description)))
+		line.WriteString("\n")
+	}
+	_, err := w.w.WriteString(line.String())
+	return err
+}
+
+// Close finishes the file and flushes it. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString(footer); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// Flush writes buffered bookmarks to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	_, err := w.w.WriteString(header)
+	return err
+}
+
+func writeDate(b *strings.Builder, attr string, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	b.WriteString(" ")
+	b.WriteString(attr)
+	b.WriteString(`="`)
+	b.WriteString(strconv.FormatInt(t.Unix(), 10))
+	b.WriteString(`"`)
+}
+
+// singleLine keeps a title on one line, the way browsers write them.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,49 @@
+package bookmarks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_RoundTrip(t *testing.T) {
+	added := time.Unix(1600000000, 0).UTC()
+	in := []Bookmark{
+		{
+			URL:          "https://example.com/?a=1&b=\"2\"",
+			Title:        "Tom & Jerry <3",
+			Description:  "First line\nsecond line\n\nNew paragraph",
+			Tags:         []string{"cartoons", "classics"},
+			AddDate:      added,
+			LastModified: added.Add(time.Hour),
+		},
+		{URL: "https://untitled.example/"},
+	}
+
+	var out strings.Builder
+	w := NewWriter(&out)
+	for _, b := range in {
+		require.NoError(t, w.Write(b))
+	}
+	require.NoError(t, w.Close())
+
+	assert.True(t, strings.HasPrefix(out.String(), "<!DOCTYPE NETSCAPE-Bookmark-file-1>"))
+	got := readAll(t, out.String())
+	require.Len(t, got, 2)
+	want := in[0]
+	want.Section = "Bookmarks"
+	assert.Equal(t, want, got[0])
+	assert.Equal(t, Bookmark{URL: "https://untitled.example/", Title: "https://untitled.example/", Section: "Bookmarks"}, got[1])
+}
+
+func TestWriter_Empty(t *testing.T) {
+	var out strings.Builder
+	w := NewWriter(&out)
+	require.NoError(t, w.Close())
+
+	assert.Contains(t, out.String(), "<DL><p>\n</DL><p>\n")
+	assert.Empty(t, readAll(t, out.String()))
+}
@@ -4,3 +4,5 @@ import "errors"
 
 var ErrNotFound = errors.New("not found")
 var ErrInvalidInput = errors.New("invalid input")
+var ErrAlreadyExists = errors.New("already exists")
+var ErrConflict = errors.New("conflict")
@@ -0,0 +1,173 @@
+package importers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// csvRow is a data row of a CSV export, addressed by header name.
+type csvRow struct {
+	fields  []string
+	columns map[string]int
+}
+
+func (r csvRow) get(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.fields) {
+		return ""
+	}
+	return strings.TrimSpace(r.fields[i])
+}
+
+// csvSource reads CSV exports. Columns are looked up by their lower-cased
+// header name, so their order and any extra columns do not matter.
+type csvSource struct {
+	r       *csv.Reader
+	columns map[string]int
+	row     int
+	parse   func(csvRow) (Record, error)
+}
+
+func newCSV(r io.Reader, required []string, parse func(csvRow) (Record, error)) (*csvSource, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, column := range required {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("missing %q column", column)
+		}
+	}
+	return &csvSource{r: cr, columns: columns, parse: parse}, nil
+}
+
+func (s *csvSource) Next() (Record, error) {
+	fields, err := s.r.Read()
+	if errors.Is(err, io.EOF) {
+		return Record{}, io.EOF
+	}
+	s.row++
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Record{}, &RowError{Row: s.row, Err: parseErr.Err}
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	row := csvRow{fields: fields, columns: s.columns}
+	rec, err := s.parse(row)
+	if err != nil {
+		return Record{}, &RowError{Row: s.row, URL: row.get("url"), Err: err}
+	}
+	rec.Row = s.row
+	return rec, nil
+}
+
+// newPocketCSV reads Pocket's CSV export: title, url, time_added, tags
+// (separated by "|") and status ("unread" or "archive").
+func newPocketCSV(r io.Reader) (*csvSource, error) {
+	return newCSV(r, []string{"url"}, func(row csvRow) (Record, error) {
+		createdAt, err := parseUnix(row.get("time_added"))
+		if err != nil {
+			return Record{}, err
+		}
+		status := apiservice.StatusUnread
+		if strings.EqualFold(row.get("status"), "archive") {
+			status = apiservice.StatusDone
+		}
+		return Record{
+			URL:       row.get("url"),
+			Title:     row.get("title"),
+			Tags:      splitTags(row.get("tags"), "|"),
+			Status:    status,
+			CreatedAt: createdAt,
+		}, nil
+	})
+}
+
+// raindropUnsorted is the collection Raindrop files links under by default.
+const raindropUnsorted = "unsorted"
+
+// newRaindrop reads Raindrop.io's CSV export. Its collection ("folder")
+// becomes a tag like a browser folder does; Raindrop has no read state.
+func newRaindrop(r io.Reader) (*csvSource, error) {
+	return newCSV(r, []string{"url"}, func(row csvRow) (Record, error) {
+		createdAt, err := parseTime(row.get("created"))
+		if err != nil {
+			return Record{}, err
+		}
+		var tags []string
+		if folder := row.get("folder"); folder != "" && !strings.EqualFold(folder, raindropUnsorted) {
+			tags = append(tags, folder)
+		}
+		return Record{
+			URL:       row.get("url"),
+			Title:     row.get("title"),
+			Notes:     row.get("note"),
+			Tags:      append(tags, splitTags(row.get("tags"), ",")...),
+			CreatedAt: createdAt,
+		}, nil
+	})
+}
+
+// Instapaper's built-in folders; any other folder is the user's own.
+const (
+	instapaperUnread  = "unread"
+	instapaperArchive = "archive"
+	instapaperStarred = "starred"
+)
+
+// newInstapaper reads Instapaper's CSV export: URL, Title, Selection (the
+// highlighted text), Folder, Timestamp and, in newer exports, Tags as a JSON
+// array. Archived items are read; starred ones keep a "starred" tag.
+func newInstapaper(r io.Reader) (*csvSource, error) {
+	return newCSV(r, []string{"url"}, func(row csvRow) (Record, error) {
+		createdAt, err := parseUnix(row.get("timestamp"))
+		if err != nil {
+			return Record{}, err
+		}
+		var tags []string
+		if raw := row.get("tags"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &tags); err != nil {
+				return Record{}, fmt.Errorf("invalid tags %q", raw)
+			}
+		}
+		status := apiservice.StatusUnread
+		switch folder := row.get("folder"); strings.ToLower(folder) {
+		case "", instapaperUnread:
+		case instapaperArchive:
+			status = apiservice.StatusDone
+		case instapaperStarred:
+			tags = append(tags, instapaperStarred)
+		default:
+			tags = append([]string{folder}, tags...)
+		}
+		return Record{
+			URL:       row.get("url"),
+			Title:     row.get("title"),
+			Notes:     row.get("selection"),
+			Tags:      tags,
+			Status:    status,
+			CreatedAt: createdAt,
+		}, nil
+	})
+}
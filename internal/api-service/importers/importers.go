@@ -0,0 +1,134 @@
+// Package importers reads the export files of browsers and other bookmarking
+// and read-later services into records that can be saved as links.
+package importers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported export formats.
+const (
+	FormatNetscape   = "netscape"
+	FormatPocketHTML = "pocket_html"
+	FormatPocketCSV  = "pocket_csv"
+	FormatRaindrop   = "raindrop_csv"
+	FormatInstapaper = "instapaper_csv"
+	FormatPinboard   = "pinboard_json"
+)
+
+// Formats lists the supported formats.
+var Formats = []string{
+	FormatNetscape,
+	FormatPocketHTML,
+	FormatPocketCSV,
+	FormatRaindrop,
+	FormatInstapaper,
+	FormatPinboard,
+}
+
+// ErrUnknownFormat is returned by New for a format it has no adapter for.
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Record is a link read from an export file. Row is its 1-based position
+// among the records of the file. Tags are raw names as the service wrote them.
+// Status is a link status, or "" when the service does not track reading.
+type Record struct {
+	Row       int
+	URL       string
+	Title     string
+	Notes     string
+	Tags      []string
+	Status    string
+	CreatedAt time.Time
+}
+
+// Source yields the records of an export file. Next returns io.EOF after the
+// last record. A *RowError reports a record that could not be read; reading
+// may go on after it, but not after any other error.
+type Source interface {
+	Next() (Record, error)
+}
+
+// RowError reports a single unreadable record.
+type RowError struct {
+	Row int
+	URL string
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// New returns a Source reading the given format from r. CSV sources read
+// their header right away and fail if it lacks the columns they need.
+func New(format string, r io.Reader) (Source, error) {
+	switch format {
+	case FormatNetscape:
+		return newNetscape(r, false), nil
+	case FormatPocketHTML:
+		return newNetscape(r, true), nil
+	case FormatPocketCSV:
+		return newPocketCSV(r)
+	case FormatRaindrop:
+		return newRaindrop(r)
+	case FormatInstapaper:
+		return newInstapaper(r)
+	case FormatPinboard:
+		return newPinboard(r), nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+}
+
+// Supported reports whether New accepts format.
+func Supported(format string) bool {
+	return slices.Contains(Formats, format)
+}
+
+// parseUnix reads a Unix timestamp in seconds; empty input is the zero time.
+func parseUnix(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 0 {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", raw)
+	}
+	return time.Unix(n, 0).UTC(), nil
+}
+
+// parseTime reads an RFC 3339 timestamp; empty input is the zero time.
+func parseTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", raw)
+	}
+	return t.UTC(), nil
+}
+
+// splitTags splits a tag list on sep, dropping empty names.
+func splitTags(raw, sep string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, sep) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,167 @@
+package importers
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// readAll reads every record of a file, collecting row errors separately.
+func readAll(t *testing.T, format, doc string) ([]Record, []*RowError) {
+	src, err := New(format, strings.NewReader(doc))
+	require.NoError(t, err)
+	var records []Record
+	var rowErrs []*RowError
+	for {
+		rec, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return records, rowErrs
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			rowErrs = append(rowErrs, rowErr)
+			continue
+		}
+		require.NoError(t, err)
+		records = append(records, rec)
+	}
+}
+
+func TestPocketHTML(t *testing.T) {
+	doc := `<!DOCTYPE html><html><body>
+<h1>Unread</h1>
+<ul><li><a href="https://a.example" time_added="1600000000" tags="go,tools">A</a></li></ul>
+<h1>Read Archive</h1>
+<ul><li><a href="https://b.example" time_added="1600000001" tags="">B</a></li></ul>
+</body></html>`
+
+	records, rowErrs := readAll(t, FormatPocketHTML, doc)
+
+	assert.Empty(t, rowErrs)
+	assert.Equal(t, []Record{
+		{Row: 1, URL: "https://a.example", Title: "A", Tags: []string{"go", "tools"}, Status: apiservice.StatusUnread, CreatedAt: time.Unix(1600000000, 0).UTC()},
+		{Row: 2, URL: "https://b.example", Title: "B", Status: apiservice.StatusDone, CreatedAt: time.Unix(1600000001, 0).UTC()},
+	}, records)
+}
+
+func TestPocketCSV(t *testing.T) {
+	doc := "\ufefftitle,url,time_added,tags,status\n" +
+		"Go,https://go.dev,1600000000,go|lang,archive\n" +
+		"Broken,https://broken.example,yesterday,,unread\n" +
+		"\"Quoted, title\",https://c.example,,,unread\n"
+
+	records, rowErrs := readAll(t, FormatPocketCSV, doc)
+
+	require.Len(t, records, 2)
+	assert.Equal(t, Record{
+		Row:       1,
+		URL:       "https://go.dev",
+		Title:     "Go",
+		Tags:      []string{"go", "lang"},
+		Status:    apiservice.StatusDone,
+		CreatedAt: time.Unix(1600000000, 0).UTC(),
+	}, records[0])
+	assert.Equal(t, 3, records[1].Row)
+	assert.Equal(t, "Quoted, title", records[1].Title)
+	assert.True(t, records[1].CreatedAt.IsZero())
+	require.Len(t, rowErrs, 1)
+	assert.Equal(t, 2, rowErrs[0].Row)
+	assert.Equal(t, "https://broken.example", rowErrs[0].URL)
+	assert.EqualError(t, rowErrs[0], `row 2: invalid timestamp "yesterday"`)
+}
+
+func TestRaindrop(t *testing.T) {
+	doc := "id,title,note,excerpt,url,folder,tags,created,cover,highlights,favorite\n" +
+		"1,Go,my note,page text,https://go.dev,Dev,\"go, lang\",2023-01-02T03:04:05.678Z,,,false\n" +
+		"2,Misc,,,https://misc.example,Unsorted,,2023-01-02T03:04:05Z,,,true\n"
+
+	records, rowErrs := readAll(t, FormatRaindrop, doc)
+
+	assert.Empty(t, rowErrs)
+	require.Len(t, records, 2)
+	assert.Equal(t, Record{
+		Row:       1,
+		URL:       "https://go.dev",
+		Title:     "Go",
+		Notes:     "my note",
+		Tags:      []string{"Dev", "go", "lang"},
+		CreatedAt: time.Date(2023, 1, 2, 3, 4, 5, 678000000, time.UTC),
+	}, records[0])
+	assert.Empty(t, records[1].Tags)
+	assert.Empty(t, records[1].Status)
+}
+
+func TestInstapaper(t *testing.T) {
+	doc := "URL,Title,Selection,Folder,Timestamp,Tags\n" +
+		"https://a.example,A,,Unread,1600000000,[]\n" +
+		"https://b.example,B,a highlight,Archive,1600000001,\"[\"\"go\"\"]\"\n" +
+		"https://c.example,C,,Starred,1600000002,\n" +
+		"https://d.example,D,,Research,1600000003,\n" +
+		"https://e.example,E,,Unread,1600000004,not json\n"
+
+	records, rowErrs := readAll(t, FormatInstapaper, doc)
+
+	require.Len(t, records, 4)
+	assert.Equal(t, apiservice.StatusUnread, records[0].Status)
+	assert.Empty(t, records[0].Tags)
+	assert.Equal(t, Record{
+		Row:       2,
+		URL:       "https://b.example",
+		Title:     "B",
+		Notes:     "a highlight",
+		Tags:      []string{"go"},
+		Status:    apiservice.StatusDone,
+		CreatedAt: time.Unix(1600000001, 0).UTC(),
+	}, records[1])
+	assert.Equal(t, []string{"starred"}, records[2].Tags)
+	assert.Equal(t, []string{"Research"}, records[3].Tags)
+	require.Len(t, rowErrs, 1)
+	assert.Equal(t, 5, rowErrs[0].Row)
+}
+
+func TestPinboard(t *testing.T) {
+	doc := `[
+{"href":"https://go.dev","description":"Go","extended":"notes","meta":"x","hash":"y","time":"2020-01-02T03:04:05Z","shared":"no","toread":"yes","tags":"go lang"},
+{"href":"https://b.example","description":"B","extended":"","time":"not a time","toread":"no","tags":""},
+{"href":42},
+{"href":"https://c.example","description":"C","extended":"","time":"2020-01-02T03:04:05Z","toread":"no","tags":""}
+]`
+
+	records, rowErrs := readAll(t, FormatPinboard, doc)
+
+	require.Len(t, records, 2)
+	assert.Equal(t, Record{
+		Row:       1,
+		URL:       "https://go.dev",
+		Title:     "Go",
+		Notes:     "notes",
+		Tags:      []string{"go", "lang"},
+		Status:    apiservice.StatusUnread,
+		CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, records[0])
+	assert.Equal(t, 4, records[1].Row)
+	assert.Equal(t, apiservice.StatusDone, records[1].Status)
+	require.Len(t, rowErrs, 2)
+	assert.Equal(t, 2, rowErrs[0].Row)
+	assert.Equal(t, 3, rowErrs[1].Row)
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New("delicious", strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = New(FormatRaindrop, strings.NewReader("id,title\n1,x\n"))
+	assert.EqualError(t, err, `missing "url" column`)
+
+	src, err := New(FormatPinboard, strings.NewReader(`{"href": "https://go.dev"}`))
+	require.NoError(t, err)
+	_, err = src.Next()
+	assert.EqualError(t, err, "pinboard export must be a JSON array")
+}
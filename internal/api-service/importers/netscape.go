@@ -0,0 +1,47 @@
+package importers
+
+import (
+	"io"
+	"strings"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/internal/api-service/bookmarks"
+)
+
+// pocketArchive is the section of a Pocket HTML export holding read items.
+const pocketArchive = "read archive"
+
+// netscapeSource reads browser bookmark files. Pocket's HTML export uses the
+// same markup, with read items under a "Read Archive" heading.
+type netscapeSource struct {
+	r      *bookmarks.Reader
+	pocket bool
+	row    int
+}
+
+func newNetscape(r io.Reader, pocket bool) *netscapeSource {
+	return &netscapeSource{r: bookmarks.NewReader(r), pocket: pocket}
+}
+
+func (s *netscapeSource) Next() (Record, error) {
+	b, err := s.r.Next()
+	if err != nil {
+		return Record{}, err
+	}
+	s.row++
+	rec := Record{
+		Row:       s.row,
+		URL:       b.URL,
+		Title:     b.Title,
+		Notes:     b.Description,
+		Tags:      append(b.Folders, b.Tags...),
+		CreatedAt: b.AddDate,
+	}
+	if s.pocket {
+		rec.Status = apiservice.StatusUnread
+		if strings.EqualFold(b.Section, pocketArchive) {
+			rec.Status = apiservice.StatusDone
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,83 @@
+package importers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// pinboardPost is a bookmark in Pinboard's JSON export. Pinboard calls the
+// title "description" and the notes "extended".
+type pinboardPost struct {
+	Href        string `json:"href"`
+	Description string `json:"description"`
+	Extended    string `json:"extended"`
+	Time        string `json:"time"`
+	ToRead      string `json:"toread"`
+	Tags        string `json:"tags"`
+}
+
+// pinboardSource streams the posts of a Pinboard export, which is one JSON
+// array. Posts marked "to read" are unread; the others count as read.
+type pinboardSource struct {
+	dec     *json.Decoder
+	started bool
+	row     int
+}
+
+func newPinboard(r io.Reader) *pinboardSource {
+	return &pinboardSource{dec: json.NewDecoder(r)}
+}
+
+func (s *pinboardSource) Next() (Record, error) {
+	if !s.started {
+		s.started = true
+		tok, err := s.dec.Token()
+		if errors.Is(err, io.EOF) {
+			return Record{}, io.EOF
+		}
+		if err != nil {
+			return Record{}, err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return Record{}, errors.New("pinboard export must be a JSON array")
+		}
+	}
+	if !s.dec.More() {
+		if _, err := s.dec.Token(); err != nil {
+			return Record{}, err
+		}
+		return Record{}, io.EOF
+	}
+
+	s.row++
+	var post pinboardPost
+	if err := s.dec.Decode(&post); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return Record{}, &RowError{Row: s.row, Err: fmt.Errorf("invalid %s", typeErr.Field)}
+		}
+		return Record{}, err
+	}
+	createdAt, err := parseTime(post.Time)
+	if err != nil {
+		return Record{}, &RowError{Row: s.row, URL: post.Href, Err: err}
+	}
+	status := apiservice.StatusDone
+	if strings.EqualFold(post.ToRead, "yes") {
+		status = apiservice.StatusUnread
+	}
+	return Record{
+		Row:       s.row,
+		URL:       strings.TrimSpace(post.Href),
+		Title:     strings.TrimSpace(post.Description),
+		Notes:     strings.TrimSpace(post.Extended),
+		Tags:      strings.Fields(post.Tags),
+		Status:    status,
+		CreatedAt: createdAt,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package metadata
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// PublicTransport returns a transport that only connects to public IP
+// addresses. The check runs on the address actually dialed, after DNS
+// resolution, so neither a hostname pointing inside the network nor a
+// redirect to one gets through. Dial errors for other addresses wrap
+// apiservice.ErrInvalidInput.
+func PublicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   publicOnly,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be the address dialed, hiding the one that matters.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: dial %s: %v", apiservice.ErrInvalidInput, address, err)
+	}
+	if !isPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: dial %s: address is not public", apiservice.ErrInvalidInput, address)
+	}
+	return nil
+}
+
+// nonPublicPrefixes are the ranges that netip.Addr has no predicate for.
+var nonPublicPrefixes = []netip.Prefix{
+	// "This network"; only 0.0.0.0 itself is unspecified.
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// Carrier-grade NAT, home of some cloud metadata endpoints (100.100.100.200).
+	netip.MustParsePrefix("100.64.0.0/10"),
+	// NAT64, which maps every IPv4 address, private ones included.
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
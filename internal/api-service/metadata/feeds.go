@@ -0,0 +1,76 @@
+package metadata
+
+import (
+	"net/url"
+	"strings"
+)
+
+// feedTypes are the media types a page can advertise its feeds with.
+var feedTypes = map[string]bool{
+	"application/rss+xml":  true,
+	"application/atom+xml": true,
+	"application/rdf+xml":  true,
+}
+
+// FeedLinks returns the feeds an HTML page advertises with
+// <link rel="alternate" type="application/rss+xml" href="...">, resolved
+// against the page URL, in document order.
+func FeedLinks(doc string, pageURL *url.URL) []string {
+	var hrefs []string
+	base := pageURL
+	for i := 0; i < len(doc); {
+		j := strings.IndexByte(doc[i:], '<')
+		if j < 0 {
+			break
+		}
+		i += j
+		rest := doc[i:]
+		if strings.HasPrefix(rest, "<!--") {
+			end := strings.Index(rest, "-->")
+			if end < 0 {
+				break
+			}
+			i += end + len("-->")
+			continue
+		}
+
+		t, n := parseTag(rest)
+		if n == 0 {
+			i++
+			continue
+		}
+		i += n
+		if t.closing {
+			continue
+		}
+		switch t.name {
+		case "body":
+			i = len(doc)
+		case "script", "style", "noscript", "template", "svg":
+			i += skipUntilClose(doc[i:], t.name)
+		case "base":
+			if href := strings.TrimSpace(t.attrs["href"]); href != "" {
+				if u, err := pageURL.Parse(href); err == nil {
+					base = u
+				}
+			}
+		case "link":
+			if !hasToken(t.attrs["rel"], "alternate") || !feedTypes[strings.ToLower(strings.TrimSpace(t.attrs["type"]))] {
+				continue
+			}
+			if href := resolve(base, t.attrs["href"]); href != "" {
+				hrefs = append(hrefs, href)
+			}
+		}
+	}
+	return hrefs
+}
+
+func hasToken(list, token string) bool {
+	for _, f := range strings.Fields(list) {
+		if strings.EqualFold(f, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedLinks(t *testing.T) {
+	doc := `<html><head>
+<base href="/blog/">
+<link rel="stylesheet" href="style.css">
+<!-- <link rel="alternate" type="application/rss+xml" href="old.xml"> -->
+<link rel="alternate" type="application/atom+xml" href="atom.xml" title="Atom">
+<link rel="Alternate" type="application/rss+xml" href="https://feeds.example.org/rss">
+<link rel="alternate" type="text/html" hreflang="de" href="/de/">
+<link rel="alternate" type="application/rss+xml" href="javascript:alert(1)">
+</head><body><link rel="alternate" type="application/rss+xml" href="body.xml"></body></html>`
+
+	feeds := FeedLinks(doc, mustParseURL(t, "https://example.com/blog/post"))
+
+	assert.Equal(t, []string{"https://example.com/blog/atom.xml", "https://feeds.example.org/rss"}, feeds)
+}
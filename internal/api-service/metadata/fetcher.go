@@ -0,0 +1,88 @@
+// Package metadata fetches web pages and extracts the metadata shown alongside saved links.
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+const (
+	// maxBodySize limits how much of a page is read; metadata lives in <head>.
+	maxBodySize  = 1 << 20
+	maxRedirects = 5
+	userAgent    = "LinkKeeperBot/1.0 (+https://github.com/danilovid/linkkeeper)"
+)
+
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher returns a Fetcher whose requests, redirects included, give up
+// after timeout. It refuses to connect to any address that is not public (see PublicTransport).
+func NewFetcher(timeout time.Duration) *Fetcher {
+	return &Fetcher{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: PublicTransport(),
+			CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Fetch downloads a page and extracts its metadata. Errors wrapping
+// apiservice.ErrInvalidInput mean the page cannot be fetched and retrying will not help.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (apiservice.PageMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return apiservice.PageMetadata{}, fmt.Errorf("%w: %v", apiservice.ErrInvalidInput, err)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return apiservice.PageMetadata{}, fmt.Errorf("%w: unsupported scheme %q", apiservice.ErrInvalidInput, req.URL.Scheme)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return apiservice.PageMetadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		err := fmt.Errorf("fetch %s: status %d", rawURL, resp.StatusCode)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			err = fmt.Errorf("%w: %v", apiservice.ErrInvalidInput, err)
+		}
+		return apiservice.PageMetadata{}, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return apiservice.PageMetadata{Metadata: apiservice.LinkMetadata{ContentType: mediaType}}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return apiservice.PageMetadata{}, err
+	}
+	page := extract(string(body), resp.Request.URL)
+	page.Metadata.ContentType = mediaType
+	return page, nil
+}
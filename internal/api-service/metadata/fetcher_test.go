@@ -0,0 +1,165 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// newTestFetcher returns a Fetcher that may connect to the loopback test servers.
+func newTestFetcher(timeout time.Duration) *Fetcher {
+	f := NewFetcher(timeout)
+	f.client.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	return f
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/article", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("User-Agent"), "LinkKeeper")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html lang="en"><head><title>Article</title><link rel="icon" href="icon.svg"></head></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	page, err := newTestFetcher(time.Second).Fetch(context.Background(), srv.URL+"/old")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Article", page.Title)
+	assert.Equal(t, "en", page.Metadata.Language)
+	assert.Equal(t, srv.URL+"/icon.svg", page.Metadata.FaviconURL)
+	assert.Equal(t, "text/html", page.Metadata.ContentType)
+}
+
+func TestFetcher_Fetch_NotHTML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.7"))
+	}))
+	defer srv.Close()
+
+	page, err := newTestFetcher(time.Second).Fetch(context.Background(), srv.URL)
+
+	require.NoError(t, err)
+	assert.Empty(t, page.Title)
+	assert.Equal(t, "application/pdf", page.Metadata.ContentType)
+}
+
+func TestFetcher_Fetch_Status(t *testing.T) {
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	f := newTestFetcher(time.Second)
+
+	_, err := f.Fetch(context.Background(), srv.URL)
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput, "client errors are not worth retrying")
+
+	status = http.StatusServiceUnavailable
+	_, err = f.Fetch(context.Background(), srv.URL)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, apiservice.ErrInvalidInput)
+}
+
+func TestFetcher_Fetch_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	_, err := newTestFetcher(50*time.Millisecond).Fetch(context.Background(), srv.URL)
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, apiservice.ErrInvalidInput)
+}
+
+func TestFetcher_Fetch_UnsupportedScheme(t *testing.T) {
+	_, err := NewFetcher(time.Second).Fetch(context.Background(), "ftp://example.com/file")
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
+
+func TestFetcher_Fetch_PrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("fetcher connected to a loopback address")
+	}))
+	defer srv.Close()
+
+	_, err := NewFetcher(time.Second).Fetch(context.Background(), srv.URL)
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
+
+func TestFetcher_Fetch_RedirectToPrivateAddress(t *testing.T) {
+	f := NewFetcher(time.Second)
+	// Let the first hop through so that only the redirect target is checked.
+	f.client.Transport = redirectTransport{to: "http://127.0.0.1:1/internal"}
+
+	_, err := f.Fetch(context.Background(), "http://example.com/")
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
+
+// redirectTransport answers requests to public hosts with a redirect and
+// hands the rest to PublicTransport.
+type redirectTransport struct {
+	to string
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == "example.com" {
+		return &http.Response{
+			StatusCode: http.StatusFound,
+			Header:     http.Header{"Location": {rt.to}},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+	return PublicTransport().RoundTrip(req)
+}
+
+func TestIsPublic(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.215.14":      true,
+		"2606:4700::1111":    true,
+		"127.0.0.1":          false,
+		"::1":                false,
+		"10.1.2.3":           false,
+		"172.16.0.1":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"fe80::1":            false,
+		"fd00::1":            false,
+		"0.0.0.0":            false,
+		"::":                 false,
+		"::ffff:10.0.0.1":    false,
+		"0.1.2.3":            false,
+		"100.64.0.1":         false,
+		"100.100.100.200":    false,
+		"100.127.255.255":    false,
+		"100.128.0.1":        true,
+		"224.0.0.251":        false,
+		"239.1.2.3":          false,
+		"ff02::1":            false,
+		"ff0e::1":            false,
+		"64:ff9b::a9fe:a9fe": false,
+		"64:ff9b::5db8:d70e": false,
+	} {
+		assert.Equal(t, want, isPublic(netip.MustParseAddr(addr)), addr)
+	}
+}
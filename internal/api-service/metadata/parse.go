@@ -0,0 +1,289 @@
+package metadata
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// maxTextLength caps titles and descriptions taken from a page, in runes.
+const maxTextLength = 1000
+
+type tag struct {
+	name    string
+	closing bool
+	attrs   map[string]string
+}
+
+// extract reads page metadata from an HTML document fetched from pageURL.
+// It is a tolerant tag scanner rather than a full HTML parser: it only looks at
+// <html>, <base>, <title>, <meta> and <link>, and stops at <body>.
+func extract(doc string, pageURL *url.URL) apiservice.PageMetadata {
+	var (
+		title, lang, baseHref      string
+		canonical, icon, touchIcon string
+		metas                      = map[string]string{}
+	)
+
+	for i := 0; i < len(doc); {
+		j := strings.IndexByte(doc[i:], '<')
+		if j < 0 {
+			break
+		}
+		i += j
+		rest := doc[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest, "-->")
+			if end < 0 {
+				i = len(doc)
+				continue
+			}
+			i += end + len("-->")
+			continue
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				i = len(doc)
+				continue
+			}
+			i += end + 1
+			continue
+		}
+
+		t, n := parseTag(rest)
+		if n == 0 {
+			i++
+			continue
+		}
+		i += n
+		if t.closing {
+			continue
+		}
+
+		switch t.name {
+		case "body":
+			i = len(doc)
+		case "script", "style", "noscript", "template", "svg":
+			i += skipUntilClose(doc[i:], t.name)
+		case "title":
+			end := indexFold(doc[i:], "</title")
+			if end < 0 {
+				end = len(doc) - i
+			}
+			if title == "" {
+				title = cleanText(html.UnescapeString(doc[i : i+end]))
+			}
+			i += end
+		case "html":
+			lang = t.attrs["lang"]
+		case "base":
+			if baseHref == "" {
+				baseHref = t.attrs["href"]
+			}
+		case "meta":
+			key := strings.ToLower(firstNonEmpty(t.attrs["property"], t.attrs["name"], t.attrs["http-equiv"]))
+			if _, seen := metas[key]; key != "" && !seen {
+				metas[key] = t.attrs["content"]
+			}
+		case "link":
+			rels := strings.Fields(strings.ToLower(t.attrs["rel"]))
+			href := t.attrs["href"]
+			for _, rel := range rels {
+				switch {
+				case rel == "canonical" && canonical == "":
+					canonical = href
+				case rel == "icon" && icon == "":
+					icon = href
+				case strings.HasPrefix(rel, "apple-touch-icon") && touchIcon == "":
+					touchIcon = href
+				}
+			}
+		}
+	}
+
+	base := pageURL
+	if baseHref != "" {
+		if u, err := pageURL.Parse(strings.TrimSpace(baseHref)); err == nil {
+			base = u
+		}
+	}
+
+	page := apiservice.PageMetadata{
+		Title: truncate(firstNonEmpty(cleanText(metas["og:title"]), cleanText(metas["twitter:title"]), title)),
+	}
+	meta := &page.Metadata
+	meta.Description = truncate(firstNonEmpty(
+		cleanText(metas["og:description"]),
+		cleanText(metas["twitter:description"]),
+		cleanText(metas["description"]),
+	))
+	meta.SiteName = truncate(firstNonEmpty(cleanText(metas["og:site_name"]), cleanText(metas["application-name"])))
+	meta.ImageURL = resolve(base, firstNonEmpty(
+		metas["og:image"],
+		metas["og:image:url"],
+		metas["og:image:secure_url"],
+		metas["twitter:image"],
+		metas["twitter:image:src"],
+	))
+	meta.CanonicalURL = resolve(base, firstNonEmpty(canonical, metas["og:url"]))
+	meta.Language = normalizeLanguage(firstNonEmpty(lang, metas["content-language"], metas["og:locale"]))
+	meta.FaviconURL = firstNonEmpty(resolve(base, icon), resolve(base, touchIcon), resolve(base, "/favicon.ico"))
+	meta.Type = strings.ToLower(cleanText(metas["og:type"]))
+	return page
+}
+
+// parseTag parses the tag at the start of s. It returns the number of bytes
+// consumed, or 0 when s does not start with a complete tag.
+func parseTag(s string) (tag, int) {
+	p := 1
+	t := tag{attrs: map[string]string{}}
+	if p < len(s) && s[p] == '/' {
+		t.closing = true
+		p++
+	}
+	start := p
+	for p < len(s) && isNameByte(s[p]) {
+		p++
+	}
+	if p == start {
+		return tag{}, 0
+	}
+	t.name = strings.ToLower(s[start:p])
+
+	for p < len(s) {
+		p = skipSpace(s, p)
+		if p >= len(s) {
+			break
+		}
+		switch s[p] {
+		case '>':
+			return t, p + 1
+		case '/':
+			p++
+			continue
+		}
+
+		start := p
+		for p < len(s) && !isSpace(s[p]) && s[p] != '=' && s[p] != '>' && s[p] != '/' {
+			p++
+		}
+		name := strings.ToLower(s[start:p])
+		p = skipSpace(s, p)
+		value := ""
+		if p < len(s) && s[p] == '=' {
+			p = skipSpace(s, p+1)
+			if p < len(s) && (s[p] == '"' || s[p] == '\'') {
+				quote := s[p]
+				end := strings.IndexByte(s[p+1:], quote)
+				if end < 0 {
+					return tag{}, 0
+				}
+				value = s[p+1 : p+1+end]
+				p += end + 2
+			} else {
+				start := p
+				for p < len(s) && !isSpace(s[p]) && s[p] != '>' {
+					p++
+				}
+				value = s[start:p]
+			}
+		}
+		if _, seen := t.attrs[name]; name != "" && !seen {
+			t.attrs[name] = html.UnescapeString(value)
+		}
+	}
+	return tag{}, 0
+}
+
+// skipUntilClose returns the offset just past the closing tag of a raw-text element.
+func skipUntilClose(s, name string) int {
+	end := indexFold(s, "</"+name)
+	if end < 0 {
+		return len(s)
+	}
+	if gt := strings.IndexByte(s[end:], '>'); gt >= 0 {
+		return end + gt + 1
+	}
+	return len(s)
+}
+
+func indexFold(s, substr string) int {
+	n := len(substr)
+	for i := 0; i+n <= len(s); i++ {
+		if strings.EqualFold(s[i:i+n], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+// normalizeLanguage turns values like "en_US" or "EN-us" into BCP 47 style "en-US".
+func normalizeLanguage(lang string) string {
+	lang = strings.TrimSpace(lang)
+	if i := strings.IndexByte(lang, ','); i >= 0 {
+		lang = strings.TrimSpace(lang[:i])
+	}
+	parts := strings.Split(strings.ReplaceAll(lang, "_", "-"), "-")
+	if parts[0] == "" {
+		return ""
+	}
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// cleanText drops invalid UTF-8 and collapses whitespace.
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(strings.ToValidUTF8(s, "")), " ")
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxTextLength {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:maxTextLength])) + "…"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func skipSpace(s string, p int) int {
+	for p < len(s) && isSpace(s[p]) {
+		p++
+	}
+	return p
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == ':'
+}
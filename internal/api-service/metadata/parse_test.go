@@ -0,0 +1,85 @@
+package metadata
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
+
+func TestExtract_OpenGraph(t *testing.T) {
+	doc := `<!DOCTYPE html>
+<html lang="en_us">
+<head>
+  <meta charset="utf-8">
+  <title>Fallback title</title>
+  <meta property="og:title" content="Tom &amp; Jerry">
+  <meta property="og:description" content="A cat and
+      a mouse">
+  <meta property="og:site_name" content="Cartoons">
+  <meta property="og:image" content="/img/cover.png">
+  <meta property="og:type" content="video.other">
+  <meta name="twitter:title" content="Not used">
+  <link rel="canonical" href="https://example.com/tom-and-jerry">
+  <link rel="shortcut icon" href="/static/icon.png">
+</head>
+<body><meta property="og:title" content="ignored"></body>
+</html>`
+
+	page := extract(doc, mustParseURL(t, "https://example.com/watch?v=1"))
+
+	assert.Equal(t, "Tom & Jerry", page.Title)
+	assert.Equal(t, "A cat and a mouse", page.Metadata.Description)
+	assert.Equal(t, "Cartoons", page.Metadata.SiteName)
+	assert.Equal(t, "https://example.com/img/cover.png", page.Metadata.ImageURL)
+	assert.Equal(t, "https://example.com/tom-and-jerry", page.Metadata.CanonicalURL)
+	assert.Equal(t, "en-US", page.Metadata.Language)
+	assert.Equal(t, "https://example.com/static/icon.png", page.Metadata.FaviconURL)
+	assert.Equal(t, "video.other", page.Metadata.Type)
+}
+
+func TestExtract_Fallbacks(t *testing.T) {
+	doc := `<html><head>
+<!-- <title>commented out</title> -->
+<script>var s = "<title>in a script</title>";</script>
+<TITLE>
+  Plain   title
+</TITLE>
+<meta name="description" content='Plain description'>
+<meta name="twitter:image" content=https://cdn.example.com/card.jpg>
+<meta http-equiv="Content-Language" content="de">
+<meta property="og:url" content="/canonical">
+<base href="https://example.com/docs/">
+</head></html>`
+
+	page := extract(doc, mustParseURL(t, "https://example.com/page"))
+
+	assert.Equal(t, "Plain title", page.Title)
+	assert.Equal(t, "Plain description", page.Metadata.Description)
+	assert.Equal(t, "https://cdn.example.com/card.jpg", page.Metadata.ImageURL)
+	assert.Equal(t, "https://example.com/canonical", page.Metadata.CanonicalURL)
+	assert.Equal(t, "de", page.Metadata.Language)
+	assert.Equal(t, "https://example.com/favicon.ico", page.Metadata.FaviconURL)
+}
+
+func TestExtract_IgnoresUnsafeURLs(t *testing.T) {
+	doc := `<head><meta property="og:image" content="javascript:alert(1)"><link rel=icon href="data:image/png;base64,AAAA"></head>`
+
+	page := extract(doc, mustParseURL(t, "https://example.com/"))
+
+	assert.Empty(t, page.Metadata.ImageURL)
+	assert.Equal(t, "https://example.com/favicon.ico", page.Metadata.FaviconURL)
+}
+
+func TestExtract_TruncatedDocument(t *testing.T) {
+	page := extract(`<html><head><title>Cut off`, mustParseURL(t, "https://example.com/"))
+
+	assert.Equal(t, "Cut off", page.Title)
+}
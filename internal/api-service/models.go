@@ -1,25 +1,366 @@
 package apiservice
 
-import "time"
+import (
+	"slices"
+	"time"
+)
+
+// Link statuses. Links start unread; see NextStatuses for the allowed transitions.
+const (
+	StatusUnread   = "unread"
+	StatusReading  = "reading"
+	StatusDone     = "done"
+	StatusArchived = "archived"
+)
+
+// statusTransitions lists the statuses a link may move to from each status.
+// Archived links have to go back to the queue before they can be read again.
+var statusTransitions = map[string][]string{
+	StatusUnread:   {StatusReading, StatusDone, StatusArchived},
+	StatusReading:  {StatusUnread, StatusDone, StatusArchived},
+	StatusDone:     {StatusReading, StatusArchived},
+	StatusArchived: {StatusUnread},
+}
+
+// NextStatuses returns the statuses a link in status may move to, or nil
+// when status is not a known status.
+func NextStatuses(status string) []string {
+	return slices.Clone(statusTransitions[status])
+}
+
+// Resource types assigned to links by the classifier. Users may also pick any other value.
+const (
+	ResourceArticle = "article"
+	ResourceVideo   = "video"
+	ResourcePodcast = "podcast"
+	ResourceRepo    = "repo"
+	ResourcePaper   = "paper"
+	ResourceSocial  = "social"
+	ResourceDocs    = "docs"
+)
+
+// Sort orders for link listings. Every order breaks ties by link ID.
+const (
+	SortCreated = "created"
+	SortUpdated = "updated"
+	SortViews   = "views"
+	SortViewed  = "viewed"
+	SortTitle   = "title"
+)
+
+// Sort directions.
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
 
 type Link struct {
-	ID        string
-	URL       string
-	Resource  string
-	Views     int64
-	ViewedAt  *time.Time
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID     string
+	UserID string
+	URL    string
+	// CanonicalURL is URL normalized for duplicate detection; a user has at most
+	// one link per canonical URL. Links saved before canonicalization have none.
+	CanonicalURL string
+	Title        string
+	Notes        string
+	Resource     string
+	Tags         []string
+	// Source is where the link came from when it was not saved by hand, e.g.
+	// the URL of the feed a subscription saved it from.
+	Source   string
+	Metadata LinkMetadata
+	Status   string
+	// ReadingAt, DoneAt and ArchivedAt record when the link last entered each status.
+	ReadingAt  *time.Time
+	DoneAt     *time.Time
+	ArchivedAt *time.Time
+	Views      int64
+	ViewedAt   *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// LinkMetadata describes the page behind a link. It is filled in asynchronously
+// after the link is saved; FetchedAt stays nil until then.
+type LinkMetadata struct {
+	Description  string
+	SiteName     string
+	ImageURL     string
+	CanonicalURL string
+	Language     string
+	FaviconURL   string
+	Type         string // og:type, e.g. "article" or "video.other"
+	ContentType  string // media type of the response, e.g. "text/html"
+	FetchedAt    *time.Time
+}
+
+// PageMetadata is what a MetadataFetcher extracts from a page.
+type PageMetadata struct {
+	Title    string
+	Metadata LinkMetadata
 }
 
 type LinkCreateInput struct {
-	URL      string
-	Resource string
+	UserID       string
+	URL          string
+	CanonicalURL string
+	Title        string
+	Notes        string
+	Resource     string
+	Tags         []string
+	Source       string
+	// CreatedAt backdates an imported link; zero means now.
+	CreatedAt time.Time
 }
 
 type LinkUpdateInput struct {
-	URL      *string
-	Resource *string
+	URL          *string
+	CanonicalURL *string
+	Title        *string
+	Notes        *string
+	Resource     *string
+	AddTags      []string
+	RemoveTags   []string
+}
+
+// LinkFilter narrows the links a query operates on. Zero values match everything;
+// a link must carry every tag in Tags and have one of Statuses to match.
+type LinkFilter struct {
+	Resource string
+	Tags     []string
+	Statuses []string
+}
+
+// PageRequest asks for a page of links. Cursor is the NextCursor of the
+// previous page, or empty for the first page. Sort and Order default to the
+// ones the cursor was issued for, or to newest first.
+type PageRequest struct {
+	Sort   string
+	Order  string
+	Cursor string
+	Limit  int
+}
+
+// LinkPage is a page of links. NextCursor is empty on the last page; Total
+// counts every link matching the filter.
+type LinkPage struct {
+	Links      []Link
+	NextCursor string
+	Total      int64
+}
+
+// ListQuery selects links in keyset order. After is the last link of the
+// previous page; only its ID and the field Sort orders by are read.
+type ListQuery struct {
+	Sort  string
+	Desc  bool
+	After *Link
+	Limit int
+}
+
+// SearchResult is a link matching a search query. Snippet holds the matching
+// text as HTML: escaped, with the matched words wrapped in <b></b>.
+type SearchResult struct {
+	Link    Link
+	Rank    float64
+	Snippet string
+}
+
+// DuplicateGroup is a set of links of one user that share a canonical URL.
+type DuplicateGroup struct {
+	CanonicalURL string
+	Links        []Link
+}
+
+// MergeResult lists the links that absorbed duplicates and how many duplicates were removed.
+type MergeResult struct {
+	Merged  []Link
+	Removed int
+}
+
+// ReclassifyResult reports how many links a reclassification run looked at and changed.
+type ReclassifyResult struct {
+	Scanned int
+	Updated int
+}
+
+// Outcomes of importing a single bookmark.
+const (
+	ImportCreated   = "created"
+	ImportDuplicate = "duplicate"
+	ImportInvalid   = "invalid"
+)
+
+// ImportItem reports what happened to one imported bookmark. Row is its
+// position in the file. LinkID is the created link, or the already saved one
+// for duplicates; it is empty in a dry run for links that would be created.
+type ImportItem struct {
+	Row       int
+	URL       string
+	Title     string
+	Tags      []string
+	CreatedAt time.Time
+	Result    string
+	LinkID    string
+	Error     string
+}
+
+// ImportResult summarizes a bookmark import. In a dry run nothing is saved and
+// Created counts the links that would be created.
+type ImportResult struct {
+	DryRun     bool
+	Created    int
+	Duplicates int
+	Invalid    int
+	Items      []ImportItem
+}
+
+// Import job statuses. Jobs wait as queued until a worker picks them up.
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// ImportJob is an import running in the background. Processed counts the
+// records read so far out of Total; Errors lists the rows that could not be
+// imported, up to a limit. Error is set when the job as a whole failed.
+type ImportJob struct {
+	ID         string
+	UserID     string
+	Format     string
+	Status     string
+	Total      int
+	Processed  int
+	Created    int
+	Duplicates int
+	Invalid    int
+	Errors     []ImportError
+	Error      string
+	Attempt    int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+// ImportError reports a row of an import file that could not be imported.
+type ImportError struct {
+	Row   int
+	URL   string
+	Error string
+}
+
+// Profile is a user's account as user-service knows it.
+type Profile struct {
+	ID         string
+	TelegramID int64
+	Username   string
+	FirstName  string
+	LastName   string
+	CreatedAt  time.Time
+}
+
+// Outcomes of restoring a link from a backup.
+const (
+	RestoreCreated  = "created"  // the link was missing and was saved with its ID
+	RestoreExisting = "existing" // the user already had the link with this ID
+	RestoreMerged   = "merged"   // the user had the page under another ID; tags and views were added to it
+)
+
+// RestoredLink reports how a link from a backup was restored. ID is the
+// link it now lives in and Views counts the view events that were added.
+type RestoredLink struct {
+	ID      string
+	Outcome string
+	Views   int
+}
+
+// RestoreResult sums up a restore. Errors lists the links of the backup that
+// could not be restored; their Row is their 1-based position in the backup.
+type RestoreResult struct {
+	Version  int
+	Created  int
+	Existing int
+	Merged   int
+	Invalid  int
+	Views    int
+	Errors   []ImportError
+}
+
+// Subscription is a feed a user follows. Entries that appear in the feed
+// after subscribing are saved as links with Tags, and the feed URL as Source.
+type Subscription struct {
+	ID      string
+	UserID  string
+	FeedURL string
+	SiteURL string
+	Title   string
+	Tags    []string
+	// ETag and LastModified come from the last response and make the next poll conditional.
+	ETag         string
+	LastModified string
+	LastPolledAt *time.Time
+	// LastError and ErrorCount describe the polls that failed in a row; polling backs off while they do.
+	LastError  string
+	ErrorCount int
+	NextPollAt time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type SubscriptionInput struct {
+	UserID  string
+	FeedURL string
+	Tags    []string
+}
+
+type SubscriptionUpdate struct {
+	Title *string
+	Tags  *[]string
+}
+
+// FeedRequest asks a FeedFetcher for a feed. ETag and LastModified, when
+// set, make the request conditional.
+type FeedRequest struct {
+	URL          string
+	ETag         string
+	LastModified string
+}
+
+// Feed is what a FeedFetcher read. URL is the feed that was read, which
+// differs from the requested URL when that was a page advertising the feed.
+// NotModified is set, and nothing else is, when a conditional request found
+// the feed unchanged.
+type Feed struct {
+	URL          string
+	Title        string
+	SiteURL      string
+	ETag         string
+	LastModified string
+	NotModified  bool
+	Entries      []FeedEntry
+}
+
+// FeedEntry is an item of a feed, in the order the feed lists them. Key
+// identifies the entry within its feed: its guid or id, or else its URL.
+type FeedEntry struct {
+	Key       string
+	URL       string
+	Title     string
+	Published time.Time
+}
+
+type Tag struct {
+	Name  string
+	Count int64
+}
+
+type LinkView struct {
+	ID       string
+	LinkID   string
+	ViewedAt time.Time
 }
 
 type ViewStats struct {
@@ -1,14 +1,109 @@
 package apiservice
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type LinkRepository interface {
+	// Create saves a new link. When the user already has a link with the same
+	// canonical URL it returns that link together with ErrAlreadyExists.
 	Create(ctx context.Context, input LinkCreateInput) (Link, error)
-	GetByID(ctx context.Context, id string) (Link, error)
-	List(ctx context.Context, limit, offset int) ([]Link, error)
-	Random(ctx context.Context, resource string) (Link, error)
-	Update(ctx context.Context, id string, input LinkUpdateInput) (Link, error)
-	Delete(ctx context.Context, id string) error
-	MarkViewed(ctx context.Context, id string) (Link, error)
-	GetViewStats(ctx context.Context, days int) ([]ViewStats, error)
+	GetByID(ctx context.Context, userID, id string) (Link, error)
+	GetByCanonicalURL(ctx context.Context, userID, canonicalURL string) (Link, error)
+	List(ctx context.Context, userID string, filter LinkFilter, query ListQuery) ([]Link, error)
+	Count(ctx context.Context, userID string, filter LinkFilter) (int64, error)
+	Random(ctx context.Context, userID string, filter LinkFilter) (Link, error)
+	Search(ctx context.Context, userID, query string, filter LinkFilter, limit, offset int) ([]SearchResult, error)
+	Update(ctx context.Context, userID, id string, input LinkUpdateInput) (Link, error)
+	Delete(ctx context.Context, userID, id string) error
+	MarkViewed(ctx context.Context, userID, id string) (Link, error)
+	// SetStatus moves a link from status from to status to and stamps the
+	// transition. It returns ErrConflict when the link is no longer in from.
+	SetStatus(ctx context.Context, userID, id, from, to string) (Link, error)
+	ListViews(ctx context.Context, userID, id string, limit, offset int) ([]LinkView, error)
+	GetViewStats(ctx context.Context, userID string, filter LinkFilter, days int) ([]ViewStats, error)
+	// SaveMetadata stores fetched page metadata on a link. The title is only
+	// taken from the page when the link has none yet.
+	SaveMetadata(ctx context.Context, userID, id string, page PageMetadata) (Link, error)
+	// ListBatch pages through links ordered by id, starting after afterID. It covers
+	// every user unless userID is set, and only links without a resource when
+	// unclassified is set.
+	ListBatch(ctx context.Context, userID string, unclassified bool, afterID string, limit int) ([]Link, error)
+	// MergeLinks folds the duplicates into the kept link: views, view history and
+	// tags are combined, empty fields are filled in and the duplicates are deleted.
+	MergeLinks(ctx context.Context, userID, keepID string, duplicateIDs []string, canonicalURL string) (Link, error)
+	// ListViewEvents returns the view events of the given links, oldest first.
+	ListViewEvents(ctx context.Context, userID string, linkIDs []string) ([]LinkView, error)
+	// Restore saves a link from a backup together with its view events. A link
+	// the user already has, by ID or by canonical URL, is kept and only gains
+	// the tags and view events it lacks, so restoring twice changes nothing.
+	// IDs taken by another user's data are replaced with new ones.
+	Restore(ctx context.Context, userID string, link Link, views []LinkView) (RestoredLink, error)
+}
+
+type TagRepository interface {
+	List(ctx context.Context, userID string) ([]Tag, error)
+	Rename(ctx context.Context, userID, from, to string) (Tag, error)
+	Merge(ctx context.Context, userID string, sources []string, into string) (Tag, error)
+}
+
+// MetadataFetcher loads a page and extracts its metadata.
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (PageMetadata, error)
+}
+
+// FeedFetcher downloads and parses RSS and Atom feeds.
+type FeedFetcher interface {
+	Fetch(ctx context.Context, req FeedRequest) (Feed, error)
+}
+
+// ProfileSource looks up user profiles in user-service.
+type ProfileSource interface {
+	GetProfile(ctx context.Context, userID string) (Profile, error)
+}
+
+// FeedTokenRepository stores hashes of the tokens that unlock a user's feeds.
+type FeedTokenRepository interface {
+	// Set replaces the user's token hash.
+	Set(ctx context.Context, userID, tokenHash string) error
+	Delete(ctx context.Context, userID string) error
+	// UserID finds the user a token hash belongs to.
+	UserID(ctx context.Context, tokenHash string) (string, error)
+}
+
+// ImportJobRepository stores import jobs together with the uploaded file.
+type ImportJobRepository interface {
+	Create(ctx context.Context, job ImportJob, payload []byte) (ImportJob, error)
+	Get(ctx context.Context, userID, id string) (ImportJob, error)
+	List(ctx context.Context, userID string, limit int) ([]ImportJob, error)
+	// Claim hands the oldest queued job, or a running job whose worker has not
+	// reported progress since staleBefore, to the caller. It returns ErrNotFound
+	// when there is none and ErrConflict when another worker claimed it first.
+	Claim(ctx context.Context, staleBefore time.Time) (ImportJob, []byte, error)
+	// Save stores the progress of a claimed job. It returns ErrConflict when the
+	// job has been claimed again since. The file is dropped once the job is done or failed.
+	Save(ctx context.Context, job ImportJob) (ImportJob, error)
+}
+
+// SubscriptionRepository stores feed subscriptions together with the keys of
+// the entries last seen in each feed.
+type SubscriptionRepository interface {
+	// Create saves a subscription and the entries its feed had at the time. It
+	// returns ErrAlreadyExists when the user already follows the feed.
+	Create(ctx context.Context, sub Subscription, seen []string) (Subscription, error)
+	Get(ctx context.Context, userID, id string) (Subscription, error)
+	List(ctx context.Context, userID string) ([]Subscription, error)
+	Update(ctx context.Context, userID, id string, input SubscriptionUpdate) (Subscription, error)
+	Delete(ctx context.Context, userID, id string) error
+	// Claim hands the subscription that has been due for polling the longest
+	// at now to the caller, and moves its next poll to leaseUntil so that no
+	// other poller takes it meanwhile. It returns ErrNotFound when none is due
+	// and ErrConflict when another poller claimed it first.
+	Claim(ctx context.Context, now, leaseUntil time.Time) (Subscription, error)
+	// Seen returns the entry keys stored with a subscription.
+	Seen(ctx context.Context, id string) ([]string, error)
+	// SavePoll stores the outcome of a poll. Unless seen is nil it replaces
+	// the subscription's entry keys.
+	SavePoll(ctx context.Context, sub Subscription, seen []string) (Subscription, error)
 }
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func (r *LinkRepo) ListViewEvents(ctx context.Context, userID string, linkIDs []string) ([]apiservice.LinkView, error) {
+	if len(linkIDs) == 0 {
+		return nil, nil
+	}
+	var models []LinkViewModel
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND link_id IN ?", userID, linkIDs).
+		Order("viewed_at, id").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+	out := make([]apiservice.LinkView, 0, len(models))
+	for _, m := range models {
+		out = append(out, apiservice.LinkView{ID: m.ID, LinkID: m.LinkID, ViewedAt: m.ViewedAt})
+	}
+	return out, nil
+}
+
+func (r *LinkRepo) Restore(
+	ctx context.Context,
+	userID string,
+	link apiservice.Link,
+	views []apiservice.LinkView,
+) (apiservice.RestoredLink, error) {
+	var out apiservice.RestoredLink
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		target, err := restoreTarget(tx, userID, &link)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			if err := createRestored(tx, userID, link); err != nil {
+				return err
+			}
+			out = apiservice.RestoredLink{ID: link.ID, Outcome: apiservice.RestoreCreated}
+		} else {
+			out = apiservice.RestoredLink{ID: target.ID, Outcome: apiservice.RestoreMerged}
+			if target.ID == link.ID {
+				out.Outcome = apiservice.RestoreExisting
+			}
+		}
+		if err := attachTags(tx, userID, out.ID, link.Tags); err != nil {
+			return err
+		}
+
+		added, err := restoreViews(tx, userID, out.ID, views)
+		if err != nil {
+			return err
+		}
+		out.Views = len(added)
+		if target == nil || len(added) == 0 {
+			// A created link carries its own counters.
+			return nil
+		}
+		viewedAt := target.ViewedAt
+		for _, v := range added {
+			if viewedAt == nil || v.ViewedAt.After(*viewedAt) {
+				viewedAt = &v.ViewedAt
+			}
+		}
+		return tx.Model(&LinkModel{}).Where("id = ?", target.ID).Updates(map[string]any{
+			"views":     gorm.Expr("views + ?", len(added)),
+			"viewed_at": viewedAt,
+		}).Error
+	})
+	if err != nil {
+		return apiservice.RestoredLink{}, err
+	}
+	return out, nil
+}
+
+// restoreTarget finds the link of the user that a restored link is the same
+// as, first by ID and then by canonical URL. When the ID is free it is kept;
+// when it is missing or belongs to another user a new one is put in link.
+func restoreTarget(tx *gorm.DB, userID string, link *apiservice.Link) (*LinkModel, error) {
+	if link.ID != "" {
+		var existing LinkModel
+		err := tx.Where("id = ?", link.ID).Take(&existing).Error
+		switch {
+		case err == nil && existing.UserID == userID:
+			return &existing, nil
+		case err == nil:
+			link.ID = ""
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	if link.ID == "" {
+		link.ID = uuid.NewString()
+	}
+	if link.CanonicalURL == "" {
+		return nil, nil
+	}
+	existing, err := findByCanonicalURL(tx, userID, link.CanonicalURL)
+	if errors.Is(err, apiservice.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+func createRestored(tx *gorm.DB, userID string, link apiservice.Link) error {
+	meta := link.Metadata
+	return tx.Create(&LinkModel{
+		ID:           link.ID,
+		UserID:       userID,
+		URL:          link.URL,
+		CanonicalURL: link.CanonicalURL,
+		Title:        link.Title,
+		Notes:        link.Notes,
+		Resource:     link.Resource,
+		Source:       link.Source,
+		Metadata: MetadataModel{
+			Description:  meta.Description,
+			SiteName:     meta.SiteName,
+			ImageURL:     meta.ImageURL,
+			CanonicalURL: meta.CanonicalURL,
+			Language:     meta.Language,
+			FaviconURL:   meta.FaviconURL,
+			Type:         meta.Type,
+			ContentType:  meta.ContentType,
+			FetchedAt:    meta.FetchedAt,
+		},
+		Status:     link.Status,
+		ReadingAt:  link.ReadingAt,
+		DoneAt:     link.DoneAt,
+		ArchivedAt: link.ArchivedAt,
+		Views:      link.Views,
+		ViewedAt:   link.ViewedAt,
+		CreatedAt:  link.CreatedAt,
+		UpdatedAt:  link.UpdatedAt,
+	}).Error
+}
+
+// restoreViews saves the view events the user does not have yet and returns
+// them. Events whose ID belongs to another user are saved under a new ID.
+func restoreViews(tx *gorm.DB, userID, linkID string, views []apiservice.LinkView) ([]apiservice.LinkView, error) {
+	if len(views) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(views))
+	for _, v := range views {
+		if v.ID != "" {
+			ids = append(ids, v.ID)
+		}
+	}
+	var existing []LinkViewModel
+	if len(ids) > 0 {
+		if err := tx.Select("id", "user_id").Where("id IN ?", ids).Find(&existing).Error; err != nil {
+			return nil, err
+		}
+	}
+	owners := make(map[string]string, len(existing))
+	for _, m := range existing {
+		owners[m.ID] = m.UserID
+	}
+
+	var added []apiservice.LinkView
+	for _, v := range views {
+		owner, taken := owners[v.ID]
+		if taken && owner == userID {
+			continue
+		}
+		if v.ID == "" || taken {
+			v.ID = uuid.NewString()
+		}
+		if err := tx.Create(&LinkViewModel{ID: v.ID, LinkID: linkID, UserID: userID, ViewedAt: v.ViewedAt}).Error; err != nil {
+			return nil, err
+		}
+		owners[v.ID] = userID
+		v.LinkID = linkID
+		added = append(added, v)
+	}
+	return added, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func TestLinkRepo_Restore_Idempotent(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	viewedAt := created.Add(time.Hour)
+
+	link := apiservice.Link{
+		ID:           uuid.NewString(),
+		URL:          "https://go.dev/",
+		CanonicalURL: "https://go.dev/",
+		Title:        "Go",
+		Tags:         []string{"golang"},
+		Status:       apiservice.StatusDone,
+		DoneAt:       &viewedAt,
+		Views:        1,
+		ViewedAt:     &viewedAt,
+		CreatedAt:    created,
+		UpdatedAt:    viewedAt,
+	}
+	views := []apiservice.LinkView{{ID: uuid.NewString(), ViewedAt: viewedAt}}
+
+	first, err := repo.Restore(ctx, owner, link, views)
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.RestoredLink{ID: link.ID, Outcome: apiservice.RestoreCreated, Views: 1}, first)
+
+	second, err := repo.Restore(ctx, owner, link, views)
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.RestoredLink{ID: link.ID, Outcome: apiservice.RestoreExisting}, second)
+
+	got, err := repo.GetByID(ctx, owner, link.ID)
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.StatusDone, got.Status)
+	assert.Equal(t, int64(1), got.Views)
+	assert.Equal(t, []string{"golang"}, got.Tags)
+	assert.True(t, got.CreatedAt.Equal(created))
+
+	events, err := repo.ListViewEvents(ctx, owner, []string{link.ID})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, views[0].ID, events[0].ID)
+}
+
+func TestLinkRepo_Restore_MergesByCanonicalURL(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	saved, err := repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID:       owner,
+		URL:          "https://go.dev",
+		CanonicalURL: "https://go.dev/",
+		Tags:         []string{"later"},
+	})
+	require.NoError(t, err)
+
+	viewedAt := time.Now().Add(-time.Minute).UTC()
+	restored, err := repo.Restore(ctx, owner, apiservice.Link{
+		ID:           uuid.NewString(),
+		URL:          "https://go.dev/",
+		CanonicalURL: "https://go.dev/",
+		Tags:         []string{"golang"},
+		Status:       apiservice.StatusUnread,
+		CreatedAt:    time.Now(),
+	}, []apiservice.LinkView{{ID: uuid.NewString(), ViewedAt: viewedAt}})
+	require.NoError(t, err)
+	assert.Equal(t, saved.ID, restored.ID)
+	assert.Equal(t, apiservice.RestoreMerged, restored.Outcome)
+	assert.Equal(t, 1, restored.Views)
+
+	got, err := repo.GetByID(ctx, owner, saved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang", "later"}, got.Tags)
+	assert.Equal(t, int64(1), got.Views)
+	require.NotNil(t, got.ViewedAt)
+	assert.True(t, got.ViewedAt.Equal(viewedAt))
+}
+
+func TestLinkRepo_Restore_IDOfAnotherUser(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	alice, bob := uuid.NewString(), uuid.NewString()
+
+	theirs, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: alice, URL: "https://example.com"})
+	require.NoError(t, err)
+
+	restored, err := repo.Restore(ctx, bob, apiservice.Link{
+		ID:        theirs.ID,
+		URL:       "https://example.com",
+		Status:    apiservice.StatusUnread,
+		CreatedAt: time.Now(),
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.RestoreCreated, restored.Outcome)
+	assert.NotEqual(t, theirs.ID, restored.ID)
+
+	_, err = repo.GetByID(ctx, bob, restored.ID)
+	assert.NoError(t, err)
+	_, err = repo.GetByID(ctx, alice, theirs.ID)
+	assert.NoError(t, err)
+}
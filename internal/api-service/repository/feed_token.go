@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type FeedTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewFeedTokenRepo(db *gorm.DB) *FeedTokenRepo {
+	return &FeedTokenRepo{db: db}
+}
+
+// FeedTokenModel is the feed token of a user. Only a hash of the token is
+// kept; a user has at most one token at a time.
+type FeedTokenModel struct {
+	UserID    string    `gorm:"type:uuid;primaryKey"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (FeedTokenModel) TableName() string {
+	return "feed_tokens"
+}
+
+func (r *FeedTokenRepo) Set(ctx context.Context, userID, tokenHash string) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "created_at"}),
+	}).Create(&FeedTokenModel{UserID: userID, TokenHash: tokenHash, CreatedAt: time.Now()}).Error
+}
+
+func (r *FeedTokenRepo) Delete(ctx context.Context, userID string) error {
+	res := r.db.WithContext(ctx).Delete(&FeedTokenModel{}, "user_id = ?", userID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apiservice.ErrNotFound
+	}
+	return nil
+}
+
+func (r *FeedTokenRepo) UserID(ctx context.Context, tokenHash string) (string, error) {
+	var model FeedTokenModel
+	if err := r.db.WithContext(ctx).Take(&model, "token_hash = ?", tokenHash).Error; err != nil {
+		return "", mapErr(err)
+	}
+	return model.UserID, nil
+}
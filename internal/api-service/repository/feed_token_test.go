@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func TestFeedTokenRepo(t *testing.T) {
+	repo := NewFeedTokenRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	require.NoError(t, repo.Set(ctx, owner, "first"))
+	got, err := repo.UserID(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, owner, got)
+
+	// Setting a new token replaces the old one.
+	require.NoError(t, repo.Set(ctx, owner, "second"))
+	_, err = repo.UserID(ctx, "first")
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+	got, err = repo.UserID(ctx, "second")
+	require.NoError(t, err)
+	assert.Equal(t, owner, got)
+
+	require.NoError(t, repo.Delete(ctx, owner))
+	_, err = repo.UserID(ctx, "second")
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, owner), apiservice.ErrNotFound)
+}
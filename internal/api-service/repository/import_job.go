@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type ImportJobRepo struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepo(db *gorm.DB) *ImportJobRepo {
+	return &ImportJobRepo{db: db}
+}
+
+// ImportJobModel is a background import. Payload holds the uploaded file
+// until the job finishes; Attempt is bumped on every claim, so that a worker
+// whose job was taken over cannot overwrite the new worker's progress.
+type ImportJobModel struct {
+	ID         string                   `gorm:"type:uuid;primaryKey"`
+	UserID     string                   `gorm:"type:uuid;not null;index"`
+	Format     string                   `gorm:"not null"`
+	Status     string                   `gorm:"not null;index"`
+	Payload    []byte                   `gorm:"default:null"`
+	Total      int                      `gorm:"not null;default:0"`
+	Processed  int                      `gorm:"not null;default:0"`
+	Created    int                      `gorm:"not null;default:0"`
+	Duplicates int                      `gorm:"not null;default:0"`
+	Invalid    int                      `gorm:"not null;default:0"`
+	Errors     []apiservice.ImportError `gorm:"type:text;serializer:json"`
+	Error      string                   `gorm:"not null;default:''"`
+	Attempt    int                      `gorm:"not null;default:0"`
+	CreatedAt  time.Time                `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time                `gorm:"autoUpdateTime"`
+	StartedAt  *time.Time               `gorm:"default:null"`
+	FinishedAt *time.Time               `gorm:"default:null"`
+}
+
+func (ImportJobModel) TableName() string {
+	return "import_jobs"
+}
+
+// jobColumns are the columns read back for a job; the payload is only loaded on claim.
+var jobColumns = []string{
+	"id", "user_id", "format", "status", "total", "processed", "created", "duplicates", "invalid",
+	"errors", "error", "attempt", "created_at", "updated_at", "started_at", "finished_at",
+}
+
+func (r *ImportJobRepo) Create(ctx context.Context, job apiservice.ImportJob, payload []byte) (apiservice.ImportJob, error) {
+	model := ImportJobModel{
+		ID:      uuid.NewString(),
+		UserID:  job.UserID,
+		Format:  job.Format,
+		Status:  apiservice.JobQueued,
+		Payload: payload,
+		Total:   job.Total,
+		Errors:  []apiservice.ImportError{},
+	}
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return apiservice.ImportJob{}, err
+	}
+	return toImportJob(model), nil
+}
+
+func (r *ImportJobRepo) Get(ctx context.Context, userID, id string) (apiservice.ImportJob, error) {
+	var model ImportJobModel
+	err := r.db.WithContext(ctx).
+		Select(jobColumns).
+		Where("user_id = ? AND id = ?", userID, id).
+		Take(&model).Error
+	if err != nil {
+		return apiservice.ImportJob{}, mapErr(err)
+	}
+	return toImportJob(model), nil
+}
+
+func (r *ImportJobRepo) List(ctx context.Context, userID string, limit int) ([]apiservice.ImportJob, error) {
+	var models []ImportJobModel
+	err := r.db.WithContext(ctx).
+		Select(jobColumns).
+		Where("user_id = ?", userID).
+		Order("created_at desc, id desc").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]apiservice.ImportJob, 0, len(models))
+	for _, m := range models {
+		jobs = append(jobs, toImportJob(m))
+	}
+	return jobs, nil
+}
+
+func (r *ImportJobRepo) Claim(ctx context.Context, staleBefore time.Time) (apiservice.ImportJob, []byte, error) {
+	db := r.db.WithContext(ctx)
+	var model ImportJobModel
+	err := db.
+		Where("status = ? OR (status = ? AND updated_at < ?)", apiservice.JobQueued, apiservice.JobRunning, staleBefore).
+		Order("created_at, id").
+		Take(&model).Error
+	if err != nil {
+		return apiservice.ImportJob{}, nil, mapErr(err)
+	}
+
+	now := time.Now()
+	if model.StartedAt == nil {
+		model.StartedAt = &now
+	}
+	res := db.Model(&ImportJobModel{}).
+		Where("id = ? AND attempt = ?", model.ID, model.Attempt).
+		Updates(map[string]any{
+			"status":     apiservice.JobRunning,
+			"attempt":    model.Attempt + 1,
+			"started_at": model.StartedAt,
+			"updated_at": now,
+		})
+	if res.Error != nil {
+		return apiservice.ImportJob{}, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apiservice.ImportJob{}, nil, apiservice.ErrConflict
+	}
+	model.Status = apiservice.JobRunning
+	model.Attempt++
+	model.UpdatedAt = now
+	return toImportJob(model), model.Payload, nil
+}
+
+func (r *ImportJobRepo) Save(ctx context.Context, job apiservice.ImportJob) (apiservice.ImportJob, error) {
+	errs, err := json.Marshal(job.Errors)
+	if err != nil {
+		return apiservice.ImportJob{}, err
+	}
+	now := time.Now()
+	updates := map[string]any{
+		"status":      job.Status,
+		"processed":   job.Processed,
+		"created":     job.Created,
+		"duplicates":  job.Duplicates,
+		"invalid":     job.Invalid,
+		"errors":      string(errs),
+		"error":       job.Error,
+		"finished_at": job.FinishedAt,
+		"updated_at":  now,
+	}
+	if job.Status == apiservice.JobDone || job.Status == apiservice.JobFailed {
+		updates["payload"] = nil
+	}
+	res := r.db.WithContext(ctx).Model(&ImportJobModel{ID: job.ID}).
+		Where("attempt = ?", job.Attempt).
+		Updates(updates)
+	if res.Error != nil {
+		return apiservice.ImportJob{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apiservice.ImportJob{}, apiservice.ErrConflict
+	}
+	job.UpdatedAt = now
+	return job, nil
+}
+
+func toImportJob(m ImportJobModel) apiservice.ImportJob {
+	errs := m.Errors
+	if errs == nil {
+		errs = []apiservice.ImportError{}
+	}
+	return apiservice.ImportJob{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		Format:     m.Format,
+		Status:     m.Status,
+		Total:      m.Total,
+		Processed:  m.Processed,
+		Created:    m.Created,
+		Duplicates: m.Duplicates,
+		Invalid:    m.Invalid,
+		Errors:     errs,
+		Error:      m.Error,
+		Attempt:    m.Attempt,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+		StartedAt:  m.StartedAt,
+		FinishedAt: m.FinishedAt,
+	}
+}
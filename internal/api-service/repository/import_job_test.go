@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func TestImportJobRepo_ClaimAndSave(t *testing.T) {
+	repo := NewImportJobRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	job, err := repo.Create(ctx, apiservice.ImportJob{UserID: owner, Format: "pinboard_json", Total: 3}, []byte("[]"))
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.JobQueued, job.Status)
+
+	claimed, payload, err := repo.Claim(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, claimed.ID)
+	assert.Equal(t, apiservice.JobRunning, claimed.Status)
+	assert.Equal(t, 1, claimed.Attempt)
+	assert.NotNil(t, claimed.StartedAt)
+	assert.Equal(t, []byte("[]"), payload)
+
+	// A running job that is still making progress is not handed out again.
+	_, _, err = repo.Claim(ctx, time.Now().Add(-time.Minute))
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	claimed.Processed, claimed.Invalid = 3, 1
+	claimed.Errors = []apiservice.ImportError{{Row: 2, URL: "ftp://x", Error: "url must be http or https"}}
+	claimed.Status = apiservice.JobDone
+	_, err = repo.Save(ctx, claimed)
+	require.NoError(t, err)
+
+	got, err := repo.Get(ctx, owner, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.JobDone, got.Status)
+	assert.Equal(t, 3, got.Processed)
+	assert.Equal(t, claimed.Errors, got.Errors)
+
+	var model ImportJobModel
+	require.NoError(t, repo.db.Take(&model, "id = ?", job.ID).Error)
+	assert.Nil(t, model.Payload, "the payload is dropped once the job finishes")
+
+	_, err = repo.Get(ctx, uuid.NewString(), job.ID)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
+
+func TestImportJobRepo_StaleJobIsTakenOver(t *testing.T) {
+	repo := NewImportJobRepo(setupTestDB(t))
+	ctx := context.Background()
+
+	_, err := repo.Create(ctx, apiservice.ImportJob{UserID: uuid.NewString(), Format: "netscape"}, []byte("x"))
+	require.NoError(t, err)
+	first, _, err := repo.Claim(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	second, _, err := repo.Claim(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, 2, second.Attempt)
+
+	// The first worker lost the job and may no longer record progress.
+	first.Processed = 1
+	_, err = repo.Save(ctx, first)
+	assert.ErrorIs(t, err, apiservice.ErrConflict)
+}
+
+func TestImportJobRepo_List(t *testing.T) {
+	repo := NewImportJobRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	for i := 0; i < 3; i++ {
+		_, err := repo.Create(ctx, apiservice.ImportJob{UserID: owner, Format: "netscape"}, []byte("x"))
+		require.NoError(t, err)
+	}
+	_, err := repo.Create(ctx, apiservice.ImportJob{UserID: uuid.NewString(), Format: "netscape"}, []byte("x"))
+	require.NoError(t, err)
+
+	jobs, err := repo.List(ctx, owner, 2)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	for _, job := range jobs {
+		assert.Equal(t, owner, job.UserID)
+	}
+}
@@ -3,10 +3,14 @@ package repository
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
 )
@@ -20,116 +24,414 @@ func NewLinkRepo(db *gorm.DB) *LinkRepo {
 }
 
 type LinkModel struct {
-	ID        string     `gorm:"type:uuid;primaryKey"`
-	URL       string     `gorm:"not null"`
-	Resource  string     `gorm:"not null;default:''"`
-	Views     int64      `gorm:"not null;default:0"`
-	ViewedAt  *time.Time `gorm:"default:null"`
-	CreatedAt time.Time  `gorm:"autoCreateTime"`
-	UpdatedAt time.Time  `gorm:"autoUpdateTime"`
+	ID           string        `gorm:"type:uuid;primaryKey"`
+	UserID       string        `gorm:"type:uuid;index;index:idx_link_models_user_status,priority:1;uniqueIndex:uq_link_models_user_canonical_url,priority:1,where:canonical_url <> ''"`
+	URL          string        `gorm:"not null"`
+	CanonicalURL string        `gorm:"not null;default:'';uniqueIndex:uq_link_models_user_canonical_url,priority:2"`
+	Title        string        `gorm:"not null;default:''"`
+	Notes        string        `gorm:"not null;default:''"`
+	Resource     string        `gorm:"not null;default:''"`
+	Source       string        `gorm:"not null;default:''"`
+	Metadata     MetadataModel `gorm:"embedded;embeddedPrefix:meta_"`
+	Status       string        `gorm:"not null;default:'unread';index:idx_link_models_user_status,priority:2"`
+	ReadingAt    *time.Time    `gorm:"default:null"`
+	DoneAt       *time.Time    `gorm:"default:null"`
+	ArchivedAt   *time.Time    `gorm:"default:null"`
+	Views        int64         `gorm:"not null;default:0"`
+	ViewedAt     *time.Time    `gorm:"default:null"`
+	CreatedAt    time.Time     `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time     `gorm:"autoUpdateTime"`
+}
+
+// MetadataModel holds the fetched page metadata of a link, stored in meta_* columns.
+type MetadataModel struct {
+	Description  string     `gorm:"not null;default:''"`
+	SiteName     string     `gorm:"not null;default:''"`
+	ImageURL     string     `gorm:"not null;default:''"`
+	CanonicalURL string     `gorm:"not null;default:''"`
+	Language     string     `gorm:"not null;default:''"`
+	FaviconURL   string     `gorm:"not null;default:''"`
+	Type         string     `gorm:"not null;default:''"`
+	ContentType  string     `gorm:"not null;default:''"`
+	FetchedAt    *time.Time `gorm:"default:null"`
+}
+
+// LinkViewModel records a single view of a link.
+type LinkViewModel struct {
+	ID       string    `gorm:"type:uuid;primaryKey"`
+	LinkID   string    `gorm:"type:uuid;not null;index"`
+	UserID   string    `gorm:"type:uuid;not null;index:idx_link_views_user_viewed_at"`
+	ViewedAt time.Time `gorm:"not null;index:idx_link_views_user_viewed_at"`
+}
+
+func (LinkViewModel) TableName() string {
+	return "link_views"
 }
 
 func (r *LinkRepo) Create(ctx context.Context, input apiservice.LinkCreateInput) (apiservice.Link, error) {
 	model := LinkModel{
-		ID:       uuid.NewString(),
-		URL:      input.URL,
-		Resource: input.Resource,
+		ID:           uuid.NewString(),
+		UserID:       input.UserID,
+		URL:          input.URL,
+		CanonicalURL: input.CanonicalURL,
+		Title:        input.Title,
+		Notes:        input.Notes,
+		Resource:     input.Resource,
+		Source:       input.Source,
+		Status:       apiservice.StatusUnread,
+		CreatedAt:    input.CreatedAt,
 	}
-	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+	var existingID string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if model.CanonicalURL != "" {
+			existing, err := findByCanonicalURL(tx, model.UserID, model.CanonicalURL)
+			if err == nil {
+				existingID = existing.ID
+				return apiservice.ErrAlreadyExists
+			}
+			if !errors.Is(err, apiservice.ErrNotFound) {
+				return err
+			}
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// A concurrent save of the same canonical URL won the race.
+			existing, err := findByCanonicalURL(tx, model.UserID, model.CanonicalURL)
+			if err != nil {
+				return err
+			}
+			existingID = existing.ID
+			return apiservice.ErrAlreadyExists
+		}
+		return attachTags(tx, input.UserID, model.ID, input.Tags)
+	})
+	if errors.Is(err, apiservice.ErrAlreadyExists) {
+		link, err := r.GetByID(ctx, input.UserID, existingID)
+		if err != nil {
+			return apiservice.Link{}, err
+		}
+		return link, apiservice.ErrAlreadyExists
+	}
+	if err != nil {
 		return apiservice.Link{}, err
 	}
-	return toLink(model), nil
+	return r.GetByID(ctx, input.UserID, model.ID)
 }
 
-func (r *LinkRepo) GetByID(ctx context.Context, id string) (apiservice.Link, error) {
+func (r *LinkRepo) GetByID(ctx context.Context, userID, id string) (apiservice.Link, error) {
 	var model LinkModel
-	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+	if err := r.owned(ctx, userID).First(&model, "id = ?", id).Error; err != nil {
 		return apiservice.Link{}, mapErr(err)
 	}
-	return toLink(model), nil
+	links, err := r.loadTags(ctx, []LinkModel{model})
+	if err != nil {
+		return apiservice.Link{}, err
+	}
+	return links[0], nil
 }
 
-func (r *LinkRepo) List(ctx context.Context, limit, offset int) ([]apiservice.Link, error) {
-	var models []LinkModel
-	if err := r.db.WithContext(ctx).
-		Order("created_at desc").
-		Limit(limit).
-		Offset(offset).
-		Find(&models).Error; err != nil {
-		return nil, err
+func (r *LinkRepo) GetByCanonicalURL(ctx context.Context, userID, canonicalURL string) (apiservice.Link, error) {
+	model, err := findByCanonicalURL(r.db.WithContext(ctx), userID, canonicalURL)
+	if err != nil {
+		return apiservice.Link{}, err
 	}
-	out := make([]apiservice.Link, 0, len(models))
-	for _, m := range models {
-		out = append(out, toLink(m))
+	links, err := r.loadTags(ctx, []LinkModel{model})
+	if err != nil {
+		return apiservice.Link{}, err
 	}
-	return out, nil
+	return links[0], nil
 }
 
-func (r *LinkRepo) Random(ctx context.Context, resource string) (apiservice.Link, error) {
+func (r *LinkRepo) Random(ctx context.Context, userID string, filter apiservice.LinkFilter) (apiservice.Link, error) {
 	var model LinkModel
-	q := r.db.WithContext(ctx).Model(&LinkModel{})
-	if resource != "" {
-		q = q.Where("resource = ?", resource)
-	}
-	if err := q.Order("random()").Limit(1).Take(&model).Error; err != nil {
+	if err := r.filtered(ctx, userID, filter).Order("random()").Limit(1).Take(&model).Error; err != nil {
 		return apiservice.Link{}, mapErr(err)
 	}
-	return toLink(model), nil
+	links, err := r.loadTags(ctx, []LinkModel{model})
+	if err != nil {
+		return apiservice.Link{}, err
+	}
+	return links[0], nil
 }
 
-func (r *LinkRepo) Update(ctx context.Context, id string, input apiservice.LinkUpdateInput) (apiservice.Link, error) {
+func (r *LinkRepo) Update(ctx context.Context, userID, id string, input apiservice.LinkUpdateInput) (apiservice.Link, error) {
 	updates := map[string]any{}
 	if input.URL != nil {
 		updates["url"] = *input.URL
 	}
+	if input.CanonicalURL != nil {
+		updates["canonical_url"] = *input.CanonicalURL
+	}
+	if input.Title != nil {
+		updates["title"] = *input.Title
+	}
+	if input.Notes != nil {
+		updates["notes"] = *input.Notes
+	}
 	if input.Resource != nil {
 		updates["resource"] = *input.Resource
 	}
-	if len(updates) > 0 {
-		res := r.db.WithContext(ctx).
-			Model(&LinkModel{}).
-			Where("id = ?", id).
-			Updates(updates)
+	if len(input.AddTags) > 0 || len(input.RemoveTags) > 0 {
+		updates["updated_at"] = time.Now()
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if input.CanonicalURL != nil && *input.CanonicalURL != "" {
+			existing, err := findByCanonicalURL(tx, userID, *input.CanonicalURL)
+			if err == nil && existing.ID != id {
+				return apiservice.ErrAlreadyExists
+			}
+			if err != nil && !errors.Is(err, apiservice.ErrNotFound) {
+				return err
+			}
+		}
+		if len(updates) > 0 {
+			res := tx.Model(&LinkModel{}).
+				Where("user_id = ? AND id = ?", userID, id).
+				Updates(updates)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return apiservice.ErrNotFound
+			}
+		}
+		if err := detachTags(tx, userID, id, input.RemoveTags); err != nil {
+			return err
+		}
+		return attachTags(tx, userID, id, input.AddTags)
+	})
+	if err != nil {
+		return apiservice.Link{}, err
+	}
+	return r.GetByID(ctx, userID, id)
+}
+
+func (r *LinkRepo) Delete(ctx context.Context, userID, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("user_id = ?", userID).Delete(&LinkModel{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apiservice.ErrNotFound
+		}
+		if err := tx.Delete(&LinkTagModel{}, "link_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&LinkViewModel{}, "link_id = ?", id).Error
+	})
+}
+
+// MarkViewed bumps the link's view counters and records the view as an event,
+// so that view history and stats survive re-reads of the same link. Viewing
+// an unread link marks it done; other statuses are left alone.
+func (r *LinkRepo) MarkViewed(ctx context.Context, userID, id string) (apiservice.Link, error) {
+	now := time.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&LinkModel{}).
+			Where("user_id = ? AND id = ?", userID, id).
+			Updates(map[string]any{
+				"views":     gorm.Expr("views + 1"),
+				"viewed_at": &now,
+				"status":    gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END", apiservice.StatusUnread, apiservice.StatusDone),
+				"done_at":   gorm.Expr("CASE WHEN status = ? THEN ? ELSE done_at END", apiservice.StatusUnread, now),
+			})
 		if res.Error != nil {
-			return apiservice.Link{}, res.Error
+			return res.Error
 		}
 		if res.RowsAffected == 0 {
-			return apiservice.Link{}, apiservice.ErrNotFound
+			return apiservice.ErrNotFound
 		}
+		return tx.Create(&LinkViewModel{
+			ID:       uuid.NewString(),
+			LinkID:   id,
+			UserID:   userID,
+			ViewedAt: now,
+		}).Error
+	})
+	if err != nil {
+		return apiservice.Link{}, err
 	}
-	return r.GetByID(ctx, id)
+	return r.GetByID(ctx, userID, id)
 }
 
-func (r *LinkRepo) Delete(ctx context.Context, id string) error {
-	res := r.db.WithContext(ctx).Delete(&LinkModel{}, "id = ?", id)
+// statusColumns holds the timestamp column stamped when a link enters a status.
+var statusColumns = map[string]string{
+	apiservice.StatusReading:  "reading_at",
+	apiservice.StatusDone:     "done_at",
+	apiservice.StatusArchived: "archived_at",
+}
+
+func (r *LinkRepo) SetStatus(ctx context.Context, userID, id, from, to string) (apiservice.Link, error) {
+	updates := map[string]any{"status": to}
+	if column, ok := statusColumns[to]; ok {
+		updates[column] = time.Now()
+	}
+	res := r.db.WithContext(ctx).
+		Model(&LinkModel{}).
+		Where("user_id = ? AND id = ? AND status = ?", userID, id, from).
+		Updates(updates)
 	if res.Error != nil {
-		return res.Error
+		return apiservice.Link{}, res.Error
 	}
 	if res.RowsAffected == 0 {
-		return apiservice.ErrNotFound
+		if _, err := r.GetByID(ctx, userID, id); err != nil {
+			return apiservice.Link{}, err
+		}
+		return apiservice.Link{}, apiservice.ErrConflict
+	}
+	return r.GetByID(ctx, userID, id)
+}
+
+func (r *LinkRepo) ListViews(ctx context.Context, userID, id string, limit, offset int) ([]apiservice.LinkView, error) {
+	if _, err := r.GetByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	var models []LinkViewModel
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND link_id = ?", userID, id).
+		Order("viewed_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error; err != nil {
+		return nil, err
 	}
-	return nil
+	out := make([]apiservice.LinkView, 0, len(models))
+	for _, m := range models {
+		out = append(out, apiservice.LinkView{
+			ID:       m.ID,
+			LinkID:   m.LinkID,
+			ViewedAt: m.ViewedAt,
+		})
+	}
+	return out, nil
 }
 
-func (r *LinkRepo) MarkViewed(ctx context.Context, id string) (apiservice.Link, error) {
+func (r *LinkRepo) SaveMetadata(ctx context.Context, userID, id string, page apiservice.PageMetadata) (apiservice.Link, error) {
 	now := time.Now()
+	meta := page.Metadata
+	meta.FetchedAt = &now
+	updates := metadataColumns(meta)
+	if page.Title != "" {
+		updates["title"] = gorm.Expr("CASE WHEN title = '' THEN ? ELSE title END", page.Title)
+	}
 	res := r.db.WithContext(ctx).
 		Model(&LinkModel{}).
-		Where("id = ?", id).
-		Updates(map[string]any{
-			"views":     gorm.Expr("views + 1"),
-			"viewed_at": &now,
-		})
+		Where("user_id = ? AND id = ?", userID, id).
+		Updates(updates)
 	if res.Error != nil {
 		return apiservice.Link{}, res.Error
 	}
 	if res.RowsAffected == 0 {
 		return apiservice.Link{}, apiservice.ErrNotFound
 	}
-	return r.GetByID(ctx, id)
+	return r.GetByID(ctx, userID, id)
 }
 
-func (r *LinkRepo) GetViewStats(ctx context.Context, days int) ([]apiservice.ViewStats, error) {
+func (r *LinkRepo) ListBatch(ctx context.Context, userID string, unclassified bool, afterID string, limit int) ([]apiservice.Link, error) {
+	q := r.db.WithContext(ctx).Model(&LinkModel{})
+	if userID != "" {
+		q = q.Where("user_id = ?", userID)
+	}
+	if unclassified {
+		q = q.Where("resource = ''")
+	}
+	if afterID != "" {
+		q = q.Where("id > ?", afterID)
+	}
+	var models []LinkModel
+	if err := q.Order("id").Limit(limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return r.loadTags(ctx, models)
+}
+
+func (r *LinkRepo) MergeLinks(
+	ctx context.Context,
+	userID, keepID string,
+	duplicateIDs []string,
+	canonicalURL string,
+) (apiservice.Link, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var keep LinkModel
+		if err := tx.Where("user_id = ? AND id = ?", userID, keepID).First(&keep).Error; err != nil {
+			return mapErr(err)
+		}
+		var duplicates []LinkModel
+		if len(duplicateIDs) > 0 {
+			if err := tx.Where("user_id = ? AND id IN ? AND id <> ?", userID, duplicateIDs, keepID).
+				Order("created_at").
+				Find(&duplicates).Error; err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]any{"canonical_url": canonicalURL}
+		if len(duplicates) > 0 {
+			ids := make([]string, 0, len(duplicates))
+			views, viewedAt := keep.Views, keep.ViewedAt
+			title, resource, meta := keep.Title, keep.Resource, keep.Metadata
+			notes := []string{}
+			if keep.Notes != "" {
+				notes = append(notes, keep.Notes)
+			}
+			for _, d := range duplicates {
+				ids = append(ids, d.ID)
+				views += d.Views
+				if d.ViewedAt != nil && (viewedAt == nil || d.ViewedAt.After(*viewedAt)) {
+					viewedAt = d.ViewedAt
+				}
+				if title == "" {
+					title = d.Title
+				}
+				if resource == "" {
+					resource = d.Resource
+				}
+				if meta.FetchedAt == nil && d.Metadata.FetchedAt != nil {
+					meta = d.Metadata
+				}
+				if d.Notes != "" && !slices.Contains(notes, d.Notes) {
+					notes = append(notes, d.Notes)
+				}
+			}
+			updates["views"] = views
+			updates["viewed_at"] = viewedAt
+			updates["title"] = title
+			updates["notes"] = strings.Join(notes, "\n\n")
+			updates["resource"] = resource
+			maps.Copy(updates, metadataColumns(toLinkMetadata(meta)))
+
+			if err := tx.Model(&LinkViewModel{}).Where("link_id IN ?", ids).Update("link_id", keepID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(
+				`INSERT INTO link_tags (link_id, tag_id)
+				 SELECT ?, tag_id FROM link_tags WHERE link_id IN ?
+				 ON CONFLICT DO NOTHING`,
+				keepID, ids,
+			).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&LinkTagModel{}, "link_id IN ?", ids).Error; err != nil {
+				return err
+			}
+			// Duplicates go first so the kept link can take over their canonical URL.
+			if err := tx.Delete(&LinkModel{}, "id IN ?", ids).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&LinkModel{}).Where("id = ?", keepID).Updates(updates).Error
+	})
+	if err != nil {
+		return apiservice.Link{}, err
+	}
+	return r.GetByID(ctx, userID, keepID)
+}
+
+func (r *LinkRepo) GetViewStats(ctx context.Context, userID string, filter apiservice.LinkFilter, days int) ([]apiservice.ViewStats, error) {
 	if days <= 0 {
 		days = 53
 	}
@@ -143,12 +445,17 @@ func (r *LinkRepo) GetViewStats(ctx context.Context, days int) ([]apiservice.Vie
 	}
 	var results []resultRow
 	startDate := time.Now().AddDate(0, 0, -days+1).Truncate(24 * time.Hour)
-	err := r.db.WithContext(ctx).
-		Model(&LinkModel{}).
-		Select("DATE(viewed_at)::text as date, COUNT(*)::bigint as count").
-		Where("viewed_at IS NOT NULL").
-		Where("viewed_at >= ?", startDate).
-		Group("DATE(viewed_at)").
+	day := r.dayExpr("viewed_at")
+	q := r.db.WithContext(ctx).
+		Model(&LinkViewModel{}).
+		Select(day+" as date, COUNT(*) as count").
+		Where("user_id = ?", userID).
+		Where("viewed_at >= ?", startDate)
+	if filter.Resource != "" || len(filter.Tags) > 0 || len(filter.Statuses) > 0 {
+		q = q.Where("link_id IN (?)", r.filtered(ctx, userID, filter).Select("id"))
+	}
+	err := q.
+		Group(day).
 		Order("date ASC").
 		Scan(&results).Error
 
@@ -199,6 +506,72 @@ func (r *LinkRepo) GetViewStats(ctx context.Context, days int) ([]apiservice.Vie
 	return stats, nil
 }
 
+// dayExpr renders a timestamp column as a YYYY-MM-DD string in the current SQL dialect.
+func (r *LinkRepo) dayExpr(column string) string {
+	if r.db.Dialector.Name() == "sqlite" {
+		return "strftime('%Y-%m-%d', " + column + ")"
+	}
+	return "to_char(" + column + ", 'YYYY-MM-DD')"
+}
+
+func findByCanonicalURL(tx *gorm.DB, userID, canonicalURL string) (LinkModel, error) {
+	var model LinkModel
+	if err := tx.Where("user_id = ? AND canonical_url = ?", userID, canonicalURL).First(&model).Error; err != nil {
+		return LinkModel{}, mapErr(err)
+	}
+	return model, nil
+}
+
+func metadataColumns(meta apiservice.LinkMetadata) map[string]any {
+	return map[string]any{
+		"meta_description":   meta.Description,
+		"meta_site_name":     meta.SiteName,
+		"meta_image_url":     meta.ImageURL,
+		"meta_canonical_url": meta.CanonicalURL,
+		"meta_language":      meta.Language,
+		"meta_favicon_url":   meta.FaviconURL,
+		"meta_type":          meta.Type,
+		"meta_content_type":  meta.ContentType,
+		"meta_fetched_at":    meta.FetchedAt,
+	}
+}
+
+// filtered scopes a query to the links of a user matching the filter.
+func (r *LinkRepo) filtered(ctx context.Context, userID string, filter apiservice.LinkFilter) *gorm.DB {
+	q := r.owned(ctx, userID)
+	if filter.Resource != "" {
+		q = q.Where("resource = ?", filter.Resource)
+	}
+	if len(filter.Statuses) > 0 {
+		q = q.Where("status IN ?", filter.Statuses)
+	}
+	return withTags(q, userID, "id", filter.Tags)
+}
+
+// loadTags converts models to links with their tags loaded.
+func (r *LinkRepo) loadTags(ctx context.Context, models []LinkModel) ([]apiservice.Link, error) {
+	ids := make([]string, 0, len(models))
+	for _, m := range models {
+		ids = append(ids, m.ID)
+	}
+	tags, err := tagsByLink(r.db.WithContext(ctx), ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]apiservice.Link, 0, len(models))
+	for _, m := range models {
+		link := toLink(m)
+		link.Tags = tags[m.ID]
+		out = append(out, link)
+	}
+	return out, nil
+}
+
+// owned scopes a query to the links of a single user.
+func (r *LinkRepo) owned(ctx context.Context, userID string) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&LinkModel{}).Where("user_id = ?", userID)
+}
+
 func mapErr(err error) error {
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return apiservice.ErrNotFound
@@ -208,12 +581,36 @@ func mapErr(err error) error {
 
 func toLink(m LinkModel) apiservice.Link {
 	return apiservice.Link{
-		ID:        m.ID,
-		URL:       m.URL,
-		Resource:  m.Resource,
-		Views:     m.Views,
-		ViewedAt:  m.ViewedAt,
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
+		ID:           m.ID,
+		UserID:       m.UserID,
+		URL:          m.URL,
+		CanonicalURL: m.CanonicalURL,
+		Title:        m.Title,
+		Notes:        m.Notes,
+		Resource:     m.Resource,
+		Source:       m.Source,
+		Metadata:     toLinkMetadata(m.Metadata),
+		Status:       m.Status,
+		ReadingAt:    m.ReadingAt,
+		DoneAt:       m.DoneAt,
+		ArchivedAt:   m.ArchivedAt,
+		Views:        m.Views,
+		ViewedAt:     m.ViewedAt,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+func toLinkMetadata(m MetadataModel) apiservice.LinkMetadata {
+	return apiservice.LinkMetadata{
+		Description:  m.Description,
+		SiteName:     m.SiteName,
+		ImageURL:     m.ImageURL,
+		CanonicalURL: m.CanonicalURL,
+		Language:     m.Language,
+		FaviconURL:   m.FaviconURL,
+		Type:         m.Type,
+		ContentType:  m.ContentType,
+		FetchedAt:    m.FetchedAt,
 	}
 }
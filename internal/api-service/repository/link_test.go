@@ -0,0 +1,406 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(
+		&LinkModel{}, &LinkViewModel{}, &TagModel{}, &LinkTagModel{},
+		&ImportJobModel{}, &FeedTokenModel{}, &SubscriptionModel{}, &SubscriptionEntryModel{},
+	)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestLinkRepo_Create(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	link, err := repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID:   owner,
+		URL:      "https://example.com",
+		Resource: "article",
+	})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, link.ID)
+	assert.Equal(t, owner, link.UserID)
+	assert.Equal(t, "article", link.Resource)
+}
+
+func TestLinkRepo_List_ScopedToUser(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	alice, bob := uuid.NewString(), uuid.NewString()
+
+	_, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: alice, URL: "https://a1.example"})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{UserID: alice, URL: "https://a2.example"})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{UserID: bob, URL: "https://b1.example"})
+	require.NoError(t, err)
+
+	links, err := repo.List(ctx, alice, apiservice.LinkFilter{}, apiservice.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, links, 2)
+	for _, link := range links {
+		assert.Equal(t, alice, link.UserID)
+	}
+
+	links, err = repo.List(ctx, bob, apiservice.LinkFilter{}, apiservice.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, links, 1)
+}
+
+func TestLinkRepo_CrossUserAccess(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner, stranger := uuid.NewString(), uuid.NewString()
+
+	link, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com", Resource: "video"})
+	require.NoError(t, err)
+
+	_, err = repo.GetByID(ctx, stranger, link.ID)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	_, err = repo.Random(ctx, stranger, apiservice.LinkFilter{})
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	url := "https://evil.example"
+	_, err = repo.Update(ctx, stranger, link.ID, apiservice.LinkUpdateInput{URL: &url})
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	_, err = repo.MarkViewed(ctx, stranger, link.ID)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	err = repo.Delete(ctx, stranger, link.ID)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	found, err := repo.GetByID(ctx, owner, link.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", found.URL)
+	assert.Equal(t, int64(0), found.Views)
+}
+
+func TestLinkRepo_Random_ScopedToUser(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	alice, bob := uuid.NewString(), uuid.NewString()
+
+	_, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: alice, URL: "https://a.example", Resource: "video"})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{UserID: bob, URL: "https://b.example", Resource: "article"})
+	require.NoError(t, err)
+
+	link, err := repo.Random(ctx, alice, apiservice.LinkFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://a.example", link.URL)
+
+	_, err = repo.Random(ctx, alice, apiservice.LinkFilter{Resource: "article"})
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
+
+func TestLinkRepo_MarkViewed_RecordsEveryView(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	link, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com"})
+	require.NoError(t, err)
+
+	_, err = repo.MarkViewed(ctx, owner, link.ID)
+	require.NoError(t, err)
+	viewed, err := repo.MarkViewed(ctx, owner, link.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), viewed.Views)
+	assert.NotNil(t, viewed.ViewedAt)
+
+	views, err := repo.ListViews(ctx, owner, link.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, link.ID, views[0].LinkID)
+	assert.False(t, views[0].ViewedAt.Before(views[1].ViewedAt))
+
+	_, err = repo.ListViews(ctx, uuid.NewString(), link.ID, 10, 0)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
+
+func TestLinkRepo_MarkViewed_FinishesUnreadLinks(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	unread, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com/unread"})
+	require.NoError(t, err)
+	archived, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com/archived"})
+	require.NoError(t, err)
+	_, err = repo.SetStatus(ctx, owner, archived.ID, apiservice.StatusUnread, apiservice.StatusArchived)
+	require.NoError(t, err)
+
+	viewed, err := repo.MarkViewed(ctx, owner, unread.ID)
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.StatusDone, viewed.Status)
+	assert.NotNil(t, viewed.DoneAt)
+
+	viewed, err = repo.MarkViewed(ctx, owner, archived.ID)
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.StatusArchived, viewed.Status)
+	assert.Nil(t, viewed.DoneAt)
+
+	_, err = repo.Random(ctx, owner, apiservice.LinkFilter{Statuses: []string{apiservice.StatusUnread}})
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
+
+func TestLinkRepo_GetViewStats_CountsEvents(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner, other := uuid.NewString(), uuid.NewString()
+
+	first, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://one.example"})
+	require.NoError(t, err)
+	second, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://two.example"})
+	require.NoError(t, err)
+	foreign, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: other, URL: "https://three.example"})
+	require.NoError(t, err)
+
+	for _, id := range []string{first.ID, first.ID, second.ID} {
+		_, err = repo.MarkViewed(ctx, owner, id)
+		require.NoError(t, err)
+	}
+	_, err = repo.MarkViewed(ctx, other, foreign.ID)
+	require.NoError(t, err)
+
+	stats, err := repo.GetViewStats(ctx, owner, apiservice.LinkFilter{}, 7)
+	require.NoError(t, err)
+	require.Len(t, stats, 7)
+
+	today := stats[len(stats)-1]
+	assert.Equal(t, time.Now().Format("2006-01-02"), today.Date)
+	assert.Equal(t, int64(3), today.Count)
+	assert.Equal(t, 4, today.Level)
+}
+
+func TestLinkRepo_Delete_RemovesViews(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewLinkRepo(db)
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	link, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com"})
+	require.NoError(t, err)
+	_, err = repo.MarkViewed(ctx, owner, link.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(ctx, owner, link.ID))
+
+	var count int64
+	require.NoError(t, db.Model(&LinkViewModel{}).Where("link_id = ?", link.ID).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestLinkRepo_SaveMetadata(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	untitled, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com/a"})
+	require.NoError(t, err)
+	titled, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com/b", Title: "Mine"})
+	require.NoError(t, err)
+	assert.Nil(t, untitled.Metadata.FetchedAt)
+
+	page := apiservice.PageMetadata{
+		Title: "From the page",
+		Metadata: apiservice.LinkMetadata{
+			Description: "About things",
+			SiteName:    "Example",
+			FaviconURL:  "https://example.com/favicon.ico",
+			ContentType: "text/html",
+		},
+	}
+
+	link, err := repo.SaveMetadata(ctx, owner, untitled.ID, page)
+	require.NoError(t, err)
+	assert.Equal(t, "From the page", link.Title)
+	assert.Equal(t, "About things", link.Metadata.Description)
+	assert.Equal(t, "Example", link.Metadata.SiteName)
+	assert.NotNil(t, link.Metadata.FetchedAt)
+
+	link, err = repo.SaveMetadata(ctx, owner, titled.ID, page)
+	require.NoError(t, err)
+	assert.Equal(t, "Mine", link.Title, "a title set by the user is kept")
+	assert.Equal(t, "About things", link.Metadata.Description)
+
+	_, err = repo.SaveMetadata(ctx, uuid.NewString(), titled.ID, page)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
+
+func TestLinkRepo_ListBatch(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	alice, bob := uuid.NewString(), uuid.NewString()
+
+	for _, input := range []apiservice.LinkCreateInput{
+		{UserID: alice, URL: "https://a1.example"},
+		{UserID: alice, URL: "https://a2.example", Resource: "video"},
+		{UserID: bob, URL: "https://b1.example"},
+	} {
+		_, err := repo.Create(ctx, input)
+		require.NoError(t, err)
+	}
+
+	first, err := repo.ListBatch(ctx, "", false, "", 2)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	rest, err := repo.ListBatch(ctx, "", false, first[1].ID, 2)
+	require.NoError(t, err)
+	assert.Len(t, rest, 1)
+	assert.Greater(t, rest[0].ID, first[1].ID)
+
+	unclassified, err := repo.ListBatch(ctx, alice, true, "", 10)
+	require.NoError(t, err)
+	require.Len(t, unclassified, 1)
+	assert.Equal(t, "https://a1.example", unclassified[0].URL)
+}
+
+func TestLinkRepo_Create_AlreadySaved(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	alice, bob := uuid.NewString(), uuid.NewString()
+	canonical := "https://example.com/post"
+
+	first, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: alice, URL: "https://example.com/post", CanonicalURL: canonical})
+	require.NoError(t, err)
+
+	again, err := repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID:       alice,
+		URL:          "https://www.example.com/post?utm_source=tg",
+		CanonicalURL: canonical,
+		Tags:         []string{"later"},
+	})
+	assert.ErrorIs(t, err, apiservice.ErrAlreadyExists)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Empty(t, again.Tags)
+
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{UserID: bob, URL: "https://example.com/post", CanonicalURL: canonical})
+	assert.NoError(t, err, "another user may save the same URL")
+
+	other, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: alice, URL: "https://example.com/other", CanonicalURL: "https://example.com/other"})
+	require.NoError(t, err)
+	_, err = repo.Update(ctx, alice, other.ID, apiservice.LinkUpdateInput{URL: &first.URL, CanonicalURL: &canonical})
+	assert.ErrorIs(t, err, apiservice.ErrAlreadyExists)
+}
+
+func TestLinkRepo_MergeLinks(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewLinkRepo(db)
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	keep, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com/post", Tags: []string{"go"}})
+	require.NoError(t, err)
+	dup, err := repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID:   owner,
+		URL:      "https://www.example.com/post/",
+		Title:    "Post",
+		Notes:    "read later",
+		Resource: "article",
+		Tags:     []string{"go", "research"},
+	})
+	require.NoError(t, err)
+	_, err = repo.MarkViewed(ctx, owner, keep.ID)
+	require.NoError(t, err)
+	_, err = repo.MarkViewed(ctx, owner, dup.ID)
+	require.NoError(t, err)
+	_, err = repo.MarkViewed(ctx, owner, dup.ID)
+	require.NoError(t, err)
+
+	merged, err := repo.MergeLinks(ctx, owner, keep.ID, []string{dup.ID}, "https://example.com/post")
+	require.NoError(t, err)
+
+	assert.Equal(t, keep.ID, merged.ID)
+	assert.Equal(t, int64(3), merged.Views)
+	assert.Equal(t, "Post", merged.Title)
+	assert.Equal(t, "read later", merged.Notes)
+	assert.Equal(t, "article", merged.Resource)
+	assert.Equal(t, []string{"go", "research"}, merged.Tags)
+	assert.Equal(t, "https://example.com/post", merged.CanonicalURL)
+
+	views, err := repo.ListViews(ctx, owner, keep.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, views, 3)
+
+	_, err = repo.GetByID(ctx, owner, dup.ID)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+	var tagRows int64
+	require.NoError(t, db.Model(&LinkTagModel{}).Where("link_id = ?", dup.ID).Count(&tagRows).Error)
+	assert.Zero(t, tagRows)
+}
+
+func TestLinkRepo_SetStatus(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	link, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.StatusUnread, link.Status)
+
+	link, err = repo.SetStatus(ctx, owner, link.ID, apiservice.StatusUnread, apiservice.StatusReading)
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.StatusReading, link.Status)
+	assert.NotNil(t, link.ReadingAt)
+	assert.Nil(t, link.DoneAt)
+
+	_, err = repo.SetStatus(ctx, owner, link.ID, apiservice.StatusUnread, apiservice.StatusDone)
+	assert.ErrorIs(t, err, apiservice.ErrConflict, "the link is no longer unread")
+
+	_, err = repo.SetStatus(ctx, uuid.NewString(), link.ID, apiservice.StatusReading, apiservice.StatusDone)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	unread, err := repo.List(ctx, owner, apiservice.LinkFilter{Statuses: []string{apiservice.StatusUnread}}, apiservice.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, unread)
+	reading, err := repo.List(ctx, owner, apiservice.LinkFilter{Statuses: []string{apiservice.StatusUnread, apiservice.StatusReading}}, apiservice.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, reading, 1)
+}
+
+func TestLinkRepo_Create_Backdated(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+	added := time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	link, err := repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID:       owner,
+		URL:          "https://example.com/old",
+		CanonicalURL: "https://example.com/old",
+		CreatedAt:    added,
+	})
+	require.NoError(t, err)
+	assert.True(t, added.Equal(link.CreatedAt))
+
+	found, err := repo.GetByCanonicalURL(ctx, owner, "https://example.com/old")
+	require.NoError(t, err)
+	assert.Equal(t, link.ID, found.ID)
+
+	_, err = repo.GetByCanonicalURL(ctx, uuid.NewString(), "https://example.com/old")
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
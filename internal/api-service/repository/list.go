@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// neverViewed stands in for a missing viewed_at, so that links that were never
+// viewed sort after all viewed ones in descending order and can still be paged by key.
+var neverViewed = time.Unix(0, 0).UTC()
+
+// List returns links in keyset order: a page continues strictly after query.After
+// by (sort key, id), so links added or removed between pages do not shift it.
+func (r *LinkRepo) List(
+	ctx context.Context,
+	userID string,
+	filter apiservice.LinkFilter,
+	query apiservice.ListQuery,
+) ([]apiservice.Link, error) {
+	key := sortKey(query.Sort)
+	q := r.filtered(ctx, userID, filter)
+	if query.After != nil {
+		q = after(q, key, query.Desc, sortValue(query.Sort, *query.After), query.After.ID)
+	}
+	direction := " ASC"
+	if query.Desc {
+		direction = " DESC"
+	}
+	var models []LinkModel
+	if err := q.
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                key.SQL + direction + ", id" + direction,
+			Vars:               key.Vars,
+			WithoutParentheses: true,
+		}}).
+		Limit(query.Limit).
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return r.loadTags(ctx, models)
+}
+
+// Count returns how many links match the filter.
+func (r *LinkRepo) Count(ctx context.Context, userID string, filter apiservice.LinkFilter) (int64, error) {
+	var total int64
+	if err := r.filtered(ctx, userID, filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// sortKey is the expression links are ordered by for a sort.
+func sortKey(sort string) clause.Expr {
+	switch sort {
+	case apiservice.SortUpdated:
+		return clause.Expr{SQL: "updated_at"}
+	case apiservice.SortViews:
+		return clause.Expr{SQL: "views"}
+	case apiservice.SortViewed:
+		return clause.Expr{SQL: "COALESCE(viewed_at, ?)", Vars: []any{neverViewed}}
+	case apiservice.SortTitle:
+		return clause.Expr{SQL: "lower(title)"}
+	default:
+		return clause.Expr{SQL: "created_at"}
+	}
+}
+
+// sortValue is the value sortKey has for a link. Titles are lower-cased by
+// the database, the same way the key is.
+func sortValue(sort string, link apiservice.Link) clause.Expr {
+	switch sort {
+	case apiservice.SortUpdated:
+		return clause.Expr{SQL: "?", Vars: []any{link.UpdatedAt}}
+	case apiservice.SortViews:
+		return clause.Expr{SQL: "?", Vars: []any{link.Views}}
+	case apiservice.SortViewed:
+		viewedAt := neverViewed
+		if link.ViewedAt != nil {
+			viewedAt = *link.ViewedAt
+		}
+		return clause.Expr{SQL: "?", Vars: []any{viewedAt}}
+	case apiservice.SortTitle:
+		return clause.Expr{SQL: "lower(?)", Vars: []any{link.Title}}
+	default:
+		return clause.Expr{SQL: "?", Vars: []any{link.CreatedAt}}
+	}
+}
+
+// after restricts q to the links that come after (value, id) in the given order.
+func after(q *gorm.DB, key clause.Expr, desc bool, value clause.Expr, id string) *gorm.DB {
+	op := " > "
+	if desc {
+		op = " < "
+	}
+	vars := make([]any, 0, 2*len(key.Vars)+2*len(value.Vars)+1)
+	vars = append(vars, key.Vars...)
+	vars = append(vars, value.Vars...)
+	vars = append(vars, key.Vars...)
+	vars = append(vars, value.Vars...)
+	vars = append(vars, id)
+	return q.Where(
+		"("+key.SQL+op+value.SQL+" OR ("+key.SQL+" = "+value.SQL+" AND id"+op+"?))",
+		vars...,
+	)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// listAll walks every page of a listing and returns the link IDs in order.
+func listAll(t *testing.T, repo *LinkRepo, owner string, query apiservice.ListQuery) []string {
+	t.Helper()
+	var ids []string
+	for {
+		links, err := repo.List(context.Background(), owner, apiservice.LinkFilter{}, query)
+		require.NoError(t, err)
+		for _, link := range links {
+			ids = append(ids, link.ID)
+		}
+		if len(links) < query.Limit {
+			return ids
+		}
+		last := links[len(links)-1]
+		query.After = &last
+	}
+}
+
+func TestLinkRepo_List_Keyset(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	var created []string
+	for _, title := range []string{"Delta", "alpha", "Charlie", "bravo", "echo"} {
+		link, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://" + title + ".example", Title: title})
+		require.NoError(t, err)
+		created = append(created, link.ID)
+	}
+	_, err := repo.MarkViewed(ctx, owner, created[3])
+	require.NoError(t, err)
+	_, err = repo.MarkViewed(ctx, owner, created[3])
+	require.NoError(t, err)
+	_, err = repo.MarkViewed(ctx, owner, created[1])
+	require.NoError(t, err)
+
+	t.Run("newest first", func(t *testing.T) {
+		ids := listAll(t, repo, owner, apiservice.ListQuery{Sort: apiservice.SortCreated, Desc: true, Limit: 2})
+		assert.Equal(t, []string{created[4], created[3], created[2], created[1], created[0]}, ids)
+	})
+
+	t.Run("title ignores case", func(t *testing.T) {
+		ids := listAll(t, repo, owner, apiservice.ListQuery{Sort: apiservice.SortTitle, Limit: 2})
+		assert.Equal(t, []string{created[1], created[3], created[2], created[0], created[4]}, ids)
+	})
+
+	t.Run("ties on views are paged by id", func(t *testing.T) {
+		ids := listAll(t, repo, owner, apiservice.ListQuery{Sort: apiservice.SortViews, Desc: true, Limit: 2})
+		require.Len(t, ids, 5)
+		assert.Equal(t, []string{created[3], created[1]}, ids[:2])
+		assert.ElementsMatch(t, created, ids)
+	})
+
+	t.Run("never viewed links come last", func(t *testing.T) {
+		ids := listAll(t, repo, owner, apiservice.ListQuery{Sort: apiservice.SortViewed, Desc: true, Limit: 2})
+		require.Len(t, ids, 5)
+		assert.Equal(t, []string{created[1], created[3]}, ids[:2])
+		assert.ElementsMatch(t, created, ids)
+	})
+}
+
+func TestLinkRepo_List_StableWhileAdding(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	for _, host := range []string{"a", "b", "c"} {
+		_, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://" + host + ".example"})
+		require.NoError(t, err)
+	}
+	query := apiservice.ListQuery{Sort: apiservice.SortCreated, Desc: true, Limit: 2}
+	first, err := repo.List(ctx, owner, apiservice.LinkFilter{}, query)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://d.example"})
+	require.NoError(t, err)
+
+	query.After = &first[1]
+	second, err := repo.List(ctx, owner, apiservice.LinkFilter{}, query)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.Equal(t, "https://a.example", second[0].URL)
+
+	total, err := repo.Count(ctx, owner, apiservice.LinkFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+}
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+const (
+	// matchStart and matchStop mark matches in a snippet until it is escaped.
+	// They are stripped from the text first, so a marker is always one of ours.
+	matchStart = "\x02"
+	matchStop  = "\x03"
+	// headlineOptions configures ts_headline to mark matches with matchStart and matchStop.
+	headlineOptions = `StartSel="` + matchStart + `", StopSel="` + matchStop + `", ` +
+		"MaxWords=35, MinWords=15, ShortWord=2, MaxFragments=2, FragmentDelimiter=\" … \""
+	// snippetRadius is how many characters the LIKE fallback keeps around the first match.
+	snippetRadius = 80
+)
+
+type searchRow struct {
+	LinkModel
+	Rank    float64
+	Snippet string
+}
+
+// Search finds links whose URL, title, notes or tags match the query. Postgres uses
+// full-text search; other dialects (SQLite in tests) fall back to LIKE matching.
+func (r *LinkRepo) Search(
+	ctx context.Context,
+	userID, query string,
+	filter apiservice.LinkFilter,
+	limit, offset int,
+) ([]apiservice.SearchResult, error) {
+	if r.db.Dialector.Name() == "postgres" {
+		return r.searchFullText(ctx, userID, query, filter, limit, offset)
+	}
+	return r.searchLike(ctx, userID, query, filter, limit, offset)
+}
+
+func (r *LinkRepo) searchFullText(
+	ctx context.Context,
+	userID, query string,
+	filter apiservice.LinkFilter,
+	limit, offset int,
+) ([]apiservice.SearchResult, error) {
+	var rows []searchRow
+	err := r.filtered(ctx, userID, filter).
+		Select(
+			`link_models.*, ts_rank(d.doc, q.query) AS rank,
+			ts_headline('simple', translate(concat_ws(' ', NULLIF(link_models.title, ''), NULLIF(link_models.notes, ''), link_models.url), ?, ''), q.query, ?) AS snippet`,
+			matchStart+matchStop, headlineOptions,
+		).
+		Joins("CROSS JOIN websearch_to_tsquery('simple', ?) AS q(query)", query).
+		Joins(`CROSS JOIN LATERAL (
+			SELECT link_models.search_vector || to_tsvector('simple', coalesce(string_agg(tags.name, ' '), '')) AS doc
+			FROM link_tags JOIN tags ON tags.id = link_tags.tag_id
+			WHERE link_tags.link_id = link_models.id
+		) AS d`).
+		Where("d.doc @@ q.query").
+		Order("rank DESC, link_models.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toSearchResults(ctx, rows)
+}
+
+func (r *LinkRepo) searchLike(
+	ctx context.Context,
+	userID, query string,
+	filter apiservice.LinkFilter,
+	limit, offset int,
+) ([]apiservice.SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []apiservice.SearchResult{}, nil
+	}
+	q := r.filtered(ctx, userID, filter)
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term) + "%"
+		q = q.Where(
+			`(lower(url) LIKE ? ESCAPE '\' OR lower(title) LIKE ? ESCAPE '\' OR lower(notes) LIKE ? ESCAPE '\' OR EXISTS (
+				SELECT 1 FROM link_tags JOIN tags ON tags.id = link_tags.tag_id
+				WHERE link_tags.link_id = link_models.id AND tags.name LIKE ? ESCAPE '\'
+			))`,
+			pattern, pattern, pattern, pattern,
+		)
+	}
+	var models []LinkModel
+	if err := q.Order("created_at desc").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	links, err := r.loadTags(ctx, models)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]apiservice.SearchResult, 0, len(links))
+	for _, link := range links {
+		results = append(results, apiservice.SearchResult{
+			Link:    link,
+			Rank:    likeRank(link, terms),
+			Snippet: snippetHTML(highlight(snippetSource(link), terms)),
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+
+	if offset >= len(results) {
+		return []apiservice.SearchResult{}, nil
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (r *LinkRepo) toSearchResults(ctx context.Context, rows []searchRow) ([]apiservice.SearchResult, error) {
+	models := make([]LinkModel, 0, len(rows))
+	for _, row := range rows {
+		models = append(models, row.LinkModel)
+	}
+	links, err := r.loadTags(ctx, models)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]apiservice.SearchResult, 0, len(rows))
+	for i, row := range rows {
+		out = append(out, apiservice.SearchResult{
+			Link:    links[i],
+			Rank:    row.Rank,
+			Snippet: snippetHTML(row.Snippet),
+		})
+	}
+	return out, nil
+}
+
+// searchTerms splits a query into lower-case words, dropping quotes and
+// web-search operators that only the full-text implementation understands.
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		field = strings.Trim(field, `"'`)
+		field = strings.TrimPrefix(field, "-")
+		if field == "" || field == "or" {
+			continue
+		}
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// likeRank weighs matches the way the full-text search vector does:
+// title above tags above notes above the URL.
+func likeRank(link apiservice.Link, terms []string) float64 {
+	title := strings.ToLower(link.Title)
+	notes := strings.ToLower(link.Notes)
+	url := strings.ToLower(link.URL)
+	var rank float64
+	for _, term := range terms {
+		switch {
+		case strings.Contains(title, term):
+			rank += 1
+		case hasTag(link.Tags, term):
+			rank += 0.8
+		case strings.Contains(notes, term):
+			rank += 0.4
+		case strings.Contains(url, term):
+			rank += 0.2
+		}
+	}
+	return rank / float64(len(terms))
+}
+
+func hasTag(tags []string, term string) bool {
+	for _, tag := range tags {
+		if strings.Contains(tag, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func snippetSource(link apiservice.Link) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{link.Title, link.Notes, link.URL} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.NewReplacer(matchStart, "", matchStop, "").Replace(strings.Join(parts, " "))
+}
+
+// snippetHTML escapes a snippet marked by highlight or ts_headline for HTML
+// and only then turns the match markers into <b></b>.
+func snippetHTML(marked string) string {
+	return strings.NewReplacer(matchStart, "<b>", matchStop, "</b>").Replace(html.EscapeString(marked))
+}
+
+// highlight cuts a window around the first matched term and marks every
+// match inside it with matchStart and matchStop, mirroring ts_headline's output.
+func highlight(text string, terms []string) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	first := -1
+	for _, term := range terms {
+		if i := indexRunes(lower, []rune(term)); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	start, end := 0, len(runes)
+	if first > snippetRadius {
+		start = first - snippetRadius
+	}
+	if end-start > 2*snippetRadius {
+		end = start + 2*snippetRadius
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		matched := 0
+		for _, term := range terms {
+			t := []rune(term)
+			if n := len(t); n > matched && i+n <= end && indexRunes(lower[i:i+n], t) == 0 {
+				matched = n
+			}
+		}
+		if matched > 0 {
+			b.WriteString(matchStart)
+			b.WriteString(string(runes[i : i+matched]))
+			b.WriteString(matchStop)
+			i += matched
+			continue
+		}
+		b.WriteRune(runes[i])
+		i++
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func indexRunes(haystack, needle []rune) int {
+	if len(needle) == 0 {
+		return -1
+	}
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j, r := range needle {
+			if haystack[i+j] != r {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func TestLinkRepo_Search_Like(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner, other := uuid.NewString(), uuid.NewString()
+
+	_, err := repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID: owner,
+		URL:    "https://go.dev/blog/generics",
+		Title:  "An Introduction To Generics",
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID: owner,
+		URL:    "https://example.com/notes",
+		Notes:  "talks about generics in passing",
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID: owner,
+		URL:    "https://example.com/video",
+		Tags:   []string{"golang"},
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{UserID: other, URL: "https://other.example", Title: "Generics"})
+	require.NoError(t, err)
+
+	results, err := repo.Search(ctx, owner, "GENERICS", apiservice.LinkFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "An Introduction To Generics", results[0].Link.Title)
+	assert.Greater(t, results[0].Rank, results[1].Rank)
+	assert.Contains(t, results[0].Snippet, "<b>Generics</b>")
+
+	results, err = repo.Search(ctx, owner, "golang", apiservice.LinkFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []string{"golang"}, results[0].Link.Tags)
+
+	results, err = repo.Search(ctx, owner, "generics", apiservice.LinkFilter{}, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://example.com/notes", results[0].Link.URL)
+
+	results, err = repo.Search(ctx, owner, "100%", apiservice.LinkFilter{}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestLinkRepo_Search_EscapesSnippet(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	_, err := repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID: owner,
+		URL:    "https://example.com/xss",
+		Title:  `Gophers <script>alert("hi")</script>`,
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID: owner,
+		URL:    "https://example.com/bold",
+		Title:  "Bold </b> rabbits <b>and \x02stray\x03 markers",
+	})
+	require.NoError(t, err)
+
+	results, err := repo.Search(ctx, owner, "gophers", apiservice.LinkFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t,
+		"<b>Gophers</b> &lt;script&gt;alert(&#34;hi&#34;)&lt;/script&gt; https://example.com/xss",
+		results[0].Snippet)
+
+	results, err = repo.Search(ctx, owner, "rabbits", apiservice.LinkFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t,
+		"Bold &lt;/b&gt; <b>rabbits</b> &lt;b&gt;and stray markers https://example.com/bold",
+		results[0].Snippet)
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "Intro to \x02Go\x03 and \x02go\x03lang", highlight("Intro to Go and golang", []string{"go"}))
+	assert.Equal(t, "no match here", highlight("no match here", []string{"zzz"}))
+}
+
+func TestSnippetHTML(t *testing.T) {
+	assert.Equal(t, "<b>a</b> &lt;/b&gt; &amp; <b>b</b>", snippetHTML("\x02a\x03 </b> & \x02b\x03"))
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type SubscriptionRepo struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepo(db *gorm.DB) *SubscriptionRepo {
+	return &SubscriptionRepo{db: db}
+}
+
+// SubscriptionModel is a feed a user follows. Claim bumps Attempt, so that
+// two pollers cannot both claim the same due subscription.
+type SubscriptionModel struct {
+	ID           string     `gorm:"type:uuid;primaryKey"`
+	UserID       string     `gorm:"type:uuid;not null;uniqueIndex:uq_subscriptions_user_feed_url"`
+	FeedURL      string     `gorm:"not null;uniqueIndex:uq_subscriptions_user_feed_url"`
+	SiteURL      string     `gorm:"not null;default:''"`
+	Title        string     `gorm:"not null;default:''"`
+	Tags         []string   `gorm:"type:text;serializer:json"`
+	ETag         string     `gorm:"column:etag;not null;default:''"`
+	LastModified string     `gorm:"not null;default:''"`
+	LastPolledAt *time.Time `gorm:"default:null"`
+	LastError    string     `gorm:"not null;default:''"`
+	ErrorCount   int        `gorm:"not null;default:0"`
+	NextPollAt   time.Time  `gorm:"not null;index"`
+	Attempt      int        `gorm:"not null;default:0"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (SubscriptionModel) TableName() string {
+	return "subscriptions"
+}
+
+// SubscriptionEntryModel is an entry seen in a subscribed feed, by its key.
+type SubscriptionEntryModel struct {
+	SubscriptionID string `gorm:"type:uuid;primaryKey"`
+	EntryKey       string `gorm:"primaryKey"`
+}
+
+func (SubscriptionEntryModel) TableName() string {
+	return "subscription_entries"
+}
+
+func (r *SubscriptionRepo) Create(ctx context.Context, sub apiservice.Subscription, seen []string) (apiservice.Subscription, error) {
+	model := SubscriptionModel{
+		ID:           uuid.NewString(),
+		UserID:       sub.UserID,
+		FeedURL:      sub.FeedURL,
+		SiteURL:      sub.SiteURL,
+		Title:        sub.Title,
+		Tags:         nonNilTags(sub.Tags),
+		ETag:         sub.ETag,
+		LastModified: sub.LastModified,
+		LastPolledAt: sub.LastPolledAt,
+		NextPollAt:   sub.NextPollAt,
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apiservice.ErrAlreadyExists
+		}
+		return replaceEntries(tx, model.ID, seen)
+	})
+	if err != nil {
+		return apiservice.Subscription{}, err
+	}
+	return toSubscription(model), nil
+}
+
+func (r *SubscriptionRepo) Get(ctx context.Context, userID, id string) (apiservice.Subscription, error) {
+	var model SubscriptionModel
+	if err := r.db.WithContext(ctx).Take(&model, "user_id = ? AND id = ?", userID, id).Error; err != nil {
+		return apiservice.Subscription{}, mapErr(err)
+	}
+	return toSubscription(model), nil
+}
+
+func (r *SubscriptionRepo) List(ctx context.Context, userID string) ([]apiservice.Subscription, error) {
+	var models []SubscriptionModel
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at, id").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	subs := make([]apiservice.Subscription, 0, len(models))
+	for _, m := range models {
+		subs = append(subs, toSubscription(m))
+	}
+	return subs, nil
+}
+
+func (r *SubscriptionRepo) Update(
+	ctx context.Context,
+	userID, id string,
+	input apiservice.SubscriptionUpdate,
+) (apiservice.Subscription, error) {
+	var model SubscriptionModel
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Take(&model, "user_id = ? AND id = ?", userID, id).Error; err != nil {
+			return mapErr(err)
+		}
+		if input.Title != nil {
+			model.Title = *input.Title
+		}
+		if input.Tags != nil {
+			model.Tags = nonNilTags(*input.Tags)
+		}
+		return tx.Select("title", "tags", "updated_at").Save(&model).Error
+	})
+	if err != nil {
+		return apiservice.Subscription{}, err
+	}
+	return toSubscription(model), nil
+}
+
+func (r *SubscriptionRepo) Delete(ctx context.Context, userID, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&SubscriptionModel{}, "user_id = ? AND id = ?", userID, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apiservice.ErrNotFound
+		}
+		return tx.Delete(&SubscriptionEntryModel{}, "subscription_id = ?", id).Error
+	})
+}
+
+func (r *SubscriptionRepo) Claim(ctx context.Context, now, leaseUntil time.Time) (apiservice.Subscription, error) {
+	db := r.db.WithContext(ctx)
+	var model SubscriptionModel
+	err := db.
+		Where("next_poll_at <= ?", now).
+		Order("next_poll_at, id").
+		Take(&model).Error
+	if err != nil {
+		return apiservice.Subscription{}, mapErr(err)
+	}
+	res := db.Model(&SubscriptionModel{}).
+		Where("id = ? AND attempt = ?", model.ID, model.Attempt).
+		Updates(map[string]any{
+			"next_poll_at": leaseUntil,
+			"attempt":      model.Attempt + 1,
+		})
+	if res.Error != nil {
+		return apiservice.Subscription{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apiservice.Subscription{}, apiservice.ErrConflict
+	}
+	model.NextPollAt = leaseUntil
+	return toSubscription(model), nil
+}
+
+func (r *SubscriptionRepo) Seen(ctx context.Context, id string) ([]string, error) {
+	var keys []string
+	err := r.db.WithContext(ctx).Model(&SubscriptionEntryModel{}).
+		Where("subscription_id = ?", id).
+		Pluck("entry_key", &keys).Error
+	return keys, err
+}
+
+func (r *SubscriptionRepo) SavePoll(
+	ctx context.Context,
+	sub apiservice.Subscription,
+	seen []string,
+) (apiservice.Subscription, error) {
+	now := time.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&SubscriptionModel{ID: sub.ID}).Updates(map[string]any{
+			"site_url":       sub.SiteURL,
+			"etag":           sub.ETag,
+			"last_modified":  sub.LastModified,
+			"last_polled_at": sub.LastPolledAt,
+			"last_error":     sub.LastError,
+			"error_count":    sub.ErrorCount,
+			"next_poll_at":   sub.NextPollAt,
+			"updated_at":     now,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// The user unsubscribed while the feed was polled.
+			return apiservice.ErrNotFound
+		}
+		if seen == nil {
+			return nil
+		}
+		return replaceEntries(tx, sub.ID, seen)
+	})
+	if err != nil {
+		return apiservice.Subscription{}, err
+	}
+	sub.UpdatedAt = now
+	return sub, nil
+}
+
+// replaceEntries makes keys the entries seen in a subscription's feed.
+func replaceEntries(tx *gorm.DB, subscriptionID string, keys []string) error {
+	if err := tx.Delete(&SubscriptionEntryModel{}, "subscription_id = ?", subscriptionID).Error; err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	entries := make([]SubscriptionEntryModel, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, SubscriptionEntryModel{SubscriptionID: subscriptionID, EntryKey: key})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(entries, 500).Error
+}
+
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func toSubscription(m SubscriptionModel) apiservice.Subscription {
+	return apiservice.Subscription{
+		ID:           m.ID,
+		UserID:       m.UserID,
+		FeedURL:      m.FeedURL,
+		SiteURL:      m.SiteURL,
+		Title:        m.Title,
+		Tags:         nonNilTags(m.Tags),
+		ETag:         m.ETag,
+		LastModified: m.LastModified,
+		LastPolledAt: m.LastPolledAt,
+		LastError:    m.LastError,
+		ErrorCount:   m.ErrorCount,
+		NextPollAt:   m.NextPollAt,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func TestSubscriptionRepo_CRUD(t *testing.T) {
+	repo := NewSubscriptionRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+	input := apiservice.Subscription{
+		UserID:     owner,
+		FeedURL:    "https://example.com/feed.xml",
+		Title:      "Example",
+		Tags:       []string{"news"},
+		NextPollAt: time.Now().Add(time.Hour),
+	}
+
+	sub, err := repo.Create(ctx, input, []string{"a", "b", "a"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, sub.ID)
+
+	_, err = repo.Create(ctx, input, nil)
+	assert.ErrorIs(t, err, apiservice.ErrAlreadyExists)
+
+	seen, err := repo.Seen(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, seen)
+
+	title, tags := "Renamed", []string{"go"}
+	updated, err := repo.Update(ctx, owner, sub.ID, apiservice.SubscriptionUpdate{Title: &title, Tags: &tags})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Title)
+	assert.Equal(t, []string{"go"}, updated.Tags)
+
+	_, err = repo.Get(ctx, uuid.NewString(), sub.ID)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	list, err := repo.List(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "https://example.com/feed.xml", list[0].FeedURL)
+	assert.Equal(t, []string{"go"}, list[0].Tags)
+
+	require.NoError(t, repo.Delete(ctx, owner, sub.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, owner, sub.ID), apiservice.ErrNotFound)
+	seen, err = repo.Seen(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Empty(t, seen)
+}
+
+func TestSubscriptionRepo_ClaimAndSavePoll(t *testing.T) {
+	repo := NewSubscriptionRepo(setupTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	due, err := repo.Create(ctx, apiservice.Subscription{
+		UserID: uuid.NewString(), FeedURL: "https://a.example/feed", NextPollAt: now.Add(-time.Minute),
+	}, []string{"old"})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.Subscription{
+		UserID: uuid.NewString(), FeedURL: "https://b.example/feed", NextPollAt: now.Add(time.Hour),
+	}, nil)
+	require.NoError(t, err)
+
+	claimed, err := repo.Claim(ctx, now, now.Add(5*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, due.ID, claimed.ID)
+
+	// The claimed subscription is leased; the other one is not due yet.
+	_, err = repo.Claim(ctx, now, now.Add(5*time.Minute))
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	claimed.ETag = `"v2"`
+	claimed.LastPolledAt = &now
+	claimed.NextPollAt = now.Add(30 * time.Minute)
+	_, err = repo.SavePoll(ctx, claimed, []string{"new", "old"})
+	require.NoError(t, err)
+
+	got, err := repo.Get(ctx, due.UserID, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, `"v2"`, got.ETag)
+	assert.NotNil(t, got.LastPolledAt)
+	seen, err := repo.Seen(ctx, due.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"new", "old"}, seen)
+
+	// Without keys the seen entries are left alone.
+	claimed.LastError, claimed.ErrorCount = "status 503", 1
+	_, err = repo.SavePoll(ctx, claimed, nil)
+	require.NoError(t, err)
+	seen, err = repo.Seen(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Len(t, seen, 2)
+
+	require.NoError(t, repo.Delete(ctx, due.UserID, due.ID))
+	_, err = repo.SavePoll(ctx, claimed, nil)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type TagRepo struct {
+	db *gorm.DB
+}
+
+func NewTagRepo(db *gorm.DB) *TagRepo {
+	return &TagRepo{db: db}
+}
+
+type TagModel struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	UserID    string    `gorm:"type:uuid;not null;uniqueIndex:uq_tags_user_name"`
+	Name      string    `gorm:"not null;uniqueIndex:uq_tags_user_name"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (TagModel) TableName() string {
+	return "tags"
+}
+
+// LinkTagModel attaches a tag to a link.
+type LinkTagModel struct {
+	LinkID string `gorm:"type:uuid;primaryKey"`
+	TagID  string `gorm:"type:uuid;primaryKey;index"`
+}
+
+func (LinkTagModel) TableName() string {
+	return "link_tags"
+}
+
+func (r *TagRepo) List(ctx context.Context, userID string) ([]apiservice.Tag, error) {
+	var tags []apiservice.Tag
+	err := r.db.WithContext(ctx).
+		Table("tags").
+		Select("tags.name AS name, COUNT(link_tags.link_id) AS count").
+		Joins("LEFT JOIN link_tags ON link_tags.tag_id = tags.id").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id, tags.name").
+		Order("count DESC, name ASC").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Rename gives a tag a new name. Renaming onto a tag that already exists merges the two.
+func (r *TagRepo) Rename(ctx context.Context, userID, from, to string) (apiservice.Tag, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		source, err := findTag(tx, userID, from)
+		if err != nil {
+			return err
+		}
+		if from == to {
+			return nil
+		}
+		target, err := findTag(tx, userID, to)
+		switch {
+		case err == nil:
+			return mergeTag(tx, source, target)
+		case errors.Is(err, apiservice.ErrNotFound):
+			return tx.Model(&TagModel{}).Where("id = ?", source.ID).Update("name", to).Error
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		return apiservice.Tag{}, err
+	}
+	return r.get(ctx, userID, to)
+}
+
+// Merge folds every source tag into the target tag, creating the target when needed.
+func (r *TagRepo) Merge(ctx context.Context, userID string, sources []string, into string) (apiservice.Tag, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var found []TagModel
+		if err := tx.Where("user_id = ? AND name IN ?", userID, sources).Find(&found).Error; err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return apiservice.ErrNotFound
+		}
+		target, err := ensureTag(tx, userID, into)
+		if err != nil {
+			return err
+		}
+		for _, source := range found {
+			if source.ID == target.ID {
+				continue
+			}
+			if err := mergeTag(tx, source, target); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return apiservice.Tag{}, err
+	}
+	return r.get(ctx, userID, into)
+}
+
+func (r *TagRepo) get(ctx context.Context, userID, name string) (apiservice.Tag, error) {
+	var tag apiservice.Tag
+	res := r.db.WithContext(ctx).
+		Table("tags").
+		Select("tags.name AS name, COUNT(link_tags.link_id) AS count").
+		Joins("LEFT JOIN link_tags ON link_tags.tag_id = tags.id").
+		Where("tags.user_id = ? AND tags.name = ?", userID, name).
+		Group("tags.id, tags.name").
+		Scan(&tag)
+	if res.Error != nil {
+		return apiservice.Tag{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apiservice.Tag{}, apiservice.ErrNotFound
+	}
+	return tag, nil
+}
+
+func findTag(tx *gorm.DB, userID, name string) (TagModel, error) {
+	var tag TagModel
+	if err := tx.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error; err != nil {
+		return TagModel{}, mapErr(err)
+	}
+	return tag, nil
+}
+
+func ensureTag(tx *gorm.DB, userID, name string) (TagModel, error) {
+	tag := TagModel{ID: uuid.NewString(), UserID: userID, Name: name}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "name"}},
+		DoNothing: true,
+	}).Create(&tag).Error
+	if err != nil {
+		return TagModel{}, err
+	}
+	return findTag(tx, userID, name)
+}
+
+// mergeTag moves every link of source onto target and drops source.
+func mergeTag(tx *gorm.DB, source, target TagModel) error {
+	if err := tx.Exec(
+		`INSERT INTO link_tags (link_id, tag_id)
+		 SELECT link_id, ? FROM link_tags WHERE tag_id = ?
+		 ON CONFLICT DO NOTHING`,
+		target.ID, source.ID,
+	).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&LinkTagModel{}, "tag_id = ?", source.ID).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&TagModel{}, "id = ?", source.ID).Error
+}
+
+// attachTags links the named tags to a link, creating missing tags on the fly.
+func attachTags(tx *gorm.DB, userID, linkID string, names []string) error {
+	for _, name := range names {
+		tag, err := ensureTag(tx, userID, name)
+		if err != nil {
+			return err
+		}
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&LinkTagModel{LinkID: linkID, TagID: tag.ID}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func detachTags(tx *gorm.DB, userID, linkID string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	return tx.Where(
+		"link_id = ? AND tag_id IN (?)",
+		linkID,
+		tx.Model(&TagModel{}).Select("id").Where("user_id = ? AND name IN ?", userID, names),
+	).Delete(&LinkTagModel{}).Error
+}
+
+// tagsByLink loads tag names for the given links, keyed by link id.
+func tagsByLink(tx *gorm.DB, linkIDs []string) (map[string][]string, error) {
+	out := make(map[string][]string, len(linkIDs))
+	if len(linkIDs) == 0 {
+		return out, nil
+	}
+	type row struct {
+		LinkID string
+		Name   string
+	}
+	var rows []row
+	err := tx.Table("link_tags").
+		Select("link_tags.link_id AS link_id, tags.name AS name").
+		Joins("JOIN tags ON tags.id = link_tags.tag_id").
+		Where("link_tags.link_id IN ?", linkIDs).
+		Order("tags.name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.LinkID] = append(out[r.LinkID], r.Name)
+	}
+	return out, nil
+}
+
+// withTags restricts a link query to links carrying every one of the given tags.
+func withTags(q *gorm.DB, userID, column string, tags []string) *gorm.DB {
+	if len(tags) == 0 {
+		return q
+	}
+	sub := q.Session(&gorm.Session{NewDB: true}).
+		Table("link_tags").
+		Select("link_tags.link_id").
+		Joins("JOIN tags ON tags.id = link_tags.tag_id").
+		Where("tags.user_id = ? AND tags.name IN ?", userID, tags).
+		Group("link_tags.link_id").
+		Having("COUNT(DISTINCT tags.name) = ?", len(tags))
+	return q.Where(column+" IN (?)", sub)
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func TestLinkRepo_CreateWithTags(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	link, err := repo.Create(ctx, apiservice.LinkCreateInput{
+		UserID: owner,
+		URL:    "https://go.dev/blog",
+		Tags:   []string{"research", "golang"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang", "research"}, link.Tags)
+}
+
+func TestLinkRepo_UpdateTags(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	link, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://example.com", Tags: []string{"later"}})
+	require.NoError(t, err)
+
+	updated, err := repo.Update(ctx, owner, link.ID, apiservice.LinkUpdateInput{
+		AddTags:    []string{"golang"},
+		RemoveTags: []string{"later"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang"}, updated.Tags)
+
+	_, err = repo.Update(ctx, uuid.NewString(), link.ID, apiservice.LinkUpdateInput{AddTags: []string{"stolen"}})
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
+
+func TestLinkRepo_FilterByTags(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner, other := uuid.NewString(), uuid.NewString()
+
+	_, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://a.example", Resource: "article", Tags: []string{"golang", "research"}})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://b.example", Resource: "video", Tags: []string{"golang"}})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, apiservice.LinkCreateInput{UserID: other, URL: "https://c.example", Tags: []string{"golang", "research"}})
+	require.NoError(t, err)
+
+	links, err := repo.List(ctx, owner, apiservice.LinkFilter{Tags: []string{"golang"}}, apiservice.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+
+	links, err = repo.List(ctx, owner, apiservice.LinkFilter{Tags: []string{"golang", "research"}}, apiservice.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, "https://a.example", links[0].URL)
+
+	link, err := repo.Random(ctx, owner, apiservice.LinkFilter{Resource: "video", Tags: []string{"golang"}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://b.example", link.URL)
+
+	_, err = repo.Random(ctx, owner, apiservice.LinkFilter{Resource: "video", Tags: []string{"research"}})
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
+
+func TestLinkRepo_GetViewStats_ByTag(t *testing.T) {
+	repo := NewLinkRepo(setupTestDB(t))
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	tagged, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://a.example", Tags: []string{"golang"}})
+	require.NoError(t, err)
+	untagged, err := repo.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://b.example"})
+	require.NoError(t, err)
+
+	for _, id := range []string{tagged.ID, untagged.ID, untagged.ID} {
+		_, err = repo.MarkViewed(ctx, owner, id)
+		require.NoError(t, err)
+	}
+
+	stats, err := repo.GetViewStats(ctx, owner, apiservice.LinkFilter{Tags: []string{"golang"}}, 1)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(1), stats[0].Count)
+}
+
+func TestTagRepo_ListWithCounts(t *testing.T) {
+	db := setupTestDB(t)
+	links, tags := NewLinkRepo(db), NewTagRepo(db)
+	ctx := context.Background()
+	owner, other := uuid.NewString(), uuid.NewString()
+
+	_, err := links.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://a.example", Tags: []string{"golang", "video"}})
+	require.NoError(t, err)
+	_, err = links.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://b.example", Tags: []string{"golang"}})
+	require.NoError(t, err)
+	_, err = links.Create(ctx, apiservice.LinkCreateInput{UserID: other, URL: "https://c.example", Tags: []string{"golang"}})
+	require.NoError(t, err)
+
+	list, err := tags.List(ctx, owner)
+	require.NoError(t, err)
+	assert.Equal(t, []apiservice.Tag{{Name: "golang", Count: 2}, {Name: "video", Count: 1}}, list)
+}
+
+func TestTagRepo_Rename(t *testing.T) {
+	db := setupTestDB(t)
+	links, tags := NewLinkRepo(db), NewTagRepo(db)
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	link, err := links.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://a.example", Tags: []string{"go"}})
+	require.NoError(t, err)
+
+	tag, err := tags.Rename(ctx, owner, "go", "golang")
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.Tag{Name: "golang", Count: 1}, tag)
+
+	found, err := links.GetByID(ctx, owner, link.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang"}, found.Tags)
+
+	_, err = tags.Rename(ctx, uuid.NewString(), "golang", "other")
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
+
+func TestTagRepo_RenameOntoExistingMerges(t *testing.T) {
+	db := setupTestDB(t)
+	links, tags := NewLinkRepo(db), NewTagRepo(db)
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	_, err := links.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://a.example", Tags: []string{"go", "golang"}})
+	require.NoError(t, err)
+	_, err = links.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://b.example", Tags: []string{"go"}})
+	require.NoError(t, err)
+
+	tag, err := tags.Rename(ctx, owner, "go", "golang")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), tag.Count)
+
+	list, err := tags.List(ctx, owner)
+	require.NoError(t, err)
+	assert.Equal(t, []apiservice.Tag{{Name: "golang", Count: 2}}, list)
+}
+
+func TestTagRepo_Merge(t *testing.T) {
+	db := setupTestDB(t)
+	links, tags := NewLinkRepo(db), NewTagRepo(db)
+	ctx := context.Background()
+	owner := uuid.NewString()
+
+	_, err := links.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://a.example", Tags: []string{"go"}})
+	require.NoError(t, err)
+	_, err = links.Create(ctx, apiservice.LinkCreateInput{UserID: owner, URL: "https://b.example", Tags: []string{"golang-dev", "go"}})
+	require.NoError(t, err)
+
+	tag, err := tags.Merge(ctx, owner, []string{"go", "golang-dev"}, "golang")
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.Tag{Name: "golang", Count: 2}, tag)
+
+	_, err = tags.Merge(ctx, owner, []string{"missing"}, "golang")
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
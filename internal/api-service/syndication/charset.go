@@ -0,0 +1,67 @@
+package syndication
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// charsetReader converts the non-UTF-8 encodings feeds still declare. Besides
+// UTF-8 and ASCII, which need no conversion, it knows ISO-8859-1 and
+// Windows-1252, the usual legacy encodings of western feeds.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "l1":
+		return &singleByteReader{r: bufio.NewReader(input)}, nil
+	case "windows-1252", "cp1252":
+		return &singleByteReader{r: bufio.NewReader(input), table: &windows1252}, nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+}
+
+// windows1252 maps bytes 0x80-0x9F, where Windows-1252 differs from
+// ISO-8859-1; unassigned ones stay as their ISO-8859-1 control characters.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// singleByteReader decodes a single-byte encoding into UTF-8. Bytes map to
+// the code point of the same value unless table overrides 0x80-0x9F.
+type singleByteReader struct {
+	r     *bufio.Reader
+	table *[32]rune
+	buf   [utf8.UTFMax]byte
+	pend  []byte
+}
+
+func (s *singleByteReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.pend) > 0 {
+			c := copy(p[n:], s.pend)
+			s.pend = s.pend[c:]
+			n += c
+			continue
+		}
+		b, err := s.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		r := rune(b)
+		if s.table != nil && b >= 0x80 && b < 0xA0 {
+			r = s.table[b-0x80]
+		}
+		size := utf8.EncodeRune(s.buf[:], r)
+		s.pend = s.buf[:size]
+	}
+	return n, nil
+}
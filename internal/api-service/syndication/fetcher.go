@@ -0,0 +1,139 @@
+// Package syndication fetches and parses the RSS and Atom feeds users subscribe to.
+package syndication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/internal/api-service/metadata"
+)
+
+const (
+	// maxFeedSize limits how much of a feed is read.
+	maxFeedSize  = 5 << 20
+	maxRedirects = 5
+	userAgent    = "LinkKeeperBot/1.0 (+https://github.com/danilovid/linkkeeper)"
+)
+
+type Fetcher struct {
+	client *http.Client
+}
+
+var _ apiservice.FeedFetcher = (*Fetcher)(nil)
+
+// NewFetcher returns a Fetcher whose requests, redirects included, give up
+// after timeout. It refuses to connect to loopback, private and link-local addresses.
+func NewFetcher(timeout time.Duration) *Fetcher {
+	return &Fetcher{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: metadata.PublicTransport(),
+			CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Fetch downloads and parses a feed. When req.URL is an HTML page instead,
+// the first feed the page advertises is read. Errors wrapping
+// apiservice.ErrInvalidInput mean the URL holds no feed and retrying will not help.
+func (f *Fetcher) Fetch(ctx context.Context, req apiservice.FeedRequest) (apiservice.Feed, error) {
+	feed, page, err := f.fetch(ctx, req)
+	if err != nil || page == nil {
+		return feed, err
+	}
+	links := metadata.FeedLinks(page.doc, page.url)
+	if len(links) == 0 {
+		return apiservice.Feed{}, fmt.Errorf("%w: %s is a web page that advertises no feed", apiservice.ErrInvalidInput, req.URL)
+	}
+	feed, page, err = f.fetch(ctx, apiservice.FeedRequest{URL: links[0]})
+	if err != nil {
+		return apiservice.Feed{}, err
+	}
+	if page != nil {
+		return apiservice.Feed{}, fmt.Errorf("%w: %s is not a feed", apiservice.ErrInvalidInput, links[0])
+	}
+	return feed, nil
+}
+
+// htmlPage is a web page fetched where a feed was expected.
+type htmlPage struct {
+	url *url.URL
+	doc string
+}
+
+// fetch reads the feed at req.URL. When the response is an HTML page rather
+// than a feed, it returns the page instead.
+func (f *Fetcher) fetch(ctx context.Context, req apiservice.FeedRequest) (apiservice.Feed, *htmlPage, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL, nil)
+	if err != nil {
+		return apiservice.Feed{}, nil, fmt.Errorf("%w: %v", apiservice.ErrInvalidInput, err)
+	}
+	if httpReq.URL.Scheme != "http" && httpReq.URL.Scheme != "https" {
+		return apiservice.Feed{}, nil, fmt.Errorf("%w: unsupported scheme %q", apiservice.ErrInvalidInput, httpReq.URL.Scheme)
+	}
+	httpReq.Header.Set("User-Agent", userAgent)
+	httpReq.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8")
+	if req.ETag != "" {
+		httpReq.Header.Set("If-None-Match", req.ETag)
+	}
+	if req.LastModified != "" {
+		httpReq.Header.Set("If-Modified-Since", req.LastModified)
+	}
+
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		return apiservice.Feed{}, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return apiservice.Feed{NotModified: true}, nil, nil
+	}
+	if resp.StatusCode >= 400 {
+		err := fmt.Errorf("fetch %s: status %d", req.URL, resp.StatusCode)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			err = fmt.Errorf("%w: %v", apiservice.ErrInvalidInput, err)
+		}
+		return apiservice.Feed{}, nil, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return apiservice.Feed{}, nil, err
+	}
+	feed, err := Parse(body, resp.Request.URL)
+	if errors.Is(err, ErrNotFeed) {
+		if isHTML(resp.Header.Get("Content-Type")) {
+			return apiservice.Feed{}, &htmlPage{url: resp.Request.URL, doc: string(body)}, nil
+		}
+		return apiservice.Feed{}, nil, fmt.Errorf("%w: %s is not a feed", apiservice.ErrInvalidInput, req.URL)
+	}
+	if err != nil {
+		return apiservice.Feed{}, nil, fmt.Errorf("%w: %v", apiservice.ErrInvalidInput, err)
+	}
+	feed.URL = req.URL
+	feed.ETag = resp.Header.Get("ETag")
+	feed.LastModified = resp.Header.Get("Last-Modified")
+	return feed, nil, nil
+}
+
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
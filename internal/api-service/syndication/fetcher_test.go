@@ -0,0 +1,113 @@
+package syndication
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+const testFeed = `<rss version="2.0"><channel><title>Blog</title><link>/</link>
+<item><title>Post</title><link>/post</link></item></channel></rss>`
+
+// newTestFetcher returns a Fetcher that may connect to the loopback test servers.
+func newTestFetcher(timeout time.Duration) *Fetcher {
+	f := NewFetcher(timeout)
+	f.client.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	return f
+}
+
+func TestFetcher_Fetch_Conditional(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("User-Agent"), "LinkKeeper")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Tue, 03 Mar 2026 10:00:00 GMT")
+		_, _ = w.Write([]byte(testFeed))
+	}))
+	defer srv.Close()
+	f := newTestFetcher(time.Second)
+
+	feed, err := f.Fetch(context.Background(), apiservice.FeedRequest{URL: srv.URL + "/feed"})
+
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/feed", feed.URL)
+	assert.Equal(t, "Blog", feed.Title)
+	assert.Equal(t, srv.URL+"/", feed.SiteURL)
+	assert.Equal(t, `"v1"`, feed.ETag)
+	assert.Equal(t, "Tue, 03 Mar 2026 10:00:00 GMT", feed.LastModified)
+	require.Len(t, feed.Entries, 1)
+	assert.Equal(t, srv.URL+"/post", feed.Entries[0].URL)
+
+	feed, err = f.Fetch(context.Background(), apiservice.FeedRequest{URL: srv.URL + "/feed", ETag: `"v1"`})
+
+	require.NoError(t, err)
+	assert.True(t, feed.NotModified)
+}
+
+func TestFetcher_Fetch_Discovery(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><link rel="alternate" type="application/rss+xml" href="/feed"></head></html>`))
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(testFeed))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>No feed here</title></head></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	f := newTestFetcher(time.Second)
+
+	feed, err := f.Fetch(context.Background(), apiservice.FeedRequest{URL: srv.URL + "/"})
+
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/feed", feed.URL)
+	assert.Equal(t, "Blog", feed.Title)
+
+	_, err = f.Fetch(context.Background(), apiservice.FeedRequest{URL: srv.URL + "/plain"})
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
+
+func TestFetcher_Fetch_Status(t *testing.T) {
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	f := newTestFetcher(time.Second)
+
+	_, err := f.Fetch(context.Background(), apiservice.FeedRequest{URL: srv.URL})
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+
+	status = http.StatusServiceUnavailable
+	_, err = f.Fetch(context.Background(), apiservice.FeedRequest{URL: srv.URL})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, apiservice.ErrInvalidInput)
+}
+
+func TestFetcher_Fetch_PrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("fetcher connected to a loopback address")
+	}))
+	defer srv.Close()
+
+	_, err := NewFetcher(time.Second).Fetch(context.Background(), apiservice.FeedRequest{URL: srv.URL + "/feed"})
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
@@ -0,0 +1,292 @@
+package syndication
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// ErrNotFeed is returned by Parse for documents that are not RSS or Atom feeds.
+var ErrNotFeed = errors.New("not an RSS or Atom feed")
+
+// maxTitleLength caps feed and entry titles, in runes.
+const maxTitleLength = 500
+
+type rssDoc struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Links []xmlLink `xml:"link"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+// rdfDoc is an RSS 1.0 document, where items are siblings of the channel.
+type rdfDoc struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Links []xmlLink `xml:"link"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title   string    `xml:"title"`
+	Links   []xmlLink `xml:"link"`
+	GUID    rssGUID   `xml:"guid"`
+	PubDate string    `xml:"pubDate"`
+	DCDate  string    `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+// rssGUID is an item's unique id. Unless isPermaLink is "false", it is also
+// the item's URL.
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Text        string `xml:",chardata"`
+}
+
+type atomDoc struct {
+	Title   atomText    `xml:"title"`
+	Links   []xmlLink   `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string    `xml:"id"`
+	Title     atomText  `xml:"title"`
+	Links     []xmlLink `xml:"link"`
+	Published string    `xml:"published"`
+	Updated   string    `xml:"updated"`
+}
+
+// atomText is an Atom text construct: plain text, escaped HTML or inline XHTML.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	switch t.Type {
+	case "html":
+		return stripTags(t.Text)
+	case "xhtml":
+		return stripTags(t.Inner)
+	}
+	return t.Text
+}
+
+// xmlLink covers RSS links, which are text, and Atom links, which are
+// attributes. RSS feeds often carry both, e.g. an atom:link to themselves.
+type xmlLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// Parse reads an RSS 2.0, RSS 1.0 or Atom document. Relative links are
+// resolved against base, the URL the document was fetched from; entries
+// without a usable http(s) link are dropped.
+func Parse(data []byte, base *url.URL) (apiservice.Feed, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	dec.CharsetReader = charsetReader
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return apiservice.Feed{}, ErrNotFeed
+		}
+		if err != nil {
+			return apiservice.Feed{}, fmt.Errorf("%w: %w", ErrNotFeed, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(start.Name.Local) {
+		case "rss":
+			var doc rssDoc
+			if err := dec.DecodeElement(&doc, &start); err != nil {
+				return apiservice.Feed{}, fmt.Errorf("reading RSS feed: %w", err)
+			}
+			return fromRSS(doc.Channel.Title, doc.Channel.Links, doc.Channel.Items, base), nil
+		case "rdf":
+			var doc rdfDoc
+			if err := dec.DecodeElement(&doc, &start); err != nil {
+				return apiservice.Feed{}, fmt.Errorf("reading RSS feed: %w", err)
+			}
+			return fromRSS(doc.Channel.Title, doc.Channel.Links, doc.Items, base), nil
+		case "feed":
+			var doc atomDoc
+			if err := dec.DecodeElement(&doc, &start); err != nil {
+				return apiservice.Feed{}, fmt.Errorf("reading Atom feed: %w", err)
+			}
+			return fromAtom(doc, base), nil
+		default:
+			return apiservice.Feed{}, ErrNotFeed
+		}
+	}
+}
+
+func fromRSS(title string, links []xmlLink, items []rssItem, base *url.URL) apiservice.Feed {
+	feed := apiservice.Feed{
+		Title:   cleanText(title),
+		SiteURL: resolve(base, rssLink(links)),
+		Entries: make([]apiservice.FeedEntry, 0, len(items)),
+	}
+	for _, item := range items {
+		link := resolve(base, rssLink(item.Links))
+		if link == "" && item.GUID.IsPermaLink != "false" && isAbsolute(item.GUID.Text) {
+			// Some feeds only give a permalink guid.
+			link = resolve(base, item.GUID.Text)
+		}
+		if link == "" {
+			continue
+		}
+		feed.Entries = append(feed.Entries, apiservice.FeedEntry{
+			Key:       firstNonEmpty(item.GUID.Text, link),
+			URL:       link,
+			Title:     cleanText(item.Title),
+			Published: parseDate(firstNonEmpty(item.PubDate, item.DCDate)),
+		})
+	}
+	return feed
+}
+
+func fromAtom(doc atomDoc, base *url.URL) apiservice.Feed {
+	feed := apiservice.Feed{
+		Title:   cleanText(doc.Title.String()),
+		SiteURL: resolve(base, atomLink(doc.Links)),
+		Entries: make([]apiservice.FeedEntry, 0, len(doc.Entries)),
+	}
+	for _, entry := range doc.Entries {
+		link := resolve(base, atomLink(entry.Links))
+		if link == "" {
+			continue
+		}
+		feed.Entries = append(feed.Entries, apiservice.FeedEntry{
+			Key:       firstNonEmpty(entry.ID, link),
+			URL:       link,
+			Title:     cleanText(entry.Title.String()),
+			Published: parseDate(firstNonEmpty(entry.Published, entry.Updated)),
+		})
+	}
+	return feed
+}
+
+// rssLink returns the first link given as text, skipping atom:link elements.
+func rssLink(links []xmlLink) string {
+	for _, l := range links {
+		if text := strings.TrimSpace(l.Text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// atomLink returns the alternate link, which is the default relation, or
+// else the first link that is not about the feed itself.
+func atomLink(links []xmlLink) string {
+	fallback := ""
+	for _, l := range links {
+		rel := strings.ToLower(strings.TrimSpace(l.Rel))
+		switch {
+		case rel == "" || rel == "alternate":
+			if !strings.HasPrefix(l.Type, "application/") || l.Type == "application/xhtml+xml" {
+				return l.Href
+			}
+		case rel != "self" && rel != "hub" && rel != "next" && rel != "previous" && fallback == "":
+			fallback = l.Href
+		}
+	}
+	return fallback
+}
+
+// dateLayouts are the date formats seen in the wild, RFC 822 variants first.
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDate returns the zero time for dates it cannot read.
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return u.String()
+}
+
+func isAbsolute(ref string) bool {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	return err == nil && u.IsAbs()
+}
+
+// stripTags turns an HTML title, as Atom allows, into text.
+func stripTags(s string) string {
+	var b strings.Builder
+	inTag := false
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>' && inTag:
+			inTag = false
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+	return html.UnescapeString(b.String())
+}
+
+// cleanText collapses whitespace and caps the length of a title.
+func cleanText(s string) string {
+	s = strings.Join(strings.Fields(strings.ToValidUTF8(s, "")), " ")
+	if utf8.RuneCountInString(s) <= maxTitleLength {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:maxTitleLength])) + "…"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
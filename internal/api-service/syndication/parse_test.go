@@ -0,0 +1,121 @@
+package syndication
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
+
+func TestParse_RSS(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+  <title>Example &amp; Co</title>
+  <atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"/>
+  <link>https://example.com/</link>
+  <item>
+    <title>Second  post</title>
+    <link>/posts/2</link>
+    <guid isPermaLink="false">post-2</guid>
+    <pubDate>Tue, 3 Mar 2026 10:00:00 GMT</pubDate>
+  </item>
+  <item>
+    <title>First post</title>
+    <guid>https://example.com/posts/1</guid>
+    <dc:date>2026-03-01T08:30:00Z</dc:date>
+  </item>
+  <item>
+    <title>No link</title>
+    <guid isPermaLink="false">post-0</guid>
+  </item>
+</channel>
+</rss>`
+
+	feed, err := Parse([]byte(doc), mustParseURL(t, "https://example.com/feed.xml"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Example & Co", feed.Title)
+	assert.Equal(t, "https://example.com/", feed.SiteURL)
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, "post-2", feed.Entries[0].Key)
+	assert.Equal(t, "https://example.com/posts/2", feed.Entries[0].URL)
+	assert.Equal(t, "Second post", feed.Entries[0].Title)
+	assert.Equal(t, time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC), feed.Entries[0].Published.UTC())
+	assert.Equal(t, "https://example.com/posts/1", feed.Entries[1].Key)
+	assert.Equal(t, "https://example.com/posts/1", feed.Entries[1].URL)
+	assert.Equal(t, time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC), feed.Entries[1].Published.UTC())
+}
+
+func TestParse_Atom(t *testing.T) {
+	doc := `<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="html">&lt;b&gt;Dev&lt;/b&gt; notes</title>
+  <link rel="self" href="https://example.org/atom.xml"/>
+  <link href="https://example.org/"/>
+  <entry>
+    <id>tag:example.org,2026:1</id>
+    <title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml">Hello <em>world</em></div></title>
+    <link rel="replies" href="/1/comments"/>
+    <link rel="alternate" type="text/html" href="/1"/>
+    <updated>2026-02-01T12:00:00+01:00</updated>
+  </entry>
+  <entry>
+    <title>Enclosure only</title>
+    <link rel="enclosure" href="https://cdn.example.org/2.mp3"/>
+  </entry>
+</feed>`
+
+	feed, err := Parse([]byte(doc), mustParseURL(t, "https://example.org/atom.xml"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Dev notes", feed.Title)
+	assert.Equal(t, "https://example.org/", feed.SiteURL)
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, "tag:example.org,2026:1", feed.Entries[0].Key)
+	assert.Equal(t, "https://example.org/1", feed.Entries[0].URL)
+	assert.Equal(t, "Hello world", feed.Entries[0].Title)
+	assert.Equal(t, time.Date(2026, 2, 1, 11, 0, 0, 0, time.UTC), feed.Entries[0].Published.UTC())
+	assert.Equal(t, "https://cdn.example.org/2.mp3", feed.Entries[1].Key)
+}
+
+func TestParse_RDF(t *testing.T) {
+	doc := `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+  <channel><title>Old school</title><link>http://example.net/</link></channel>
+  <item><title>Item</title><link>http://example.net/item</link></item>
+</rdf:RDF>`
+
+	feed, err := Parse([]byte(doc), mustParseURL(t, "http://example.net/index.rdf"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Old school", feed.Title)
+	require.Len(t, feed.Entries, 1)
+	assert.Equal(t, "http://example.net/item", feed.Entries[0].URL)
+}
+
+func TestParse_Latin1(t *testing.T) {
+	doc := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><rss><channel><title>Caf\xe9</title></channel></rss>"
+
+	feed, err := Parse([]byte(doc), mustParseURL(t, "https://example.com/"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Café", feed.Title)
+}
+
+func TestParse_NotFeed(t *testing.T) {
+	for _, doc := range []string{
+		`<!DOCTYPE html><html><head><title>Page</title></head></html>`,
+		`{"items": []}`,
+		``,
+	} {
+		_, err := Parse([]byte(doc), mustParseURL(t, "https://example.com/"))
+		assert.ErrorIs(t, err, ErrNotFeed, doc)
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// adminTokenHeader carries the token that unlocks the admin endpoints.
+const adminTokenHeader = "X-Admin-Token"
+
+type reclassifyResponse struct {
+	Scanned int `json:"scanned"`
+	Updated int `json:"updated"`
+}
+
+// adminOnly rejects requests that do not carry the configured admin token.
+func (s *Server) adminOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(adminTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Reclassify re-runs resource classification. By default it only fills in
+// links without a resource; overwrite=true reclassifies every link.
+// user_id limits the run to one user.
+func (s *Server) Reclassify() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		overwrite, _ := strconv.ParseBool(q.Get("overwrite"))
+		result, err := s.uc.Reclassify(r.Context(), strings.TrimSpace(q.Get("user_id")), overwrite)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, reclassifyResponse{
+			Scanned: result.Scanned,
+			Updated: result.Updated,
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// maxBackupSize caps an uploaded backup.
+const maxBackupSize = 64 << 20
+
+type restoreErrorResponse struct {
+	Row   int    `json:"row"`
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error"`
+}
+
+type restoreResponse struct {
+	Version  int                    `json:"version"`
+	Created  int                    `json:"created"`
+	Existing int                    `json:"existing"`
+	Merged   int                    `json:"merged"`
+	Invalid  int                    `json:"invalid"`
+	Views    int                    `json:"views"`
+	Errors   []restoreErrorResponse `json:"errors"`
+}
+
+// ExportBackup streams a JSON backup of the user's account.
+func (s *Server) ExportBackup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		out := &lazyHeaderWriter{w: w, contentType: "application/json", filename: "linkkeeper-backup.json"}
+		if err := s.backups.Export(r.Context(), userID(r), out); err != nil {
+			if !out.started {
+				writeError(w, err)
+				return
+			}
+			logger.L().Error().Err(err).Msg("export backup")
+		}
+	}
+}
+
+// RestoreBackup merges a backup, sent as the request body or as the "file"
+// field of a multipart form, into the user's account.
+func (s *Server) RestoreBackup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBackupSize)
+		body, err := uploadedFile(r)
+		if err != nil {
+			http.Error(w, "multipart form has no file field", http.StatusBadRequest)
+			return
+		}
+		defer body.Close()
+
+		result, err := s.backups.Restore(r.Context(), userID(r), body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "backup is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := restoreResponse{
+			Version:  result.Version,
+			Created:  result.Created,
+			Existing: result.Existing,
+			Merged:   result.Merged,
+			Invalid:  result.Invalid,
+			Views:    result.Views,
+			Errors:   make([]restoreErrorResponse, 0, len(result.Errors)),
+		}
+		for _, e := range result.Errors {
+			resp.Errors = append(resp.Errors, restoreErrorResponse{Row: e.Row, URL: e.URL, Error: e.Error})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// maxBookmarksSize caps an uploaded bookmark file.
+const maxBookmarksSize = 32 << 20
+
+type importItemResponse struct {
+	Row       int        `json:"row"`
+	URL       string     `json:"url"`
+	Title     string     `json:"title"`
+	Tags      []string   `json:"tags"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Result    string     `json:"result"`
+	LinkID    string     `json:"link_id,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+type importResponse struct {
+	DryRun     bool                 `json:"dry_run"`
+	Created    int                  `json:"created"`
+	Duplicates int                  `json:"duplicates"`
+	Invalid    int                  `json:"invalid"`
+	Items      []importItemResponse `json:"items"`
+}
+
+// ImportBookmarks reads a Netscape bookmark file, either as the request body
+// or as the "file" field of a multipart form. dry_run=true only reports what
+// would be imported.
+func (s *Server) ImportBookmarks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+		r.Body = http.MaxBytesReader(w, r.Body, maxBookmarksSize)
+
+		body, err := uploadedFile(r)
+		if err != nil {
+			http.Error(w, "multipart form has no file field", http.StatusBadRequest)
+			return
+		}
+		defer body.Close()
+
+		result, err := s.uc.ImportBookmarks(r.Context(), userID(r), body, dryRun)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "bookmark file is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := importResponse{
+			DryRun:     result.DryRun,
+			Created:    result.Created,
+			Duplicates: result.Duplicates,
+			Invalid:    result.Invalid,
+			Items:      make([]importItemResponse, 0, len(result.Items)),
+		}
+		for _, item := range result.Items {
+			itemResp := importItemResponse{
+				Row:    item.Row,
+				URL:    item.URL,
+				Title:  item.Title,
+				Tags:   nonNil(item.Tags),
+				Result: item.Result,
+				LinkID: item.LinkID,
+				Error:  item.Error,
+			}
+			if !item.CreatedAt.IsZero() {
+				createdAt := item.CreatedAt
+				itemResp.CreatedAt = &createdAt
+			}
+			resp.Items = append(resp.Items, itemResp)
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// ExportBookmarks streams every link as a Netscape bookmark file.
+func (s *Server) ExportBookmarks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		out := &lazyHeaderWriter{w: w, contentType: "text/html; charset=UTF-8", filename: "linkkeeper-bookmarks.html"}
+		if err := s.uc.ExportBookmarks(r.Context(), userID(r), out); err != nil {
+			if !out.started {
+				writeError(w, err)
+				return
+			}
+			// The status line is already sent; all that is left is to cut the file short.
+			logger.L().Error().Err(err).Msg("export bookmarks")
+		}
+	}
+}
+
+// uploadedFile returns the "file" field of a multipart form, or the request
+// body when it is not a form.
+func uploadedFile(r *http.Request) (io.ReadCloser, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return r.Body, nil
+	}
+	return filePart(mr)
+}
+
+// filePart finds the "file" field of a multipart form.
+func filePart(mr *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		_ = part.Close()
+	}
+}
+
+// lazyHeaderWriter sends the download headers with the first byte of the
+// file, so that errors before that still get a proper status.
+type lazyHeaderWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (lw *lazyHeaderWriter) Write(p []byte) (int, error) {
+	if !lw.started {
+		lw.started = true
+		lw.w.Header().Set("Content-Type", lw.contentType)
+		lw.w.Header().Set("Content-Disposition", `attachment; filename="`+lw.filename+`"`)
+		lw.w.WriteHeader(http.StatusOK)
+	}
+	return lw.w.Write(p)
+}
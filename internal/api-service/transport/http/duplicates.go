@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type duplicateGroupResponse struct {
+	CanonicalURL string         `json:"canonical_url"`
+	Links        []linkResponse `json:"links"`
+}
+
+type mergeDuplicatesResponse struct {
+	Merged  []linkResponse `json:"merged"`
+	Removed int            `json:"removed"`
+}
+
+func (s *Server) FindDuplicates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		groups, err := s.uc.FindDuplicates(r.Context(), userID(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		resp := make([]duplicateGroupResponse, 0, len(groups))
+		for _, group := range groups {
+			resp = append(resp, duplicateGroupResponse{
+				CanonicalURL: group.CanonicalURL,
+				Links:        toLinkResponses(group.Links),
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// MergeDuplicates merges every group of duplicate links into its oldest link.
+func (s *Server) MergeDuplicates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := s.uc.MergeDuplicates(r.Context(), userID(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, mergeDuplicatesResponse{
+			Merged:  toLinkResponses(result.Merged),
+			Removed: result.Removed,
+		})
+	}
+}
+
+func toLinkResponses(links []apiservice.Link) []linkResponse {
+	resp := make([]linkResponse, 0, len(links))
+	for _, link := range links {
+		resp = append(resp, toLinkResponse(link))
+	}
+	return resp
+}
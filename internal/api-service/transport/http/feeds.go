@@ -0,0 +1,355 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// defaultFeedSize is the number of links in a feed unless limit says otherwise.
+const defaultFeedSize = 50
+
+const (
+	atomContentType = "application/atom+xml; charset=utf-8"
+	rssContentType  = "application/rss+xml; charset=utf-8"
+)
+
+type feedTokenResponse struct {
+	Token   string `json:"token"`
+	AtomURL string `json:"atom_url"`
+	RSSURL  string `json:"rss_url"`
+}
+
+// RotateFeedToken issues a new feed token, which stops the old feed URLs from working.
+func (s *Server) RotateFeedToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := s.feeds.RotateToken(r.Context(), userID(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		base := baseURL(r) + "/api/v1/feeds/" + token
+		writeJSON(w, http.StatusCreated, feedTokenResponse{Token: token, AtomURL: base + "/atom", RSSURL: base + "/rss"})
+	}
+}
+
+func (s *Server) RevokeFeedToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.feeds.RevokeToken(r.Context(), userID(r)); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// feed is what the Atom and RSS renderings of a feed are made from.
+type feed struct {
+	id, title, selfURL string
+	updated            time.Time
+	links              []apiservice.Link
+}
+
+// loadFeed resolves the feed token and loads the newest links matching the
+// filter in the query. It writes the response itself and returns false when
+// there is nothing more to send: on errors, and on conditional requests for
+// a feed whose entries have not changed.
+func (s *Server) loadFeed(w http.ResponseWriter, r *http.Request) (feed, bool) {
+	user, err := s.feeds.UserID(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		writeError(w, err)
+		return feed{}, false
+	}
+	filter := linkFilter(r)
+	page, err := s.uc.List(r.Context(), user, filter, apiservice.PageRequest{
+		Limit: parseIntDefault(r.URL.Query().Get("limit"), defaultFeedSize),
+	})
+	if err != nil {
+		writeError(w, err)
+		return feed{}, false
+	}
+
+	// The newest UpdatedAt dates the feed, but it does not move when a link
+	// is deleted or drops out of the filter, so only the ETag, which covers
+	// the entries themselves, decides whether the feed has changed.
+	var updated time.Time
+	for _, link := range page.Links {
+		if link.UpdatedAt.After(updated) {
+			updated = link.UpdatedAt
+		}
+	}
+	etag := feedETag(page)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return feed{}, false
+	}
+	if !updated.IsZero() {
+		updated = updated.UTC().Truncate(time.Second)
+		w.Header().Set("Last-Modified", updated.Format(http.TimeFormat))
+	} else {
+		updated = time.Now().UTC().Truncate(time.Second)
+	}
+
+	return feed{
+		id:      "urn:linkkeeper:feed:" + user + feedQuery(filter),
+		title:   feedTitle(filter),
+		selfURL: baseURL(r) + r.URL.RequestURI(),
+		updated: updated,
+		links:   page.Links,
+	}, true
+}
+
+// feedETag identifies the entries of a feed page: which links are in it and
+// when each was last changed.
+func feedETag(page apiservice.LinkPage) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n", page.Total)
+	for _, link := range page.Links {
+		fmt.Fprintf(h, "%s %d\n", link.ID, link.UpdatedAt.UnixNano())
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing weakly.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Author    atomPerson  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// AtomFeed publishes the newest links of the feed token's owner as Atom.
+// Entries are updated whenever their link is.
+func (s *Server) AtomFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, ok := s.loadFeed(w, r)
+		if !ok {
+			return
+		}
+		out := atomFeed{
+			ID:        f.id,
+			Title:     f.title,
+			Updated:   f.updated.Format(time.RFC3339),
+			Links:     []atomLink{{Href: f.selfURL, Rel: "self", Type: "application/atom+xml"}},
+			Author:    atomPerson{Name: "LinkKeeper"},
+			Generator: "LinkKeeper",
+			Entries:   make([]atomEntry, 0, len(f.links)),
+		}
+		for _, link := range f.links {
+			entry := atomEntry{
+				ID:        "urn:uuid:" + link.ID,
+				Title:     entryTitle(link),
+				Link:      atomLink{Href: link.URL, Rel: "alternate"},
+				Published: link.CreatedAt.UTC().Format(time.RFC3339),
+				Updated:   link.UpdatedAt.UTC().Format(time.RFC3339),
+				Summary:   entrySummary(link),
+			}
+			for _, tag := range link.Tags {
+				entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+			}
+			out.Entries = append(out.Entries, entry)
+		}
+		writeXML(w, atomContentType, out)
+	}
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          rssSelf   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssSelf is the atom:link RSS feeds use to point at themselves.
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description,omitempty"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSSFeed publishes the same links as AtomFeed as RSS 2.0, which has no
+// per-item update time; lastBuildDate follows the most recent update.
+func (s *Server) RSSFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, ok := s.loadFeed(w, r)
+		if !ok {
+			return
+		}
+		out := rssFeed{
+			Version: "2.0",
+			AtomNS:  "http://www.w3.org/2005/Atom",
+			Channel: rssChannel{
+				Title:         f.title,
+				Link:          f.selfURL,
+				Description:   "Links saved in LinkKeeper",
+				Self:          rssSelf{Href: f.selfURL, Rel: "self", Type: "application/rss+xml"},
+				LastBuildDate: f.updated.Format(time.RFC1123Z),
+				Generator:     "LinkKeeper",
+				Items:         make([]rssItem, 0, len(f.links)),
+			},
+		}
+		for _, link := range f.links {
+			out.Channel.Items = append(out.Channel.Items, rssItem{
+				Title:       entryTitle(link),
+				Link:        link.URL,
+				Description: entrySummary(link),
+				GUID:        rssGUID{Value: "urn:uuid:" + link.ID},
+				PubDate:     link.CreatedAt.UTC().Format(time.RFC1123Z),
+				Categories:  link.Tags,
+			})
+		}
+		writeXML(w, rssContentType, out)
+	}
+}
+
+func writeXML(w http.ResponseWriter, contentType string, v any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		logger.L().Error().Err(err).Msg("encode feed")
+	}
+}
+
+// entryTitle prefers the saved or fetched title and falls back to the URL.
+func entryTitle(link apiservice.Link) string {
+	if link.Title != "" {
+		return link.Title
+	}
+	return link.URL
+}
+
+// entrySummary joins the user's notes and the fetched page description.
+func entrySummary(link apiservice.Link) string {
+	var parts []string
+	for _, text := range []string{link.Notes, link.Metadata.Description} {
+		if text = strings.TrimSpace(text); text != "" && (len(parts) == 0 || parts[0] != text) {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func feedTitle(filter apiservice.LinkFilter) string {
+	var parts []string
+	if len(filter.Tags) > 0 {
+		parts = append(parts, "#"+strings.Join(filter.Tags, " #"))
+	}
+	if filter.Resource != "" {
+		parts = append(parts, filter.Resource)
+	}
+	if len(filter.Statuses) > 0 {
+		parts = append(parts, strings.Join(filter.Statuses, ", "))
+	}
+	if len(parts) == 0 {
+		return "LinkKeeper"
+	}
+	return "LinkKeeper: " + strings.Join(parts, " · ")
+}
+
+// feedQuery renders the filter so that feeds with the same filter get the same id.
+func feedQuery(filter apiservice.LinkFilter) string {
+	q := url.Values{}
+	if filter.Resource != "" {
+		q.Set("resource", filter.Resource)
+	}
+	if len(filter.Tags) > 0 {
+		q.Set("tag", strings.Join(filter.Tags, ","))
+	}
+	if len(filter.Statuses) > 0 {
+		q.Set("status", strings.Join(filter.Statuses, ","))
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+// baseURL is the scheme and host the request was made to, as seen by the client.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
@@ -2,28 +2,104 @@ package http
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/justinas/alice"
 
 	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/pkg/auth"
 	"github.com/danilovid/linkkeeper/pkg/logger"
 	"github.com/rs/cors"
 )
 
 type Server struct {
-	uc      apiservice.LinkService
-	router  *mux.Router
-	handler http.Handler
+	uc            apiservice.LinkService
+	tags          apiservice.TagService
+	imports       apiservice.ImportService
+	backups       apiservice.BackupService
+	feeds         apiservice.FeedService
+	subscriptions apiservice.SubscriptionService
+	adminToken    string
+	tokens        auth.TokenResolver
+	services      *auth.SignatureVerifier
+	authRequired  bool
+	router        *mux.Router
+	handler       http.Handler
 }
 
-func NewServer(uc apiservice.LinkService) *Server {
+// Option configures optional Server behavior.
+type Option func(*Server)
+
+// WithAdminToken enables the admin endpoints for requests carrying token.
+func WithAdminToken(token string) Option {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+// WithTokenResolver authenticates requests carrying an API token with tokens.
+func WithTokenResolver(tokens auth.TokenResolver) Option {
+	return func(s *Server) {
+		s.tokens = tokens
+	}
+}
+
+// WithAuthRequired rejects requests that do not authenticate. Without it
+// callers are trusted, as on a private network, and name their user in X-User-ID.
+func WithAuthRequired(required bool) Option {
+	return func(s *Server) {
+		s.authRequired = required
+	}
+}
+
+// WithServiceVerifier accepts requests signed by other services, such as
+// the bot, which name the user they act for.
+func WithServiceVerifier(services *auth.SignatureVerifier) Option {
+	return func(s *Server) {
+		s.services = services
+	}
+}
+
+// WithImports enables the background import endpoints.
+func WithImports(imports apiservice.ImportService) Option {
+	return func(s *Server) {
+		s.imports = imports
+	}
+}
+
+// WithBackups enables the backup and restore endpoints.
+func WithBackups(backups apiservice.BackupService) Option {
+	return func(s *Server) {
+		s.backups = backups
+	}
+}
+
+// WithFeeds enables the Atom and RSS feeds and their token endpoints.
+func WithFeeds(feeds apiservice.FeedService) Option {
+	return func(s *Server) {
+		s.feeds = feeds
+	}
+}
+
+// WithSubscriptions enables the feed subscription endpoints.
+func WithSubscriptions(subscriptions apiservice.SubscriptionService) Option {
+	return func(s *Server) {
+		s.subscriptions = subscriptions
+	}
+}
+
+func NewServer(uc apiservice.LinkService, tags apiservice.TagService, opts ...Option) *Server {
 	r := mux.NewRouter()
 	s := &Server{
 		uc:     uc,
+		tags:   tags,
 		router: r,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
 	s.routes()
 	return s
 }
@@ -38,12 +114,18 @@ func (s *Server) routes() {
 	corsOpts := cors.Options{
 		AllowedOrigins: []string{"*"},
 		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization", "X-Auth-Key"},
+		AllowedHeaders: []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization", "X-Auth-Key", auth.UserIDHeader},
 	}
 
 	s.handler = alice.New(
 		requestLogger,
 		cors.New(corsOpts).Handler,
+		auth.Middleware(auth.Options{
+			Tokens:   s.tokens,
+			Services: s.services,
+			Required: s.authRequired,
+			Public:   public,
+		}),
 	).Then(s.router)
 
 	s.router.HandleFunc("/health", Health).Methods(http.MethodGet)
@@ -52,11 +134,51 @@ func (s *Server) routes() {
 	api.HandleFunc("/links", s.Create()).Methods(http.MethodPost)
 	api.HandleFunc("/links", s.List()).Methods(http.MethodGet)
 	api.HandleFunc("/links/random", s.Random()).Methods(http.MethodGet)
+	api.HandleFunc("/links/search", s.Search()).Methods(http.MethodGet)
+	api.HandleFunc("/links/duplicates", s.FindDuplicates()).Methods(http.MethodGet)
+	api.HandleFunc("/links/duplicates/merge", s.MergeDuplicates()).Methods(http.MethodPost)
 	api.HandleFunc("/links/{id}", s.Get()).Methods(http.MethodGet)
 	api.HandleFunc("/links/{id}", s.Update()).Methods(http.MethodPatch)
 	api.HandleFunc("/links/{id}", s.Delete()).Methods(http.MethodDelete)
 	api.HandleFunc("/links/{id}/viewed", s.MarkViewed()).Methods(http.MethodPost)
+	api.HandleFunc("/links/{id}/status", s.SetStatus()).Methods(http.MethodPost)
+	api.HandleFunc("/links/{id}/views", s.ListViews()).Methods(http.MethodGet)
 	api.HandleFunc("/stats/views", s.GetViewStats()).Methods(http.MethodGet)
+	api.HandleFunc("/import/bookmarks", s.ImportBookmarks()).Methods(http.MethodPost)
+	api.HandleFunc("/export/bookmarks", s.ExportBookmarks()).Methods(http.MethodGet)
+	api.HandleFunc("/tags", s.ListTags()).Methods(http.MethodGet)
+	api.HandleFunc("/tags/merge", s.MergeTags()).Methods(http.MethodPost)
+	api.HandleFunc("/tags/{name}", s.RenameTag()).Methods(http.MethodPatch)
+
+	if s.imports != nil {
+		api.HandleFunc("/imports", s.SubmitImport()).Methods(http.MethodPost)
+		api.HandleFunc("/imports", s.ListImports()).Methods(http.MethodGet)
+		api.HandleFunc("/imports/{id}", s.GetImport()).Methods(http.MethodGet)
+	}
+
+	if s.backups != nil {
+		api.HandleFunc("/export", s.ExportBackup()).Methods(http.MethodGet)
+		api.HandleFunc("/restore", s.RestoreBackup()).Methods(http.MethodPost)
+	}
+
+	if s.feeds != nil {
+		api.HandleFunc("/feeds/token", s.RotateFeedToken()).Methods(http.MethodPost)
+		api.HandleFunc("/feeds/token", s.RevokeFeedToken()).Methods(http.MethodDelete)
+		api.HandleFunc("/feeds/{token}/atom", s.AtomFeed()).Methods(http.MethodGet)
+		api.HandleFunc("/feeds/{token}/rss", s.RSSFeed()).Methods(http.MethodGet)
+	}
+
+	if s.subscriptions != nil {
+		api.HandleFunc("/subscriptions", s.Subscribe()).Methods(http.MethodPost)
+		api.HandleFunc("/subscriptions", s.ListSubscriptions()).Methods(http.MethodGet)
+		api.HandleFunc("/subscriptions/{id}", s.GetSubscription()).Methods(http.MethodGet)
+		api.HandleFunc("/subscriptions/{id}", s.UpdateSubscription()).Methods(http.MethodPatch)
+		api.HandleFunc("/subscriptions/{id}", s.Unsubscribe()).Methods(http.MethodDelete)
+	}
+
+	if s.adminToken != "" {
+		api.HandleFunc("/admin/links/reclassify", s.adminOnly(s.Reclassify())).Methods(http.MethodPost)
+	}
 }
 
 func requestLogger(next http.Handler) http.Handler {
@@ -65,8 +187,45 @@ func requestLogger(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 		logger.L().Info().
 			Str("method", r.Method).
-			Str("path", r.URL.Path).
+			Str("path", logPath(r.URL.Path)).
 			Dur("duration", time.Since(start)).
 			Msg("request")
 	})
 }
+
+// feedsPrefix starts the feed URLs, whose next path segment is a feed token.
+const feedsPrefix = "/api/v1/feeds/"
+
+// public reports whether a request is let through without an API token:
+// health checks, feeds, which carry their own token in the URL, and admin
+// endpoints, which check the admin token.
+func public(r *http.Request) bool {
+	path := r.URL.Path
+	switch {
+	case path == "/health":
+		return true
+	case strings.HasPrefix(path, "/api/v1/admin/"):
+		return true
+	case strings.HasPrefix(path, feedsPrefix):
+		token, _, _ := strings.Cut(strings.TrimPrefix(path, feedsPrefix), "/")
+		return token != "token"
+	}
+	return false
+}
+
+// logPath hides the feed token in feed URLs, which would otherwise leave a
+// working credential in the logs.
+func logPath(path string) string {
+	rest, ok := strings.CutPrefix(path, feedsPrefix)
+	if !ok {
+		return path
+	}
+	token, tail, found := strings.Cut(rest, "/")
+	if token == "" || token == "token" {
+		return path
+	}
+	if !found {
+		return feedsPrefix + "[redacted]"
+	}
+	return feedsPrefix + "[redacted]/" + tail
+}
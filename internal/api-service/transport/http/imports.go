@@ -0,0 +1,117 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type importErrorResponse struct {
+	Row   int    `json:"row"`
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error"`
+}
+
+type importJobResponse struct {
+	ID         string                `json:"id"`
+	Format     string                `json:"format"`
+	Status     string                `json:"status"`
+	Total      int                   `json:"total"`
+	Processed  int                   `json:"processed"`
+	Created    int                   `json:"created"`
+	Duplicates int                   `json:"duplicates"`
+	Invalid    int                   `json:"invalid"`
+	Errors     []importErrorResponse `json:"errors"`
+	Error      string                `json:"error,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+	StartedAt  *time.Time            `json:"started_at,omitempty"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+}
+
+func toImportJobResponse(job apiservice.ImportJob) importJobResponse {
+	resp := importJobResponse{
+		ID:         job.ID,
+		Format:     job.Format,
+		Status:     job.Status,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Created:    job.Created,
+		Duplicates: job.Duplicates,
+		Invalid:    job.Invalid,
+		Errors:     make([]importErrorResponse, 0, len(job.Errors)),
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	for _, e := range job.Errors {
+		resp.Errors = append(resp.Errors, importErrorResponse{Row: e.Row, URL: e.URL, Error: e.Error})
+	}
+	return resp
+}
+
+// SubmitImport queues an export file of another service for import. The
+// file is the request body or the "file" field of a multipart form, and
+// format names its format.
+func (s *Server) SubmitImport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBookmarksSize)
+		body, err := uploadedFile(r)
+		if err != nil {
+			http.Error(w, "multipart form has no file field", http.StatusBadRequest)
+			return
+		}
+		defer body.Close()
+
+		payload, err := io.ReadAll(body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "import file is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to read import file", http.StatusBadRequest)
+			return
+		}
+
+		job, err := s.imports.Submit(r.Context(), userID(r), r.URL.Query().Get("format"), payload)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, toImportJobResponse(job))
+	}
+}
+
+func (s *Server) GetImport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := s.imports.Get(r.Context(), userID(r), mux.Vars(r)["id"])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toImportJobResponse(job))
+	}
+}
+
+func (s *Server) ListImports() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobs, err := s.imports.List(r.Context(), userID(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		resp := make([]importJobResponse, 0, len(jobs))
+		for _, job := range jobs {
+			resp = append(resp, toImportJobResponse(job))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
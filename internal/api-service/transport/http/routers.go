@@ -11,26 +11,87 @@ import (
 	"github.com/gorilla/mux"
 
 	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/pkg/auth"
 )
 
 type createLinkRequest struct {
-	URL      string `json:"url"`
-	Resource string `json:"resource"`
+	URL      string   `json:"url"`
+	Title    string   `json:"title"`
+	Notes    string   `json:"notes"`
+	Resource string   `json:"resource"`
+	Tags     []string `json:"tags"`
 }
 
 type updateLinkRequest struct {
-	URL      *string `json:"url"`
-	Resource *string `json:"resource"`
+	URL        *string  `json:"url"`
+	Title      *string  `json:"title"`
+	Notes      *string  `json:"notes"`
+	Resource   *string  `json:"resource"`
+	AddTags    []string `json:"add_tags"`
+	RemoveTags []string `json:"remove_tags"`
 }
 
 type linkResponse struct {
-	ID        string     `json:"id"`
-	URL       string     `json:"url"`
-	Resource  string     `json:"resource,omitempty"`
-	Views     int64      `json:"views"`
-	ViewedAt  *time.Time `json:"viewed_at,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID           string            `json:"id"`
+	UserID       string            `json:"user_id"`
+	URL          string            `json:"url"`
+	CanonicalURL string            `json:"canonical_url,omitempty"`
+	Title        string            `json:"title,omitempty"`
+	Notes        string            `json:"notes,omitempty"`
+	Resource     string            `json:"resource,omitempty"`
+	Tags         []string          `json:"tags"`
+	Source       string            `json:"source,omitempty"`
+	Metadata     *metadataResponse `json:"metadata,omitempty"`
+	Status       string            `json:"status"`
+	NextStatuses []string          `json:"next_statuses"`
+	ReadingAt    *time.Time        `json:"reading_at,omitempty"`
+	DoneAt       *time.Time        `json:"done_at,omitempty"`
+	ArchivedAt   *time.Time        `json:"archived_at,omitempty"`
+	Views        int64             `json:"views"`
+	ViewedAt     *time.Time        `json:"viewed_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// createLinkResponse tells whether the link was just created or had been saved before.
+type createLinkResponse struct {
+	linkResponse
+	AlreadySaved bool `json:"already_saved"`
+}
+
+// linkPageResponse is a page of a link listing; next_cursor is empty on the last page.
+type linkPageResponse struct {
+	Links      []linkResponse `json:"links"`
+	NextCursor string         `json:"next_cursor"`
+	Total      int64          `json:"total"`
+}
+
+type metadataResponse struct {
+	Description  string    `json:"description,omitempty"`
+	SiteName     string    `json:"site_name,omitempty"`
+	ImageURL     string    `json:"image_url,omitempty"`
+	CanonicalURL string    `json:"canonical_url,omitempty"`
+	Language     string    `json:"language,omitempty"`
+	FaviconURL   string    `json:"favicon_url,omitempty"`
+	Type         string    `json:"type,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+type searchResultResponse struct {
+	linkResponse
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet,omitempty"`
+}
+
+type setStatusRequest struct {
+	Status string `json:"status"`
+}
+
+type linkViewResponse struct {
+	ID       string    `json:"id"`
+	LinkID   string    `json:"link_id"`
+	ViewedAt time.Time `json:"viewed_at"`
 }
 
 func Health(w http.ResponseWriter, _ *http.Request) {
@@ -45,24 +106,33 @@ func (s *Server) Create() http.HandlerFunc {
 			return
 		}
 		req.URL = strings.TrimSpace(req.URL)
+		req.Title = strings.TrimSpace(req.Title)
 		req.Resource = strings.TrimSpace(req.Resource)
 		input := apiservice.LinkCreateInput{
+			UserID:   userID(r),
 			URL:      req.URL,
+			Title:    req.Title,
+			Notes:    req.Notes,
 			Resource: req.Resource,
+			Tags:     req.Tags,
 		}
 		link, err := s.uc.Create(r.Context(), input)
+		if errors.Is(err, apiservice.ErrAlreadyExists) {
+			writeJSON(w, http.StatusOK, createLinkResponse{linkResponse: toLinkResponse(link), AlreadySaved: true})
+			return
+		}
 		if err != nil {
 			writeError(w, err)
 			return
 		}
-		writeJSON(w, http.StatusCreated, toLinkResponse(link))
+		writeJSON(w, http.StatusCreated, createLinkResponse{linkResponse: toLinkResponse(link)})
 	}
 }
 
 func (s *Server) Get() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := mux.Vars(r)["id"]
-		link, err := s.uc.GetByID(r.Context(), id)
+		link, err := s.uc.GetByID(r.Context(), userID(r), id)
 		if err != nil {
 			writeError(w, err)
 			return
@@ -73,28 +143,28 @@ func (s *Server) Get() http.HandlerFunc {
 
 func (s *Server) List() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		limit := parseIntDefault(r.URL.Query().Get("limit"), 50)
-		offset := parseIntDefault(r.URL.Query().Get("offset"), 0)
-		if limit > 200 {
-			limit = 200
-		}
-		links, err := s.uc.List(r.Context(), limit, offset)
+		q := r.URL.Query()
+		page, err := s.uc.List(r.Context(), userID(r), linkFilter(r), apiservice.PageRequest{
+			Sort:   q.Get("sort"),
+			Order:  q.Get("order"),
+			Cursor: q.Get("cursor"),
+			Limit:  parseIntDefault(q.Get("limit"), 0),
+		})
 		if err != nil {
 			writeError(w, err)
 			return
 		}
-		resp := make([]linkResponse, 0, len(links))
-		for _, link := range links {
-			resp = append(resp, toLinkResponse(link))
-		}
-		writeJSON(w, http.StatusOK, resp)
+		writeJSON(w, http.StatusOK, linkPageResponse{
+			Links:      toLinkResponses(page.Links),
+			NextCursor: page.NextCursor,
+			Total:      page.Total,
+		})
 	}
 }
 
 func (s *Server) Random() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		resource := strings.TrimSpace(r.URL.Query().Get("resource"))
-		link, err := s.uc.Random(r.Context(), resource)
+		link, err := s.uc.Random(r.Context(), userID(r), linkFilter(r))
 		if err != nil {
 			writeError(w, err)
 			return
@@ -103,6 +173,31 @@ func (s *Server) Random() http.HandlerFunc {
 	}
 }
 
+func (s *Server) Search() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		limit := parseIntDefault(r.URL.Query().Get("limit"), 20)
+		offset := parseIntDefault(r.URL.Query().Get("offset"), 0)
+		if limit > 200 {
+			limit = 200
+		}
+		results, err := s.uc.Search(r.Context(), userID(r), query, linkFilter(r), limit, offset)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		resp := make([]searchResultResponse, 0, len(results))
+		for _, result := range results {
+			resp = append(resp, searchResultResponse{
+				linkResponse: toLinkResponse(result.Link),
+				Rank:         result.Rank,
+				Snippet:      result.Snippet,
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
 func (s *Server) Update() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := mux.Vars(r)["id"]
@@ -115,15 +210,23 @@ func (s *Server) Update() http.HandlerFunc {
 			trimmed := strings.TrimSpace(*req.URL)
 			req.URL = &trimmed
 		}
+		if req.Title != nil {
+			trimmed := strings.TrimSpace(*req.Title)
+			req.Title = &trimmed
+		}
 		if req.Resource != nil {
 			trimmed := strings.TrimSpace(*req.Resource)
 			req.Resource = &trimmed
 		}
 		input := apiservice.LinkUpdateInput{
-			URL:      req.URL,
-			Resource: req.Resource,
+			URL:        req.URL,
+			Title:      req.Title,
+			Notes:      req.Notes,
+			Resource:   req.Resource,
+			AddTags:    req.AddTags,
+			RemoveTags: req.RemoveTags,
 		}
-		link, err := s.uc.Update(r.Context(), id, input)
+		link, err := s.uc.Update(r.Context(), userID(r), id, input)
 		if err != nil {
 			writeError(w, err)
 			return
@@ -135,7 +238,7 @@ func (s *Server) Update() http.HandlerFunc {
 func (s *Server) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := mux.Vars(r)["id"]
-		if err := s.uc.Delete(r.Context(), id); err != nil {
+		if err := s.uc.Delete(r.Context(), userID(r), id); err != nil {
 			writeError(w, err)
 			return
 		}
@@ -146,7 +249,24 @@ func (s *Server) Delete() http.HandlerFunc {
 func (s *Server) MarkViewed() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := mux.Vars(r)["id"]
-		link, err := s.uc.MarkViewed(r.Context(), id)
+		link, err := s.uc.MarkViewed(r.Context(), userID(r), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toLinkResponse(link))
+	}
+}
+
+func (s *Server) SetStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		var req setStatusRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		link, err := s.uc.SetStatus(r.Context(), userID(r), id, req.Status)
 		if err != nil {
 			writeError(w, err)
 			return
@@ -155,13 +275,38 @@ func (s *Server) MarkViewed() http.HandlerFunc {
 	}
 }
 
+func (s *Server) ListViews() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		limit := parseIntDefault(r.URL.Query().Get("limit"), 50)
+		offset := parseIntDefault(r.URL.Query().Get("offset"), 0)
+		if limit > 200 {
+			limit = 200
+		}
+		views, err := s.uc.ListViews(r.Context(), userID(r), id, limit, offset)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		resp := make([]linkViewResponse, 0, len(views))
+		for _, view := range views {
+			resp = append(resp, linkViewResponse{
+				ID:       view.ID,
+				LinkID:   view.LinkID,
+				ViewedAt: view.ViewedAt,
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
 func (s *Server) GetViewStats() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		days := parseIntDefault(r.URL.Query().Get("days"), 53)
 		if days > 365 {
 			days = 365
 		}
-		stats, err := s.uc.GetViewStats(r.Context(), days)
+		stats, err := s.uc.GetViewStats(r.Context(), userID(r), linkFilter(r), days)
 		if err != nil {
 			writeError(w, err)
 			return
@@ -182,6 +327,10 @@ func writeError(w http.ResponseWriter, err error) {
 		http.Error(w, "not found", http.StatusNotFound)
 	case errors.Is(err, apiservice.ErrInvalidInput):
 		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, apiservice.ErrAlreadyExists):
+		http.Error(w, "link already saved", http.StatusConflict)
+	case errors.Is(err, apiservice.ErrConflict):
+		http.Error(w, "link was changed concurrently, try again", http.StatusConflict)
 	default:
 		http.Error(w, "internal error", http.StatusInternalServerError)
 	}
@@ -189,16 +338,81 @@ func writeError(w http.ResponseWriter, err error) {
 
 func toLinkResponse(link apiservice.Link) linkResponse {
 	return linkResponse{
-		ID:        link.ID,
-		URL:       link.URL,
-		Resource:  link.Resource,
-		Views:     link.Views,
-		ViewedAt:  link.ViewedAt,
-		CreatedAt: link.CreatedAt,
-		UpdatedAt: link.UpdatedAt,
+		ID:           link.ID,
+		UserID:       link.UserID,
+		URL:          link.URL,
+		CanonicalURL: link.CanonicalURL,
+		Title:        link.Title,
+		Notes:        link.Notes,
+		Resource:     link.Resource,
+		Tags:         nonNil(link.Tags),
+		Source:       link.Source,
+		Metadata:     toMetadataResponse(link.Metadata),
+		Status:       link.Status,
+		NextStatuses: nonNil(apiservice.NextStatuses(link.Status)),
+		ReadingAt:    link.ReadingAt,
+		DoneAt:       link.DoneAt,
+		ArchivedAt:   link.ArchivedAt,
+		Views:        link.Views,
+		ViewedAt:     link.ViewedAt,
+		CreatedAt:    link.CreatedAt,
+		UpdatedAt:    link.UpdatedAt,
 	}
 }
 
+// toMetadataResponse returns nil until the link's page has been fetched.
+func toMetadataResponse(meta apiservice.LinkMetadata) *metadataResponse {
+	if meta.FetchedAt == nil {
+		return nil
+	}
+	return &metadataResponse{
+		Description:  meta.Description,
+		SiteName:     meta.SiteName,
+		ImageURL:     meta.ImageURL,
+		CanonicalURL: meta.CanonicalURL,
+		Language:     meta.Language,
+		FaviconURL:   meta.FaviconURL,
+		Type:         meta.Type,
+		ContentType:  meta.ContentType,
+		FetchedAt:    *meta.FetchedAt,
+	}
+}
+
+// linkFilter reads the resource, tag and status filters of a request. Tags and
+// statuses may be given as repeated parameters or as a comma-separated list.
+func linkFilter(r *http.Request) apiservice.LinkFilter {
+	q := r.URL.Query()
+	return apiservice.LinkFilter{
+		Resource: strings.TrimSpace(q.Get("resource")),
+		Tags:     listParam(q["tag"]),
+		Statuses: listParam(q["status"]),
+	}
+}
+
+func listParam(raw []string) []string {
+	var out []string
+	for _, value := range raw {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// userID is the user the request was authenticated as; see auth.Middleware.
+func userID(r *http.Request) string {
+	return auth.UserID(r.Context())
+}
+
 func parseIntDefault(raw string, def int) int {
 	if raw == "" {
 		return def
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type subscribeRequest struct {
+	URL  string   `json:"url"`
+	Tags []string `json:"tags"`
+}
+
+type updateSubscriptionRequest struct {
+	Title *string   `json:"title"`
+	Tags  *[]string `json:"tags"`
+}
+
+type subscriptionResponse struct {
+	ID           string     `json:"id"`
+	FeedURL      string     `json:"feed_url"`
+	SiteURL      string     `json:"site_url,omitempty"`
+	Title        string     `json:"title"`
+	Tags         []string   `json:"tags"`
+	LastPolledAt *time.Time `json:"last_polled_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	NextPollAt   time.Time  `json:"next_poll_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func toSubscriptionResponse(sub apiservice.Subscription) subscriptionResponse {
+	return subscriptionResponse{
+		ID:           sub.ID,
+		FeedURL:      sub.FeedURL,
+		SiteURL:      sub.SiteURL,
+		Title:        sub.Title,
+		Tags:         nonNil(sub.Tags),
+		LastPolledAt: sub.LastPolledAt,
+		LastError:    sub.LastError,
+		NextPollAt:   sub.NextPollAt,
+		CreatedAt:    sub.CreatedAt,
+		UpdatedAt:    sub.UpdatedAt,
+	}
+}
+
+// Subscribe follows a feed. The URL may also be a web page that advertises its feed.
+func (s *Server) Subscribe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req subscribeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		sub, err := s.subscriptions.Subscribe(r.Context(), apiservice.SubscriptionInput{
+			UserID:  userID(r),
+			FeedURL: req.URL,
+			Tags:    req.Tags,
+		})
+		if errors.Is(err, apiservice.ErrAlreadyExists) {
+			http.Error(w, "already subscribed to this feed", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, toSubscriptionResponse(sub))
+	}
+}
+
+func (s *Server) ListSubscriptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subs, err := s.subscriptions.List(r.Context(), userID(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		resp := make([]subscriptionResponse, 0, len(subs))
+		for _, sub := range subs {
+			resp = append(resp, toSubscriptionResponse(sub))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) GetSubscription() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sub, err := s.subscriptions.Get(r.Context(), userID(r), mux.Vars(r)["id"])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toSubscriptionResponse(sub))
+	}
+}
+
+func (s *Server) UpdateSubscription() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req updateSubscriptionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		sub, err := s.subscriptions.Update(r.Context(), userID(r), mux.Vars(r)["id"], apiservice.SubscriptionUpdate{
+			Title: req.Title,
+			Tags:  req.Tags,
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toSubscriptionResponse(sub))
+	}
+}
+
+func (s *Server) Unsubscribe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.subscriptions.Unsubscribe(r.Context(), userID(r), mux.Vars(r)["id"]); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type tagResponse struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type renameTagRequest struct {
+	Name string `json:"name"`
+}
+
+type mergeTagsRequest struct {
+	Sources []string `json:"sources"`
+	Into    string   `json:"into"`
+}
+
+func (s *Server) ListTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tags, err := s.tags.List(r.Context(), userID(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		resp := make([]tagResponse, 0, len(tags))
+		for _, tag := range tags {
+			resp = append(resp, toTagResponse(tag))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) RenameTag() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["name"]
+		var req renameTagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		tag, err := s.tags.Rename(r.Context(), userID(r), name, req.Name)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toTagResponse(tag))
+	}
+}
+
+func (s *Server) MergeTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req mergeTagsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		tag, err := s.tags.Merge(r.Context(), userID(r), req.Sources, req.Into)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toTagResponse(tag))
+	}
+}
+
+func toTagResponse(tag apiservice.Tag) tagResponse {
+	return tagResponse{
+		Name:  tag.Name,
+		Count: tag.Count,
+	}
+}
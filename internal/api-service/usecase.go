@@ -1,14 +1,90 @@
 package apiservice
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 type LinkService interface {
+	// Create saves a link. When the URL is already saved it returns the existing
+	// link together with ErrAlreadyExists.
 	Create(ctx context.Context, input LinkCreateInput) (Link, error)
-	GetByID(ctx context.Context, id string) (Link, error)
-	List(ctx context.Context, limit, offset int) ([]Link, error)
-	Random(ctx context.Context, resource string) (Link, error)
-	Update(ctx context.Context, id string, input LinkUpdateInput) (Link, error)
-	Delete(ctx context.Context, id string) error
-	MarkViewed(ctx context.Context, id string) (Link, error)
-	GetViewStats(ctx context.Context, days int) ([]ViewStats, error)
+	GetByID(ctx context.Context, userID, id string) (Link, error)
+	// List returns a page of links in keyset order, so that pages stay stable
+	// while links are added or removed.
+	List(ctx context.Context, userID string, filter LinkFilter, page PageRequest) (LinkPage, error)
+	Random(ctx context.Context, userID string, filter LinkFilter) (Link, error)
+	Search(ctx context.Context, userID, query string, filter LinkFilter, limit, offset int) ([]SearchResult, error)
+	Update(ctx context.Context, userID, id string, input LinkUpdateInput) (Link, error)
+	Delete(ctx context.Context, userID, id string) error
+	MarkViewed(ctx context.Context, userID, id string) (Link, error)
+	SetStatus(ctx context.Context, userID, id, status string) (Link, error)
+	ListViews(ctx context.Context, userID, id string, limit, offset int) ([]LinkView, error)
+	GetViewStats(ctx context.Context, userID string, filter LinkFilter, days int) ([]ViewStats, error)
+	// FindDuplicates groups the links of a user that canonicalize to the same URL.
+	FindDuplicates(ctx context.Context, userID string) ([]DuplicateGroup, error)
+	// MergeDuplicates merges every duplicate group into its oldest link.
+	MergeDuplicates(ctx context.Context, userID string) (MergeResult, error)
+	// Reclassify re-runs the classifier over the links of every user, or of one
+	// user when userID is set. Links that already have a resource are only
+	// changed when overwrite is set.
+	Reclassify(ctx context.Context, userID string, overwrite bool) (ReclassifyResult, error)
+	// ImportBookmarks saves the links of a Netscape bookmark file as it is read.
+	// Folders become tags and pages that are already saved are skipped. A dry
+	// run only reports what would happen.
+	ImportBookmarks(ctx context.Context, userID string, r io.Reader, dryRun bool) (ImportResult, error)
+	// ExportBookmarks writes every link of a user as a Netscape bookmark file.
+	ExportBookmarks(ctx context.Context, userID string, w io.Writer) error
+}
+
+// Classifier infers the resource type of a link from its URL and fetched
+// metadata. It returns "" when it cannot tell.
+type Classifier interface {
+	Classify(link Link) string
+}
+
+type TagService interface {
+	List(ctx context.Context, userID string) ([]Tag, error)
+	Rename(ctx context.Context, userID, from, to string) (Tag, error)
+	Merge(ctx context.Context, userID string, sources []string, into string) (Tag, error)
+}
+
+// ImportService runs imports of other services' export files in the background.
+type ImportService interface {
+	// Submit checks that the file can be read in the given format and queues it for import.
+	Submit(ctx context.Context, userID, format string, payload []byte) (ImportJob, error)
+	Get(ctx context.Context, userID, id string) (ImportJob, error)
+	List(ctx context.Context, userID string) ([]ImportJob, error)
+}
+
+// BackupService takes and restores full backups of a user's data.
+type BackupService interface {
+	// Export writes a backup of the user's profile, links, tags and views.
+	Export(ctx context.Context, userID string, w io.Writer) error
+	// Restore merges a backup into the user's account; see LinkRepository.Restore.
+	Restore(ctx context.Context, userID string, r io.Reader) (RestoreResult, error)
+}
+
+// SubscriptionService follows RSS and Atom feeds for users and saves their new entries as links.
+type SubscriptionService interface {
+	// Subscribe follows the feed at input.FeedURL, or the feed a page at that
+	// URL advertises. Entries already in the feed are not saved; only the
+	// ones that appear later are.
+	Subscribe(ctx context.Context, input SubscriptionInput) (Subscription, error)
+	Get(ctx context.Context, userID, id string) (Subscription, error)
+	List(ctx context.Context, userID string) ([]Subscription, error)
+	Update(ctx context.Context, userID, id string, input SubscriptionUpdate) (Subscription, error)
+	// Unsubscribe stops following a feed. Links already saved from it are kept.
+	Unsubscribe(ctx context.Context, userID, id string) error
+}
+
+// FeedService manages the tokens that let feed readers, which cannot send the
+// user header, read a user's link feeds.
+type FeedService interface {
+	// RotateToken issues a new feed token and revokes the previous one. Only
+	// a hash is stored, so the token cannot be shown again later.
+	RotateToken(ctx context.Context, userID string) (string, error)
+	RevokeToken(ctx context.Context, userID string) error
+	// UserID returns the user a feed token belongs to.
+	UserID(ctx context.Context, token string) (string, error)
 }
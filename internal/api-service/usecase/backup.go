@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/internal/api-service/backup"
+)
+
+// BackupService exports and restores whole accounts. Profiles come from
+// user-service; without a profile source backups carry no profile.
+type BackupService struct {
+	links    *LinkService
+	profiles apiservice.ProfileSource
+}
+
+var _ apiservice.BackupService = (*BackupService)(nil)
+
+// NewBackupService returns a BackupService; profiles may be nil.
+func NewBackupService(links *LinkService, profiles apiservice.ProfileSource) *BackupService {
+	return &BackupService{links: links, profiles: profiles}
+}
+
+// Export streams links oldest first, a batch at a time, each with its view events.
+func (s *BackupService) Export(ctx context.Context, userID string, w io.Writer) error {
+	if err := validateUserID(userID); err != nil {
+		return err
+	}
+	var user *backup.User
+	if s.profiles != nil {
+		profile, err := s.profiles.GetProfile(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("loading profile: %w", err)
+		}
+		user = &backup.User{
+			ID:         profile.ID,
+			TelegramID: profile.TelegramID,
+			Username:   profile.Username,
+			FirstName:  profile.FirstName,
+			LastName:   profile.LastName,
+			CreatedAt:  profile.CreatedAt,
+		}
+	}
+
+	bw := backup.NewWriter(w, time.Now().UTC(), user)
+	repo := s.links.repo
+	query := apiservice.ListQuery{Sort: apiservice.SortCreated, Limit: exportBatchSize}
+	for {
+		links, err := repo.List(ctx, userID, apiservice.LinkFilter{}, query)
+		if err != nil {
+			return err
+		}
+		ids := make([]string, 0, len(links))
+		for _, link := range links {
+			ids = append(ids, link.ID)
+		}
+		views, err := repo.ListViewEvents(ctx, userID, ids)
+		if err != nil {
+			return err
+		}
+		byLink := make(map[string][]backup.View, len(links))
+		for _, v := range views {
+			byLink[v.LinkID] = append(byLink[v.LinkID], backup.View{ID: v.ID, ViewedAt: v.ViewedAt})
+		}
+		for _, link := range links {
+			if err := bw.Write(toBackupLink(link, byLink[link.ID])); err != nil {
+				return err
+			}
+		}
+		if len(links) < query.Limit {
+			return bw.Close()
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		query.After = &links[len(links)-1]
+	}
+}
+
+// Restore merges a backup into the account of userID, which need not be the
+// account it was taken from. Links that cannot be restored are reported and
+// skipped. The profile in the backup is not restored: it belongs to the
+// Telegram account and user-service keeps it up to date.
+func (s *BackupService) Restore(ctx context.Context, userID string, r io.Reader) (apiservice.RestoreResult, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.RestoreResult{}, err
+	}
+	archive, err := backup.Read(r)
+	if err != nil {
+		return apiservice.RestoreResult{}, fmt.Errorf("%w: reading backup: %w", apiservice.ErrInvalidInput, err)
+	}
+
+	result := apiservice.RestoreResult{Version: archive.Version, Errors: []apiservice.ImportError{}}
+	for i, bl := range archive.Links {
+		link, views, err := s.fromBackupLink(bl)
+		if err != nil {
+			result.Invalid++
+			result.Errors = append(result.Errors, apiservice.ImportError{
+				Row:   i + 1,
+				URL:   bl.URL,
+				Error: invalidItem(apiservice.ImportItem{}, err).Error,
+			})
+			continue
+		}
+		restored, err := s.links.repo.Restore(ctx, userID, link, views)
+		if err != nil {
+			return apiservice.RestoreResult{}, err
+		}
+		switch restored.Outcome {
+		case apiservice.RestoreCreated:
+			result.Created++
+		case apiservice.RestoreExisting:
+			result.Existing++
+		case apiservice.RestoreMerged:
+			result.Merged++
+		}
+		result.Views += restored.Views
+	}
+	return result, nil
+}
+
+// fromBackupLink checks a link from a backup. The canonical URL is derived
+// again, since the rules may have changed since the backup was taken, and
+// IDs that are not UUIDs are dropped so that the repository assigns new ones.
+func (s *BackupService) fromBackupLink(bl backup.Link) (apiservice.Link, []apiservice.LinkView, error) {
+	if u, err := url.Parse(bl.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return apiservice.Link{}, nil, fmt.Errorf("%w: url must be http or https", apiservice.ErrInvalidInput)
+	}
+	canonical, err := s.links.canonicalizer.Canonicalize(bl.URL)
+	if err != nil {
+		return apiservice.Link{}, nil, err
+	}
+	status := apiservice.StatusUnread
+	if bl.Status != "" {
+		if status, err = normalizeStatus(bl.Status); err != nil {
+			return apiservice.Link{}, nil, err
+		}
+	}
+	if bl.CreatedAt.IsZero() {
+		return apiservice.Link{}, nil, fmt.Errorf("%w: created_at is required", apiservice.ErrInvalidInput)
+	}
+
+	link := apiservice.Link{
+		ID:           validUUID(bl.ID),
+		URL:          bl.URL,
+		CanonicalURL: canonical,
+		Title:        bl.Title,
+		Notes:        bl.Notes,
+		Resource:     bl.Resource,
+		Tags:         importTags(bl.Tags),
+		Source:       bl.Source,
+		Status:       status,
+		ReadingAt:    bl.ReadingAt,
+		DoneAt:       bl.DoneAt,
+		ArchivedAt:   bl.ArchivedAt,
+		Views:        max(bl.Views, int64(len(bl.ViewEvents))),
+		ViewedAt:     bl.ViewedAt,
+		CreatedAt:    bl.CreatedAt,
+		UpdatedAt:    bl.UpdatedAt,
+	}
+	if m := bl.Metadata; m != nil {
+		link.Metadata = apiservice.LinkMetadata{
+			Description:  m.Description,
+			SiteName:     m.SiteName,
+			ImageURL:     m.ImageURL,
+			CanonicalURL: m.CanonicalURL,
+			Language:     m.Language,
+			FaviconURL:   m.FaviconURL,
+			Type:         m.Type,
+			ContentType:  m.ContentType,
+			FetchedAt:    m.FetchedAt,
+		}
+	}
+	views := make([]apiservice.LinkView, 0, len(bl.ViewEvents))
+	for _, v := range bl.ViewEvents {
+		if v.ViewedAt.IsZero() {
+			continue
+		}
+		views = append(views, apiservice.LinkView{ID: validUUID(v.ID), ViewedAt: v.ViewedAt})
+	}
+	return link, views, nil
+}
+
+func toBackupLink(link apiservice.Link, views []backup.View) backup.Link {
+	bl := backup.Link{
+		ID:           link.ID,
+		URL:          link.URL,
+		CanonicalURL: link.CanonicalURL,
+		Title:        link.Title,
+		Notes:        link.Notes,
+		Resource:     link.Resource,
+		Tags:         link.Tags,
+		Source:       link.Source,
+		Status:       link.Status,
+		ReadingAt:    link.ReadingAt,
+		DoneAt:       link.DoneAt,
+		ArchivedAt:   link.ArchivedAt,
+		Views:        link.Views,
+		ViewedAt:     link.ViewedAt,
+		ViewEvents:   views,
+		CreatedAt:    link.CreatedAt,
+		UpdatedAt:    link.UpdatedAt,
+	}
+	if m := link.Metadata; m != (apiservice.LinkMetadata{}) {
+		bl.Metadata = &backup.Metadata{
+			Description:  m.Description,
+			SiteName:     m.SiteName,
+			ImageURL:     m.ImageURL,
+			CanonicalURL: m.CanonicalURL,
+			Language:     m.Language,
+			FaviconURL:   m.FaviconURL,
+			Type:         m.Type,
+			ContentType:  m.ContentType,
+			FetchedAt:    m.FetchedAt,
+		}
+	}
+	return bl
+}
+
+// validUUID returns id when it is a UUID and "" otherwise.
+func validUUID(id string) string {
+	if uuid.Validate(id) != nil {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/internal/api-service/backup"
+)
+
+type stubProfiles map[string]apiservice.Profile
+
+func (p stubProfiles) GetProfile(_ context.Context, userID string) (apiservice.Profile, error) {
+	profile, ok := p[userID]
+	if !ok {
+		return apiservice.Profile{}, apiservice.ErrNotFound
+	}
+	return profile, nil
+}
+
+func TestBackupService_Export(t *testing.T) {
+	mockRepo := new(MockRepository)
+	profiles := stubProfiles{testUserID: {ID: testUserID, TelegramID: 42, Username: "reader"}}
+	service := NewBackupService(NewLinkService(mockRepo), profiles)
+	ctx := context.Background()
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	links := []apiservice.Link{
+		{ID: "l1", URL: "https://go.dev/", Tags: []string{"golang"}, Status: apiservice.StatusDone, Views: 1, CreatedAt: created},
+		{ID: "l2", URL: "https://example.com/", Status: apiservice.StatusUnread, CreatedAt: created.Add(time.Hour)},
+	}
+	mockRepo.On("List", ctx, testUserID, apiservice.LinkFilter{},
+		apiservice.ListQuery{Sort: apiservice.SortCreated, Limit: exportBatchSize}).Return(links, nil)
+	mockRepo.On("ListViewEvents", ctx, testUserID, []string{"l1", "l2"}).
+		Return([]apiservice.LinkView{{ID: "v1", LinkID: "l1", ViewedAt: created.Add(time.Minute)}}, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.Export(ctx, testUserID, &buf))
+
+	archive, err := backup.Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, backup.Version, archive.Version)
+	require.NotNil(t, archive.User)
+	assert.Equal(t, int64(42), archive.User.TelegramID)
+	require.Len(t, archive.Links, 2)
+	assert.Equal(t, []backup.View{{ID: "v1", ViewedAt: created.Add(time.Minute)}}, archive.Links[0].ViewEvents)
+	assert.Equal(t, []string{"golang"}, archive.Links[0].Tags)
+	assert.Empty(t, archive.Links[1].ViewEvents)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBackupService_Export_ProfileMissing(t *testing.T) {
+	service := NewBackupService(NewLinkService(new(MockRepository)), stubProfiles{})
+
+	err := service.Export(context.Background(), testUserID, &bytes.Buffer{})
+
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+}
+
+func TestBackupService_Restore(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewBackupService(NewLinkService(mockRepo), nil)
+	ctx := context.Background()
+
+	const linkID = "0b9f6e4c-2f0a-4d8e-9b7a-1c3d5e7f9a2b"
+	archive := `{"version":1,"links":[
+{"id":"` + linkID + `","url":"https://go.dev/?utm_source=x","tags":["Go"],"status":"done","views":2,
+ "view_events":[{"id":"not-a-uuid","viewed_at":"2024-01-02T00:00:00Z"}],"created_at":"2024-01-01T00:00:00Z"},
+{"id":"x","url":"javascript:void(0)","status":"unread","created_at":"2024-01-01T00:00:00Z"},
+{"id":"y","url":"https://example.com/","status":"lost","created_at":"2024-01-01T00:00:00Z"}
+]}`
+
+	mockRepo.On("Restore", ctx, testUserID, mock.MatchedBy(func(link apiservice.Link) bool {
+		return link.ID == linkID && link.CanonicalURL == "https://go.dev/" &&
+			link.Status == apiservice.StatusDone && link.Views == 2 &&
+			len(link.Tags) == 1 && link.Tags[0] == "go"
+	}), []apiservice.LinkView{{ViewedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}}).
+		Return(apiservice.RestoredLink{ID: linkID, Outcome: apiservice.RestoreCreated, Views: 1}, nil)
+
+	result, err := service.Restore(ctx, testUserID, strings.NewReader(archive))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Version)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Views)
+	assert.Equal(t, 2, result.Invalid)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, apiservice.ImportError{Row: 2, URL: "javascript:void(0)", Error: "url must be http or https"}, result.Errors[0])
+	assert.Equal(t, 3, result.Errors[1].Row)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBackupService_Restore_UnsupportedVersion(t *testing.T) {
+	service := NewBackupService(NewLinkService(new(MockRepository)), nil)
+
+	_, err := service.Restore(context.Background(), testUserID, strings.NewReader(`{"version":99,"links":[]}`))
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	assert.ErrorIs(t, err, backup.ErrUnsupportedVersion)
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/internal/api-service/bookmarks"
+	"github.com/danilovid/linkkeeper/internal/api-service/importers"
+)
+
+const exportBatchSize = 500
+
+// ImportBookmarks saves bookmarks one at a time as they are read, so large
+// files are never held in memory. Imported links are not queued for metadata
+// enrichment: they come with titles, and a large import would flood the queue.
+func (s *LinkService) ImportBookmarks(
+	ctx context.Context,
+	userID string,
+	r io.Reader,
+	dryRun bool,
+) (apiservice.ImportResult, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.ImportResult{}, err
+	}
+	src, err := importers.New(importers.FormatNetscape, r)
+	if err != nil {
+		return apiservice.ImportResult{}, err
+	}
+	result := apiservice.ImportResult{DryRun: dryRun, Items: []apiservice.ImportItem{}}
+	// seen maps the canonical URLs met so far in a dry run to their links, so
+	// that a page bookmarked in two folders is reported as a duplicate.
+	seen := map[string]string{}
+	for {
+		item, err := s.importNext(ctx, userID, src, dryRun, seen)
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return apiservice.ImportResult{}, err
+		}
+		switch item.Result {
+		case apiservice.ImportCreated:
+			result.Created++
+		case apiservice.ImportDuplicate:
+			result.Duplicates++
+		case apiservice.ImportInvalid:
+			result.Invalid++
+		}
+		result.Items = append(result.Items, item)
+	}
+}
+
+// importNext imports the next record of src. Rows that cannot be read or
+// saved are reported as invalid items; only other errors stop the import.
+func (s *LinkService) importNext(
+	ctx context.Context,
+	userID string,
+	src importers.Source,
+	dryRun bool,
+	seen map[string]string,
+) (apiservice.ImportItem, error) {
+	rec, err := src.Next()
+	var rowErr *importers.RowError
+	if errors.As(err, &rowErr) {
+		return apiservice.ImportItem{
+			Row:    rowErr.Row,
+			URL:    rowErr.URL,
+			Result: apiservice.ImportInvalid,
+			Error:  rowErr.Err.Error(),
+		}, nil
+	}
+	if errors.Is(err, io.EOF) {
+		return apiservice.ImportItem{}, io.EOF
+	}
+	if err != nil {
+		return apiservice.ImportItem{}, fmt.Errorf("%w: reading import file: %w", apiservice.ErrInvalidInput, err)
+	}
+	return s.importRecord(ctx, userID, rec, dryRun, seen)
+}
+
+func (s *LinkService) importRecord(
+	ctx context.Context,
+	userID string,
+	rec importers.Record,
+	dryRun bool,
+	seen map[string]string,
+) (apiservice.ImportItem, error) {
+	item := apiservice.ImportItem{
+		Row:       rec.Row,
+		URL:       rec.URL,
+		Title:     rec.Title,
+		Tags:      importTags(rec.Tags),
+		CreatedAt: rec.CreatedAt,
+	}
+	input := apiservice.LinkCreateInput{
+		UserID:    userID,
+		URL:       rec.URL,
+		Title:     rec.Title,
+		Notes:     rec.Notes,
+		Tags:      item.Tags,
+		CreatedAt: rec.CreatedAt,
+	}
+	// Export files hold bookmarklets and browser-internal pages, which
+	// withScheme would otherwise turn into https URLs.
+	if u, err := url.Parse(rec.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return invalidItem(item, fmt.Errorf("%w: url must be http or https", apiservice.ErrInvalidInput)), nil
+	}
+	canonical, err := s.canonicalizer.Canonicalize(input.URL)
+	if err != nil {
+		return invalidItem(item, err), nil
+	}
+
+	if dryRun {
+		if id, ok := seen[canonical]; ok {
+			item.Result, item.LinkID = apiservice.ImportDuplicate, id
+			return item, nil
+		}
+		existing, err := s.repo.GetByCanonicalURL(ctx, userID, canonical)
+		switch {
+		case err == nil:
+			item.Result, item.LinkID = apiservice.ImportDuplicate, existing.ID
+		case errors.Is(err, apiservice.ErrNotFound):
+			item.Result = apiservice.ImportCreated
+		default:
+			return apiservice.ImportItem{}, err
+		}
+		seen[canonical] = item.LinkID
+		return item, nil
+	}
+
+	link, err := s.create(ctx, input, false)
+	switch {
+	case err == nil:
+		item.Result, item.LinkID = apiservice.ImportCreated, link.ID
+	case errors.Is(err, apiservice.ErrAlreadyExists):
+		item.Result, item.LinkID = apiservice.ImportDuplicate, link.ID
+		return item, nil
+	case errors.Is(err, apiservice.ErrInvalidInput):
+		return invalidItem(item, err), nil
+	default:
+		return apiservice.ImportItem{}, err
+	}
+	// New links start unread; records the other service marked read follow it.
+	if rec.Status != "" && rec.Status != apiservice.StatusUnread {
+		if _, err := s.repo.SetStatus(ctx, userID, link.ID, apiservice.StatusUnread, rec.Status); err != nil {
+			return apiservice.ImportItem{}, err
+		}
+	}
+	return item, nil
+}
+
+// ExportBookmarks streams links oldest first, a batch at a time.
+func (s *LinkService) ExportBookmarks(ctx context.Context, userID string, w io.Writer) error {
+	if err := validateUserID(userID); err != nil {
+		return err
+	}
+	bw := bookmarks.NewWriter(w)
+	query := apiservice.ListQuery{Sort: apiservice.SortCreated, Limit: exportBatchSize}
+	for {
+		links, err := s.repo.List(ctx, userID, apiservice.LinkFilter{}, query)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if err := bw.Write(bookmarks.Bookmark{
+				URL:          link.URL,
+				Title:        link.Title,
+				Description:  link.Notes,
+				Tags:         link.Tags,
+				AddDate:      link.CreatedAt,
+				LastModified: link.UpdatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		if len(links) < query.Limit {
+			return bw.Close()
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		query.After = &links[len(links)-1]
+	}
+}
+
+// importTags turns the folder and tag names of an export file into valid tag
+// names, dropping the ones that cannot be salvaged.
+func importTags(names []string) []string {
+	var tags []string
+	seen := map[string]struct{}{}
+	for _, raw := range names {
+		name := strings.Join(strings.Fields(strings.ReplaceAll(raw, ",", " ")), " ")
+		for utf8.RuneCountInString(name) > maxTagLength {
+			_, size := utf8.DecodeLastRuneInString(name)
+			name = name[:len(name)-size]
+		}
+		name, err := normalizeTag(name)
+		if err != nil {
+			continue
+		}
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+func invalidItem(item apiservice.ImportItem, err error) apiservice.ImportItem {
+	item.Result = apiservice.ImportInvalid
+	item.Error = strings.TrimPrefix(err.Error(), apiservice.ErrInvalidInput.Error()+": ")
+	return item
+}
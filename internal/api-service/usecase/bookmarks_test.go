@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+const bookmarkFile = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<DL><p>
+    <DT><H3>Go, Tools</H3>
+    <DL><p>
+        <DT><A HREF="https://go.dev/?utm_source=x" ADD_DATE="1600000000">Go</A>
+        <DD>The home page
+        <DT><A HREF="javascript:void(0)">Bookmarklet</A>
+    </DL><p>
+    <DT><A HREF="https://example.com/" TAGS="Misc">Example</A>
+    <DT><A HREF="https://www.example.com">Example again</A>
+</DL><p>`
+
+func TestLinkService_ImportBookmarks(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	goInput := withCanonicalURL(apiservice.LinkCreateInput{
+		UserID:    testUserID,
+		URL:       "https://go.dev/?utm_source=x",
+		Title:     "Go",
+		Notes:     "The home page",
+		Tags:      []string{"go tools"},
+		CreatedAt: time.Unix(1600000000, 0).UTC(),
+	})
+	mockRepo.On("Create", ctx, goInput).Return(apiservice.Link{ID: "go"}, nil)
+	mockRepo.On("Create", ctx, withCanonicalURL(apiservice.LinkCreateInput{
+		UserID: testUserID,
+		URL:    "https://example.com/",
+		Title:  "Example",
+		Tags:   []string{"misc"},
+	})).Return(apiservice.Link{ID: "example"}, nil)
+	mockRepo.On("Create", ctx, withCanonicalURL(apiservice.LinkCreateInput{
+		UserID: testUserID,
+		URL:    "https://www.example.com",
+		Title:  "Example again",
+	})).Return(apiservice.Link{ID: "example"}, apiservice.ErrAlreadyExists)
+
+	result, err := service.ImportBookmarks(ctx, testUserID, strings.NewReader(bookmarkFile), false)
+
+	require.NoError(t, err)
+	assert.False(t, result.DryRun)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 1, result.Duplicates)
+	assert.Equal(t, 1, result.Invalid)
+	require.Len(t, result.Items, 4)
+	assert.Equal(t, apiservice.ImportCreated, result.Items[0].Result)
+	assert.Equal(t, "go", result.Items[0].LinkID)
+	assert.Equal(t, apiservice.ImportInvalid, result.Items[1].Result)
+	assert.Equal(t, "url must be http or https", result.Items[1].Error)
+	assert.Equal(t, apiservice.ImportDuplicate, result.Items[3].Result)
+	assert.Equal(t, "example", result.Items[3].LinkID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkService_ImportBookmarks_DryRun(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("GetByCanonicalURL", ctx, testUserID, "https://go.dev/").
+		Return(apiservice.Link{ID: "saved"}, nil)
+	mockRepo.On("GetByCanonicalURL", ctx, testUserID, "https://example.com/").
+		Return(apiservice.Link{}, apiservice.ErrNotFound).Once()
+
+	result, err := service.ImportBookmarks(ctx, testUserID, strings.NewReader(bookmarkFile), true)
+
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 2, result.Duplicates)
+	assert.Equal(t, 1, result.Invalid)
+	assert.Equal(t, "saved", result.Items[0].LinkID)
+	assert.Equal(t, apiservice.ImportCreated, result.Items[2].Result)
+	assert.Equal(t, apiservice.ImportDuplicate, result.Items[3].Result)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLinkService_ExportBookmarks(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	links := []apiservice.Link{{
+		ID:        "1",
+		URL:       "https://go.dev/",
+		Title:     "Go & friends",
+		Notes:     "Read later",
+		Tags:      []string{"go", "tools"},
+		CreatedAt: time.Unix(1600000000, 0),
+	}}
+	mockRepo.On("List", ctx, testUserID, apiservice.LinkFilter{},
+		apiservice.ListQuery{Sort: apiservice.SortCreated, Limit: exportBatchSize}).Return(links, nil)
+
+	var out strings.Builder
+	require.NoError(t, service.ExportBookmarks(ctx, testUserID, &out))
+
+	assert.Contains(t, out.String(), `<DT><A HREF="https://go.dev/" ADD_DATE="1600000000" TAGS="go,tools">Go &amp; friends</A>`)
+	assert.Contains(t, out.String(), "<DD>Read later\n")
+	assert.True(t, strings.HasSuffix(out.String(), "</DL><p>\n"))
+}
+
+func TestImportTags(t *testing.T) {
+	long := strings.Repeat("ж", maxTagLength+5)
+
+	tags := importTags([]string{"Research", " #Go ", "research", long, "a,b"})
+
+	assert.Equal(t, []string{"research", "go", strings.Repeat("ж", maxTagLength), "a b"}, tags)
+}
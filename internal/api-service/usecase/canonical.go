@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// CanonicalRules configures how URLs are canonicalized for duplicate detection.
+type CanonicalRules struct {
+	// StripParams lists query parameters to drop. A trailing "*" matches by prefix, as in "utm_*".
+	StripParams []string
+	// StripHostPrefixes are removed from the start of the host, such as "www." or "m.".
+	StripHostPrefixes []string
+	// KeepFragment keeps "#..." parts. Fragments that look like client-side
+	// routes ("#!" or "#/") are kept regardless.
+	KeepFragment bool
+	// UpgradeHTTP treats http and https URLs as the same page.
+	UpgradeHTTP bool
+}
+
+// DefaultCanonicalRules strips common tracking parameters and mobile/www hosts.
+func DefaultCanonicalRules() CanonicalRules {
+	return CanonicalRules{
+		StripParams: []string{
+			"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid", "twclid",
+			"igshid", "mc_cid", "mc_eid", "_hsenc", "_hsmi", "mkt_tok", "ref_src", "ref_url", "si", "spm",
+		},
+		StripHostPrefixes: []string{"www.", "m.", "mobile."},
+		UpgradeHTTP:       true,
+	}
+}
+
+// Canonicalizer turns URLs into a canonical form so that the same page saved
+// through different URLs is recognized as one link.
+type Canonicalizer struct {
+	rules CanonicalRules
+}
+
+func NewCanonicalizer(rules CanonicalRules) *Canonicalizer {
+	return &Canonicalizer{rules: rules}
+}
+
+// Canonicalize returns the canonical form of an http(s) URL. A URL without a
+// scheme is taken to be https.
+func (c *Canonicalizer) Canonicalize(raw string) (string, error) {
+	u, err := url.Parse(withScheme(raw))
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid url", apiservice.ErrInvalidInput)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%w: url must be http or https", apiservice.ErrInvalidInput)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("%w: url has no host", apiservice.ErrInvalidInput)
+	}
+	u.User = nil
+	u.Host = c.host(u)
+	if c.rules.UpgradeHTTP {
+		u.Scheme = "https"
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawQuery = c.query(u.Query())
+	u.ForceQuery = false
+	if !c.rules.KeepFragment && !strings.HasPrefix(u.Fragment, "!") && !strings.HasPrefix(u.Fragment, "/") {
+		u.Fragment = ""
+	}
+	u.RawFragment = ""
+	return u.String(), nil
+}
+
+func (c *Canonicalizer) host(u *url.URL) string {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, prefix := range c.rules.StripHostPrefixes {
+		// Keep the prefix when it is all there is, as in "m.example".
+		if trimmed := strings.TrimPrefix(host, prefix); trimmed != host && strings.Contains(trimmed, ".") {
+			host = trimmed
+			break
+		}
+	}
+	port := u.Port()
+	if port == "" || (u.Scheme == "https" && port == "443") || (u.Scheme == "http" && port == "80") {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// query drops stripped parameters and sorts the rest by key.
+func (c *Canonicalizer) query(values url.Values) string {
+	for key := range values {
+		if c.stripped(strings.ToLower(key)) {
+			values.Del(key)
+		}
+	}
+	return values.Encode()
+}
+
+func (c *Canonicalizer) stripped(key string) bool {
+	for _, param := range c.rules.StripParams {
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == param {
+			return true
+		}
+	}
+	return false
+}
+
+// withScheme assumes https for URLs saved without a scheme, such as "example.com/post".
+func withScheme(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		return "https://" + raw
+	}
+	return raw
+}
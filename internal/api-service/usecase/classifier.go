@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// hostResources maps well-known hosts to resource types. Subdomains match too,
+// so "gist.github.com" is a repo and "en.wikipedia.org" would match "wikipedia.org".
+var hostResources = map[string]string{
+	"youtube.com":     apiservice.ResourceVideo,
+	"youtu.be":        apiservice.ResourceVideo,
+	"vimeo.com":       apiservice.ResourceVideo,
+	"twitch.tv":       apiservice.ResourceVideo,
+	"dailymotion.com": apiservice.ResourceVideo,
+	"tiktok.com":      apiservice.ResourceVideo,
+	"rutube.ru":       apiservice.ResourceVideo,
+	"loom.com":        apiservice.ResourceVideo,
+
+	"podcasts.apple.com":     apiservice.ResourcePodcast,
+	"podcasts.google.com":    apiservice.ResourcePodcast,
+	"soundcloud.com":         apiservice.ResourcePodcast,
+	"overcast.fm":            apiservice.ResourcePodcast,
+	"pocketcasts.com":        apiservice.ResourcePodcast,
+	"castbox.fm":             apiservice.ResourcePodcast,
+	"music.yandex.ru":        apiservice.ResourcePodcast,
+	"podcasters.spotify.com": apiservice.ResourcePodcast,
+
+	"github.com":    apiservice.ResourceRepo,
+	"gitlab.com":    apiservice.ResourceRepo,
+	"bitbucket.org": apiservice.ResourceRepo,
+	"codeberg.org":  apiservice.ResourceRepo,
+	"sr.ht":         apiservice.ResourceRepo,
+
+	"arxiv.org":             apiservice.ResourcePaper,
+	"biorxiv.org":           apiservice.ResourcePaper,
+	"doi.org":               apiservice.ResourcePaper,
+	"openreview.net":        apiservice.ResourcePaper,
+	"semanticscholar.org":   apiservice.ResourcePaper,
+	"dl.acm.org":            apiservice.ResourcePaper,
+	"ieeexplore.ieee.org":   apiservice.ResourcePaper,
+	"papers.nips.cc":        apiservice.ResourcePaper,
+	"proceedings.mlr.press": apiservice.ResourcePaper,
+
+	"twitter.com":          apiservice.ResourceSocial,
+	"x.com":                apiservice.ResourceSocial,
+	"bsky.app":             apiservice.ResourceSocial,
+	"mastodon.social":      apiservice.ResourceSocial,
+	"threads.net":          apiservice.ResourceSocial,
+	"facebook.com":         apiservice.ResourceSocial,
+	"instagram.com":        apiservice.ResourceSocial,
+	"reddit.com":           apiservice.ResourceSocial,
+	"news.ycombinator.com": apiservice.ResourceSocial,
+	"t.me":                 apiservice.ResourceSocial,
+	"vk.com":               apiservice.ResourceSocial,
+
+	"readthedocs.io":        apiservice.ResourceDocs,
+	"pkg.go.dev":            apiservice.ResourceDocs,
+	"docs.rs":               apiservice.ResourceDocs,
+	"developer.mozilla.org": apiservice.ResourceDocs,
+	"docs.github.com":       apiservice.ResourceDocs,
+
+	"medium.com":    apiservice.ResourceArticle,
+	"substack.com":  apiservice.ResourceArticle,
+	"dev.to":        apiservice.ResourceArticle,
+	"habr.com":      apiservice.ResourceArticle,
+	"hashnode.dev":  apiservice.ResourceArticle,
+	"wikipedia.org": apiservice.ResourceArticle,
+}
+
+// docsHostPrefixes mark documentation sites such as docs.python.org.
+var docsHostPrefixes = []string{"docs.", "doc.", "developer.", "developers."}
+
+// RuleClassifier is the default Classifier. It looks at, in order: well-known
+// hosts, the fetched content type, the file extension, the page's og:type and
+// finally path conventions such as /docs/ or /blog/.
+type RuleClassifier struct{}
+
+func NewRuleClassifier() RuleClassifier {
+	return RuleClassifier{}
+}
+
+func (RuleClassifier) Classify(link apiservice.Link) string {
+	u, err := url.Parse(strings.TrimSpace(link.URL))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "mobile."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	urlPath := strings.ToLower(u.Path)
+
+	if resource := classifyHost(host, urlPath); resource != "" {
+		return resource
+	}
+	if resource := classifyContentType(link.Metadata.ContentType); resource != "" {
+		return resource
+	}
+	if path.Ext(urlPath) == ".pdf" {
+		return apiservice.ResourcePaper
+	}
+	if resource := classifyOGType(link.Metadata.Type); resource != "" {
+		return resource
+	}
+	return classifyPath(urlPath)
+}
+
+func classifyHost(host, urlPath string) string {
+	// Spotify hosts music as well as podcasts; only shows and episodes count.
+	if host == "open.spotify.com" {
+		if strings.HasPrefix(urlPath, "/episode/") || strings.HasPrefix(urlPath, "/show/") {
+			return apiservice.ResourcePodcast
+		}
+		return ""
+	}
+	if host == "linkedin.com" && strings.HasPrefix(urlPath, "/posts/") {
+		return apiservice.ResourceSocial
+	}
+	for h := host; h != ""; {
+		if resource, ok := hostResources[h]; ok {
+			return resource
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	for _, prefix := range docsHostPrefixes {
+		if strings.HasPrefix(host, prefix) {
+			return apiservice.ResourceDocs
+		}
+	}
+	return ""
+}
+
+func classifyContentType(contentType string) string {
+	switch {
+	case contentType == "application/pdf":
+		return apiservice.ResourcePaper
+	case strings.HasPrefix(contentType, "video/"):
+		return apiservice.ResourceVideo
+	case strings.HasPrefix(contentType, "audio/"):
+		return apiservice.ResourcePodcast
+	}
+	return ""
+}
+
+func classifyOGType(ogType string) string {
+	switch {
+	case strings.HasPrefix(ogType, "video"):
+		return apiservice.ResourceVideo
+	case ogType == "music.song", ogType == "music.radio_station":
+		return apiservice.ResourcePodcast
+	case ogType == "article", ogType == "blog", strings.HasPrefix(ogType, "article:"):
+		return apiservice.ResourceArticle
+	}
+	return ""
+}
+
+func classifyPath(urlPath string) string {
+	segments := strings.Split(strings.Trim(urlPath, "/"), "/")
+	for _, segment := range segments {
+		switch segment {
+		case "docs", "doc", "documentation", "manual", "reference", "api-reference":
+			return apiservice.ResourceDocs
+		case "blog", "posts", "post", "articles", "article", "news":
+			return apiservice.ResourceArticle
+		case "podcast", "podcasts", "episode", "episodes":
+			return apiservice.ResourcePodcast
+		}
+	}
+	return ""
+}
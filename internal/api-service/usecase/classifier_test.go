@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func TestRuleClassifier_Classify(t *testing.T) {
+	tests := []struct {
+		name string
+		link apiservice.Link
+		want string
+	}{
+		{"youtube", apiservice.Link{URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}, apiservice.ResourceVideo},
+		{"youtube mobile", apiservice.Link{URL: "https://m.youtube.com/watch?v=1"}, apiservice.ResourceVideo},
+		{"short youtube", apiservice.Link{URL: "https://youtu.be/dQw4w9WgXcQ"}, apiservice.ResourceVideo},
+		{"vimeo", apiservice.Link{URL: "https://vimeo.com/76979871"}, apiservice.ResourceVideo},
+		{"github repo", apiservice.Link{URL: "https://github.com/golang/go"}, apiservice.ResourceRepo},
+		{"gist", apiservice.Link{URL: "https://gist.github.com/someone/abc"}, apiservice.ResourceRepo},
+		{"github docs", apiservice.Link{URL: "https://docs.github.com/en/actions"}, apiservice.ResourceDocs},
+		{"arxiv", apiservice.Link{URL: "https://arxiv.org/abs/1706.03762"}, apiservice.ResourcePaper},
+		{"pdf", apiservice.Link{URL: "https://example.com/files/report.PDF"}, apiservice.ResourcePaper},
+		{"spotify episode", apiservice.Link{URL: "https://open.spotify.com/episode/123"}, apiservice.ResourcePodcast},
+		{"spotify track", apiservice.Link{URL: "https://open.spotify.com/track/123"}, ""},
+		{"tweet", apiservice.Link{URL: "https://x.com/golang/status/1"}, apiservice.ResourceSocial},
+		{"docs host", apiservice.Link{URL: "https://docs.python.org/3/library/os.html"}, apiservice.ResourceDocs},
+		{"docs path", apiservice.Link{URL: "https://example.com/docs/getting-started"}, apiservice.ResourceDocs},
+		{"blog path", apiservice.Link{URL: "https://example.com/blog/hello"}, apiservice.ResourceArticle},
+		{"substack", apiservice.Link{URL: "https://someone.substack.com/p/post"}, apiservice.ResourceArticle},
+		{"unknown", apiservice.Link{URL: "https://example.com/"}, ""},
+		{"not a url", apiservice.Link{URL: "not a url"}, ""},
+		{
+			"og article",
+			apiservice.Link{URL: "https://example.com/2024/05/hello", Metadata: apiservice.LinkMetadata{Type: "article"}},
+			apiservice.ResourceArticle,
+		},
+		{
+			"og video",
+			apiservice.Link{URL: "https://example.com/watch/1", Metadata: apiservice.LinkMetadata{Type: "video.other"}},
+			apiservice.ResourceVideo,
+		},
+		{
+			"pdf content type",
+			apiservice.Link{URL: "https://example.com/download?id=1", Metadata: apiservice.LinkMetadata{ContentType: "application/pdf"}},
+			apiservice.ResourcePaper,
+		},
+		{
+			"audio content type",
+			apiservice.Link{URL: "https://example.com/ep1", Metadata: apiservice.LinkMetadata{ContentType: "audio/mpeg"}},
+			apiservice.ResourcePodcast,
+		},
+		{
+			"host wins over og:type",
+			apiservice.Link{URL: "https://github.com/golang/go", Metadata: apiservice.LinkMetadata{Type: "object"}},
+			apiservice.ResourceRepo,
+		},
+	}
+	c := NewRuleClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Classify(tt.link))
+		})
+	}
+}
+
+func TestLinkService_Create_ClassifiesResource(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo, WithClassifier(NewRuleClassifier()))
+	ctx := context.Background()
+
+	input := apiservice.LinkCreateInput{UserID: testUserID, URL: "https://youtu.be/dQw4w9WgXcQ"}
+	classified := input
+	classified.Resource = apiservice.ResourceVideo
+	mockRepo.On("Create", ctx, withCanonicalURL(classified)).Return(apiservice.Link{ID: "link-1", Resource: apiservice.ResourceVideo}, nil)
+
+	link, err := service.Create(ctx, input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, apiservice.ResourceVideo, link.Resource)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkService_Create_KeepsGivenResource(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo, WithClassifier(NewRuleClassifier()))
+	ctx := context.Background()
+
+	input := apiservice.LinkCreateInput{UserID: testUserID, URL: "https://youtu.be/dQw4w9WgXcQ", Resource: "music"}
+	mockRepo.On("Create", ctx, withCanonicalURL(input)).Return(apiservice.Link{ID: "link-1", Resource: "music"}, nil)
+
+	_, err := service.Create(ctx, input)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkService_Reclassify(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo, WithClassifier(NewRuleClassifier()))
+	ctx := context.Background()
+
+	links := []apiservice.Link{
+		{ID: "a", UserID: testUserID, URL: "https://github.com/golang/go"},
+		{ID: "b", UserID: testUserID, URL: "https://example.com/"},
+	}
+	repoResource := apiservice.ResourceRepo
+	mockRepo.On("ListBatch", ctx, "", true, "", reclassifyBatchSize).Return(links, nil)
+	mockRepo.On("Update", ctx, testUserID, "a", apiservice.LinkUpdateInput{Resource: &repoResource}).
+		Return(apiservice.Link{ID: "a", Resource: repoResource}, nil)
+
+	result, err := service.Reclassify(ctx, "", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, apiservice.ReclassifyResult{Scanned: 2, Updated: 1}, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkService_Reclassify_WithoutClassifier(t *testing.T) {
+	service := NewLinkService(new(MockRepository))
+
+	_, err := service.Reclassify(context.Background(), "", false)
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
+
+func TestEnricher_ClassifiesAfterFetch(t *testing.T) {
+	mockRepo := new(MockRepository)
+	page := apiservice.PageMetadata{Metadata: apiservice.LinkMetadata{Type: "article"}}
+	link := apiservice.Link{ID: "link-1", UserID: testUserID, URL: "https://example.com/2024/hello"}
+	enriched := link
+	enriched.Metadata = page.Metadata
+	article := apiservice.ResourceArticle
+
+	mockRepo.On("SaveMetadata", mock.Anything, testUserID, "link-1", page).Return(enriched, nil)
+	mockRepo.On("Update", mock.Anything, testUserID, "link-1", apiservice.LinkUpdateInput{Resource: &article}).
+		Return(apiservice.Link{}, nil)
+
+	e := NewEnricher(mockRepo, &stubFetcher{page: page}, NewRuleClassifier(), testEnricherConfig())
+	e.Start(context.Background())
+	e.Enqueue(link)
+	e.Stop()
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+const duplicatesBatchSize = 500
+
+func (s *LinkService) FindDuplicates(ctx context.Context, userID string) ([]apiservice.DuplicateGroup, error) {
+	if err := validateUserID(userID); err != nil {
+		return nil, err
+	}
+	groups, err := s.groupByCanonicalURL(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]apiservice.DuplicateGroup, 0)
+	for _, group := range groups {
+		if len(group.Links) > 1 {
+			out = append(out, group)
+		}
+	}
+	return out, nil
+}
+
+// MergeDuplicates merges every group of duplicates into its oldest link. It also
+// records the canonical URL of links saved before URLs were canonicalized, so
+// that later saves of the same page are recognized.
+func (s *LinkService) MergeDuplicates(ctx context.Context, userID string) (apiservice.MergeResult, error) {
+	result := apiservice.MergeResult{Merged: []apiservice.Link{}}
+	if err := validateUserID(userID); err != nil {
+		return result, err
+	}
+	groups, err := s.groupByCanonicalURL(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+	for _, group := range groups {
+		keep := group.Links[0]
+		if len(group.Links) == 1 {
+			if keep.CanonicalURL == group.CanonicalURL {
+				continue
+			}
+			canonical := group.CanonicalURL
+			if _, err := s.repo.Update(ctx, userID, keep.ID, apiservice.LinkUpdateInput{CanonicalURL: &canonical}); err != nil {
+				return result, err
+			}
+			continue
+		}
+		ids := make([]string, 0, len(group.Links)-1)
+		for _, link := range group.Links[1:] {
+			ids = append(ids, link.ID)
+		}
+		merged, err := s.repo.MergeLinks(ctx, userID, keep.ID, ids, group.CanonicalURL)
+		if err != nil {
+			return result, err
+		}
+		result.Merged = append(result.Merged, merged)
+		result.Removed += len(ids)
+	}
+	return result, nil
+}
+
+// groupByCanonicalURL canonicalizes the URL of every link of a user and groups
+// the links by it, oldest link first. Links whose URL cannot be canonicalized
+// (saved before URLs were validated) are left out.
+func (s *LinkService) groupByCanonicalURL(ctx context.Context, userID string) ([]apiservice.DuplicateGroup, error) {
+	byURL := map[string][]apiservice.Link{}
+	afterID := ""
+	for {
+		links, err := s.repo.ListBatch(ctx, userID, false, afterID, duplicatesBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			canonical, err := s.canonicalizer.Canonicalize(link.URL)
+			if err != nil {
+				continue
+			}
+			byURL[canonical] = append(byURL[canonical], link)
+		}
+		if len(links) < duplicatesBatchSize {
+			break
+		}
+		afterID = links[len(links)-1].ID
+	}
+
+	groups := make([]apiservice.DuplicateGroup, 0, len(byURL))
+	for canonical, links := range byURL {
+		sort.SliceStable(links, func(i, j int) bool {
+			return links[i].CreatedAt.Before(links[j].CreatedAt)
+		})
+		groups = append(groups, apiservice.DuplicateGroup{CanonicalURL: canonical, Links: links})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].CanonicalURL < groups[j].CanonicalURL
+	})
+	return groups, nil
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// EnricherConfig tunes the metadata enricher. Zero values fall back to defaults.
+type EnricherConfig struct {
+	Workers   int           // concurrent fetches
+	QueueSize int           // links waiting to be fetched; further links are dropped
+	Attempts  int           // fetch attempts per link
+	Timeout   time.Duration // per attempt
+	Backoff   time.Duration // wait before the second attempt, doubled after each failure
+}
+
+func (c EnricherConfig) withDefaults() EnricherConfig {
+	if c.Workers <= 0 {
+		c.Workers = 2
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 100
+	}
+	if c.Attempts <= 0 {
+		c.Attempts = 3
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = time.Second
+	}
+	return c
+}
+
+type enrichJob struct {
+	userID string
+	linkID string
+	url    string
+}
+
+// Enricher fetches page metadata for saved links in the background. When it
+// has a classifier, links still without a resource are classified once their
+// metadata is known.
+type Enricher struct {
+	repo       apiservice.LinkRepository
+	fetcher    apiservice.MetadataFetcher
+	classifier apiservice.Classifier
+	cfg        EnricherConfig
+
+	jobs   chan enrichJob
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+	cancel context.CancelFunc
+}
+
+// NewEnricher returns an Enricher; classifier may be nil.
+func NewEnricher(
+	repo apiservice.LinkRepository,
+	fetcher apiservice.MetadataFetcher,
+	classifier apiservice.Classifier,
+	cfg EnricherConfig,
+) *Enricher {
+	cfg = cfg.withDefaults()
+	return &Enricher{
+		repo:       repo,
+		fetcher:    fetcher,
+		classifier: classifier,
+		cfg:        cfg,
+		jobs:       make(chan enrichJob, cfg.QueueSize),
+	}
+}
+
+// Start launches the workers. They run until Stop is called or ctx is done.
+func (e *Enricher) Start(ctx context.Context) {
+	ctx, e.cancel = context.WithCancel(ctx)
+	for i := 0; i < e.cfg.Workers; i++ {
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			for job := range e.jobs {
+				e.enrich(ctx, job)
+			}
+		}()
+	}
+}
+
+// Stop stops accepting links, lets the workers finish the queued ones and waits for them.
+// Canceling the context passed to Start beforehand makes pending retries give up early.
+func (e *Enricher) Stop() {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.jobs)
+	}
+	e.mu.Unlock()
+	e.wg.Wait()
+	if e.cancel != nil {
+		e.cancel()
+	}
+}
+
+// Enqueue schedules a link for enrichment without blocking. It reports
+// whether the link was queued.
+func (e *Enricher) Enqueue(link apiservice.Link) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return false
+	}
+	select {
+	case e.jobs <- enrichJob{userID: link.UserID, linkID: link.ID, url: link.URL}:
+		return true
+	default:
+		logger.L().Warn().Str("link_id", link.ID).Msg("metadata queue is full, skipping link")
+		return false
+	}
+}
+
+func (e *Enricher) enrich(ctx context.Context, job enrichJob) {
+	log := logger.L().With().Str("link_id", job.linkID).Logger()
+	backoff := e.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		page, err := e.fetch(ctx, job.url)
+		if err == nil {
+			link, err := e.repo.SaveMetadata(ctx, job.userID, job.linkID, page)
+			if err != nil {
+				if !errors.Is(err, apiservice.ErrNotFound) {
+					log.Error().Err(err).Msg("save link metadata")
+				}
+				return
+			}
+			e.classify(ctx, link)
+			return
+		}
+		if errors.Is(err, apiservice.ErrInvalidInput) || attempt >= e.cfg.Attempts {
+			log.Warn().Err(err).Int("attempt", attempt).Msg("fetch link metadata")
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (e *Enricher) classify(ctx context.Context, link apiservice.Link) {
+	if e.classifier == nil || link.Resource != "" {
+		return
+	}
+	resource := e.classifier.Classify(link)
+	if resource == "" {
+		return
+	}
+	_, err := e.repo.Update(ctx, link.UserID, link.ID, apiservice.LinkUpdateInput{Resource: &resource})
+	if err != nil && !errors.Is(err, apiservice.ErrNotFound) {
+		logger.L().Error().Err(err).Str("link_id", link.ID).Msg("classify link")
+	}
+}
+
+func (e *Enricher) fetch(ctx context.Context, url string) (apiservice.PageMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+	return e.fetcher.Fetch(ctx, url)
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// stubFetcher fails with the queued errors before returning page.
+type stubFetcher struct {
+	mu    sync.Mutex
+	errs  []error
+	page  apiservice.PageMetadata
+	calls int
+}
+
+func (f *stubFetcher) Fetch(_ context.Context, _ string) (apiservice.PageMetadata, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return apiservice.PageMetadata{}, err
+	}
+	return f.page, nil
+}
+
+func testEnricherConfig() EnricherConfig {
+	return EnricherConfig{Workers: 1, Attempts: 3, Timeout: time.Second, Backoff: time.Millisecond}
+}
+
+func TestEnricher_RetriesThenSaves(t *testing.T) {
+	mockRepo := new(MockRepository)
+	page := apiservice.PageMetadata{Title: "Example", Metadata: apiservice.LinkMetadata{SiteName: "Example"}}
+	fetcher := &stubFetcher{errs: []error{errors.New("connection reset")}, page: page}
+	link := apiservice.Link{ID: "link-1", UserID: testUserID, URL: "https://example.com"}
+
+	mockRepo.On("SaveMetadata", mock.Anything, testUserID, "link-1", page).Return(link, nil)
+
+	e := NewEnricher(mockRepo, fetcher, nil, testEnricherConfig())
+	e.Start(context.Background())
+	assert.True(t, e.Enqueue(link))
+	e.Stop()
+
+	assert.Equal(t, 2, fetcher.calls)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEnricher_GivesUp(t *testing.T) {
+	mockRepo := new(MockRepository)
+	fail := errors.New("timeout")
+	fetcher := &stubFetcher{errs: []error{fail, fail, fail, fail}}
+
+	e := NewEnricher(mockRepo, fetcher, nil, testEnricherConfig())
+	e.Start(context.Background())
+	e.Enqueue(apiservice.Link{ID: "link-1", UserID: testUserID, URL: "https://example.com"})
+	e.Stop()
+
+	assert.Equal(t, 3, fetcher.calls)
+	mockRepo.AssertNotCalled(t, "SaveMetadata", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEnricher_DoesNotRetryPermanentErrors(t *testing.T) {
+	mockRepo := new(MockRepository)
+	fetcher := &stubFetcher{errs: []error{fmt.Errorf("%w: status 404", apiservice.ErrInvalidInput)}}
+
+	e := NewEnricher(mockRepo, fetcher, nil, testEnricherConfig())
+	e.Start(context.Background())
+	e.Enqueue(apiservice.Link{ID: "link-1", UserID: testUserID, URL: "https://example.com/missing"})
+	e.Stop()
+
+	assert.Equal(t, 1, fetcher.calls)
+	mockRepo.AssertNotCalled(t, "SaveMetadata", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEnricher_EnqueueAfterStop(t *testing.T) {
+	e := NewEnricher(new(MockRepository), &stubFetcher{}, nil, testEnricherConfig())
+	e.Start(context.Background())
+	e.Stop()
+
+	assert.False(t, e.Enqueue(apiservice.Link{ID: "link-1", UserID: testUserID, URL: "https://example.com"}))
+}
+
+func TestLinkService_Create_EnqueuesEnrichment(t *testing.T) {
+	mockRepo := new(MockRepository)
+	page := apiservice.PageMetadata{Title: "Example"}
+	fetcher := &stubFetcher{page: page}
+	e := NewEnricher(mockRepo, fetcher, nil, testEnricherConfig())
+	service := NewLinkService(mockRepo, WithEnricher(e))
+	ctx := context.Background()
+
+	input := apiservice.LinkCreateInput{UserID: testUserID, URL: "https://example.com"}
+	link := apiservice.Link{ID: "link-1", UserID: testUserID, URL: "https://example.com"}
+	mockRepo.On("Create", ctx, withCanonicalURL(input)).Return(link, nil)
+	mockRepo.On("SaveMetadata", mock.Anything, testUserID, "link-1", page).Return(link, nil)
+
+	e.Start(ctx)
+	_, err := service.Create(ctx, input)
+	e.Stop()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetcher.calls)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// feedTokenBytes is the entropy of a feed token.
+const feedTokenBytes = 32
+
+type FeedTokenService struct {
+	repo apiservice.FeedTokenRepository
+}
+
+var _ apiservice.FeedService = (*FeedTokenService)(nil)
+
+func NewFeedTokenService(repo apiservice.FeedTokenRepository) *FeedTokenService {
+	return &FeedTokenService{repo: repo}
+}
+
+func (s *FeedTokenService) RotateToken(ctx context.Context, userID string) (string, error) {
+	if err := validateUserID(userID); err != nil {
+		return "", err
+	}
+	raw := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	if err := s.repo.Set(ctx, userID, hashToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *FeedTokenService) RevokeToken(ctx context.Context, userID string) error {
+	if err := validateUserID(userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, userID)
+}
+
+func (s *FeedTokenService) UserID(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", apiservice.ErrNotFound
+	}
+	return s.repo.UserID(ctx, hashToken(token))
+}
+
+// hashToken returns the form a token is stored in. Tokens are random enough
+// that a plain SHA-256 is as good as a slow password hash.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
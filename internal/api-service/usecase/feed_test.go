@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// memFeedTokens keeps token hashes by user.
+type memFeedTokens map[string]string
+
+func (m memFeedTokens) Set(_ context.Context, userID, tokenHash string) error {
+	m[userID] = tokenHash
+	return nil
+}
+
+func (m memFeedTokens) Delete(_ context.Context, userID string) error {
+	if _, ok := m[userID]; !ok {
+		return apiservice.ErrNotFound
+	}
+	delete(m, userID)
+	return nil
+}
+
+func (m memFeedTokens) UserID(_ context.Context, tokenHash string) (string, error) {
+	for userID, hash := range m {
+		if hash == tokenHash {
+			return userID, nil
+		}
+	}
+	return "", apiservice.ErrNotFound
+}
+
+func TestFeedTokenService(t *testing.T) {
+	tokens := memFeedTokens{}
+	service := NewFeedTokenService(tokens)
+	ctx := context.Background()
+
+	first, err := service.RotateToken(ctx, testUserID)
+	require.NoError(t, err)
+	assert.NotContains(t, tokens[testUserID], first, "only a hash of the token is stored")
+
+	got, err := service.UserID(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, testUserID, got)
+
+	second, err := service.RotateToken(ctx, testUserID)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	_, err = service.UserID(ctx, first)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	require.NoError(t, service.RevokeToken(ctx, testUserID))
+	_, err = service.UserID(ctx, second)
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+
+	_, err = service.UserID(ctx, "")
+	assert.ErrorIs(t, err, apiservice.ErrNotFound)
+	_, err = service.RotateToken(ctx, "nobody")
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
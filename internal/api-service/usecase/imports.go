@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/internal/api-service/importers"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// ImporterConfig tunes the import runner. Zero values fall back to defaults.
+type ImporterConfig struct {
+	Workers      int           // jobs run at the same time
+	PollInterval time.Duration // how often idle workers look for queued jobs
+	StaleAfter   time.Duration // a running job without progress for this long is taken over
+	SaveEvery    int           // records between progress saves
+	MaxErrors    int           // row errors kept per job; further ones are only counted
+}
+
+func (c ImporterConfig) withDefaults() ImporterConfig {
+	if c.Workers <= 0 {
+		c.Workers = 1
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = 5 * time.Second
+	}
+	if c.StaleAfter <= 0 {
+		c.StaleAfter = 5 * time.Minute
+	}
+	if c.SaveEvery <= 0 {
+		c.SaveEvery = 25
+	}
+	if c.MaxErrors <= 0 {
+		c.MaxErrors = 100
+	}
+	return c
+}
+
+// maxListedJobs caps the jobs returned by List.
+const maxListedJobs = 50
+
+// Importer runs imports of export files in the background. Jobs are kept in
+// the database with their file, so they survive restarts: a job left running
+// by a stopped instance is taken over once it has made no progress for
+// StaleAfter, and resumes after the records it already processed.
+type Importer struct {
+	links *LinkService
+	jobs  apiservice.ImportJobRepository
+	cfg   ImporterConfig
+
+	wake   chan struct{}
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+var _ apiservice.ImportService = (*Importer)(nil)
+
+func NewImporter(links *LinkService, jobs apiservice.ImportJobRepository, cfg ImporterConfig) *Importer {
+	return &Importer{
+		links: links,
+		jobs:  jobs,
+		cfg:   cfg.withDefaults(),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Start launches the workers. They run until Stop is called or ctx is done.
+func (im *Importer) Start(ctx context.Context) {
+	ctx, im.cancel = context.WithCancel(ctx)
+	for i := 0; i < im.cfg.Workers; i++ {
+		im.wg.Add(1)
+		go func() {
+			defer im.wg.Done()
+			im.work(ctx)
+		}()
+	}
+}
+
+// Stop interrupts the workers and waits for them. Jobs they were running are
+// put back in the queue with their progress saved.
+func (im *Importer) Stop() {
+	if im.cancel != nil {
+		im.cancel()
+	}
+	im.wg.Wait()
+}
+
+func (im *Importer) Submit(ctx context.Context, userID, format string, payload []byte) (apiservice.ImportJob, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.ImportJob{}, err
+	}
+	if !importers.Supported(format) {
+		return apiservice.ImportJob{}, fmt.Errorf("%w: unknown format %q", apiservice.ErrInvalidInput, format)
+	}
+	if len(payload) == 0 {
+		return apiservice.ImportJob{}, fmt.Errorf("%w: file is empty", apiservice.ErrInvalidInput)
+	}
+	total, err := countRecords(format, payload)
+	if err != nil {
+		return apiservice.ImportJob{}, err
+	}
+	job, err := im.jobs.Create(ctx, apiservice.ImportJob{UserID: userID, Format: format, Total: total}, payload)
+	if err != nil {
+		return apiservice.ImportJob{}, err
+	}
+	select {
+	case im.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (im *Importer) Get(ctx context.Context, userID, id string) (apiservice.ImportJob, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.ImportJob{}, err
+	}
+	// Job ids are uuids; anything else cannot name a job.
+	if uuid.Validate(id) != nil {
+		return apiservice.ImportJob{}, apiservice.ErrNotFound
+	}
+	return im.jobs.Get(ctx, userID, id)
+}
+
+func (im *Importer) List(ctx context.Context, userID string) ([]apiservice.ImportJob, error) {
+	if err := validateUserID(userID); err != nil {
+		return nil, err
+	}
+	return im.jobs.List(ctx, userID, maxListedJobs)
+}
+
+// countRecords reads the whole file once, so that a file that cannot be
+// read is rejected up front and progress can be reported against a total.
+func countRecords(format string, payload []byte) (int, error) {
+	src, err := importers.New(format, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", apiservice.ErrInvalidInput, err)
+	}
+	total := 0
+	for {
+		_, err := src.Next()
+		var rowErr *importers.RowError
+		switch {
+		case errors.Is(err, io.EOF):
+			return total, nil
+		case err == nil, errors.As(err, &rowErr):
+			total++
+		default:
+			return 0, fmt.Errorf("%w: reading import file: %w", apiservice.ErrInvalidInput, err)
+		}
+	}
+}
+
+func (im *Importer) work(ctx context.Context) {
+	ticker := time.NewTicker(im.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		job, payload, err := im.jobs.Claim(ctx, time.Now().Add(-im.cfg.StaleAfter))
+		switch {
+		case err == nil:
+			im.run(ctx, job, payload)
+			continue
+		case errors.Is(err, apiservice.ErrConflict):
+			// Another worker claimed the same job; look for the next one.
+			continue
+		case ctx.Err() != nil:
+			return
+		case !errors.Is(err, apiservice.ErrNotFound):
+			logger.L().Error().Err(err).Msg("claim import job")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-im.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (im *Importer) run(ctx context.Context, job apiservice.ImportJob, payload []byte) {
+	log := logger.L().With().Str("job_id", job.ID).Logger()
+	if job.Processed > 0 {
+		log.Info().Int("processed", job.Processed).Msg("resuming import job")
+	}
+
+	err := im.process(ctx, &job, payload)
+	if errors.Is(err, apiservice.ErrConflict) {
+		log.Warn().Msg("import job was taken over by another worker")
+		return
+	}
+	// Saves below use a fresh context so that a stopping worker still records where it got to.
+	saveCtx := context.WithoutCancel(ctx)
+	switch {
+	case err == nil:
+		job.Status = apiservice.JobDone
+	case ctx.Err() != nil:
+		job.Status = apiservice.JobQueued
+	default:
+		log.Error().Err(err).Msg("import job failed")
+		job.Status, job.Error = apiservice.JobFailed, err.Error()
+	}
+	if job.Status != apiservice.JobQueued {
+		now := time.Now()
+		job.FinishedAt = &now
+	}
+	if _, err := im.jobs.Save(saveCtx, job); err != nil && !errors.Is(err, apiservice.ErrConflict) {
+		log.Error().Err(err).Msg("save import job")
+	}
+}
+
+// process imports the records of payload after the first job.Processed ones,
+// saving progress every SaveEvery records.
+func (im *Importer) process(
+	ctx context.Context,
+	job *apiservice.ImportJob,
+	payload []byte,
+) error {
+	src, err := importers.New(job.Format, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for row := 0; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var item apiservice.ImportItem
+		var err error
+		if row < job.Processed {
+			_, err = src.Next()
+			var rowErr *importers.RowError
+			if errors.As(err, &rowErr) {
+				err = nil
+			}
+		} else {
+			item, err = im.links.importNext(ctx, job.UserID, src, false, nil)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if row < job.Processed {
+			continue
+		}
+
+		job.Processed++
+		switch item.Result {
+		case apiservice.ImportCreated:
+			job.Created++
+		case apiservice.ImportDuplicate:
+			job.Duplicates++
+		case apiservice.ImportInvalid:
+			job.Invalid++
+			if len(job.Errors) < im.cfg.MaxErrors {
+				job.Errors = append(job.Errors, apiservice.ImportError{Row: item.Row, URL: item.URL, Error: item.Error})
+			}
+		}
+		if job.Processed%im.cfg.SaveEvery == 0 {
+			if _, err := im.jobs.Save(ctx, *job); err != nil {
+				return err
+			}
+		}
+	}
+}
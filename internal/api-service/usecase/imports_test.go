@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type MockImportJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockImportJobRepository) Create(ctx context.Context, job apiservice.ImportJob, payload []byte) (apiservice.ImportJob, error) {
+	args := m.Called(ctx, job, payload)
+	return args.Get(0).(apiservice.ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobRepository) Get(ctx context.Context, userID, id string) (apiservice.ImportJob, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(apiservice.ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobRepository) List(ctx context.Context, userID string, limit int) ([]apiservice.ImportJob, error) {
+	args := m.Called(ctx, userID, limit)
+	return args.Get(0).([]apiservice.ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobRepository) Claim(ctx context.Context, staleBefore time.Time) (apiservice.ImportJob, []byte, error) {
+	args := m.Called(ctx, staleBefore)
+	return args.Get(0).(apiservice.ImportJob), args.Get(1).([]byte), args.Error(2)
+}
+
+func (m *MockImportJobRepository) Save(ctx context.Context, job apiservice.ImportJob) (apiservice.ImportJob, error) {
+	args := m.Called(ctx, job)
+	return args.Get(0).(apiservice.ImportJob), args.Error(1)
+}
+
+const pinboardExport = `[
+{"href":"https://go.dev/","description":"Go","extended":"","time":"2020-09-13T12:26:40Z","toread":"no","tags":"golang"},
+{"href":"ftp://files.example","description":"Files","time":"2020-09-13T12:26:40Z","toread":"yes","tags":""},
+{"href":"https://example.com/","description":"Example","time":"not a time","toread":"yes","tags":""},
+{"href":"https://blog.example/","description":"Blog","time":"","toread":"yes","tags":"reading list"}
+]`
+
+func TestImporter_Submit(t *testing.T) {
+	jobs := new(MockImportJobRepository)
+	importer := NewImporter(NewLinkService(new(MockRepository)), jobs, ImporterConfig{})
+	ctx := context.Background()
+
+	payload := []byte(pinboardExport)
+	queued := apiservice.ImportJob{ID: "job", Status: apiservice.JobQueued}
+	jobs.On("Create", ctx, apiservice.ImportJob{UserID: testUserID, Format: "pinboard_json", Total: 4}, payload).
+		Return(queued, nil)
+
+	job, err := importer.Submit(ctx, testUserID, "pinboard_json", payload)
+
+	require.NoError(t, err)
+	assert.Equal(t, queued, job)
+	jobs.AssertExpectations(t)
+}
+
+func TestImporter_Submit_Invalid(t *testing.T) {
+	importer := NewImporter(NewLinkService(new(MockRepository)), new(MockImportJobRepository), ImporterConfig{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		format  string
+		payload string
+	}{
+		{"unknown format", "delicious", "x"},
+		{"empty file", "pinboard_json", ""},
+		{"missing column", "pocket_csv", "title,time_added\nGo,1600000000\n"},
+		{"not an array", "pinboard_json", `{"href":"https://go.dev/"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := importer.Submit(ctx, testUserID, tt.format, []byte(tt.payload))
+			assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+		})
+	}
+}
+
+func TestImporter_Run(t *testing.T) {
+	mockRepo := new(MockRepository)
+	jobs := new(MockImportJobRepository)
+	importer := NewImporter(NewLinkService(mockRepo), jobs, ImporterConfig{SaveEvery: 2})
+	ctx := context.Background()
+
+	mockRepo.On("Create", ctx, withCanonicalURL(apiservice.LinkCreateInput{
+		UserID:    testUserID,
+		URL:       "https://go.dev/",
+		Title:     "Go",
+		Tags:      []string{"golang"},
+		CreatedAt: time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC),
+	})).Return(apiservice.Link{ID: "go"}, nil)
+	mockRepo.On("SetStatus", ctx, testUserID, "go", apiservice.StatusUnread, apiservice.StatusDone).
+		Return(apiservice.Link{ID: "go"}, nil)
+	mockRepo.On("Create", ctx, withCanonicalURL(apiservice.LinkCreateInput{
+		UserID: testUserID,
+		URL:    "https://blog.example/",
+		Title:  "Blog",
+		Tags:   []string{"reading", "list"},
+	})).Return(apiservice.Link{ID: "blog"}, apiservice.ErrAlreadyExists)
+
+	job := apiservice.ImportJob{ID: "job", UserID: testUserID, Format: "pinboard_json", Status: apiservice.JobRunning, Total: 4, Attempt: 1}
+	jobs.On("Save", ctx, mock.MatchedBy(func(j apiservice.ImportJob) bool { return j.Status == apiservice.JobRunning })).
+		Return(apiservice.ImportJob{}, nil).Twice()
+	var final apiservice.ImportJob
+	jobs.On("Save", mock.Anything, mock.MatchedBy(func(j apiservice.ImportJob) bool { return j.Status == apiservice.JobDone })).
+		Run(func(args mock.Arguments) { final = args.Get(1).(apiservice.ImportJob) }).
+		Return(apiservice.ImportJob{}, nil).Once()
+
+	importer.run(ctx, job, []byte(pinboardExport))
+
+	assert.Equal(t, 4, final.Processed)
+	assert.Equal(t, 1, final.Created)
+	assert.Equal(t, 1, final.Duplicates)
+	assert.Equal(t, 2, final.Invalid)
+	assert.Equal(t, []apiservice.ImportError{
+		{Row: 2, URL: "ftp://files.example", Error: "url must be http or https"},
+		{Row: 3, URL: "https://example.com/", Error: `invalid timestamp "not a time"`},
+	}, final.Errors)
+	assert.NotNil(t, final.FinishedAt)
+	mockRepo.AssertExpectations(t)
+	jobs.AssertExpectations(t)
+}
+
+func TestImporter_Run_Resumes(t *testing.T) {
+	mockRepo := new(MockRepository)
+	jobs := new(MockImportJobRepository)
+	importer := NewImporter(NewLinkService(mockRepo), jobs, ImporterConfig{})
+	ctx := context.Background()
+
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(in apiservice.LinkCreateInput) bool {
+		return in.URL == "https://blog.example/"
+	})).Return(apiservice.Link{ID: "blog"}, nil)
+	var final apiservice.ImportJob
+	jobs.On("Save", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { final = args.Get(1).(apiservice.ImportJob) }).
+		Return(apiservice.ImportJob{}, nil)
+
+	job := apiservice.ImportJob{ID: "job", UserID: testUserID, Format: "pinboard_json", Total: 4, Processed: 3, Created: 1, Attempt: 2}
+	importer.run(ctx, job, []byte(pinboardExport))
+
+	assert.Equal(t, apiservice.JobDone, final.Status)
+	assert.Equal(t, 4, final.Processed)
+	assert.Equal(t, 2, final.Created)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImporter_Run_ReleasesOnStop(t *testing.T) {
+	jobs := new(MockImportJobRepository)
+	importer := NewImporter(NewLinkService(new(MockRepository)), jobs, ImporterConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var final apiservice.ImportJob
+	jobs.On("Save", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { final = args.Get(1).(apiservice.ImportJob) }).
+		Return(apiservice.ImportJob{}, nil)
+
+	importer.run(ctx, apiservice.ImportJob{ID: "job", UserID: testUserID, Format: "pinboard_json"}, []byte(pinboardExport))
+
+	assert.Equal(t, apiservice.JobQueued, final.Status)
+	assert.Nil(t, final.FinishedAt)
+}
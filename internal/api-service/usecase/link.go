@@ -2,61 +2,254 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
 
 	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
 )
 
+const (
+	maxQueryLength      = 256
+	reclassifyBatchSize = 200
+)
+
 type LinkService struct {
-	repo apiservice.LinkRepository
+	repo          apiservice.LinkRepository
+	enricher      *Enricher
+	classifier    apiservice.Classifier
+	canonicalizer *Canonicalizer
 }
 
-func NewLinkService(repo apiservice.LinkRepository) *LinkService {
-	return &LinkService{repo: repo}
+// Option configures optional LinkService behavior.
+type Option func(*LinkService)
+
+// WithEnricher makes Create schedule new links for metadata enrichment.
+func WithEnricher(e *Enricher) Option {
+	return func(s *LinkService) {
+		s.enricher = e
+	}
+}
+
+// WithClassifier makes Create infer the resource of links saved without one.
+func WithClassifier(c apiservice.Classifier) Option {
+	return func(s *LinkService) {
+		s.classifier = c
+	}
+}
+
+// WithCanonicalizer replaces the default URL canonicalization rules.
+func WithCanonicalizer(c *Canonicalizer) Option {
+	return func(s *LinkService) {
+		s.canonicalizer = c
+	}
+}
+
+func NewLinkService(repo apiservice.LinkRepository, opts ...Option) *LinkService {
+	s := &LinkService{
+		repo:          repo,
+		canonicalizer: NewCanonicalizer(DefaultCanonicalRules()),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *LinkService) Create(ctx context.Context, input apiservice.LinkCreateInput) (apiservice.Link, error) {
+	return s.create(ctx, input, true)
+}
+
+// create saves a link; enrich controls whether it is queued for metadata enrichment.
+func (s *LinkService) create(ctx context.Context, input apiservice.LinkCreateInput, enrich bool) (apiservice.Link, error) {
 	if err := validateCreate(input); err != nil {
 		return apiservice.Link{}, err
 	}
-	return s.repo.Create(ctx, input)
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return apiservice.Link{}, err
+	}
+	input.Tags = tags
+	input.URL = withScheme(input.URL)
+	if input.CanonicalURL, err = s.canonicalizer.Canonicalize(input.URL); err != nil {
+		return apiservice.Link{}, err
+	}
+	if input.Resource == "" && s.classifier != nil {
+		input.Resource = s.classifier.Classify(apiservice.Link{URL: input.URL, Title: input.Title})
+	}
+	link, err := s.repo.Create(ctx, input)
+	if errors.Is(err, apiservice.ErrAlreadyExists) {
+		return link, err
+	}
+	if err != nil {
+		return apiservice.Link{}, err
+	}
+	if enrich && s.enricher != nil {
+		s.enricher.Enqueue(link)
+	}
+	return link, nil
 }
 
-func (s *LinkService) GetByID(ctx context.Context, id string) (apiservice.Link, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *LinkService) GetByID(ctx context.Context, userID, id string) (apiservice.Link, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.Link{}, err
+	}
+	return s.repo.GetByID(ctx, userID, id)
 }
 
-func (s *LinkService) List(ctx context.Context, limit, offset int) ([]apiservice.Link, error) {
-	return s.repo.List(ctx, limit, offset)
+// Random picks a random link matching the filter. Without a status filter it
+// only picks unread links, so that it walks through the reading queue.
+func (s *LinkService) Random(ctx context.Context, userID string, filter apiservice.LinkFilter) (apiservice.Link, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.Link{}, err
+	}
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return apiservice.Link{}, err
+	}
+	if len(filter.Statuses) == 0 {
+		filter.Statuses = []string{apiservice.StatusUnread}
+	}
+	return s.repo.Random(ctx, userID, filter)
 }
 
-func (s *LinkService) Random(ctx context.Context, resource string) (apiservice.Link, error) {
-	return s.repo.Random(ctx, resource)
+func (s *LinkService) Search(
+	ctx context.Context,
+	userID, query string,
+	filter apiservice.LinkFilter,
+	limit, offset int,
+) ([]apiservice.SearchResult, error) {
+	if err := validateUserID(userID); err != nil {
+		return nil, err
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: search query is required", apiservice.ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(query) > maxQueryLength {
+		return nil, fmt.Errorf("%w: search query is longer than %d characters", apiservice.ErrInvalidInput, maxQueryLength)
+	}
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Search(ctx, userID, query, filter, limit, offset)
 }
 
-func (s *LinkService) Update(ctx context.Context, id string, input apiservice.LinkUpdateInput) (apiservice.Link, error) {
+func (s *LinkService) Update(ctx context.Context, userID, id string, input apiservice.LinkUpdateInput) (apiservice.Link, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.Link{}, err
+	}
 	if err := validateUpdate(input); err != nil {
 		return apiservice.Link{}, err
 	}
-	return s.repo.Update(ctx, id, input)
+	var err error
+	if input.URL != nil {
+		rawURL := withScheme(*input.URL)
+		canonical, err := s.canonicalizer.Canonicalize(rawURL)
+		if err != nil {
+			return apiservice.Link{}, err
+		}
+		input.URL, input.CanonicalURL = &rawURL, &canonical
+	}
+	if input.AddTags, err = normalizeTags(input.AddTags); err != nil {
+		return apiservice.Link{}, err
+	}
+	if input.RemoveTags, err = normalizeTags(input.RemoveTags); err != nil {
+		return apiservice.Link{}, err
+	}
+	return s.repo.Update(ctx, userID, id, input)
+}
+
+func (s *LinkService) Delete(ctx context.Context, userID, id string) error {
+	if err := validateUserID(userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, userID, id)
 }
 
-func (s *LinkService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+func (s *LinkService) MarkViewed(ctx context.Context, userID, id string) (apiservice.Link, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.Link{}, err
+	}
+	return s.repo.MarkViewed(ctx, userID, id)
 }
 
-func (s *LinkService) MarkViewed(ctx context.Context, id string) (apiservice.Link, error) {
-	return s.repo.MarkViewed(ctx, id)
+func (s *LinkService) ListViews(ctx context.Context, userID, id string, limit, offset int) ([]apiservice.LinkView, error) {
+	if err := validateUserID(userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListViews(ctx, userID, id, limit, offset)
 }
 
-func (s *LinkService) GetViewStats(ctx context.Context, days int) ([]apiservice.ViewStats, error) {
+func (s *LinkService) GetViewStats(ctx context.Context, userID string, filter apiservice.LinkFilter, days int) ([]apiservice.ViewStats, error) {
+	if err := validateUserID(userID); err != nil {
+		return nil, err
+	}
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
 	if days <= 0 {
 		days = 53
 	}
-	return s.repo.GetViewStats(ctx, days)
+	return s.repo.GetViewStats(ctx, userID, filter, days)
+}
+
+func (s *LinkService) Reclassify(ctx context.Context, userID string, overwrite bool) (apiservice.ReclassifyResult, error) {
+	var result apiservice.ReclassifyResult
+	if s.classifier == nil {
+		return result, fmt.Errorf("%w: no classifier configured", apiservice.ErrInvalidInput)
+	}
+	if userID != "" {
+		if err := validateUserID(userID); err != nil {
+			return result, err
+		}
+	}
+	afterID := ""
+	for {
+		links, err := s.repo.ListBatch(ctx, userID, !overwrite, afterID, reclassifyBatchSize)
+		if err != nil {
+			return result, err
+		}
+		for _, link := range links {
+			result.Scanned++
+			resource := s.classifier.Classify(link)
+			if resource == "" || resource == link.Resource {
+				continue
+			}
+			if _, err := s.repo.Update(ctx, link.UserID, link.ID, apiservice.LinkUpdateInput{Resource: &resource}); err != nil {
+				if errors.Is(err, apiservice.ErrNotFound) {
+					continue
+				}
+				return result, err
+			}
+			result.Updated++
+		}
+		if len(links) < reclassifyBatchSize {
+			return result, nil
+		}
+		afterID = links[len(links)-1].ID
+	}
+}
+
+func validateUserID(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user id is required", apiservice.ErrInvalidInput)
+	}
+	if err := uuid.Validate(userID); err != nil {
+		return fmt.Errorf("%w: invalid user id", apiservice.ErrInvalidInput)
+	}
+	return nil
 }
 
 func validateCreate(input apiservice.LinkCreateInput) error {
+	if err := validateUserID(input.UserID); err != nil {
+		return err
+	}
 	if input.URL == "" {
 		return fmt.Errorf("%w: url is required", apiservice.ErrInvalidInput)
 	}
@@ -64,7 +257,8 @@ func validateCreate(input apiservice.LinkCreateInput) error {
 }
 
 func validateUpdate(input apiservice.LinkUpdateInput) error {
-	if input.URL == nil && input.Resource == nil {
+	if input.URL == nil && input.Title == nil && input.Notes == nil && input.Resource == nil &&
+		len(input.AddTags) == 0 && len(input.RemoveTags) == 0 {
 		return fmt.Errorf("%w: no fields to update", apiservice.ErrInvalidInput)
 	}
 	if input.URL != nil && *input.URL == "" {
@@ -72,3 +266,15 @@ func validateUpdate(input apiservice.LinkUpdateInput) error {
 	}
 	return nil
 }
+
+func normalizeFilter(filter apiservice.LinkFilter) (apiservice.LinkFilter, error) {
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		return apiservice.LinkFilter{}, err
+	}
+	filter.Tags = tags
+	if filter.Statuses, err = normalizeStatuses(filter.Statuses); err != nil {
+		return apiservice.LinkFilter{}, err
+	}
+	return filter, nil
+}
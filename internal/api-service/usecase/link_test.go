@@ -21,53 +21,136 @@ func (m *MockRepository) Create(ctx context.Context, input apiservice.LinkCreate
 	return args.Get(0).(apiservice.Link), args.Error(1)
 }
 
-func (m *MockRepository) GetByID(ctx context.Context, id string) (apiservice.Link, error) {
-	args := m.Called(ctx, id)
+func (m *MockRepository) GetByID(ctx context.Context, userID, id string) (apiservice.Link, error) {
+	args := m.Called(ctx, userID, id)
 	return args.Get(0).(apiservice.Link), args.Error(1)
 }
 
-func (m *MockRepository) List(ctx context.Context, limit, offset int) ([]apiservice.Link, error) {
-	args := m.Called(ctx, limit, offset)
+func (m *MockRepository) List(ctx context.Context, userID string, filter apiservice.LinkFilter, query apiservice.ListQuery) ([]apiservice.Link, error) {
+	args := m.Called(ctx, userID, filter, query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]apiservice.Link), args.Error(1)
 }
 
-func (m *MockRepository) Random(ctx context.Context, resource string) (apiservice.Link, error) {
-	args := m.Called(ctx, resource)
+func (m *MockRepository) Count(ctx context.Context, userID string, filter apiservice.LinkFilter) (int64, error) {
+	args := m.Called(ctx, userID, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) GetByCanonicalURL(ctx context.Context, userID, canonicalURL string) (apiservice.Link, error) {
+	args := m.Called(ctx, userID, canonicalURL)
+	return args.Get(0).(apiservice.Link), args.Error(1)
+}
+
+func (m *MockRepository) Random(ctx context.Context, userID string, filter apiservice.LinkFilter) (apiservice.Link, error) {
+	args := m.Called(ctx, userID, filter)
 	return args.Get(0).(apiservice.Link), args.Error(1)
 }
 
-func (m *MockRepository) Update(ctx context.Context, id string, input apiservice.LinkUpdateInput) (apiservice.Link, error) {
-	args := m.Called(ctx, id, input)
+func (m *MockRepository) Search(
+	ctx context.Context,
+	userID, query string,
+	filter apiservice.LinkFilter,
+	limit, offset int,
+) ([]apiservice.SearchResult, error) {
+	args := m.Called(ctx, userID, query, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]apiservice.SearchResult), args.Error(1)
+}
+
+func (m *MockRepository) Update(ctx context.Context, userID, id string, input apiservice.LinkUpdateInput) (apiservice.Link, error) {
+	args := m.Called(ctx, userID, id, input)
 	return args.Get(0).(apiservice.Link), args.Error(1)
 }
 
-func (m *MockRepository) Delete(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
+func (m *MockRepository) Delete(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
 	return args.Error(0)
 }
 
-func (m *MockRepository) MarkViewed(ctx context.Context, id string) (apiservice.Link, error) {
-	args := m.Called(ctx, id)
+func (m *MockRepository) MarkViewed(ctx context.Context, userID, id string) (apiservice.Link, error) {
+	args := m.Called(ctx, userID, id)
 	return args.Get(0).(apiservice.Link), args.Error(1)
 }
 
-func (m *MockRepository) GetViewStats(ctx context.Context, days int) ([]apiservice.ViewStats, error) {
-	args := m.Called(ctx, days)
+func (m *MockRepository) ListViews(ctx context.Context, userID, id string, limit, offset int) ([]apiservice.LinkView, error) {
+	args := m.Called(ctx, userID, id, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]apiservice.LinkView), args.Error(1)
+}
+
+func (m *MockRepository) GetViewStats(ctx context.Context, userID string, filter apiservice.LinkFilter, days int) ([]apiservice.ViewStats, error) {
+	args := m.Called(ctx, userID, filter, days)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]apiservice.ViewStats), args.Error(1)
 }
 
+func (m *MockRepository) SaveMetadata(ctx context.Context, userID, id string, page apiservice.PageMetadata) (apiservice.Link, error) {
+	args := m.Called(ctx, userID, id, page)
+	return args.Get(0).(apiservice.Link), args.Error(1)
+}
+
+func (m *MockRepository) ListBatch(ctx context.Context, userID string, unclassified bool, afterID string, limit int) ([]apiservice.Link, error) {
+	args := m.Called(ctx, userID, unclassified, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]apiservice.Link), args.Error(1)
+}
+
+func (m *MockRepository) MergeLinks(
+	ctx context.Context,
+	userID, keepID string,
+	duplicateIDs []string,
+	canonicalURL string,
+) (apiservice.Link, error) {
+	args := m.Called(ctx, userID, keepID, duplicateIDs, canonicalURL)
+	return args.Get(0).(apiservice.Link), args.Error(1)
+}
+
+func (m *MockRepository) SetStatus(ctx context.Context, userID, id, from, to string) (apiservice.Link, error) {
+	args := m.Called(ctx, userID, id, from, to)
+	return args.Get(0).(apiservice.Link), args.Error(1)
+}
+
+func (m *MockRepository) ListViewEvents(ctx context.Context, userID string, linkIDs []string) ([]apiservice.LinkView, error) {
+	args := m.Called(ctx, userID, linkIDs)
+	return args.Get(0).([]apiservice.LinkView), args.Error(1)
+}
+
+func (m *MockRepository) Restore(
+	ctx context.Context,
+	userID string,
+	link apiservice.Link,
+	views []apiservice.LinkView,
+) (apiservice.RestoredLink, error) {
+	args := m.Called(ctx, userID, link, views)
+	return args.Get(0).(apiservice.RestoredLink), args.Error(1)
+}
+
+const testUserID = "4f9d3c2e-8a51-4b1e-9c7d-2f6a0e3b5d18"
+
+// withCanonicalURL fills in the canonical URL LinkService.Create derives before saving.
+func withCanonicalURL(input apiservice.LinkCreateInput) apiservice.LinkCreateInput {
+	input.CanonicalURL, _ = NewCanonicalizer(DefaultCanonicalRules()).Canonicalize(input.URL)
+	return input
+}
+
 func TestLinkService_Create(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewLinkService(mockRepo)
 	ctx := context.Background()
 
 	input := apiservice.LinkCreateInput{
+		UserID:   testUserID,
 		URL:      "https://example.com",
 		Resource: "",
 	}
@@ -78,7 +161,7 @@ func TestLinkService_Create(t *testing.T) {
 		CreatedAt: time.Now(),
 	}
 
-	mockRepo.On("Create", ctx, input).Return(expectedLink, nil)
+	mockRepo.On("Create", ctx, withCanonicalURL(input)).Return(expectedLink, nil)
 
 	link, err := service.Create(ctx, input)
 
@@ -93,6 +176,7 @@ func TestLinkService_Create_WithResource(t *testing.T) {
 	ctx := context.Background()
 
 	input := apiservice.LinkCreateInput{
+		UserID:   testUserID,
 		URL:      "https://example.com",
 		Resource: "article",
 	}
@@ -102,7 +186,7 @@ func TestLinkService_Create_WithResource(t *testing.T) {
 		Resource: "article",
 	}
 
-	mockRepo.On("Create", ctx, input).Return(expectedLink, nil)
+	mockRepo.On("Create", ctx, withCanonicalURL(input)).Return(expectedLink, nil)
 
 	link, err := service.Create(ctx, input)
 
@@ -112,6 +196,78 @@ func TestLinkService_Create_WithResource(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestLinkService_Create_NormalizesTags(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	expectedInput := apiservice.LinkCreateInput{
+		UserID: testUserID,
+		URL:    "https://go.dev/blog",
+		Tags:   []string{"golang", "research"},
+	}
+	mockRepo.On("Create", ctx, withCanonicalURL(expectedInput)).Return(apiservice.Link{ID: "test-id", Tags: expectedInput.Tags}, nil)
+
+	link, err := service.Create(ctx, apiservice.LinkCreateInput{
+		UserID: testUserID,
+		URL:    "https://go.dev/blog",
+		Tags:   []string{" #GoLang", "research", "golang"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"golang", "research"}, link.Tags)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkService_Create_InvalidTag(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+
+	_, err := service.Create(context.Background(), apiservice.LinkCreateInput{
+		UserID: testUserID,
+		URL:    "https://example.com",
+		Tags:   []string{"#"},
+	})
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLinkService_Update_TagsOnly(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	expectedInput := apiservice.LinkUpdateInput{
+		AddTags:    []string{"golang"},
+		RemoveTags: []string{"later"},
+	}
+	mockRepo.On("Update", ctx, testUserID, "test-id", expectedInput).Return(apiservice.Link{ID: "test-id"}, nil)
+
+	_, err := service.Update(ctx, testUserID, "test-id", apiservice.LinkUpdateInput{
+		AddTags:    []string{"GoLang"},
+		RemoveTags: []string{"#later"},
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkService_Random_ByTag(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	filter := apiservice.LinkFilter{Resource: "article", Tags: []string{"golang"}, Statuses: []string{apiservice.StatusUnread}}
+	mockRepo.On("Random", ctx, testUserID, filter).Return(apiservice.Link{ID: "test-id"}, nil)
+
+	link, err := service.Random(ctx, testUserID, apiservice.LinkFilter{Resource: "article", Tags: []string{"#Golang"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test-id", link.ID)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestLinkService_GetByID(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewLinkService(mockRepo)
@@ -123,9 +279,9 @@ func TestLinkService_GetByID(t *testing.T) {
 		URL: "https://example.com",
 	}
 
-	mockRepo.On("GetByID", ctx, linkID).Return(expectedLink, nil)
+	mockRepo.On("GetByID", ctx, testUserID, linkID).Return(expectedLink, nil)
 
-	link, err := service.GetByID(ctx, linkID)
+	link, err := service.GetByID(ctx, testUserID, linkID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedLink, link)
@@ -142,12 +298,16 @@ func TestLinkService_List(t *testing.T) {
 		{ID: "2", URL: "https://example2.com"},
 	}
 
-	mockRepo.On("List", ctx, 10, 0).Return(expectedLinks, nil)
+	query := apiservice.ListQuery{Sort: apiservice.SortCreated, Desc: true, Limit: 11}
+	mockRepo.On("List", ctx, testUserID, apiservice.LinkFilter{}, query).Return(expectedLinks, nil)
+	mockRepo.On("Count", ctx, testUserID, apiservice.LinkFilter{}).Return(int64(2), nil)
 
-	links, err := service.List(ctx, 10, 0)
+	page, err := service.List(ctx, testUserID, apiservice.LinkFilter{}, apiservice.PageRequest{Limit: 10})
 
 	assert.NoError(t, err)
-	assert.Len(t, links, 2)
+	assert.Len(t, page.Links, 2)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Empty(t, page.NextCursor)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -161,15 +321,43 @@ func TestLinkService_Random(t *testing.T) {
 		URL: "https://example.com",
 	}
 
-	mockRepo.On("Random", ctx, "").Return(expectedLink, nil)
+	unread := apiservice.LinkFilter{Statuses: []string{apiservice.StatusUnread}}
+	mockRepo.On("Random", ctx, testUserID, unread).Return(expectedLink, nil)
 
-	link, err := service.Random(ctx, "")
+	link, err := service.Random(ctx, testUserID, apiservice.LinkFilter{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedLink, link)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestLinkService_Search(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	expected := []apiservice.SearchResult{
+		{Link: apiservice.Link{ID: "test-id", Title: "Go generics"}, Rank: 0.6, Snippet: "<b>Go</b> generics"},
+	}
+	mockRepo.On("Search", ctx, testUserID, "go generics", apiservice.LinkFilter{}, 20, 0).Return(expected, nil)
+
+	results, err := service.Search(ctx, testUserID, "  go generics ", apiservice.LinkFilter{}, 20, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, results)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkService_Search_EmptyQuery(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+
+	_, err := service.Search(context.Background(), testUserID, "   ", apiservice.LinkFilter{}, 20, 0)
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestLinkService_MarkViewed(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewLinkService(mockRepo)
@@ -182,24 +370,43 @@ func TestLinkService_MarkViewed(t *testing.T) {
 		Views: 1,
 	}
 
-	mockRepo.On("MarkViewed", ctx, linkID).Return(expectedLink, nil)
+	mockRepo.On("MarkViewed", ctx, testUserID, linkID).Return(expectedLink, nil)
 
-	link, err := service.MarkViewed(ctx, linkID)
+	link, err := service.MarkViewed(ctx, testUserID, linkID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), link.Views)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestLinkService_ListViews(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	expectedViews := []apiservice.LinkView{
+		{ID: "v2", LinkID: "test-id", ViewedAt: time.Now()},
+		{ID: "v1", LinkID: "test-id", ViewedAt: time.Now().Add(-time.Hour)},
+	}
+
+	mockRepo.On("ListViews", ctx, testUserID, "test-id", 20, 0).Return(expectedViews, nil)
+
+	views, err := service.ListViews(ctx, testUserID, "test-id", 20, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedViews, views)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestLinkService_Delete(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewLinkService(mockRepo)
 	ctx := context.Background()
 
 	linkID := "test-id"
-	mockRepo.On("Delete", ctx, linkID).Return(nil)
+	mockRepo.On("Delete", ctx, testUserID, linkID).Return(nil)
 
-	err := service.Delete(ctx, linkID)
+	err := service.Delete(ctx, testUserID, linkID)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -215,9 +422,9 @@ func TestLinkService_GetViewStats(t *testing.T) {
 		{Date: "2026-01-02", Count: 10, Level: 3},
 	}
 
-	mockRepo.On("GetViewStats", ctx, 30).Return(expectedStats, nil)
+	mockRepo.On("GetViewStats", ctx, testUserID, apiservice.LinkFilter{}, 30).Return(expectedStats, nil)
 
-	stats, err := service.GetViewStats(ctx, 30)
+	stats, err := service.GetViewStats(ctx, testUserID, apiservice.LinkFilter{}, 30)
 
 	assert.NoError(t, err)
 	assert.Len(t, stats, 2)
@@ -230,8 +437,8 @@ func TestLinkService_Error_Handling(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("Create Error", func(t *testing.T) {
-		input := apiservice.LinkCreateInput{URL: "https://example.com"}
-		mockRepo.On("Create", ctx, input).Return(apiservice.Link{}, errors.New("db error")).Once()
+		input := apiservice.LinkCreateInput{UserID: testUserID, URL: "https://example.com"}
+		mockRepo.On("Create", ctx, withCanonicalURL(input)).Return(apiservice.Link{}, errors.New("db error")).Once()
 
 		_, err := service.Create(ctx, input)
 
@@ -239,26 +446,55 @@ func TestLinkService_Error_Handling(t *testing.T) {
 	})
 
 	t.Run("GetByID Error", func(t *testing.T) {
-		mockRepo.On("GetByID", ctx, "invalid-id").Return(apiservice.Link{}, errors.New("not found")).Once()
+		mockRepo.On("GetByID", ctx, testUserID, "invalid-id").Return(apiservice.Link{}, errors.New("not found")).Once()
 
-		_, err := service.GetByID(ctx, "invalid-id")
+		_, err := service.GetByID(ctx, testUserID, "invalid-id")
 
 		assert.Error(t, err)
 	})
 
 	t.Run("List Error", func(t *testing.T) {
-		mockRepo.On("List", ctx, 10, 0).Return(nil, errors.New("db error")).Once()
+		mockRepo.On("List", ctx, testUserID, apiservice.LinkFilter{}, mock.Anything).Return(nil, errors.New("db error")).Once()
 
-		_, err := service.List(ctx, 10, 0)
+		_, err := service.List(ctx, testUserID, apiservice.LinkFilter{}, apiservice.PageRequest{Limit: 10})
 
 		assert.Error(t, err)
 	})
 
 	t.Run("Random Error", func(t *testing.T) {
-		mockRepo.On("Random", ctx, "").Return(apiservice.Link{}, errors.New("not found")).Once()
+		unread := apiservice.LinkFilter{Statuses: []string{apiservice.StatusUnread}}
+		mockRepo.On("Random", ctx, testUserID, unread).Return(apiservice.Link{}, errors.New("not found")).Once()
 
-		_, err := service.Random(ctx, "")
+		_, err := service.Random(ctx, testUserID, apiservice.LinkFilter{})
 
 		assert.Error(t, err)
 	})
 }
+
+func TestLinkService_RequiresUser(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	t.Run("Create without user", func(t *testing.T) {
+		_, err := service.Create(ctx, apiservice.LinkCreateInput{URL: "https://example.com"})
+
+		assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	})
+
+	t.Run("List without user", func(t *testing.T) {
+		_, err := service.List(ctx, "", apiservice.LinkFilter{}, apiservice.PageRequest{Limit: 10})
+
+		assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	})
+
+	t.Run("GetByID with malformed user", func(t *testing.T) {
+		_, err := service.GetByID(ctx, "not-a-uuid", "test-id")
+
+		assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	})
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// defaultOrders is the direction each sort uses when none is asked for:
+// titles alphabetically, everything else largest or latest first.
+var defaultOrders = map[string]string{
+	apiservice.SortCreated: apiservice.OrderDesc,
+	apiservice.SortUpdated: apiservice.OrderDesc,
+	apiservice.SortViews:   apiservice.OrderDesc,
+	apiservice.SortViewed:  apiservice.OrderDesc,
+	apiservice.SortTitle:   apiservice.OrderAsc,
+}
+
+// cursor is what an opaque page cursor encodes: the order it was issued for
+// and the sort key of the last link on the page.
+type cursor struct {
+	Sort  string     `json:"s"`
+	Desc  bool       `json:"d,omitempty"`
+	ID    string     `json:"i"`
+	Time  *time.Time `json:"t,omitempty"`
+	Views int64      `json:"v,omitempty"`
+	Title string     `json:"k,omitempty"`
+}
+
+func (s *LinkService) List(
+	ctx context.Context,
+	userID string,
+	filter apiservice.LinkFilter,
+	page apiservice.PageRequest,
+) (apiservice.LinkPage, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.LinkPage{}, err
+	}
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return apiservice.LinkPage{}, err
+	}
+	query, err := listQuery(page)
+	if err != nil {
+		return apiservice.LinkPage{}, err
+	}
+
+	limit := query.Limit
+	query.Limit++
+	links, err := s.repo.List(ctx, userID, filter, query)
+	if err != nil {
+		return apiservice.LinkPage{}, err
+	}
+	total, err := s.repo.Count(ctx, userID, filter)
+	if err != nil {
+		return apiservice.LinkPage{}, err
+	}
+
+	result := apiservice.LinkPage{Links: links, Total: total}
+	if len(links) > limit {
+		result.Links = links[:limit]
+		result.NextCursor = encodeCursor(query.Sort, query.Desc, result.Links[limit-1])
+	}
+	return result, nil
+}
+
+// listQuery resolves a page request into a keyset query. A cursor carries its
+// own order, so later pages may omit sort and order but must not change them.
+func listQuery(page apiservice.PageRequest) (apiservice.ListQuery, error) {
+	sort := strings.ToLower(strings.TrimSpace(page.Sort))
+	order := strings.ToLower(strings.TrimSpace(page.Order))
+	if sort != "" {
+		if _, ok := defaultOrders[sort]; !ok {
+			return apiservice.ListQuery{}, fmt.Errorf("%w: unknown sort %q", apiservice.ErrInvalidInput, page.Sort)
+		}
+	}
+	if order != "" && order != apiservice.OrderAsc && order != apiservice.OrderDesc {
+		return apiservice.ListQuery{}, fmt.Errorf("%w: order must be asc or desc", apiservice.ErrInvalidInput)
+	}
+
+	query := apiservice.ListQuery{Limit: page.Limit}
+	if query.Limit <= 0 {
+		query.Limit = defaultPageSize
+	}
+	if query.Limit > maxPageSize {
+		query.Limit = maxPageSize
+	}
+
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return apiservice.ListQuery{}, err
+		}
+		if (sort != "" && sort != c.Sort) || (order != "" && (order == apiservice.OrderDesc) != c.Desc) {
+			return apiservice.ListQuery{}, fmt.Errorf("%w: cursor was issued for a different sort order", apiservice.ErrInvalidInput)
+		}
+		query.Sort, query.Desc = c.Sort, c.Desc
+		query.After = c.link()
+		return query, nil
+	}
+
+	if sort == "" {
+		sort = apiservice.SortCreated
+	}
+	if order == "" {
+		order = defaultOrders[sort]
+	}
+	query.Sort, query.Desc = sort, order == apiservice.OrderDesc
+	return query, nil
+}
+
+// encodeCursor builds the cursor that continues a listing after link.
+func encodeCursor(sort string, desc bool, link apiservice.Link) string {
+	c := cursor{Sort: sort, Desc: desc, ID: link.ID}
+	switch sort {
+	case apiservice.SortCreated:
+		c.Time = &link.CreatedAt
+	case apiservice.SortUpdated:
+		c.Time = &link.UpdatedAt
+	case apiservice.SortViewed:
+		c.Time = link.ViewedAt
+	case apiservice.SortViews:
+		c.Views = link.Views
+	case apiservice.SortTitle:
+		c.Title = link.Title
+	}
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(raw string) (cursor, error) {
+	invalid := fmt.Errorf("%w: malformed cursor", apiservice.ErrInvalidInput)
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor{}, invalid
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return cursor{}, invalid
+	}
+	if _, ok := defaultOrders[c.Sort]; !ok || c.ID == "" {
+		return cursor{}, invalid
+	}
+	if (c.Sort == apiservice.SortCreated || c.Sort == apiservice.SortUpdated) && c.Time == nil {
+		return cursor{}, invalid
+	}
+	return c, nil
+}
+
+// link rebuilds the part of the last link that the repository pages after.
+func (c cursor) link() *apiservice.Link {
+	link := &apiservice.Link{ID: c.ID, Views: c.Views, Title: c.Title}
+	switch c.Sort {
+	case apiservice.SortCreated:
+		link.CreatedAt = *c.Time
+	case apiservice.SortUpdated:
+		link.UpdatedAt = *c.Time
+	case apiservice.SortViewed:
+		link.ViewedAt = c.Time
+	}
+	return link
+}
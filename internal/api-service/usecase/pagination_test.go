@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func TestLinkService_List_NextCursor(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	links := []apiservice.Link{
+		{ID: "1", Title: "Alpha"},
+		{ID: "2", Title: "Bravo"},
+		{ID: "3", Title: "Charlie"},
+	}
+	mockRepo.On("List", ctx, testUserID, apiservice.LinkFilter{},
+		apiservice.ListQuery{Sort: apiservice.SortTitle, Limit: 3}).Return(links, nil).Once()
+	mockRepo.On("Count", ctx, testUserID, apiservice.LinkFilter{}).Return(int64(5), nil)
+
+	page, err := service.List(ctx, testUserID, apiservice.LinkFilter{}, apiservice.PageRequest{Sort: "Title", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, links[:2], page.Links)
+	assert.Equal(t, int64(5), page.Total)
+	require.NotEmpty(t, page.NextCursor)
+
+	mockRepo.On("List", ctx, testUserID, apiservice.LinkFilter{}, apiservice.ListQuery{
+		Sort:  apiservice.SortTitle,
+		After: &apiservice.Link{ID: "2", Title: "Bravo"},
+		Limit: 3,
+	}).Return(links[2:], nil).Once()
+
+	page, err = service.List(ctx, testUserID, apiservice.LinkFilter{}, apiservice.PageRequest{Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, links[2:], page.Links)
+	assert.Empty(t, page.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkService_List_CursorKeepsTime(t *testing.T) {
+	viewedAt := time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.UTC)
+	cursor := encodeCursor(apiservice.SortViewed, true, apiservice.Link{ID: "7", ViewedAt: &viewedAt})
+
+	query, err := listQuery(apiservice.PageRequest{Cursor: cursor})
+
+	require.NoError(t, err)
+	assert.Equal(t, apiservice.SortViewed, query.Sort)
+	assert.True(t, query.Desc)
+	require.NotNil(t, query.After.ViewedAt)
+	assert.True(t, viewedAt.Equal(*query.After.ViewedAt))
+	assert.Equal(t, "7", query.After.ID)
+}
+
+func TestLinkService_List_InvalidPage(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+	titleCursor := encodeCursor(apiservice.SortTitle, false, apiservice.Link{ID: "1", Title: "a"})
+
+	for name, page := range map[string]apiservice.PageRequest{
+		"unknown sort":     {Sort: "popularity"},
+		"unknown order":    {Order: "sideways"},
+		"malformed cursor": {Cursor: "not a cursor"},
+		"changed sort":     {Sort: apiservice.SortCreated, Cursor: titleCursor},
+		"changed order":    {Order: apiservice.OrderDesc, Cursor: titleCursor},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.List(ctx, testUserID, apiservice.LinkFilter{}, page)
+			assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+		})
+	}
+	mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
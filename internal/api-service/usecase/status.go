@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+// SetStatus moves a link to a new status. Setting the status a link already has is a no-op.
+func (s *LinkService) SetStatus(ctx context.Context, userID, id, status string) (apiservice.Link, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.Link{}, err
+	}
+	status, err := normalizeStatus(status)
+	if err != nil {
+		return apiservice.Link{}, err
+	}
+	link, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return apiservice.Link{}, err
+	}
+	if link.Status == status {
+		return link, nil
+	}
+	if !slices.Contains(apiservice.NextStatuses(link.Status), status) {
+		return apiservice.Link{}, fmt.Errorf("%w: cannot move a %s link to %s", apiservice.ErrInvalidInput, link.Status, status)
+	}
+	return s.repo.SetStatus(ctx, userID, id, link.Status, status)
+}
+
+func normalizeStatus(status string) (string, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if apiservice.NextStatuses(status) == nil {
+		return "", fmt.Errorf("%w: unknown status %q", apiservice.ErrInvalidInput, status)
+	}
+	return status, nil
+}
+
+func normalizeStatuses(statuses []string) ([]string, error) {
+	var out []string
+	for _, status := range statuses {
+		status, err := normalizeStatus(status)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(out, status) {
+			out = append(out, status)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+func TestLinkService_SetStatus(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, testUserID, "link-1").
+		Return(apiservice.Link{ID: "link-1", Status: apiservice.StatusUnread}, nil)
+	mockRepo.On("SetStatus", ctx, testUserID, "link-1", apiservice.StatusUnread, apiservice.StatusDone).
+		Return(apiservice.Link{ID: "link-1", Status: apiservice.StatusDone}, nil)
+
+	link, err := service.SetStatus(ctx, testUserID, "link-1", " Done ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, apiservice.StatusDone, link.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkService_SetStatus_SameStatus(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	current := apiservice.Link{ID: "link-1", Status: apiservice.StatusReading}
+	mockRepo.On("GetByID", ctx, testUserID, "link-1").Return(current, nil)
+
+	link, err := service.SetStatus(ctx, testUserID, "link-1", apiservice.StatusReading)
+
+	assert.NoError(t, err)
+	assert.Equal(t, current, link)
+	mockRepo.AssertNotCalled(t, "SetStatus")
+}
+
+func TestLinkService_SetStatus_InvalidTransition(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, testUserID, "link-1").
+		Return(apiservice.Link{ID: "link-1", Status: apiservice.StatusArchived}, nil)
+
+	_, err := service.SetStatus(ctx, testUserID, "link-1", apiservice.StatusDone)
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "SetStatus")
+}
+
+func TestLinkService_SetStatus_UnknownStatus(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewLinkService(mockRepo)
+
+	_, err := service.SetStatus(context.Background(), testUserID, "link-1", "abandoned")
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "GetByID")
+}
+
+func TestLinkService_List_UnknownStatus(t *testing.T) {
+	service := NewLinkService(new(MockRepository))
+
+	_, err := service.List(context.Background(), testUserID, apiservice.LinkFilter{Statuses: []string{"later"}}, apiservice.PageRequest{})
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
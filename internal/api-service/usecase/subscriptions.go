@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// SubscriptionConfig tunes the feed poller. Zero values fall back to defaults.
+type SubscriptionConfig struct {
+	Workers         int           // feeds polled at the same time
+	PollInterval    time.Duration // how often idle workers look for due feeds
+	RefreshInterval time.Duration // time between two polls of a feed
+	MaxBackoff      time.Duration // longest wait after failed polls
+	Lease           time.Duration // a feed claimed by a worker that stopped is polled again after this
+	MaxNewEntries   int           // links saved per poll; further new entries are skipped
+}
+
+func (c SubscriptionConfig) withDefaults() SubscriptionConfig {
+	if c.Workers <= 0 {
+		c.Workers = 2
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = 30 * time.Second
+	}
+	if c.RefreshInterval <= 0 {
+		c.RefreshInterval = 30 * time.Minute
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 24 * time.Hour
+	}
+	if c.Lease <= 0 {
+		c.Lease = 5 * time.Minute
+	}
+	if c.MaxNewEntries <= 0 {
+		c.MaxNewEntries = 20
+	}
+	return c
+}
+
+// SubscriptionService follows feeds for users. Its poller claims due feeds
+// from the database, so that several instances share the polling.
+type SubscriptionService struct {
+	links   *LinkService
+	subs    apiservice.SubscriptionRepository
+	fetcher apiservice.FeedFetcher
+	cfg     SubscriptionConfig
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+var _ apiservice.SubscriptionService = (*SubscriptionService)(nil)
+
+func NewSubscriptionService(
+	links *LinkService,
+	subs apiservice.SubscriptionRepository,
+	fetcher apiservice.FeedFetcher,
+	cfg SubscriptionConfig,
+) *SubscriptionService {
+	return &SubscriptionService{links: links, subs: subs, fetcher: fetcher, cfg: cfg.withDefaults()}
+}
+
+func (s *SubscriptionService) Subscribe(ctx context.Context, input apiservice.SubscriptionInput) (apiservice.Subscription, error) {
+	if err := validateUserID(input.UserID); err != nil {
+		return apiservice.Subscription{}, err
+	}
+	rawURL := strings.TrimSpace(input.FeedURL)
+	if rawURL == "" {
+		return apiservice.Subscription{}, fmt.Errorf("%w: feed url is required", apiservice.ErrInvalidInput)
+	}
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return apiservice.Subscription{}, err
+	}
+	feed, err := s.fetcher.Fetch(ctx, apiservice.FeedRequest{URL: withScheme(rawURL)})
+	if err != nil {
+		return apiservice.Subscription{}, err
+	}
+
+	now := time.Now()
+	sub := apiservice.Subscription{
+		UserID:       input.UserID,
+		FeedURL:      feed.URL,
+		SiteURL:      feed.SiteURL,
+		Title:        feed.Title,
+		Tags:         tags,
+		ETag:         feed.ETag,
+		LastModified: feed.LastModified,
+		LastPolledAt: &now,
+		NextPollAt:   now.Add(s.cfg.RefreshInterval),
+	}
+	if sub.Title == "" {
+		sub.Title = feed.URL
+	}
+	// What the feed holds now is the backlog; only later entries are saved.
+	return s.subs.Create(ctx, sub, entryKeys(feed.Entries))
+}
+
+func (s *SubscriptionService) Get(ctx context.Context, userID, id string) (apiservice.Subscription, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.Subscription{}, err
+	}
+	if uuid.Validate(id) != nil {
+		return apiservice.Subscription{}, apiservice.ErrNotFound
+	}
+	return s.subs.Get(ctx, userID, id)
+}
+
+func (s *SubscriptionService) List(ctx context.Context, userID string) ([]apiservice.Subscription, error) {
+	if err := validateUserID(userID); err != nil {
+		return nil, err
+	}
+	return s.subs.List(ctx, userID)
+}
+
+func (s *SubscriptionService) Update(
+	ctx context.Context,
+	userID, id string,
+	input apiservice.SubscriptionUpdate,
+) (apiservice.Subscription, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.Subscription{}, err
+	}
+	if uuid.Validate(id) != nil {
+		return apiservice.Subscription{}, apiservice.ErrNotFound
+	}
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		if title == "" {
+			return apiservice.Subscription{}, fmt.Errorf("%w: title cannot be empty", apiservice.ErrInvalidInput)
+		}
+		input.Title = &title
+	}
+	if input.Tags != nil {
+		tags, err := normalizeTags(*input.Tags)
+		if err != nil {
+			return apiservice.Subscription{}, err
+		}
+		input.Tags = &tags
+	}
+	return s.subs.Update(ctx, userID, id, input)
+}
+
+func (s *SubscriptionService) Unsubscribe(ctx context.Context, userID, id string) error {
+	if err := validateUserID(userID); err != nil {
+		return err
+	}
+	if uuid.Validate(id) != nil {
+		return apiservice.ErrNotFound
+	}
+	return s.subs.Delete(ctx, userID, id)
+}
+
+// Start launches the poller. It runs until Stop is called or ctx is done.
+func (s *SubscriptionService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.work(ctx)
+		}()
+	}
+}
+
+// Stop interrupts the poller and waits for it. Feeds it was polling are
+// polled again once their lease runs out.
+func (s *SubscriptionService) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *SubscriptionService) work(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		sub, err := s.subs.Claim(ctx, now, now.Add(s.cfg.Lease))
+		switch {
+		case err == nil:
+			s.poll(ctx, sub)
+			continue
+		case errors.Is(err, apiservice.ErrConflict):
+			// Another worker claimed the same feed; look for the next one.
+			continue
+		case ctx.Err() != nil:
+			return
+		case !errors.Is(err, apiservice.ErrNotFound):
+			logger.L().Error().Err(err).Msg("claim subscription")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches a claimed feed, saves its new entries and schedules the next poll.
+func (s *SubscriptionService) poll(ctx context.Context, sub apiservice.Subscription) {
+	log := logger.L().With().Str("subscription_id", sub.ID).Str("feed_url", sub.FeedURL).Logger()
+
+	seen, created, err := s.refresh(ctx, &sub)
+	if ctx.Err() != nil {
+		// Stopping; the lease runs out and the feed is polled again.
+		return
+	}
+	now := time.Now()
+	sub.LastPolledAt = &now
+	if err != nil {
+		sub.ErrorCount++
+		sub.LastError = err.Error()
+		sub.NextPollAt = now.Add(s.backoff(sub.ErrorCount))
+		log.Warn().Err(err).Int("errors", sub.ErrorCount).Msg("poll feed")
+	} else {
+		sub.ErrorCount, sub.LastError = 0, ""
+		sub.NextPollAt = now.Add(s.cfg.RefreshInterval)
+		if created > 0 {
+			log.Info().Int("created", created).Msg("saved new feed entries")
+		}
+	}
+	if _, err := s.subs.SavePoll(ctx, sub, seen); err != nil && !errors.Is(err, apiservice.ErrNotFound) {
+		log.Error().Err(err).Msg("save subscription")
+	}
+}
+
+// refresh fetches the feed and saves the entries it has not seen before,
+// oldest first. It returns the keys of every entry now in the feed, or nil
+// when the feed did not change, and how many links were created. On error
+// sub keeps its previous validators, so that the next poll reads the whole
+// feed again.
+func (s *SubscriptionService) refresh(ctx context.Context, sub *apiservice.Subscription) ([]string, int, error) {
+	feed, err := s.fetcher.Fetch(ctx, apiservice.FeedRequest{
+		URL:          sub.FeedURL,
+		ETag:         sub.ETag,
+		LastModified: sub.LastModified,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if feed.NotModified {
+		return nil, 0, nil
+	}
+	keys := entryKeys(feed.Entries)
+	if len(keys) == 0 {
+		// An empty feed is more likely broken than emptied; keep what was seen.
+		keys = nil
+	}
+
+	old, err := s.subs.Seen(ctx, sub.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	seen := make(map[string]bool, len(old))
+	for _, key := range old {
+		seen[key] = true
+	}
+	// Feeds list their newest entries first. Beyond MaxNewEntries the
+	// oldest new entries are skipped, so a feed that reshuffles its ids
+	// cannot flood the user.
+	var fresh []apiservice.FeedEntry
+	for _, entry := range feed.Entries {
+		if !seen[entry.Key] && len(fresh) < s.cfg.MaxNewEntries {
+			fresh = append(fresh, entry)
+		}
+		seen[entry.Key] = true
+	}
+
+	created := 0
+	for i := len(fresh) - 1; i >= 0; i-- {
+		_, err := s.links.Create(ctx, apiservice.LinkCreateInput{
+			UserID: sub.UserID,
+			URL:    fresh[i].URL,
+			Title:  fresh[i].Title,
+			Tags:   sub.Tags,
+			Source: sub.FeedURL,
+		})
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, apiservice.ErrAlreadyExists), errors.Is(err, apiservice.ErrInvalidInput):
+		default:
+			return nil, created, fmt.Errorf("save %s: %w", fresh[i].URL, err)
+		}
+	}
+	if feed.SiteURL != "" {
+		sub.SiteURL = feed.SiteURL
+	}
+	sub.ETag, sub.LastModified = feed.ETag, feed.LastModified
+	return keys, created, nil
+}
+
+// backoff is the wait after the given number of failed polls in a row:
+// RefreshInterval doubling with each failure, up to MaxBackoff.
+func (s *SubscriptionService) backoff(failures int) time.Duration {
+	wait := s.cfg.RefreshInterval
+	for i := 1; i < failures && wait < s.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, s.cfg.MaxBackoff)
+}
+
+func entryKeys(entries []apiservice.FeedEntry) []string {
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type MockSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionRepository) Create(
+	ctx context.Context,
+	sub apiservice.Subscription,
+	seen []string,
+) (apiservice.Subscription, error) {
+	args := m.Called(ctx, sub, seen)
+	return args.Get(0).(apiservice.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Get(ctx context.Context, userID, id string) (apiservice.Subscription, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(apiservice.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) List(ctx context.Context, userID string) ([]apiservice.Subscription, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]apiservice.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Update(
+	ctx context.Context,
+	userID, id string,
+	input apiservice.SubscriptionUpdate,
+) (apiservice.Subscription, error) {
+	args := m.Called(ctx, userID, id, input)
+	return args.Get(0).(apiservice.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Delete(ctx context.Context, userID, id string) error {
+	return m.Called(ctx, userID, id).Error(0)
+}
+
+func (m *MockSubscriptionRepository) Claim(ctx context.Context, now, leaseUntil time.Time) (apiservice.Subscription, error) {
+	args := m.Called(ctx, now, leaseUntil)
+	return args.Get(0).(apiservice.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Seen(ctx context.Context, id string) ([]string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) SavePoll(
+	ctx context.Context,
+	sub apiservice.Subscription,
+	seen []string,
+) (apiservice.Subscription, error) {
+	args := m.Called(ctx, sub, seen)
+	return args.Get(0).(apiservice.Subscription), args.Error(1)
+}
+
+// stubFeeds serves a fixed feed, or fails with err.
+type stubFeeds struct {
+	feed apiservice.Feed
+	err  error
+	reqs []apiservice.FeedRequest
+}
+
+func (f *stubFeeds) Fetch(_ context.Context, req apiservice.FeedRequest) (apiservice.Feed, error) {
+	f.reqs = append(f.reqs, req)
+	return f.feed, f.err
+}
+
+var blogFeed = apiservice.Feed{
+	URL:     "https://blog.example/feed.xml",
+	Title:   "Blog",
+	SiteURL: "https://blog.example/",
+	ETag:    `"v2"`,
+	Entries: []apiservice.FeedEntry{
+		{Key: "3", URL: "https://blog.example/3", Title: "Third"},
+		{Key: "2", URL: "https://blog.example/2", Title: "Second"},
+		{Key: "1", URL: "https://blog.example/1", Title: "First"},
+	},
+}
+
+func TestSubscriptionService_Subscribe(t *testing.T) {
+	subs := new(MockSubscriptionRepository)
+	fetcher := &stubFeeds{feed: blogFeed}
+	service := NewSubscriptionService(NewLinkService(new(MockRepository)), subs, fetcher, SubscriptionConfig{})
+	ctx := context.Background()
+
+	subs.On("Create", ctx, mock.MatchedBy(func(s apiservice.Subscription) bool {
+		return s.UserID == testUserID && s.FeedURL == blogFeed.URL && s.Title == "Blog" &&
+			s.ETag == `"v2"` && assert.ObjectsAreEqual([]string{"dev"}, s.Tags) && s.NextPollAt.After(time.Now())
+	}), []string{"3", "2", "1"}).Return(apiservice.Subscription{ID: "sub"}, nil)
+
+	sub, err := service.Subscribe(ctx, apiservice.SubscriptionInput{
+		UserID:  testUserID,
+		FeedURL: "blog.example",
+		Tags:    []string{"#Dev"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "sub", sub.ID)
+	assert.Equal(t, "https://blog.example", fetcher.reqs[0].URL)
+	subs.AssertExpectations(t)
+}
+
+func TestSubscriptionService_Subscribe_Invalid(t *testing.T) {
+	fetcher := &stubFeeds{err: apiservice.ErrInvalidInput}
+	service := NewSubscriptionService(NewLinkService(new(MockRepository)), new(MockSubscriptionRepository), fetcher, SubscriptionConfig{})
+	ctx := context.Background()
+
+	_, err := service.Subscribe(ctx, apiservice.SubscriptionInput{UserID: testUserID, FeedURL: " "})
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	assert.Empty(t, fetcher.reqs)
+
+	_, err = service.Subscribe(ctx, apiservice.SubscriptionInput{UserID: testUserID, FeedURL: "https://example.com/"})
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
+
+func TestSubscriptionService_Poll(t *testing.T) {
+	mockRepo := new(MockRepository)
+	subs := new(MockSubscriptionRepository)
+	fetcher := &stubFeeds{feed: blogFeed}
+	service := NewSubscriptionService(NewLinkService(mockRepo), subs, fetcher, SubscriptionConfig{})
+	ctx := context.Background()
+
+	sub := apiservice.Subscription{
+		ID:      "sub",
+		UserID:  testUserID,
+		FeedURL: blogFeed.URL,
+		Tags:    []string{"dev"},
+		ETag:    `"v1"`,
+	}
+	subs.On("Seen", ctx, "sub").Return([]string{"1"}, nil)
+	var created []string
+	for _, entry := range blogFeed.Entries[:2] {
+		var err error
+		if entry.Key == "3" {
+			err = apiservice.ErrAlreadyExists
+		}
+		mockRepo.On("Create", ctx, withCanonicalURL(apiservice.LinkCreateInput{
+			UserID: testUserID,
+			URL:    entry.URL,
+			Title:  entry.Title,
+			Tags:   []string{"dev"},
+			Source: blogFeed.URL,
+		})).Run(func(args mock.Arguments) {
+			created = append(created, args.Get(1).(apiservice.LinkCreateInput).URL)
+		}).Return(apiservice.Link{ID: entry.Key}, err)
+	}
+	var saved apiservice.Subscription
+	subs.On("SavePoll", ctx, mock.Anything, []string{"3", "2", "1"}).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(apiservice.Subscription) }).
+		Return(apiservice.Subscription{}, nil)
+
+	service.poll(ctx, sub)
+
+	assert.Equal(t, `"v1"`, fetcher.reqs[0].ETag)
+	assert.Equal(t, []string{"https://blog.example/2", "https://blog.example/3"}, created, "oldest entries first")
+	assert.Equal(t, `"v2"`, saved.ETag)
+	assert.Equal(t, "https://blog.example/", saved.SiteURL)
+	assert.NotNil(t, saved.LastPolledAt)
+	assert.Zero(t, saved.ErrorCount)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), saved.NextPollAt, time.Minute)
+	mockRepo.AssertExpectations(t)
+	subs.AssertExpectations(t)
+}
+
+func TestSubscriptionService_Poll_CapsNewEntries(t *testing.T) {
+	mockRepo := new(MockRepository)
+	subs := new(MockSubscriptionRepository)
+	service := NewSubscriptionService(NewLinkService(mockRepo), subs, &stubFeeds{feed: blogFeed}, SubscriptionConfig{MaxNewEntries: 1})
+	ctx := context.Background()
+
+	subs.On("Seen", ctx, "sub").Return([]string{}, nil)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(in apiservice.LinkCreateInput) bool {
+		return in.URL == "https://blog.example/3"
+	})).Return(apiservice.Link{ID: "3"}, nil).Once()
+	subs.On("SavePoll", ctx, mock.Anything, []string{"3", "2", "1"}).Return(apiservice.Subscription{}, nil)
+
+	service.poll(ctx, apiservice.Subscription{ID: "sub", UserID: testUserID, FeedURL: blogFeed.URL})
+
+	mockRepo.AssertExpectations(t)
+	subs.AssertExpectations(t)
+}
+
+func TestSubscriptionService_Poll_NotModified(t *testing.T) {
+	subs := new(MockSubscriptionRepository)
+	fetcher := &stubFeeds{feed: apiservice.Feed{NotModified: true}}
+	service := NewSubscriptionService(NewLinkService(new(MockRepository)), subs, fetcher, SubscriptionConfig{})
+	ctx := context.Background()
+
+	var saved apiservice.Subscription
+	subs.On("SavePoll", ctx, mock.Anything, []string(nil)).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(apiservice.Subscription) }).
+		Return(apiservice.Subscription{}, nil)
+
+	service.poll(ctx, apiservice.Subscription{ID: "sub", UserID: testUserID, ETag: `"v1"`, ErrorCount: 2, LastError: "boom"})
+
+	assert.Equal(t, `"v1"`, saved.ETag)
+	assert.Zero(t, saved.ErrorCount)
+	assert.Empty(t, saved.LastError)
+	subs.AssertNotCalled(t, "Seen", mock.Anything, mock.Anything)
+}
+
+func TestSubscriptionService_Poll_BacksOff(t *testing.T) {
+	subs := new(MockSubscriptionRepository)
+	fetcher := &stubFeeds{err: errors.New("status 503")}
+	service := NewSubscriptionService(NewLinkService(new(MockRepository)), subs, fetcher, SubscriptionConfig{
+		RefreshInterval: time.Hour,
+		MaxBackoff:      6 * time.Hour,
+	})
+	ctx := context.Background()
+
+	var saved apiservice.Subscription
+	subs.On("SavePoll", ctx, mock.Anything, []string(nil)).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(apiservice.Subscription) }).
+		Return(apiservice.Subscription{}, nil)
+
+	service.poll(ctx, apiservice.Subscription{ID: "sub", UserID: testUserID, ErrorCount: 2})
+
+	assert.Equal(t, 3, saved.ErrorCount)
+	assert.Equal(t, "status 503", saved.LastError)
+	assert.WithinDuration(t, time.Now().Add(4*time.Hour), saved.NextPollAt, time.Minute)
+
+	assert.Equal(t, time.Hour, service.backoff(1))
+	assert.Equal(t, 6*time.Hour, service.backoff(10))
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+const maxTagLength = 64
+
+type TagService struct {
+	repo apiservice.TagRepository
+}
+
+func NewTagService(repo apiservice.TagRepository) *TagService {
+	return &TagService{repo: repo}
+}
+
+func (s *TagService) List(ctx context.Context, userID string) ([]apiservice.Tag, error) {
+	if err := validateUserID(userID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, userID)
+}
+
+func (s *TagService) Rename(ctx context.Context, userID, from, to string) (apiservice.Tag, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.Tag{}, err
+	}
+	from, err := normalizeTag(from)
+	if err != nil {
+		return apiservice.Tag{}, err
+	}
+	to, err = normalizeTag(to)
+	if err != nil {
+		return apiservice.Tag{}, err
+	}
+	return s.repo.Rename(ctx, userID, from, to)
+}
+
+func (s *TagService) Merge(ctx context.Context, userID string, sources []string, into string) (apiservice.Tag, error) {
+	if err := validateUserID(userID); err != nil {
+		return apiservice.Tag{}, err
+	}
+	sources, err := normalizeTags(sources)
+	if err != nil {
+		return apiservice.Tag{}, err
+	}
+	if len(sources) == 0 {
+		return apiservice.Tag{}, fmt.Errorf("%w: no tags to merge", apiservice.ErrInvalidInput)
+	}
+	into, err = normalizeTag(into)
+	if err != nil {
+		return apiservice.Tag{}, err
+	}
+	return s.repo.Merge(ctx, userID, sources, into)
+}
+
+// normalizeTag folds a tag name to its stored form: trimmed, lower-case and without a leading '#'.
+func normalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(name), "#")))
+	if name == "" {
+		return "", fmt.Errorf("%w: tag name is required", apiservice.ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(name) > maxTagLength {
+		return "", fmt.Errorf("%w: tag %q is longer than %d characters", apiservice.ErrInvalidInput, name, maxTagLength)
+	}
+	if strings.ContainsAny(name, ",\n\t") {
+		return "", fmt.Errorf("%w: tag %q contains forbidden characters", apiservice.ErrInvalidInput, name)
+	}
+	return name, nil
+}
+
+// normalizeTags normalizes every name and drops duplicates, keeping the first occurrence.
+func normalizeTags(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, raw := range names {
+		name, err := normalizeTag(raw)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	return out, nil
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+)
+
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) List(ctx context.Context, userID string) ([]apiservice.Tag, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]apiservice.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) Rename(ctx context.Context, userID, from, to string) (apiservice.Tag, error) {
+	args := m.Called(ctx, userID, from, to)
+	return args.Get(0).(apiservice.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) Merge(ctx context.Context, userID string, sources []string, into string) (apiservice.Tag, error) {
+	args := m.Called(ctx, userID, sources, into)
+	return args.Get(0).(apiservice.Tag), args.Error(1)
+}
+
+func TestTagService_List(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewTagService(mockRepo)
+	ctx := context.Background()
+
+	expected := []apiservice.Tag{{Name: "golang", Count: 3}, {Name: "video", Count: 1}}
+	mockRepo.On("List", ctx, testUserID).Return(expected, nil)
+
+	tags, err := service.List(ctx, testUserID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, tags)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTagService_Rename(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewTagService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("Rename", ctx, testUserID, "go", "golang").Return(apiservice.Tag{Name: "golang", Count: 2}, nil)
+
+	tag, err := service.Rename(ctx, testUserID, "#Go", " golang ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "golang", tag.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTagService_Rename_EmptyTarget(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewTagService(mockRepo)
+
+	_, err := service.Rename(context.Background(), testUserID, "go", "  ")
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "Rename", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTagService_Merge(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewTagService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("Merge", ctx, testUserID, []string{"go", "golang-lang"}, "golang").
+		Return(apiservice.Tag{Name: "golang", Count: 5}, nil)
+
+	tag, err := service.Merge(ctx, testUserID, []string{"Go", "golang-lang", "go"}, "golang")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), tag.Count)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTagService_Merge_NoSources(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewTagService(mockRepo)
+
+	_, err := service.Merge(context.Background(), testUserID, nil, "golang")
+
+	assert.ErrorIs(t, err, apiservice.ErrInvalidInput)
+}
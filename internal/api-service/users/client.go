@@ -0,0 +1,107 @@
+// Package users reads user profiles from user-service and resolves the API
+// tokens it issues.
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	apiservice "github.com/danilovid/linkkeeper/internal/api-service"
+	"github.com/danilovid/linkkeeper/pkg/auth"
+)
+
+// Client implements apiservice.ProfileSource over the user-service HTTP API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+var (
+	_ apiservice.ProfileSource = (*Client)(nil)
+	_ auth.TokenResolver       = (*Client)(nil)
+)
+
+// NewClient returns a client of the service at baseURL. Requests are signed
+// with signer unless it is nil.
+func NewClient(baseURL string, timeout time.Duration, signer *auth.Signer) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: timeout, Transport: signer.Transport(nil)},
+	}
+}
+
+type userResponse struct {
+	ID         string    `json:"id"`
+	TelegramID int64     `json:"telegram_id"`
+	Username   string    `json:"username"`
+	FirstName  string    `json:"first_name"`
+	LastName   string    `json:"last_name"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (c *Client) GetProfile(ctx context.Context, userID string) (apiservice.Profile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/users/"+url.PathEscape(userID), nil)
+	if err != nil {
+		return apiservice.Profile{}, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return apiservice.Profile{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return apiservice.Profile{}, apiservice.ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return apiservice.Profile{}, fmt.Errorf("user-service status: %s", resp.Status)
+	}
+	var user userResponse
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return apiservice.Profile{}, err
+	}
+	return apiservice.Profile{
+		ID:         user.ID,
+		TelegramID: user.TelegramID,
+		Username:   user.Username,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		CreatedAt:  user.CreatedAt,
+	}, nil
+}
+
+// ResolveToken asks user-service whose token it is.
+func (c *Client) ResolveToken(ctx context.Context, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/auth/whoami", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return "", auth.ErrInvalidToken
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("user-service status: %s", resp.Status)
+	}
+	var out struct {
+		UserID string `json:"user_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if out.UserID == "" {
+		return "", auth.ErrInvalidToken
+	}
+	return out.UserID, nil
+}
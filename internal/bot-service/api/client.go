@@ -4,88 +4,226 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/danilovid/linkkeeper/pkg/auth"
+)
+
+var (
+	// ErrInvalidTransition means the link cannot move to the requested status.
+	ErrInvalidTransition = errors.New("status change not allowed")
+	// ErrNoFeed means the URL is neither a feed nor a page that advertises one.
+	ErrNoFeed = errors.New("no feed found")
+	// ErrAlreadySubscribed means the user already follows the feed.
+	ErrAlreadySubscribed = errors.New("already subscribed")
+	// ErrLinkNotFound means the user has no link with the given id.
+	ErrLinkNotFound = errors.New("link not found")
 )
 
+// userIDHeader identifies the user on whose behalf the api-service should act.
+const userIDHeader = "X-User-ID"
+
 type Client struct {
 	baseURL string
 	http    *http.Client
 }
 
 type Link struct {
-	ID       string `json:"id"`
-	URL      string `json:"url"`
-	Resource string `json:"resource"`
+	ID           string   `json:"id"`
+	URL          string   `json:"url"`
+	Title        string   `json:"title"`
+	Resource     string   `json:"resource"`
+	Tags         []string `json:"tags"`
+	Status       string   `json:"status"`
+	NextStatuses []string `json:"next_statuses"`
+}
+
+type Subscription struct {
+	ID        string   `json:"id"`
+	FeedURL   string   `json:"feed_url"`
+	SiteURL   string   `json:"site_url"`
+	Title     string   `json:"title"`
+	Tags      []string `json:"tags"`
+	LastError string   `json:"last_error"`
 }
 
-func NewClient(baseURL string, timeout time.Duration) *Client {
+type SearchResult struct {
+	Link
+	Snippet string `json:"snippet"`
+}
+
+// NewClient returns a client of the service at baseURL. Requests are signed
+// with signer unless it is nil.
+func NewClient(baseURL string, timeout time.Duration, signer *auth.Signer) *Client {
 	return &Client{
 		baseURL: strings.TrimRight(baseURL, "/"),
-		http:    &http.Client{Timeout: timeout},
+		http:    &http.Client{Timeout: timeout, Transport: signer.Transport(nil)},
 	}
 }
 
-func (c *Client) CreateLink(ctx context.Context, url string) (string, error) {
-	payload, err := json.Marshal(map[string]string{"url": url})
+// CreateLink saves a link and returns its id. alreadySaved reports that the
+// user had saved the same page before, in which case the existing link's id is returned.
+func (c *Client) CreateLink(ctx context.Context, userID, url string, tags []string) (id string, alreadySaved bool, err error) {
+	payload, err := json.Marshal(map[string]any{"url": url, "tags": tags})
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/links", bytes.NewReader(payload))
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(userIDHeader, userID)
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		return "", fmt.Errorf("api status: %s", resp.Status)
+		return "", false, fmt.Errorf("api status: %s", resp.Status)
 	}
 	var out struct {
-		ID string `json:"id"`
+		ID           string `json:"id"`
+		AlreadySaved bool   `json:"already_saved"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return "", err
+		return "", false, err
 	}
-	return out.ID, nil
+	return out.ID, out.AlreadySaved, nil
 }
 
-func (c *Client) MarkViewed(ctx context.Context, id string) error {
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/links/"+id+"/viewed", http.NoBody)
+func (c *Client) GetLink(ctx context.Context, userID, id string) (Link, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/v1/links/"+url.PathEscape(id), http.NoBody)
+	if err != nil {
+		return Link{}, err
+	}
+	req.Header.Set(userIDHeader, userID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Link{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Link{}, ErrLinkNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return Link{}, fmt.Errorf("api status: %s", resp.Status)
+	}
+	var out Link
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Link{}, err
+	}
+	return out, nil
+}
+
+// LinkUpdate lists the changes to a link; nil and empty fields are left as they are.
+type LinkUpdate struct {
+	Resource *string  `json:"resource,omitempty"`
+	AddTags  []string `json:"add_tags,omitempty"`
+}
+
+func (c *Client) UpdateLink(ctx context.Context, userID, id string, update LinkUpdate) (Link, error) {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return Link{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "PATCH", c.baseURL+"/api/v1/links/"+url.PathEscape(id), bytes.NewReader(payload))
+	if err != nil {
+		return Link{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(userIDHeader, userID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Link{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Link{}, ErrLinkNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return Link{}, fmt.Errorf("api status: %s", resp.Status)
+	}
+	var out Link
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Link{}, err
+	}
+	return out, nil
+}
+
+func (c *Client) DeleteLink(ctx context.Context, userID, id string) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.baseURL+"/api/v1/links/"+url.PathEscape(id), http.NoBody)
 	if err != nil {
 		return err
 	}
+	req.Header.Set(userIDHeader, userID)
 	resp, err := c.http.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrLinkNotFound
+	}
 	if resp.StatusCode >= 300 {
 		return fmt.Errorf("api status: %s", resp.Status)
 	}
 	return nil
 }
 
-func (c *Client) RandomLink(ctx context.Context, resource string) (Link, error) {
-	requestURL := c.baseURL + "/api/v1/links/random"
+func (c *Client) MarkViewed(ctx context.Context, userID, id string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/links/"+url.PathEscape(id)+"/viewed", http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(userIDHeader, userID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrLinkNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("api status: %s", resp.Status)
+	}
+	return nil
+}
+
+func (c *Client) RandomLink(ctx context.Context, userID, resource string, tags []string) (Link, error) {
+	query := url.Values{}
 	if resource != "" {
-		requestURL += "?resource=" + url.QueryEscape(resource)
+		query.Set("resource", resource)
+	}
+	for _, tag := range tags {
+		query.Add("tag", tag)
+	}
+	requestURL := c.baseURL + "/api/v1/links/random"
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
 	}
 	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, http.NoBody)
 	if err != nil {
 		return Link{}, err
 	}
+	req.Header.Set(userIDHeader, userID)
 	resp, err := c.http.Do(req)
 	if err != nil {
 		return Link{}, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		// Nothing matches the filter: an empty link, not an error.
+		return Link{}, nil
+	}
 	if resp.StatusCode >= 300 {
 		return Link{}, fmt.Errorf("api status: %s", resp.Status)
 	}
@@ -95,3 +233,150 @@ func (c *Client) RandomLink(ctx context.Context, resource string) (Link, error)
 	}
 	return out, nil
 }
+
+// SetStatus moves a link to another read-later status. ErrInvalidTransition is
+// returned when the api-service does not allow the change.
+func (c *Client) SetStatus(ctx context.Context, userID, id, status string) (Link, error) {
+	payload, err := json.Marshal(map[string]string{"status": status})
+	if err != nil {
+		return Link{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/links/"+url.PathEscape(id)+"/status", bytes.NewReader(payload))
+	if err != nil {
+		return Link{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(userIDHeader, userID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Link{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusConflict {
+		return Link{}, ErrInvalidTransition
+	}
+	if resp.StatusCode >= 300 {
+		return Link{}, fmt.Errorf("api status: %s", resp.Status)
+	}
+	var out Link
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Link{}, err
+	}
+	return out, nil
+}
+
+func (c *Client) SearchLinks(ctx context.Context, userID, query string, limit, offset int) ([]SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("offset", strconv.Itoa(offset))
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/v1/links/search?"+params.Encode(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(userIDHeader, userID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("api status: %s", resp.Status)
+	}
+	var out []SearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Subscribe follows the feed at feedURL, or the feed a page there advertises,
+// saving its new entries with tags. ErrNoFeed and ErrAlreadySubscribed tell
+// why the api-service refused.
+func (c *Client) Subscribe(ctx context.Context, userID, feedURL string, tags []string) (Subscription, error) {
+	payload, err := json.Marshal(map[string]any{"url": feedURL, "tags": tags})
+	if err != nil {
+		return Subscription{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/subscriptions", bytes.NewReader(payload))
+	if err != nil {
+		return Subscription{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(userIDHeader, userID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Subscription{}, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusBadRequest:
+		return Subscription{}, ErrNoFeed
+	case resp.StatusCode == http.StatusConflict:
+		return Subscription{}, ErrAlreadySubscribed
+	case resp.StatusCode >= 300:
+		return Subscription{}, fmt.Errorf("api status: %s", resp.Status)
+	}
+	var out Subscription
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Subscription{}, err
+	}
+	return out, nil
+}
+
+func (c *Client) ListSubscriptions(ctx context.Context, userID string) ([]Subscription, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/v1/subscriptions", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(userIDHeader, userID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("api status: %s", resp.Status)
+	}
+	var out []Subscription
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) Unsubscribe(ctx context.Context, userID, id string) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.baseURL+"/api/v1/subscriptions/"+url.PathEscape(id), http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(userIDHeader, userID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("api status: %s", resp.Status)
+	}
+	return nil
+}
+
+// ExportBackup returns the JSON backup of the user's links, with their tags
+// and view events, as GET /api/v1/export serves it.
+func (c *Client) ExportBackup(ctx context.Context, userID string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/v1/export", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(userIDHeader, userID)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("api status: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/user"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// btnDeleteAccount answers the question of /deleteme with "yes" or "no".
+var btnDeleteAccount = tb.Btn{Unique: "deleteme"}
+
+// deleteConfirmTTL is how long the question of /deleteme can be answered;
+// an old message scrolled back to should not delete an account.
+const deleteConfirmTTL = 10 * time.Minute
+
+const deletePrompt = "⚠️ Delete your account?\n\n" +
+	"Your links with their tags and views, subscriptions, reminders, digest, settings and API tokens " +
+	"are deleted for good. The files above hold all of it; keep them to import your links again later."
+
+// handleDeleteMe sends the user everything stored about them and asks
+// whether to delete it. Without the export there is no question.
+func (w *Wrapper) handleDeleteMe(c tb.Context) error {
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to prepare your data, try again later")
+	}
+	links, err := w.api.ExportBackup(ctx, u.ID)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("export backup failed")
+		return c.Send("failed to prepare your data, try again later")
+	}
+	account, err := w.userService.ExportAccount(ctx, u.ID)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("export account failed")
+		return c.Send("failed to prepare your data, try again later")
+	}
+
+	if err := c.Send(exportDocument(links, "linkkeeper-backup.json", "your links")); err != nil {
+		return err
+	}
+	if err := c.Send(exportDocument(account, "linkkeeper-account.json", "your profile, settings, reminders and API tokens")); err != nil {
+		return err
+	}
+	return c.Send(deletePrompt, deleteAccountMarkup())
+}
+
+func (w *Wrapper) handleDeleteAccountButton(c tb.Context) error {
+	if c.Data() != "yes" {
+		if err := c.Respond(); err != nil {
+			return err
+		}
+		return c.Edit("Your account stays as it is.")
+	}
+	if confirmationExpired(c.Message(), time.Now()) {
+		if err := c.Respond(&tb.CallbackResponse{Text: "this question has expired"}); err != nil {
+			return err
+		}
+		return c.Edit("This question has expired; send /deleteme to be asked again.")
+	}
+
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Respond(&tb.CallbackResponse{Text: "something went wrong, try again"})
+	}
+	if err := w.userService.DeleteUser(ctx, u.ID); err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		logger.L().Error().Err(err).Msg("delete user failed")
+		return c.Respond(&tb.CallbackResponse{Text: "something went wrong, try again"})
+	}
+	logger.L().Info().Int64("telegram_id", c.Sender().ID).Msg("user deleted their account")
+	if err := c.Respond(&tb.CallbackResponse{Text: "account deleted"}); err != nil {
+		return err
+	}
+	return c.Edit("Your account and everything in it were deleted 👋\nSend /start if you ever want to come back.")
+}
+
+func deleteAccountMarkup() *tb.ReplyMarkup {
+	markup := &tb.ReplyMarkup{}
+	markup.Inline(markup.Row(
+		markup.Data("🗑 Yes, delete everything", btnDeleteAccount.Unique, "yes"),
+		markup.Data("↩️ Keep my account", btnDeleteAccount.Unique, "no"),
+	))
+	return markup
+}
+
+// confirmationExpired reports whether the question in msg is too old to answer.
+func confirmationExpired(msg *tb.Message, now time.Time) bool {
+	return msg == nil || now.Sub(msg.Time()) > deleteConfirmTTL
+}
+
+func exportDocument(data []byte, name, caption string) *tb.Document {
+	return &tb.Document{
+		File:     tb.FromReader(bytes.NewReader(data)),
+		FileName: name,
+		MIME:     "application/json",
+		Caption:  caption,
+	}
+}
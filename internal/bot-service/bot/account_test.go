@@ -0,0 +1,33 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/telebot.v4"
+)
+
+func TestDeleteAccountMarkup(t *testing.T) {
+	markup := deleteAccountMarkup()
+	require.Len(t, markup.InlineKeyboard, 1)
+	var answers []string
+	for _, btn := range markup.InlineKeyboard[0] {
+		assert.Equal(t, btnDeleteAccount.Unique, btn.Unique)
+		answers = append(answers, btn.Data)
+	}
+	assert.Equal(t, []string{"yes", "no"}, answers)
+}
+
+func TestConfirmationExpired(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	asked := func(ago time.Duration) *tb.Message {
+		return &tb.Message{Unixtime: now.Add(-ago).Unix()}
+	}
+
+	assert.False(t, confirmationExpired(asked(time.Minute), now))
+	assert.False(t, confirmationExpired(asked(deleteConfirmTTL), now))
+	assert.True(t, confirmationExpired(asked(deleteConfirmTTL+time.Second), now))
+	assert.True(t, confirmationExpired(nil, now))
+}
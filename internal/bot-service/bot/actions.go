@@ -0,0 +1,361 @@
+package bot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/api"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// btnLinkAction carries every button under a link message. Its data is
+// "<action>|<link>|<arg>|<mac>": the link id is its 16 bytes in base64url and
+// the MAC binds the rest to the Telegram user the buttons were made for, so
+// that they fit Telegram's 64 bytes and work for nobody else.
+var btnLinkAction = tb.Btn{Unique: "la"}
+
+// Link actions.
+const (
+	actionViewed        = "v"
+	actionStatus        = "s" // arg: index in statusButtons
+	actionRandom        = "r"
+	actionDelete        = "d" // asks to confirm
+	actionConfirmDelete = "D"
+	actionResources     = "R" // offers the resources
+	actionResource      = "e" // arg: index in resources
+	actionTag           = "t"
+	actionShow          = "c" // back to the link's buttons
+	actionSend          = "l" // sends the link in a message of its own
+)
+
+// resources are offered by the "set resource" action.
+var resources = []string{"article", "video", "podcast", "repo", "paper", "social", "docs"}
+
+// macSize is the length of the truncated MAC in callback data, in bytes.
+const macSize = 6
+
+// tagPrompt is the question a reply to which adds tags to the link it names.
+var tagPrompt = regexp.MustCompile(`^Send tags for link ([0-9a-f-]{36})`)
+
+// linkAction is a verified press of a link button.
+type linkAction struct {
+	action string
+	linkID string
+	arg    string
+}
+
+// callbackSigner signs and verifies the data of link buttons.
+type callbackSigner struct {
+	key []byte
+}
+
+// newCallbackSigner derives the key from the bot token, which every instance
+// of the bot shares and nobody else knows.
+func newCallbackSigner(botToken string) callbackSigner {
+	mac := hmac.New(sha256.New, []byte("link buttons"))
+	mac.Write([]byte(botToken))
+	return callbackSigner{key: mac.Sum(nil)}
+}
+
+func (s callbackSigner) mac(telegramID int64, action, link, arg string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strconv.FormatInt(telegramID, 10) + "|" + action + "|" + link + "|" + arg))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:macSize])
+}
+
+// button returns a button acting on linkID for the Telegram user telegramID.
+func (s callbackSigner) button(markup *tb.ReplyMarkup, telegramID int64, label, action, linkID, arg string) tb.Btn {
+	id, err := uuid.Parse(linkID)
+	if err != nil {
+		// Link ids are UUIDs; anything else cannot be packed.
+		return markup.Data(label, btnLinkAction.Unique, action, "", arg, "")
+	}
+	link := base64.RawURLEncoding.EncodeToString(id[:])
+	return markup.Data(label, btnLinkAction.Unique, action, link, arg, s.mac(telegramID, action, link, arg))
+}
+
+// verify unpacks the data of a pressed button, checking that it was made for
+// the user pressing it.
+func (s callbackSigner) verify(telegramID int64, data string) (linkAction, bool) {
+	parts := strings.Split(data, "|")
+	if len(parts) != 4 {
+		return linkAction{}, false
+	}
+	action, link, arg, mac := parts[0], parts[1], parts[2], parts[3]
+	if !hmac.Equal([]byte(mac), []byte(s.mac(telegramID, action, link, arg))) {
+		return linkAction{}, false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(link)
+	if err != nil {
+		return linkAction{}, false
+	}
+	id, err := uuid.FromBytes(raw)
+	if err != nil {
+		return linkAction{}, false
+	}
+	return linkAction{action: action, linkID: id.String(), arg: arg}, true
+}
+
+// linkMarkup offers the actions on a link to the user it was sent to.
+func (w *Wrapper) linkMarkup(telegramID int64, link api.Link) *tb.ReplyMarkup {
+	markup := &tb.ReplyMarkup{}
+	btn := func(label, action, arg string) tb.Btn {
+		return w.callbacks.button(markup, telegramID, label, action, link.ID, arg)
+	}
+
+	var statuses []tb.Btn
+	for i, b := range statusButtons {
+		if slices.Contains(link.NextStatuses, b.status) {
+			statuses = append(statuses, btn(b.label, actionStatus, strconv.Itoa(i)))
+		}
+	}
+	markup.Inline(
+		markup.Row(markup.URL("🔗 Open", link.URL), btn("👀 Viewed", actionViewed, "")),
+		markup.Row(statuses...),
+		markup.Row(
+			btn("🏷 Add tag", actionTag, ""),
+			btn("📂 Resource", actionResources, ""),
+			btn("🗑 Delete", actionDelete, ""),
+		),
+		markup.Row(btn("🎲 Another random", actionRandom, "")),
+	)
+	return markup
+}
+
+func (w *Wrapper) resourceMarkup(telegramID int64, link api.Link) *tb.ReplyMarkup {
+	markup := &tb.ReplyMarkup{}
+	var rows []tb.Row
+	var row []tb.Btn
+	for i, resource := range resources {
+		label := resource
+		if resource == link.Resource {
+			label = "• " + label
+		}
+		row = append(row, w.callbacks.button(markup, telegramID, label, actionResource, link.ID, strconv.Itoa(i)))
+		if len(row) == 3 {
+			rows = append(rows, markup.Row(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, markup.Row(row...))
+	}
+	rows = append(rows, markup.Row(w.callbacks.button(markup, telegramID, "↩️ Back", actionShow, link.ID, "")))
+	markup.Inline(rows...)
+	return markup
+}
+
+func (w *Wrapper) confirmDeleteMarkup(telegramID int64, linkID string) *tb.ReplyMarkup {
+	markup := &tb.ReplyMarkup{}
+	markup.Inline(markup.Row(
+		w.callbacks.button(markup, telegramID, "🗑 Yes, delete", actionConfirmDelete, linkID, ""),
+		w.callbacks.button(markup, telegramID, "↩️ Keep", actionShow, linkID, ""),
+	))
+	return markup
+}
+
+// sendLink replies with a link and the buttons to act on it.
+func (w *Wrapper) sendLink(c tb.Context, prefix string, link api.Link) error {
+	return c.Send(prefix+formatLink(link), w.linkMarkup(c.Sender().ID, link))
+}
+
+// showLink edits the message a button was pressed on to show the link's current state.
+func (w *Wrapper) showLink(c tb.Context, link api.Link) error {
+	return c.Edit(formatLink(link), w.linkMarkup(c.Sender().ID, link))
+}
+
+func (w *Wrapper) handleLinkAction(c tb.Context) error {
+	sender := c.Sender()
+	if sender == nil {
+		return c.Respond()
+	}
+	a, ok := w.callbacks.verify(sender.ID, c.Data())
+	if !ok {
+		return c.Respond(&tb.CallbackResponse{Text: "this button is not for you or no longer valid"})
+	}
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Respond(&tb.CallbackResponse{Text: "something went wrong, try again"})
+	}
+
+	err = w.doLinkAction(ctx, c, u.ID, a)
+	if errors.Is(err, api.ErrLinkNotFound) {
+		if err := c.Respond(&tb.CallbackResponse{Text: "this link is gone"}); err != nil {
+			return err
+		}
+		return c.Edit(c.Message().Text)
+	}
+	if errors.Is(err, api.ErrInvalidTransition) {
+		return c.Respond(&tb.CallbackResponse{Text: "can't move this link there any more"})
+	}
+	if err != nil {
+		logger.L().Error().Err(err).Str("action", a.action).Str("id", a.linkID).Msg("link action failed")
+		return c.Respond(&tb.CallbackResponse{Text: "something went wrong, try again"})
+	}
+	return nil
+}
+
+func (w *Wrapper) doLinkAction(ctx context.Context, c tb.Context, userID string, a linkAction) error {
+	switch a.action {
+	case actionViewed:
+		if err := w.api.MarkViewed(ctx, userID, a.linkID); err != nil {
+			return err
+		}
+		link, err := w.api.GetLink(ctx, userID, a.linkID)
+		if err != nil {
+			return err
+		}
+		if err := c.Respond(&tb.CallbackResponse{Text: "marked viewed ✅"}); err != nil {
+			return err
+		}
+		return w.showLink(c, link)
+
+	case actionStatus:
+		i, err := strconv.Atoi(a.arg)
+		if err != nil || i < 0 || i >= len(statusButtons) {
+			return c.Respond(&tb.CallbackResponse{Text: "this button is no longer valid"})
+		}
+		link, err := w.api.SetStatus(ctx, userID, a.linkID, statusButtons[i].status)
+		if err != nil {
+			return err
+		}
+		if err := c.Respond(&tb.CallbackResponse{Text: statusButtons[i].done}); err != nil {
+			return err
+		}
+		return w.showLink(c, link)
+
+	case actionRandom:
+		link, err := w.api.RandomLink(ctx, userID, w.settings(ctx, userID).DefaultResource, nil)
+		if err != nil {
+			return err
+		}
+		if link.URL == "" {
+			return c.Respond(&tb.CallbackResponse{Text: "no unread links left 🎉"})
+		}
+		if err := c.Respond(); err != nil {
+			return err
+		}
+		return w.showLink(c, link)
+
+	case actionDelete:
+		if err := c.Respond(); err != nil {
+			return err
+		}
+		return c.Edit(c.Message().Text+"\n\nDelete this link?", w.confirmDeleteMarkup(c.Sender().ID, a.linkID))
+
+	case actionConfirmDelete:
+		if err := w.api.DeleteLink(ctx, userID, a.linkID); err != nil {
+			return err
+		}
+		if err := c.Respond(&tb.CallbackResponse{Text: "deleted 🗑"}); err != nil {
+			return err
+		}
+		text := strings.TrimSuffix(c.Message().Text, "\n\nDelete this link?")
+		return c.Edit(text + "\n\nDeleted 🗑")
+
+	case actionResources:
+		link, err := w.api.GetLink(ctx, userID, a.linkID)
+		if err != nil {
+			return err
+		}
+		if err := c.Respond(); err != nil {
+			return err
+		}
+		return c.Edit(formatLink(link)+"\n\nPick a resource:", w.resourceMarkup(c.Sender().ID, link))
+
+	case actionResource:
+		i, err := strconv.Atoi(a.arg)
+		if err != nil || i < 0 || i >= len(resources) {
+			return c.Respond(&tb.CallbackResponse{Text: "this button is no longer valid"})
+		}
+		link, err := w.api.UpdateLink(ctx, userID, a.linkID, api.LinkUpdate{Resource: &resources[i]})
+		if err != nil {
+			return err
+		}
+		if err := c.Respond(&tb.CallbackResponse{Text: "resource set to " + resources[i]}); err != nil {
+			return err
+		}
+		return w.showLink(c, link)
+
+	case actionTag:
+		if err := c.Respond(); err != nil {
+			return err
+		}
+		return c.Send(
+			"Send tags for link "+a.linkID+" as a reply, e.g. #golang #later",
+			&tb.ReplyMarkup{ForceReply: true, Placeholder: "#tag"},
+		)
+
+	case actionSend:
+		link, err := w.api.GetLink(ctx, userID, a.linkID)
+		if err != nil {
+			return err
+		}
+		if err := c.Respond(); err != nil {
+			return err
+		}
+		return w.sendLink(c, "", link)
+
+	case actionShow:
+		link, err := w.api.GetLink(ctx, userID, a.linkID)
+		if err != nil {
+			return err
+		}
+		if err := c.Respond(); err != nil {
+			return err
+		}
+		return w.showLink(c, link)
+	}
+	return c.Respond(&tb.CallbackResponse{Text: "this button is no longer valid"})
+}
+
+// taggedLinkID returns the id of the link a message adds tags to, when it
+// replies to the bot's question for them.
+func (w *Wrapper) taggedLinkID(m *tb.Message) (string, bool) {
+	if m.ReplyTo == nil || m.ReplyTo.Sender == nil || w.bot.Me == nil || m.ReplyTo.Sender.ID != w.bot.Me.ID {
+		return "", false
+	}
+	match := tagPrompt.FindStringSubmatch(m.ReplyTo.Text)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// handleTagReply adds the tags of a reply to the tag question.
+func (w *Wrapper) handleTagReply(c tb.Context, linkID string) error {
+	var tags []string
+	for _, field := range strings.Fields(c.Text()) {
+		if tag := strings.TrimLeft(field, "#"); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return c.Send("no tags given")
+	}
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to add tags")
+	}
+	link, err := w.api.UpdateLink(ctx, u.ID, linkID, api.LinkUpdate{AddTags: tags})
+	if errors.Is(err, api.ErrLinkNotFound) {
+		return c.Send("this link is gone")
+	}
+	if err != nil {
+		logger.L().Error().Err(err).Str("id", linkID).Msg("add tags failed")
+		return c.Send("failed to add tags")
+	}
+	return w.sendLink(c, "tagged 🏷\n", link)
+}
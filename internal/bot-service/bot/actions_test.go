@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/api"
+)
+
+func TestCallbackSigner(t *testing.T) {
+	signer := newCallbackSigner("token")
+	linkID := uuid.NewString()
+	btn := signer.button(&tb.ReplyMarkup{}, 42, "archive", actionStatus, linkID, "2").Inline()
+
+	// Telegram gets "\f<unique>|<data>" and takes at most 64 bytes of it.
+	assert.LessOrEqual(t, len("\f"+btn.Unique+"|"+btn.Data), 64)
+
+	a, ok := signer.verify(42, btn.Data)
+	require.True(t, ok)
+	assert.Equal(t, linkAction{action: actionStatus, linkID: linkID, arg: "2"}, a)
+}
+
+func TestCallbackSigner_Rejects(t *testing.T) {
+	signer := newCallbackSigner("token")
+	data := signer.button(&tb.ReplyMarkup{}, 42, "archive", actionStatus, uuid.NewString(), "2").Data
+
+	_, ok := signer.verify(43, data)
+	assert.False(t, ok, "another user")
+
+	_, ok = signer.verify(42, strings.Replace(data, "s|", "D|", 1))
+	assert.False(t, ok, "another action")
+
+	_, ok = newCallbackSigner("other token").verify(42, data)
+	assert.False(t, ok, "another bot")
+
+	_, ok = signer.verify(42, "link-id|done")
+	assert.False(t, ok, "old status button")
+}
+
+func TestLinkMarkup(t *testing.T) {
+	w := &Wrapper{callbacks: newCallbackSigner("token")}
+	link := api.Link{
+		ID:           uuid.NewString(),
+		URL:          "https://go.dev",
+		Status:       "done",
+		NextStatuses: []string{"reading", "archived"},
+	}
+
+	markup := w.linkMarkup(42, link)
+	var labels []string
+	for _, row := range markup.InlineKeyboard {
+		for _, btn := range row {
+			labels = append(labels, btn.Text)
+			if btn.URL == "" {
+				a, ok := w.callbacks.verify(42, btn.Data)
+				require.True(t, ok, btn.Text)
+				assert.Equal(t, link.ID, a.linkID)
+			}
+		}
+	}
+	assert.Equal(t, []string{
+		"🔗 Open", "👀 Viewed",
+		"📖 Reading", "🗄 Archive",
+		"🏷 Add tag", "📂 Resource", "🗑 Delete",
+		"🎲 Another random",
+	}, labels)
+}
+
+func TestTagPrompt(t *testing.T) {
+	linkID := uuid.NewString()
+	match := tagPrompt.FindStringSubmatch("Send tags for link " + linkID + " as a reply, e.g. #golang #later")
+	require.NotNil(t, match)
+	assert.Equal(t, linkID, match[1])
+}
@@ -2,8 +2,12 @@ package bot
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"strings"
 	"time"
+
+	"github.com/danilovid/linkkeeper/pkg/auth"
 )
 
 type Config struct {
@@ -11,6 +15,17 @@ type Config struct {
 	APIBaseURL     string
 	UserServiceURL string
 	Timeout        time.Duration
+	// ServiceKeys are the "id:secret" keys requests to the other services are
+	// signed with; the first one signs. Requests are not signed without them.
+	ServiceKeys string
+	// HTTPAddr is where /health and, in webhook mode, the webhook are served.
+	HTTPAddr string
+	// WebhookURL is the public URL Telegram posts updates to. The bot long
+	// polls when it is empty.
+	WebhookURL string
+	// WebhookSecret is the secret Telegram sends with every update; required
+	// in webhook mode.
+	WebhookSecret string
 }
 
 func (c *Config) Validate() error {
@@ -23,8 +38,36 @@ func (c *Config) Validate() error {
 	if strings.TrimSpace(c.UserServiceURL) == "" {
 		return errors.New("missing USER_SERVICE_URL")
 	}
+	if _, err := auth.ParseKeys(c.ServiceKeys); err != nil {
+		return fmt.Errorf("invalid SERVICE_KEYS: %w", err)
+	}
+	if c.WebhookURL != "" {
+		u, err := url.Parse(c.WebhookURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return errors.New("WEBHOOK_URL must be an https URL")
+		}
+		if !validWebhookSecret(c.WebhookSecret) {
+			return errors.New("WEBHOOK_SECRET must be 1-256 letters, digits, '_' or '-'")
+		}
+	}
+	if c.HTTPAddr == "" {
+		c.HTTPAddr = ":8082"
+	}
 	if c.Timeout <= 0 {
 		c.Timeout = 10 * time.Second
 	}
 	return nil
 }
+
+// validWebhookSecret reports whether Telegram accepts secret as a webhook secret token.
+func validWebhookSecret(secret string) bool {
+	if secret == "" || len(secret) > 256 {
+		return false
+	}
+	for _, r := range secret {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,285 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/api"
+	"github.com/danilovid/linkkeeper/internal/bot-service/user"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+const (
+	digestUsage = "usage: /digest daily HH:MM [timezone] [count] [resource]\n" +
+		"/digest weekly <day> HH:MM [timezone] [count] [resource]\n" +
+		"/digest off\n" +
+		"e.g. /digest weekly sat 10:00 Europe/Berlin 3 video"
+	remindUsage = "usage: /remind <id> <when>, where when is e.g. 30m, 2h, 3d, 18:00, tomorrow, tomorrow 08:30 or 2026-12-24 10:00"
+)
+
+// defaultRemindTime is the time of day of reminders given only a day.
+const defaultRemindTime = 9 * time.Hour
+
+// relativeTime matches reminder times like "45m", "2h", "3d" or "1w".
+var relativeTime = regexp.MustCompile(`^(\d+)([mhdw])$`)
+
+func (w *Wrapper) handleDigest(c tb.Context) error {
+	args := strings.Fields(c.Message().Payload)
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to update digest")
+	}
+
+	current, err := w.userService.GetDigest(ctx, u.ID)
+	if err != nil && !errors.Is(err, user.ErrNoDigest) {
+		logger.L().Error().Err(err).Msg("get digest failed")
+		return c.Send("failed to get digest")
+	}
+
+	switch {
+	case len(args) == 0:
+		if current == nil {
+			return c.Send("no digest yet\n\n" + digestUsage)
+		}
+		return c.Send(describeDigest(current) + "\n\nchange it with /digest daily|weekly …, stop it with /digest off")
+	case args[0] == "off":
+		err := w.userService.DisableDigest(ctx, u.ID)
+		if err != nil && !errors.Is(err, user.ErrNoDigest) {
+			logger.L().Error().Err(err).Msg("disable digest failed")
+			return c.Send("failed to stop digest")
+		}
+		return c.Send("digest stopped 🔕")
+	}
+
+	timezone := w.settings(ctx, u.ID).Timezone
+	if current != nil {
+		timezone = current.Timezone
+	}
+	req, err := parseDigest(args, timezone)
+	if err != nil {
+		return c.Send(err.Error() + "\n\n" + digestUsage)
+	}
+	digest, err := w.userService.SetDigest(ctx, u.ID, req)
+	if errors.Is(err, user.ErrInvalidInput) {
+		return c.Send(err.Error() + "\n\n" + digestUsage)
+	}
+	if err != nil {
+		logger.L().Error().Err(err).Msg("set digest failed")
+		return c.Send("failed to set digest")
+	}
+	return c.Send("digest scheduled 📬\n" + describeDigest(digest))
+}
+
+// parseDigest reads "daily|weekly [day] HH:MM [timezone] [count] [resource]";
+// the options after the time may come in any order.
+func parseDigest(args []string, timezone string) (user.DigestRequest, error) {
+	req := user.DigestRequest{Frequency: args[0], Timezone: timezone}
+	rest := args[1:]
+	switch req.Frequency {
+	case "daily":
+	case "weekly":
+		if len(rest) == 0 {
+			return req, errors.New("which day?")
+		}
+		if _, ok := parseWeekday(rest[0]); !ok {
+			return req, fmt.Errorf("unknown day %q", rest[0])
+		}
+		req.Weekday, rest = strings.ToLower(rest[0]), rest[1:]
+	default:
+		return req, fmt.Errorf("unknown frequency %q", req.Frequency)
+	}
+
+	if len(rest) == 0 {
+		return req, errors.New("at what time?")
+	}
+	if _, err := time.Parse("15:04", rest[0]); err != nil {
+		return req, fmt.Errorf("time must be HH:MM, not %q", rest[0])
+	}
+	req.Time, rest = rest[0], rest[1:]
+
+	for _, opt := range rest {
+		if n, err := strconv.Atoi(opt); err == nil {
+			req.Count = n
+			continue
+		}
+		if resource := strings.ToLower(opt); slices.Contains(resources, resource) {
+			req.Resource = resource
+			continue
+		}
+		if _, err := time.LoadLocation(opt); err == nil && opt != "Local" {
+			req.Timezone = opt
+			continue
+		}
+		return req, fmt.Errorf("unknown option %q, expected a timezone, count or one of: %s", opt, strings.Join(resources, ", "))
+	}
+	return req, nil
+}
+
+func describeDigest(d *user.Digest) string {
+	when := "every day"
+	if d.Frequency == "weekly" {
+		when = "every " + d.Weekday
+	}
+	what := plural(d.Count, "unread link")
+	if d.Resource != "" {
+		what += " (" + d.Resource + ")"
+	}
+	next := d.NextRunAt
+	if loc, err := time.LoadLocation(d.Timezone); err == nil {
+		next = next.In(loc)
+	}
+	return fmt.Sprintf("%s %s at %s %s\nnext: %s", what, when, d.Time, d.Timezone, next.Format("Mon 2 Jan 15:04"))
+}
+
+func (w *Wrapper) handleRemind(c tb.Context) error {
+	args := strings.Fields(c.Message().Payload)
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to set reminder")
+	}
+	loc := w.userLocation(ctx, u.ID)
+	if len(args) == 0 {
+		return w.sendReminders(c, u.ID, loc)
+	}
+	if len(args) < 2 {
+		return c.Send(remindUsage)
+	}
+
+	remindAt, err := parseRemindAt(strings.Join(args[1:], " "), time.Now(), loc)
+	if err != nil {
+		return c.Send(err.Error() + "\n\n" + remindUsage)
+	}
+	link, err := w.api.GetLink(ctx, u.ID, args[0])
+	if errors.Is(err, api.ErrLinkNotFound) {
+		return c.Send("no such link")
+	}
+	if err != nil {
+		logger.L().Error().Err(err).Str("id", args[0]).Msg("get link for reminder failed")
+		return c.Send("failed to set reminder")
+	}
+
+	_, err = w.userService.AddReminder(ctx, u.ID, link.ID, remindAt)
+	if errors.Is(err, user.ErrInvalidInput) {
+		return c.Send(err.Error())
+	}
+	if err != nil {
+		logger.L().Error().Err(err).Msg("add reminder failed")
+		return c.Send("failed to set reminder")
+	}
+	return c.Send(fmt.Sprintf("I'll remind you of %s on %s (%s) ⏰", link.URL, remindAt.Format("Mon 2 Jan 15:04"), loc), tb.NoPreview)
+}
+
+func (w *Wrapper) sendReminders(c tb.Context, userID string, loc *time.Location) error {
+	reminders, err := w.userService.ListReminders(context.Background(), userID)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("list reminders failed")
+		return c.Send("failed to list reminders")
+	}
+	if len(reminders) == 0 {
+		return c.Send("no reminders\n\n" + remindUsage)
+	}
+	lines := make([]string, 0, len(reminders))
+	for _, r := range reminders {
+		lines = append(lines, r.RemindAt.In(loc).Format("Mon 2 Jan 15:04")+": link "+r.LinkID)
+	}
+	return c.Send("your reminders ⏰\n" + strings.Join(lines, "\n"))
+}
+
+// userLocation is the timezone of the user's settings.
+func (w *Wrapper) userLocation(ctx context.Context, userID string) *time.Location {
+	loc, err := time.LoadLocation(w.settings(ctx, userID).Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseRemindAt reads when a reminder is due: after a delay ("45m", "2h",
+// "3d", "1w", "1h30m"), at a time of day ("18:00", today or else tomorrow),
+// "tomorrow [HH:MM]" or "YYYY-MM-DD [HH:MM]". Days without a time mean 09:00.
+func parseRemindAt(when string, now time.Time, loc *time.Location) (time.Time, error) {
+	when = strings.ToLower(strings.TrimSpace(when))
+	now = now.In(loc)
+	midnight := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	// timeOfDay adds "HH:MM", or the default time when it is empty, to a day.
+	timeOfDay := func(day time.Time, clock string) (time.Time, error) {
+		if clock == "" {
+			return day.Add(defaultRemindTime), nil
+		}
+		t, err := time.Parse("15:04", clock)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("time must be HH:MM, not %q", clock)
+		}
+		return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, loc), nil
+	}
+
+	var at time.Time
+	var err error
+	day, clock, _ := strings.Cut(when, " ")
+	switch {
+	case relativeTime.MatchString(when):
+		m := relativeTime.FindStringSubmatch(when)
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "m":
+			at = now.Add(time.Duration(n) * time.Minute)
+		case "h":
+			at = now.Add(time.Duration(n) * time.Hour)
+		case "d":
+			at = now.AddDate(0, 0, n)
+		case "w":
+			at = now.AddDate(0, 0, 7*n)
+		}
+	case day == "tomorrow":
+		at, err = timeOfDay(midnight(now).AddDate(0, 0, 1), clock)
+	case strings.Contains(day, "-"):
+		var date time.Time
+		date, err = time.ParseInLocation("2006-01-02", day, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("date must be YYYY-MM-DD, not %q", day)
+		}
+		at, err = timeOfDay(date, clock)
+	case strings.Contains(when, ":"):
+		at, err = timeOfDay(midnight(now), when)
+		if err == nil && !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+	default:
+		d, parseErr := time.ParseDuration(when)
+		if parseErr != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("can't tell when %q is", when)
+		}
+		at = now.Add(d)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !at.After(now) {
+		return time.Time{}, errors.New("that time has passed")
+	}
+	return at, nil
+}
+
+// parseWeekday reads an English weekday name, full or abbreviated to three letters.
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(name)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || (len(name) == 3 && name == full[:3]) {
+			return d, true
+		}
+	}
+	return time.Sunday, false
+}
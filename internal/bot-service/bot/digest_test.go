@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/user"
+)
+
+func TestParseDigest(t *testing.T) {
+	req, err := parseDigest([]string{"weekly", "Sat", "10:00", "video", "Europe/Berlin", "3"}, "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, user.DigestRequest{
+		Frequency: "weekly", Weekday: "sat", Time: "10:00", Timezone: "Europe/Berlin", Count: 3, Resource: "video",
+	}, req)
+
+	req, err = parseDigest([]string{"daily", "07:30"}, "Asia/Tokyo")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", req.Timezone, "keeps the current timezone")
+
+	for _, args := range [][]string{
+		{"hourly", "10:00"},
+		{"weekly", "10:00"},
+		{"daily"},
+		{"daily", "7pm"},
+		{"daily", "07:00", "Mars/Olympus"},
+	} {
+		_, err := parseDigest(args, "UTC")
+		assert.Error(t, err, args)
+	}
+}
+
+func TestParseRemindAt(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 2, 14, 0, 0, 0, berlin)
+
+	tests := map[string]time.Time{
+		"30m":              now.Add(30 * time.Minute),
+		"2h":               now.Add(2 * time.Hour),
+		"1h30m":            now.Add(90 * time.Minute),
+		"3d":               time.Date(2026, 3, 5, 14, 0, 0, 0, berlin),
+		"1w":               time.Date(2026, 3, 9, 14, 0, 0, 0, berlin),
+		"18:00":            time.Date(2026, 3, 2, 18, 0, 0, 0, berlin),
+		"08:00":            time.Date(2026, 3, 3, 8, 0, 0, 0, berlin),
+		"tomorrow":         time.Date(2026, 3, 3, 9, 0, 0, 0, berlin),
+		"Tomorrow 07:15":   time.Date(2026, 3, 3, 7, 15, 0, 0, berlin),
+		"2026-12-24":       time.Date(2026, 12, 24, 9, 0, 0, 0, berlin),
+		"2026-12-24 18:30": time.Date(2026, 12, 24, 18, 30, 0, 0, berlin),
+	}
+	for when, want := range tests {
+		got, err := parseRemindAt(when, now, berlin)
+		if assert.NoError(t, err, when) {
+			assert.True(t, want.Equal(got), "%s: want %s, got %s", when, want, got)
+		}
+	}
+
+	for _, when := range []string{"", "soon", "2025-01-01", "tomorrow 25:00", "0m", "-1h"} {
+		_, err := parseRemindAt(when, now, berlin)
+		assert.Error(t, err, when)
+	}
+}
@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/api"
+	"github.com/danilovid/linkkeeper/internal/bot-service/user"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// schedulerInterval is how often the scheduler looks for due digests and
+// reminders; schedules are kept to the minute.
+const schedulerInterval = time.Minute
+
+// scheduleStore is the part of user-service the scheduler uses.
+type scheduleStore interface {
+	DueDigests(ctx context.Context, before time.Time) ([]user.Digest, error)
+	DigestSent(ctx context.Context, userID string, sentAt time.Time) error
+	DueReminders(ctx context.Context, before time.Time) ([]user.Reminder, error)
+	CancelReminder(ctx context.Context, userID, reminderID string) error
+}
+
+// linkStore is the part of api-service the scheduler uses.
+type linkStore interface {
+	RandomLink(ctx context.Context, userID, resource string, tags []string) (api.Link, error)
+	GetLink(ctx context.Context, userID, id string) (api.Link, error)
+}
+
+// scheduler sends the digests and reminders user-service says are due.
+// Schedules live in user-service, so nothing is lost when the bot restarts:
+// what fell due while it was down is sent on the first tick.
+type scheduler struct {
+	w         *Wrapper
+	schedules scheduleStore
+	links     linkStore
+	now       func() time.Time
+}
+
+// run ticks until stop is closed.
+func (s *scheduler) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for {
+		s.tick(context.Background())
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick sends everything that is due now.
+func (s *scheduler) tick(ctx context.Context) {
+	now := s.now()
+
+	digests, err := s.schedules.DueDigests(ctx, now)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("list due digests failed")
+	}
+	for _, digest := range digests {
+		s.sendDigest(ctx, digest, now)
+	}
+
+	reminders, err := s.schedules.DueReminders(ctx, now)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("list due reminders failed")
+	}
+	for _, reminder := range reminders {
+		s.sendReminder(ctx, reminder)
+	}
+}
+
+// sendDigest sends up to digest.Count distinct random unread links. When the
+// links cannot be fetched the digest stays due and is retried on the next
+// tick; once it was sent, or Telegram refused it, the next one is scheduled.
+func (s *scheduler) sendDigest(ctx context.Context, digest user.Digest, now time.Time) {
+	log := logger.L().With().Str("user_id", digest.UserID).Logger()
+
+	var links []api.Link
+	seen := make(map[string]bool)
+	// Random picks repeat, so ask a few more times than there are links to pick.
+	for i := 0; i < 3*digest.Count && len(links) < digest.Count; i++ {
+		link, err := s.links.RandomLink(ctx, digest.UserID, digest.Resource, nil)
+		if err != nil {
+			log.Error().Err(err).Msg("random link for digest failed")
+			return
+		}
+		if link.ID == "" {
+			break
+		}
+		if !seen[link.ID] {
+			seen[link.ID] = true
+			links = append(links, link)
+		}
+	}
+
+	if len(links) > 0 {
+		text, markup := s.digestMessage(digest, links)
+		if _, err := s.w.bot.Send(tb.ChatID(digest.TelegramID), text, markup, tb.NoPreview); err != nil {
+			log.Error().Err(err).Int64("telegram_id", digest.TelegramID).Msg("send digest failed")
+		}
+	}
+	if err := s.schedules.DigestSent(ctx, digest.UserID, now); err != nil {
+		log.Error().Err(err).Msg("mark digest sent failed")
+	}
+}
+
+func (s *scheduler) digestMessage(digest user.Digest, links []api.Link) (string, *tb.ReplyMarkup) {
+	what := plural(len(links), "unread link")
+	if digest.Resource != "" {
+		what += " (" + digest.Resource + ")"
+	}
+	lines := []string{"📬 your " + digest.Frequency + " digest: " + what}
+	shared := make([]sharedLink, 0, len(links))
+	for i, link := range links {
+		line := strconv.Itoa(i+1) + ". " + link.URL
+		if link.Title != "" {
+			line = strconv.Itoa(i+1) + ". " + link.Title + "\n" + link.URL
+		}
+		lines = append(lines, line)
+		shared = append(shared, sharedLink{url: link.URL, id: link.ID})
+	}
+	return strings.Join(lines, "\n\n"), s.w.sharedMarkup(digest.TelegramID, shared)
+}
+
+// sendReminder sends the link a reminder is for and removes the reminder.
+// Reminders of deleted links are removed without a message.
+func (s *scheduler) sendReminder(ctx context.Context, reminder user.Reminder) {
+	log := logger.L().With().Str("user_id", reminder.UserID).Str("reminder_id", reminder.ID).Logger()
+
+	link, err := s.links.GetLink(ctx, reminder.UserID, reminder.LinkID)
+	switch {
+	case errors.Is(err, api.ErrLinkNotFound):
+		log.Info().Str("link_id", reminder.LinkID).Msg("dropping reminder of a deleted link")
+	case err != nil:
+		log.Error().Err(err).Msg("get link for reminder failed")
+		return
+	default:
+		text := "⏰ reminder\n" + formatLink(link)
+		if _, err := s.w.bot.Send(tb.ChatID(reminder.TelegramID), text, s.w.linkMarkup(reminder.TelegramID, link)); err != nil {
+			log.Error().Err(err).Int64("telegram_id", reminder.TelegramID).Msg("send reminder failed")
+		}
+	}
+	if err := s.schedules.CancelReminder(ctx, reminder.UserID, reminder.ID); err != nil && !errors.Is(err, user.ErrReminderNotFound) {
+		log.Error().Err(err).Msg("remove sent reminder failed")
+	}
+}
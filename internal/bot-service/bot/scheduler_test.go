@@ -0,0 +1,231 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/api"
+	"github.com/danilovid/linkkeeper/internal/bot-service/user"
+)
+
+// fakeTelegram records the messages the bot sends; chats in blocked refuse them.
+type fakeTelegram struct {
+	mu       sync.Mutex
+	messages []sentMessage
+	blocked  map[string]bool
+}
+
+type sentMessage struct {
+	ChatID      string `json:"chat_id"`
+	Text        string `json:"text"`
+	ReplyMarkup string `json:"reply_markup"`
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/sendMessage") {
+		http.NotFound(w, r)
+		return
+	}
+	var msg sentMessage
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if f.blocked[msg.ChatID] {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`))
+		return
+	}
+	f.mu.Lock()
+	f.messages = append(f.messages, msg)
+	f.mu.Unlock()
+	_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"chat":{"id":` + msg.ChatID + `}}}`))
+}
+
+// fakeSchedules hands out what is due at the fake clock's time.
+type fakeSchedules struct {
+	digests   []user.Digest
+	reminders []user.Reminder
+	sent      map[string]time.Time
+	cancelled []string
+}
+
+func (f *fakeSchedules) DueDigests(_ context.Context, before time.Time) ([]user.Digest, error) {
+	var due []user.Digest
+	for _, d := range f.digests {
+		if !d.NextRunAt.After(before) {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeSchedules) DigestSent(_ context.Context, userID string, sentAt time.Time) error {
+	f.sent[userID] = sentAt
+	for i := range f.digests {
+		if f.digests[i].UserID == userID {
+			f.digests[i].NextRunAt = f.digests[i].NextRunAt.Add(24 * time.Hour)
+		}
+	}
+	return nil
+}
+
+func (f *fakeSchedules) DueReminders(_ context.Context, before time.Time) ([]user.Reminder, error) {
+	var due []user.Reminder
+	for _, r := range f.reminders {
+		if !r.RemindAt.After(before) {
+			due = append(due, r)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeSchedules) CancelReminder(_ context.Context, _, reminderID string) error {
+	f.cancelled = append(f.cancelled, reminderID)
+	for i, r := range f.reminders {
+		if r.ID == reminderID {
+			f.reminders = append(f.reminders[:i], f.reminders[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// fakeLinks picks "random" links in turn.
+type fakeLinks struct {
+	links []api.Link
+	next  int
+	err   error
+}
+
+func (f *fakeLinks) RandomLink(context.Context, string, string, []string) (api.Link, error) {
+	if f.err != nil {
+		return api.Link{}, f.err
+	}
+	if len(f.links) == 0 {
+		return api.Link{}, nil
+	}
+	link := f.links[f.next%len(f.links)]
+	f.next++
+	return link, nil
+}
+
+func (f *fakeLinks) GetLink(_ context.Context, _, id string) (api.Link, error) {
+	for _, link := range f.links {
+		if link.ID == id {
+			return link, nil
+		}
+	}
+	return api.Link{}, api.ErrLinkNotFound
+}
+
+func newTestScheduler(t *testing.T, telegram *fakeTelegram, schedules *fakeSchedules, links *fakeLinks, now *time.Time) *scheduler {
+	srv := httptest.NewServer(telegram)
+	t.Cleanup(srv.Close)
+	b, err := tb.NewBot(tb.Settings{Token: "token", URL: srv.URL, Offline: true})
+	require.NoError(t, err)
+	w := &Wrapper{bot: b, callbacks: newCallbackSigner("token")}
+	return &scheduler{w: w, schedules: schedules, links: links, now: func() time.Time { return *now }}
+}
+
+func TestScheduler_Digest(t *testing.T) {
+	now := time.Date(2026, 3, 2, 8, 59, 0, 0, time.UTC)
+	telegram := &fakeTelegram{}
+	schedules := &fakeSchedules{
+		digests: []user.Digest{{
+			UserID: "u1", TelegramID: 42, Frequency: "daily", Count: 3,
+			NextRunAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		}},
+		sent: map[string]time.Time{},
+	}
+	links := &fakeLinks{links: []api.Link{
+		{ID: uuid.NewString(), URL: "https://go.dev", Title: "Go"},
+		{ID: uuid.NewString(), URL: "https://pkg.go.dev"},
+	}}
+	s := newTestScheduler(t, telegram, schedules, links, &now)
+
+	s.tick(context.Background())
+	assert.Empty(t, telegram.messages, "not due yet")
+
+	now = now.Add(time.Minute)
+	s.tick(context.Background())
+	require.Len(t, telegram.messages, 1)
+	msg := telegram.messages[0]
+	assert.Equal(t, "42", msg.ChatID)
+	assert.Contains(t, msg.Text, "2 unread links")
+	assert.Contains(t, msg.Text, "1. Go\nhttps://go.dev")
+	assert.Contains(t, msg.Text, "2. https://pkg.go.dev")
+	assert.Contains(t, msg.ReplyMarkup, "https://go.dev")
+	assert.Equal(t, now, schedules.sent["u1"])
+
+	s.tick(context.Background())
+	assert.Len(t, telegram.messages, 1, "the next digest is due tomorrow")
+}
+
+func TestScheduler_DigestRetriedWhenLinksFail(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	telegram := &fakeTelegram{}
+	schedules := &fakeSchedules{
+		digests: []user.Digest{{UserID: "u1", TelegramID: 42, Frequency: "daily", Count: 3, NextRunAt: now}},
+		sent:    map[string]time.Time{},
+	}
+	links := &fakeLinks{err: assert.AnError}
+	s := newTestScheduler(t, telegram, schedules, links, &now)
+
+	s.tick(context.Background())
+	assert.Empty(t, telegram.messages)
+	assert.Empty(t, schedules.sent, "stays due")
+
+	links.err = nil
+	links.links = []api.Link{{ID: uuid.NewString(), URL: "https://go.dev"}}
+	now = now.Add(time.Minute)
+	s.tick(context.Background())
+	assert.Len(t, telegram.messages, 1)
+	assert.Contains(t, schedules.sent, "u1")
+}
+
+func TestScheduler_BlockedChatStillAdvances(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	telegram := &fakeTelegram{blocked: map[string]bool{"42": true}}
+	schedules := &fakeSchedules{
+		digests: []user.Digest{{UserID: "u1", TelegramID: 42, Frequency: "daily", Count: 1, NextRunAt: now}},
+		sent:    map[string]time.Time{},
+	}
+	links := &fakeLinks{links: []api.Link{{ID: uuid.NewString(), URL: "https://go.dev"}}}
+	s := newTestScheduler(t, telegram, schedules, links, &now)
+
+	s.tick(context.Background())
+	assert.Empty(t, telegram.messages)
+	assert.Contains(t, schedules.sent, "u1", "a chat that refuses messages is not retried every minute")
+}
+
+func TestScheduler_Reminders(t *testing.T) {
+	now := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	telegram := &fakeTelegram{}
+	link := api.Link{ID: uuid.NewString(), URL: "https://go.dev/blog", NextStatuses: []string{"reading"}}
+	schedules := &fakeSchedules{reminders: []user.Reminder{
+		{ID: "r1", UserID: "u1", TelegramID: 42, LinkID: link.ID, RemindAt: now},
+		{ID: "r2", UserID: "u1", TelegramID: 42, LinkID: uuid.NewString(), RemindAt: now},
+		{ID: "r3", UserID: "u1", TelegramID: 42, LinkID: link.ID, RemindAt: now.Add(time.Hour)},
+	}}
+	s := newTestScheduler(t, telegram, schedules, &fakeLinks{links: []api.Link{link}}, &now)
+
+	s.tick(context.Background())
+
+	require.Len(t, telegram.messages, 1, "the reminder of a deleted link is dropped")
+	assert.True(t, strings.HasPrefix(telegram.messages[0].Text, "⏰ reminder\nhttps://go.dev/blog"))
+	assert.Contains(t, telegram.messages[0].ReplyMarkup, "📖 Reading")
+	assert.Equal(t, []string{"r1", "r2"}, schedules.cancelled)
+	assert.Len(t, schedules.reminders, 1)
+}
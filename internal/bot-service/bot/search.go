@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/api"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+const (
+	searchPageSize = 5
+	// maxPagedQuery keeps "\fsearch_page|<offset>|<query>" within Telegram's 64-byte callback data limit.
+	maxPagedQuery = 46
+)
+
+var btnSearchPage = tb.Btn{Unique: "search_page"}
+
+func (w *Wrapper) handleSearch(c tb.Context) error {
+	query := strings.TrimSpace(c.Message().Payload)
+	if query == "" {
+		return c.Send("usage: /search <query>")
+	}
+	text, markup, err := w.searchPage(c, query, 0)
+	if err != nil {
+		return c.Send("search failed")
+	}
+	return c.Send(text, markup, tb.ModeHTML, tb.NoPreview)
+}
+
+func (w *Wrapper) handleSearchPage(c tb.Context) error {
+	rawOffset, query, ok := strings.Cut(c.Data(), "|")
+	offset, err := strconv.Atoi(rawOffset)
+	if !ok || err != nil || offset < 0 || query == "" {
+		return c.Respond(&tb.CallbackResponse{Text: "this page is no longer available"})
+	}
+	text, markup, err := w.searchPage(c, query, offset)
+	if err != nil {
+		return c.Respond(&tb.CallbackResponse{Text: "search failed"})
+	}
+	return c.Edit(text, markup, tb.ModeHTML, tb.NoPreview)
+}
+
+// searchPage renders one page of search results with prev/next buttons.
+func (w *Wrapper) searchPage(c tb.Context, query string, offset int) (string, *tb.ReplyMarkup, error) {
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return "", nil, err
+	}
+	// Ask for one extra result to learn whether there is a next page.
+	results, err := w.api.SearchLinks(ctx, u.ID, query, searchPageSize+1, offset)
+	if err != nil {
+		logger.L().Error().Err(err).Str("query", query).Msg("search links failed")
+		return "", nil, err
+	}
+	hasNext := len(results) > searchPageSize
+	if hasNext {
+		results = results[:searchPageSize]
+	}
+
+	markup := &tb.ReplyMarkup{}
+	if len(results) == 0 {
+		if offset == 0 {
+			return fmt.Sprintf("🔎 nothing found for <b>%s</b>", html.EscapeString(query)), markup, nil
+		}
+		return fmt.Sprintf("🔎 no more results for <b>%s</b>", html.EscapeString(query)), markup, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔎 results for <b>%s</b> (page %d)\n", html.EscapeString(query), offset/searchPageSize+1)
+	for i, result := range results {
+		b.WriteString("\n")
+		b.WriteString(formatSearchResult(offset+i+1, result))
+	}
+
+	if len(query) <= maxPagedQuery {
+		var buttons []tb.Btn
+		if offset > 0 {
+			prev := offset - searchPageSize
+			if prev < 0 {
+				prev = 0
+			}
+			buttons = append(buttons, markup.Data("◀ Prev", btnSearchPage.Unique, strconv.Itoa(prev), query))
+		}
+		if hasNext {
+			buttons = append(buttons, markup.Data("Next ▶", btnSearchPage.Unique, strconv.Itoa(offset+searchPageSize), query))
+		}
+		if len(buttons) > 0 {
+			markup.Inline(markup.Row(buttons...))
+		}
+	}
+	return b.String(), markup, nil
+}
+
+func formatSearchResult(n int, result api.SearchResult) string {
+	title := result.Title
+	if title == "" {
+		title = result.URL
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d. <b>%s</b>\n%s\n", n, html.EscapeString(title), html.EscapeString(result.URL))
+	if result.Snippet != "" {
+		// The API escapes snippets itself, leaving only the <b></b> around matches.
+		b.WriteString(result.Snippet)
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "ID: <code>%s</code>\n", html.EscapeString(result.ID))
+	return b.String()
+}
@@ -0,0 +1,27 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/api"
+)
+
+func TestFormatSearchResult(t *testing.T) {
+	result := api.SearchResult{
+		Link: api.Link{
+			ID:    "link-1",
+			URL:   "https://example.com/?a=1&b=2",
+			Title: "Bold </b> <script>x</script>",
+		},
+		Snippet: "Bold &lt;/b&gt; &lt;script&gt;<b>x</b>&lt;/script&gt;",
+	}
+
+	assert.Equal(t,
+		"1. <b>Bold &lt;/b&gt; &lt;script&gt;x&lt;/script&gt;</b>\n"+
+			"https://example.com/?a=1&amp;b=2\n"+
+			"Bold &lt;/b&gt; &lt;script&gt;<b>x</b>&lt;/script&gt;\n"+
+			"ID: <code>link-1</code>\n",
+		formatSearchResult(1, result))
+}
@@ -0,0 +1,288 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/user"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// btnSetting carries "<setting>|<value>" for a setting to change, or
+// "menu|<page>" for a page of the editor to show.
+var btnSetting = tb.Btn{Unique: "settings"}
+
+// Values offered by the settings editor; /settings timezone <Area/City> sets
+// any other timezone.
+var (
+	settingTimezones = []string{
+		"UTC", "Europe/London", "Europe/Berlin", "Europe/Moscow",
+		"Asia/Dubai", "Asia/Kolkata", "Asia/Singapore", "Asia/Tokyo",
+		"America/New_York", "America/Chicago", "America/Los_Angeles", "Australia/Sydney",
+	}
+	settingLanguages = []string{"en", "ru"}
+	digestTimes      = []string{"07:00", "09:00", "12:00", "18:00", "21:00"}
+	digestCounts     = []int{3, 5, 10}
+)
+
+const settingsUsage = "pick a setting below, or set any timezone with /settings timezone <Area/City>"
+
+// settings returns the user's settings, or the defaults when they cannot be
+// read: a failing user-service should not stop the bot from working.
+func (w *Wrapper) settings(ctx context.Context, userID string) user.Settings {
+	settings, err := w.userService.GetSettings(ctx, userID)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("get settings failed")
+		return user.Settings{Timezone: "UTC", Language: "en", Privacy: user.Privacy{AutoSave: true}}
+	}
+	return *settings
+}
+
+func (w *Wrapper) handleSettings(c tb.Context) error {
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to get settings")
+	}
+
+	args := strings.Fields(c.Message().Payload)
+	if len(args) == 2 && args[0] == "timezone" {
+		settings, err := w.userService.UpdateSettings(ctx, u.ID, user.SettingsPatch{Timezone: &args[1]})
+		if errors.Is(err, user.ErrInvalidInput) {
+			return c.Send(err.Error() + "\n\n" + settingsUsage)
+		}
+		if err != nil {
+			logger.L().Error().Err(err).Msg("update settings failed")
+			return c.Send("failed to update settings")
+		}
+		return c.Send(describeSettings(settings), settingsMarkup(settings, "main"))
+	}
+	if len(args) > 0 {
+		return c.Send(settingsUsage)
+	}
+
+	settings, err := w.userService.GetSettings(ctx, u.ID)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("get settings failed")
+		return c.Send("failed to get settings")
+	}
+	return c.Send(describeSettings(settings), settingsMarkup(settings, "main"))
+}
+
+// handleSettingButton changes a setting or turns a page of the editor, and
+// edits the message to show the result.
+func (w *Wrapper) handleSettingButton(c tb.Context) error {
+	setting, value, _ := strings.Cut(c.Data(), "|")
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Respond(&tb.CallbackResponse{Text: "failed to update settings"})
+	}
+
+	page := "main"
+	var settings *user.Settings
+	if setting == "menu" {
+		page = value
+		settings, err = w.userService.GetSettings(ctx, u.ID)
+	} else {
+		var patch user.SettingsPatch
+		patch, page, err = settingPatch(setting, value)
+		if err != nil {
+			return c.Respond(&tb.CallbackResponse{Text: "this button is no longer available"})
+		}
+		settings, err = w.userService.UpdateSettings(ctx, u.ID, patch)
+	}
+	if errors.Is(err, user.ErrInvalidInput) {
+		return c.Respond(&tb.CallbackResponse{Text: err.Error(), ShowAlert: true})
+	}
+	if err != nil {
+		logger.L().Error().Err(err).Str("setting", setting).Msg("update settings failed")
+		return c.Respond(&tb.CallbackResponse{Text: "failed to update settings"})
+	}
+
+	err = c.Edit(describeSettings(settings), settingsMarkup(settings, page))
+	if errors.Is(err, tb.ErrSameMessageContent) {
+		return nil
+	}
+	return err
+}
+
+// settingPatch turns a button's setting and value into a change, and tells
+// which page of the editor to show afterwards.
+func settingPatch(setting, value string) (user.SettingsPatch, string, error) {
+	var patch user.SettingsPatch
+	switch setting {
+	case "tz":
+		patch.Timezone = &value
+		return patch, "main", nil
+	case "lang":
+		patch.Language = &value
+		return patch, "main", nil
+	case "res":
+		patch.DefaultResource = &value
+		return patch, "main", nil
+	case "auto":
+		on := value == "on"
+		patch.Privacy = &user.PrivacyPatch{AutoSave: &on}
+		return patch, "main", nil
+	case "digest":
+		if value == "off" {
+			off := false
+			patch.Digest = &user.DigestPatch{Enabled: &off}
+		} else {
+			patch.Digest = &user.DigestPatch{Frequency: &value}
+		}
+	case "day":
+		patch.Digest = &user.DigestPatch{Weekday: &value}
+	case "time":
+		patch.Digest = &user.DigestPatch{Time: &value}
+	case "count":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return patch, "", err
+		}
+		patch.Digest = &user.DigestPatch{Count: &n}
+	default:
+		return patch, "", errors.New("unknown setting " + setting)
+	}
+	return patch, "digest", nil
+}
+
+func describeSettings(s *user.Settings) string {
+	resource := s.DefaultResource
+	if resource == "" {
+		resource = "any"
+	}
+	autoSave := "off, only /save saves links"
+	if s.Privacy.AutoSave {
+		autoSave = "on"
+	}
+	return strings.Join([]string{
+		"⚙️ settings",
+		"🌍 timezone: " + s.Timezone,
+		"🗣 language: " + s.Language,
+		"📂 default resource for /random: " + resource,
+		"📬 digest: " + describeDigestSettings(s.Digest, s.Timezone),
+		"🔒 save links of every message: " + autoSave,
+	}, "\n")
+}
+
+func describeDigestSettings(d user.DigestSettings, timezone string) string {
+	if !d.Enabled {
+		return "off"
+	}
+	when := "every day"
+	if d.Frequency == "weekly" {
+		when = "every " + d.Weekday
+	}
+	text := plural(d.Count, "link") + " " + when + " at " + d.Time
+	if d.Resource != "" {
+		text += " (" + d.Resource + ")"
+	}
+	if d.NextRunAt != nil {
+		next := *d.NextRunAt
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			next = next.In(loc)
+		}
+		text += ", next " + next.Format("Mon 2 Jan 15:04")
+	}
+	return text
+}
+
+// settingsMarkup renders a page of the editor: "main", or the choices of
+// one setting. The current choice is marked with a dot.
+func settingsMarkup(s *user.Settings, page string) *tb.ReplyMarkup {
+	markup := &tb.ReplyMarkup{}
+	option := func(label, setting, value string, current bool) tb.Btn {
+		if current {
+			label = "• " + label
+		}
+		return markup.Data(label, btnSetting.Unique, setting, value)
+	}
+	back := markup.Row(markup.Data("↩️ Back", btnSetting.Unique, "menu", "main"))
+
+	var rows []tb.Row
+	switch page {
+	case "tz":
+		rows = chunk(markup, 3, len(settingTimezones), func(i int) tb.Btn {
+			tz := settingTimezones[i]
+			return option(tz, "tz", tz, tz == s.Timezone)
+		})
+		rows = append(rows, back)
+	case "lang":
+		var langs []tb.Btn
+		for _, lang := range settingLanguages {
+			langs = append(langs, option(lang, "lang", lang, lang == s.Language))
+		}
+		rows = append(rows, markup.Row(langs...), back)
+	case "res":
+		rows = append(rows, markup.Row(option("any", "res", "", s.DefaultResource == "")))
+		rows = append(rows, chunk(markup, 3, len(resources), func(i int) tb.Btn {
+			return option(resources[i], "res", resources[i], resources[i] == s.DefaultResource)
+		})...)
+		rows = append(rows, back)
+	case "digest":
+		d := s.Digest
+		rows = append(rows, markup.Row(
+			option("Off", "digest", "off", !d.Enabled),
+			option("Daily", "digest", "daily", d.Enabled && d.Frequency == "daily"),
+			option("Weekly", "digest", "weekly", d.Enabled && d.Frequency == "weekly"),
+		))
+		if d.Enabled && d.Frequency == "weekly" {
+			var days []tb.Btn
+			// Weeks start on Monday here.
+			for i := 1; i <= 7; i++ {
+				name := strings.ToLower(time.Weekday(i % 7).String())
+				days = append(days, option(name[:2], "day", name[:3], name == d.Weekday))
+			}
+			rows = append(rows, markup.Row(days...))
+		}
+		if d.Enabled {
+			var times, counts []tb.Btn
+			for _, t := range digestTimes {
+				times = append(times, option(t, "time", t, t == d.Time))
+			}
+			for _, n := range digestCounts {
+				counts = append(counts, option(plural(n, "link"), "count", strconv.Itoa(n), n == d.Count))
+			}
+			rows = append(rows, markup.Row(times...), markup.Row(counts...))
+		}
+		rows = append(rows, back)
+	default:
+		autoSave, toggle := "off", "on"
+		if s.Privacy.AutoSave {
+			autoSave, toggle = "on", "off"
+		}
+		rows = append(rows,
+			markup.Row(
+				markup.Data("🌍 Timezone", btnSetting.Unique, "menu", "tz"),
+				markup.Data("🗣 Language", btnSetting.Unique, "menu", "lang"),
+			),
+			markup.Row(
+				markup.Data("📂 Default resource", btnSetting.Unique, "menu", "res"),
+				markup.Data("📬 Digest", btnSetting.Unique, "menu", "digest"),
+			),
+			markup.Row(markup.Data("🔒 Save every message's links: "+autoSave, btnSetting.Unique, "auto", toggle)),
+		)
+	}
+	markup.Inline(rows...)
+	return markup
+}
+
+// chunk lays out n buttons in rows of size.
+func chunk(markup *tb.ReplyMarkup, size, n int, btn func(i int) tb.Btn) []tb.Row {
+	var rows []tb.Row
+	for start := 0; start < n; start += size {
+		var row []tb.Btn
+		for i := start; i < n && i < start+size; i++ {
+			row = append(row, btn(i))
+		}
+		rows = append(rows, markup.Row(row...))
+	}
+	return rows
+}
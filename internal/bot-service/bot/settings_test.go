@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/user"
+)
+
+func TestSettingPatch(t *testing.T) {
+	patch, page, err := settingPatch("auto", "off")
+	require.NoError(t, err)
+	assert.Equal(t, "main", page)
+	require.NotNil(t, patch.Privacy)
+	assert.False(t, *patch.Privacy.AutoSave)
+
+	patch, page, err = settingPatch("count", "10")
+	require.NoError(t, err)
+	assert.Equal(t, "digest", page)
+	assert.Equal(t, 10, *patch.Digest.Count)
+
+	patch, _, err = settingPatch("digest", "off")
+	require.NoError(t, err)
+	assert.False(t, *patch.Digest.Enabled)
+
+	_, _, err = settingPatch("count", "many")
+	assert.Error(t, err)
+	_, _, err = settingPatch("theme", "dark")
+	assert.Error(t, err)
+}
+
+func TestSettingsMarkup(t *testing.T) {
+	settings := &user.Settings{
+		Timezone: "Europe/Berlin",
+		Language: "en",
+		Privacy:  user.Privacy{AutoSave: true},
+		Digest:   user.DigestSettings{Enabled: true, Frequency: "weekly", Weekday: "saturday", Time: "09:00", Count: 5},
+	}
+
+	for _, page := range []string{"main", "tz", "lang", "res", "digest"} {
+		markup := settingsMarkup(settings, page)
+		for _, row := range markup.InlineKeyboard {
+			for _, btn := range row {
+				// Telegram gets "\f<unique>|<data>" and takes at most 64 bytes of it.
+				assert.LessOrEqual(t, len("\f"+btn.Unique+"|"+btn.Data), 64, btn.Text)
+			}
+		}
+	}
+
+	var marked []string
+	for _, row := range settingsMarkup(settings, "digest").InlineKeyboard {
+		for _, btn := range row {
+			if strings.HasPrefix(btn.Text, "• ") {
+				marked = append(marked, btn.Data)
+			}
+		}
+	}
+	assert.Equal(t, []string{"digest|weekly", "day|sat", "time|09:00", "count|5"}, marked)
+
+	assert.Contains(t, describeSettings(settings), "📬 digest: 5 links every saturday at 09:00")
+}
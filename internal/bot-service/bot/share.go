@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// maxSharedLinks bounds the links saved from a single message.
+const maxSharedLinks = 10
+
+const helpText = "commands: /save <url> [#tag ...], /viewed <id>, /random [resource] [#tag ...], /search <query>, /subscribe <url> [#tag ...], /unsubscribe [url], /digest, /remind <id> <when>, /settings, /deleteme\nor just send or forward a message with links to save them"
+
+// urlPattern finds URLs in text that carries no entities, e.g. captions of
+// messages forwarded by other bots.
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// sharedLink is the outcome of saving one URL of a shared message.
+type sharedLink struct {
+	url          string
+	id           string
+	alreadySaved bool
+	err          error
+}
+
+// handleShared saves every link in a message the user sent or forwarded: in
+// its text or the caption of a photo or other media.
+func (w *Wrapper) handleShared(c tb.Context) error {
+	urls := sharedURLs(c.Message())
+	if len(urls) == 0 {
+		return c.Send(helpText, menu)
+	}
+	truncated := len(urls) > maxSharedLinks
+	if truncated {
+		urls = urls[:maxSharedLinks]
+	}
+
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to save links")
+	}
+	if settings := w.settings(ctx, u.ID); !settings.Privacy.AutoSave {
+		return c.Send("links of messages are not saved, as set in /settings; use /save <url>", menu)
+	}
+	links := make([]sharedLink, 0, len(urls))
+	for _, raw := range urls {
+		id, alreadySaved, err := w.api.CreateLink(ctx, u.ID, raw, nil)
+		if err != nil {
+			logger.L().Error().Err(err).Str("url", raw).Msg("create shared link failed")
+		}
+		links = append(links, sharedLink{url: raw, id: id, alreadySaved: alreadySaved, err: err})
+	}
+
+	text := sharedSummary(links)
+	if truncated {
+		text += fmt.Sprintf("\nonly the first %d links of a message are saved", maxSharedLinks)
+	}
+	return c.Send(text, w.sharedMarkup(c.Sender().ID, links), tb.NoPreview)
+}
+
+// sharedURLs returns the links of a message in the order they appear, each
+// once: URLs in its text or caption and the targets of text links.
+func sharedURLs(m *tb.Message) []string {
+	text, entities := m.Text, m.Entities
+	if text == "" {
+		text, entities = m.Caption, m.CaptionEntities
+	}
+
+	var urls []string
+	seen := make(map[string]bool)
+	add := func(raw string) {
+		raw = strings.TrimSpace(raw)
+		if !strings.Contains(raw, "://") {
+			// Telegram also marks "example.com/page" as a URL.
+			raw = "https://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return
+		}
+		if !seen[raw] {
+			seen[raw] = true
+			urls = append(urls, raw)
+		}
+	}
+
+	marked := false
+	for _, e := range entities {
+		switch e.Type {
+		case tb.EntityURL:
+			// EntityText reads the caption when there is no text, as here.
+			add(m.EntityText(e))
+			marked = true
+		case tb.EntityTextLink:
+			add(e.URL)
+			marked = true
+		}
+	}
+	if !marked {
+		for _, match := range urlPattern.FindAllString(text, -1) {
+			add(strings.TrimRight(match, ".,;:!?)]}'"))
+		}
+	}
+	return urls
+}
+
+func sharedSummary(links []sharedLink) string {
+	var saved, already, failed int
+	lines := make([]string, 0, len(links))
+	for i, link := range links {
+		n := strconv.Itoa(i+1) + ". "
+		switch {
+		case link.err != nil:
+			failed++
+			lines = append(lines, n+"❌ "+link.url)
+		case link.alreadySaved:
+			already++
+			lines = append(lines, n+"📌 "+link.url)
+		default:
+			saved++
+			lines = append(lines, n+"✅ "+link.url)
+		}
+	}
+
+	var counts []string
+	if saved > 0 {
+		counts = append(counts, plural(saved, "link")+" saved ✅")
+	}
+	if already > 0 {
+		counts = append(counts, plural(already, "link")+" already saved 📌")
+	}
+	if failed > 0 {
+		counts = append(counts, plural(failed, "link")+" failed ❌")
+	}
+	return strings.Join(counts, ", ") + "\n\n" + strings.Join(lines, "\n")
+}
+
+// sharedMarkup offers a row of buttons per saved link: one opening the page
+// and one sending the link with its actions.
+func (w *Wrapper) sharedMarkup(telegramID int64, links []sharedLink) *tb.ReplyMarkup {
+	markup := &tb.ReplyMarkup{}
+	var rows []tb.Row
+	for i, link := range links {
+		if link.err != nil {
+			continue
+		}
+		open, more := "🔗 Open", "⋯ More"
+		if len(links) > 1 {
+			open += " " + strconv.Itoa(i+1)
+			more += " " + strconv.Itoa(i+1)
+		}
+		rows = append(rows, markup.Row(
+			markup.URL(open, link.url),
+			w.callbacks.button(markup, telegramID, more, actionSend, link.id, ""),
+		))
+	}
+	markup.Inline(rows...)
+	return markup
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
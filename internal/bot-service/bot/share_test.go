@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tb "gopkg.in/telebot.v4"
+)
+
+func TestSharedURLs_Entities(t *testing.T) {
+	// Offsets count UTF-16 code units: the emoji takes two.
+	m := &tb.Message{
+		Text: "🔥 go.dev/blog and the docs, again go.dev/blog",
+		Entities: tb.Entities{
+			{Type: tb.EntityURL, Offset: 3, Length: 11},
+			{Type: tb.EntityTextLink, Offset: 19, Length: 8, URL: "https://pkg.go.dev/"},
+			{Type: tb.EntityBold, Offset: 0, Length: 2},
+			{Type: tb.EntityURL, Offset: 35, Length: 11},
+		},
+	}
+
+	assert.Equal(t, []string{"https://go.dev/blog", "https://pkg.go.dev/"}, sharedURLs(m))
+}
+
+func TestSharedURLs_Caption(t *testing.T) {
+	m := &tb.Message{
+		Photo:           &tb.Photo{},
+		Caption:         "read https://example.com/post",
+		CaptionEntities: tb.Entities{{Type: tb.EntityURL, Offset: 5, Length: 24}},
+	}
+
+	assert.Equal(t, []string{"https://example.com/post"}, sharedURLs(m))
+}
+
+func TestSharedURLs_WithoutEntities(t *testing.T) {
+	m := &tb.Message{Text: "see https://example.com/a, (http://example.org/b) and mailto:x@example.com"}
+
+	assert.Equal(t, []string{"https://example.com/a", "http://example.org/b"}, sharedURLs(m))
+}
+
+func TestSharedURLs_None(t *testing.T) {
+	assert.Empty(t, sharedURLs(&tb.Message{Text: "hello"}))
+	assert.Empty(t, sharedURLs(&tb.Message{Photo: &tb.Photo{}}))
+}
+
+func TestSharedSummary(t *testing.T) {
+	links := []sharedLink{
+		{url: "https://a.example", id: "1"},
+		{url: "https://b.example", id: "2", alreadySaved: true},
+		{url: "https://c.example", err: errors.New("boom")},
+		{url: "https://d.example", id: "4"},
+	}
+
+	assert.Equal(t, "2 links saved ✅, 1 link already saved 📌, 1 link failed ❌\n\n"+
+		"1. ✅ https://a.example\n2. 📌 https://b.example\n3. ❌ https://c.example\n4. ✅ https://d.example",
+		sharedSummary(links))
+
+	w := &Wrapper{callbacks: newCallbackSigner("token")}
+	markup := w.sharedMarkup(42, links)
+	if assert.Len(t, markup.InlineKeyboard, 3, "no buttons for the failed link") {
+		assert.Equal(t, "https://d.example", markup.InlineKeyboard[2][0].URL)
+		assert.Equal(t, "⋯ More 4", markup.InlineKeyboard[2][1].Text)
+	}
+}
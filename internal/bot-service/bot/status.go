@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/api"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// statusButtons are offered under a link, in this order; only the statuses the
+// API says the link may move to are shown.
+var statusButtons = []struct {
+	status string
+	label  string
+	done   string
+}{
+	{"reading", "📖 Reading", "marked as reading 📖"},
+	{"done", "✅ Done", "marked as done ✅"},
+	{"archived", "🗄 Archive", "archived 🗄"},
+	{"unread", "↩️ Unread", "back in the queue ↩️"},
+}
+
+// sendRandom replies with a random link matching resource and tags, with buttons to change its status.
+// Without either it picks from the user's default resource, if they set one.
+func (w *Wrapper) sendRandom(c tb.Context, resource string, tags []string, what string) error {
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to get random "+what, menu)
+	}
+	if resource == "" && len(tags) == 0 {
+		resource = w.settings(ctx, u.ID).DefaultResource
+	}
+	link, err := w.api.RandomLink(ctx, u.ID, resource, tags)
+	if err != nil {
+		logger.L().Error().Err(err).Str("resource", resource).Msg("random link failed")
+		return c.Send("failed to get random "+what, menu)
+	}
+	if link.URL == "" {
+		return c.Send("no unread "+what+"s found", menu)
+	}
+	return w.sendLink(c, "random ✅\n", link)
+}
+
+func formatLink(link api.Link) string {
+	msg := link.URL + "\nID: " + link.ID
+	if link.Title != "" {
+		msg = link.Title + "\n" + msg
+	}
+	if link.Resource != "" {
+		msg += "\nResource: " + link.Resource
+	}
+	if len(link.Tags) > 0 {
+		msg += "\nTags: #" + strings.Join(link.Tags, " #")
+	}
+	if link.Status != "" {
+		msg += "\nStatus: " + link.Status
+	}
+	return msg
+}
@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/api"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+var btnUnsubscribe = tb.Btn{Unique: "unsubscribe"}
+
+func (w *Wrapper) handleSubscribe(c tb.Context) error {
+	args, tags := splitTags(c.Message().Payload)
+	if len(args) == 0 {
+		return c.Send("usage: /subscribe <feed or site url> [#tag ...]")
+	}
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to subscribe")
+	}
+	sub, err := w.api.Subscribe(ctx, u.ID, args[0], tags)
+	switch {
+	case errors.Is(err, api.ErrNoFeed):
+		return c.Send("no RSS or Atom feed found at " + args[0])
+	case errors.Is(err, api.ErrAlreadySubscribed):
+		return c.Send("already subscribed 📌")
+	case err != nil:
+		logger.L().Error().Err(err).Str("url", args[0]).Msg("subscribe failed")
+		return c.Send("failed to subscribe")
+	}
+	return c.Send("subscribed to "+sub.Title+" ✅\nnew posts will be saved as links", tb.NoPreview)
+}
+
+// handleUnsubscribe drops the subscription to the given feed or site, or
+// lists the subscriptions with a button each when no URL is given.
+func (w *Wrapper) handleUnsubscribe(c tb.Context) error {
+	target := strings.TrimSpace(c.Message().Payload)
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to unsubscribe")
+	}
+	subs, err := w.api.ListSubscriptions(ctx, u.ID)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("list subscriptions failed")
+		return c.Send("failed to unsubscribe")
+	}
+	if len(subs) == 0 {
+		return c.Send("no subscriptions yet, add one with /subscribe <url>")
+	}
+	if target == "" {
+		return c.Send("pick a feed to unsubscribe from:", unsubscribeMarkup(subs))
+	}
+
+	for _, sub := range subs {
+		if sameURL(sub.FeedURL, target) || sameURL(sub.SiteURL, target) {
+			if err := w.api.Unsubscribe(ctx, u.ID, sub.ID); err != nil {
+				logger.L().Error().Err(err).Str("id", sub.ID).Msg("unsubscribe failed")
+				return c.Send("failed to unsubscribe")
+			}
+			return c.Send("unsubscribed from " + sub.Title + " 🔕")
+		}
+	}
+	return c.Send("you are not subscribed to "+target, unsubscribeMarkup(subs))
+}
+
+func (w *Wrapper) handleUnsubscribeButton(c tb.Context) error {
+	id := c.Data()
+	if id == "" {
+		return c.Respond(&tb.CallbackResponse{Text: "this button is no longer valid"})
+	}
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Respond(&tb.CallbackResponse{Text: "failed to unsubscribe"})
+	}
+	if err := w.api.Unsubscribe(ctx, u.ID, id); err != nil {
+		logger.L().Error().Err(err).Str("id", id).Msg("unsubscribe failed")
+		return c.Respond(&tb.CallbackResponse{Text: "failed to unsubscribe"})
+	}
+	if err := c.Respond(&tb.CallbackResponse{Text: "unsubscribed 🔕"}); err != nil {
+		return err
+	}
+	subs, err := w.api.ListSubscriptions(ctx, u.ID)
+	if err != nil || len(subs) == 0 {
+		return c.Edit("no subscriptions left")
+	}
+	return c.Edit("pick a feed to unsubscribe from:", unsubscribeMarkup(subs))
+}
+
+// unsubscribeMarkup has a button per subscription, one per row.
+func unsubscribeMarkup(subs []api.Subscription) *tb.ReplyMarkup {
+	markup := &tb.ReplyMarkup{}
+	rows := make([]tb.Row, 0, len(subs))
+	for _, sub := range subs {
+		label := "🔕 " + sub.Title
+		if sub.LastError != "" {
+			label += " ⚠️"
+		}
+		rows = append(rows, markup.Row(markup.Data(label, btnUnsubscribe.Unique, sub.ID)))
+	}
+	markup.Inline(rows...)
+	return markup
+}
+
+// sameURL compares URLs the way users type them: without caring about the
+// scheme, a "www." prefix or a trailing slash.
+func sameURL(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return bareURL(a) == bareURL(b)
+}
+
+func bareURL(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "https://"), "http://")
+	s = strings.TrimPrefix(s, "www.")
+	return strings.TrimRight(s, "/")
+}
@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	tb "gopkg.in/telebot.v4"
+
+	"github.com/danilovid/linkkeeper/internal/bot-service/user"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// handleToken issues a personal API token for the frontend or scripts.
+func (w *Wrapper) handleToken(c tb.Context) error {
+	name := strings.TrimSpace(c.Message().Payload)
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to issue token")
+	}
+	token, err := w.userService.IssueToken(ctx, u.ID, name)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("issue token failed")
+		return c.Send("failed to issue token")
+	}
+	return c.Send(
+		"your API token 🔑\n<code>"+token.Token+"</code>\n\n"+
+			"send it as <code>Authorization: Bearer …</code>. It is shown only once; revoke it with /revoke "+token.ID,
+		tb.ModeHTML,
+	)
+}
+
+func (w *Wrapper) handleTokens(c tb.Context) error {
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to list tokens")
+	}
+	tokens, err := w.userService.ListTokens(ctx, u.ID)
+	if err != nil {
+		logger.L().Error().Err(err).Msg("list tokens failed")
+		return c.Send("failed to list tokens")
+	}
+	if len(tokens) == 0 {
+		return c.Send("no API tokens yet, issue one with /token [name]")
+	}
+	lines := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		line := token.Prefix + "… " + token.ID
+		if token.Name != "" {
+			line = token.Name + ": " + line
+		}
+		if token.LastUsedAt != nil {
+			line += "\nlast used " + token.LastUsedAt.Format("2006-01-02 15:04")
+		}
+		lines = append(lines, line)
+	}
+	return c.Send(strings.Join(lines, "\n\n") + "\n\nrevoke one with /revoke <id>")
+}
+
+func (w *Wrapper) handleRevoke(c tb.Context) error {
+	id := strings.TrimSpace(c.Message().Payload)
+	if id == "" {
+		return c.Send("usage: /revoke <token id>, see /tokens")
+	}
+	ctx := context.Background()
+	u, err := w.resolveUser(ctx, c)
+	if err != nil {
+		return c.Send("failed to revoke token")
+	}
+	err = w.userService.RevokeToken(ctx, u.ID, id)
+	if errors.Is(err, user.ErrTokenNotFound) {
+		return c.Send("no such token, see /tokens")
+	}
+	if err != nil {
+		logger.L().Error().Err(err).Str("id", id).Msg("revoke token failed")
+		return c.Send("failed to revoke token")
+	}
+	return c.Send("token revoked 🔒")
+}
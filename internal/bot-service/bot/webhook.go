@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/danilovid/linkkeeper/pkg/logger"
+	tb "gopkg.in/telebot.v4"
+)
+
+// secretTokenHeader carries the secret Telegram was given when the webhook
+// was registered.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxUpdateSize bounds the body of a webhook request; updates are small.
+const maxUpdateSize = 1 << 20
+
+// webhookPoller registers the webhook with Telegram and then waits: updates
+// arrive through webhookHandler instead. Unlike tb.Webhook it leaves serving
+// HTTP to the process, which also serves /health and shuts it down.
+type webhookPoller struct {
+	url    string
+	secret string
+}
+
+func (p *webhookPoller) Poll(b *tb.Bot, _ chan tb.Update, stop chan struct{}) {
+	err := b.SetWebhook(&tb.Webhook{
+		SecretToken:    p.secret,
+		AllowedUpdates: tb.AllowedUpdates,
+		Endpoint:       &tb.WebhookEndpoint{PublicURL: p.url},
+	})
+	if err != nil {
+		logger.L().Error().Err(err).Msg("failed to register webhook")
+	} else {
+		logger.L().Info().Msg("webhook registered")
+	}
+	<-stop
+}
+
+// webhookHandler accepts updates Telegram posts to the webhook.
+type webhookHandler struct {
+	secret  string
+	updates chan<- tb.Update
+}
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	got := r.Header.Get(secretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(got), []byte(h.secret)) != 1 {
+		logger.L().Warn().Str("remote", r.RemoteAddr).Msg("webhook request with a wrong secret token")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var update tb.Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil || update.ID == 0 {
+		http.Error(w, "invalid update", http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case h.updates <- update:
+		w.WriteHeader(http.StatusOK)
+	case <-r.Context().Done():
+		// Telegram delivers the update again later.
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}
+}
+
+// webhookPath is the path Telegram posts updates to, taken from the public URL.
+func webhookPath(publicURL string) string {
+	u, err := url.Parse(publicURL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+// Handler serves /health and, in webhook mode, the webhook.
+func (w *Wrapper) Handler() http.Handler {
+	mux := http.NewServeMux()
+	if w.config.WebhookURL != "" {
+		mux.Handle("POST "+webhookPath(w.config.WebhookURL), &webhookHandler{
+			secret:  w.config.WebhookSecret,
+			updates: w.bot.Updates,
+		})
+	}
+	mux.HandleFunc("GET /health", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		if _, err := rw.Write([]byte("OK")); err != nil {
+			logger.L().Error().Err(err).Msg("failed to write health response")
+		}
+	})
+	return mux
+}
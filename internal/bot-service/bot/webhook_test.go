@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/telebot.v4"
+)
+
+func postUpdate(h http.Handler, secret, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(body))
+	if secret != "" {
+		req.Header.Set(secretTokenHeader, secret)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWebhookHandler(t *testing.T) {
+	updates := make(chan tb.Update, 1)
+	h := &webhookHandler{secret: "s3cret", updates: updates}
+
+	rec := postUpdate(h, "s3cret", `{"update_id": 7, "message": {"message_id": 1, "text": "hi"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	update := <-updates
+	assert.Equal(t, 7, update.ID)
+	assert.Equal(t, "hi", update.Message.Text)
+}
+
+func TestWebhookHandler_Rejects(t *testing.T) {
+	updates := make(chan tb.Update, 1)
+	h := &webhookHandler{secret: "s3cret", updates: updates}
+
+	assert.Equal(t, http.StatusUnauthorized, postUpdate(h, "", `{"update_id": 7}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, postUpdate(h, "guess", `{"update_id": 7}`).Code)
+	assert.Equal(t, http.StatusBadRequest, postUpdate(h, "s3cret", `not json`).Code)
+	assert.Equal(t, http.StatusBadRequest, postUpdate(h, "s3cret", `{}`).Code)
+	assert.Empty(t, updates)
+}
+
+func TestConfigValidate_Webhook(t *testing.T) {
+	cfg := Config{Token: "t", APIBaseURL: "http://api", UserServiceURL: "http://users"}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, ":8082", cfg.HTTPAddr)
+
+	cfg.WebhookURL = "https://bot.example.com/telegram"
+	assert.Error(t, cfg.Validate(), "a webhook needs a secret")
+
+	cfg.WebhookSecret = "not allowed!"
+	assert.Error(t, cfg.Validate())
+
+	cfg.WebhookSecret = "s3cret_-"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.WebhookURL = "http://bot.example.com/telegram"
+	assert.Error(t, cfg.Validate(), "Telegram only posts to https")
+}
+
+func TestWebhookPath(t *testing.T) {
+	assert.Equal(t, "/telegram", webhookPath("https://bot.example.com/telegram"))
+	assert.Equal(t, "/", webhookPath("https://bot.example.com"))
+}
@@ -2,10 +2,13 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/danilovid/linkkeeper/internal/bot-service/api"
 	"github.com/danilovid/linkkeeper/internal/bot-service/user"
+	"github.com/danilovid/linkkeeper/pkg/auth"
 	"github.com/danilovid/linkkeeper/pkg/logger"
 	tb "gopkg.in/telebot.v4"
 	"gopkg.in/telebot.v4/middleware"
@@ -16,6 +19,9 @@ type Wrapper struct {
 	config      *Config
 	api         *api.Client
 	userService *user.Client
+	callbacks   callbackSigner
+	scheduler   *scheduler
+	stop        chan struct{}
 }
 
 var (
@@ -33,9 +39,13 @@ func NewWrapper(config *Config) (*Wrapper, error) {
 		return nil, err
 	}
 
+	var poller tb.Poller = &tb.LongPoller{Timeout: config.Timeout}
+	if config.WebhookURL != "" {
+		poller = &webhookPoller{url: config.WebhookURL, secret: config.WebhookSecret}
+	}
 	settings := tb.Settings{
 		Token:  config.Token,
-		Poller: &tb.LongPoller{Timeout: config.Timeout},
+		Poller: poller,
 	}
 
 	b, err := tb.NewBot(settings)
@@ -46,21 +56,42 @@ func NewWrapper(config *Config) (*Wrapper, error) {
 	b.Use(middleware.Logger())
 	b.Use(middleware.AutoRespond())
 
+	// Validate has checked the keys already.
+	keys, _ := auth.ParseKeys(config.ServiceKeys)
+	signer := auth.NewSigner(keys)
 	w := &Wrapper{
 		bot:         b,
 		config:      config,
-		api:         api.NewClient(config.APIBaseURL, config.Timeout),
-		userService: user.NewClient(config.UserServiceURL, config.Timeout),
+		api:         api.NewClient(config.APIBaseURL, config.Timeout, signer),
+		userService: user.NewClient(config.UserServiceURL, config.Timeout, signer),
+		callbacks:   newCallbackSigner(config.Token),
+		stop:        make(chan struct{}),
 	}
+	w.scheduler = &scheduler{w: w, schedules: w.userService, links: w.api, now: time.Now}
 	w.prepare()
 	return w, nil
 }
 
+// Start handles updates and sends scheduled digests and reminders until
+// Stop is called.
 func (w *Wrapper) Start() error {
+	if w.config.WebhookURL == "" {
+		// Telegram refuses to hand out updates by polling while a webhook is set.
+		if err := w.bot.RemoveWebhook(); err != nil {
+			return err
+		}
+	}
+	go w.scheduler.run(w.stop)
 	w.bot.Start()
 	return nil
 }
 
+// Stop stops receiving updates and sending scheduled messages.
+func (w *Wrapper) Stop() {
+	close(w.stop)
+	w.bot.Stop()
+}
+
 func (w *Wrapper) prepare() {
 	menu.Reply(
 		menu.Row(btnSave, btnViewed),
@@ -72,7 +103,7 @@ func (w *Wrapper) prepare() {
 		sender := c.Sender()
 		if sender != nil {
 			ctx := context.Background()
-			_, err := w.userService.GetOrCreateUser(
+			u, err := w.userService.GetOrCreateUser(
 				ctx,
 				sender.ID,
 				sender.Username,
@@ -81,25 +112,39 @@ func (w *Wrapper) prepare() {
 			)
 			if err != nil {
 				logger.L().Error().Err(err).Int64("telegram_id", sender.ID).Msg("failed to get or create user")
-			} else {
-				logger.L().Info().Int64("telegram_id", sender.ID).Str("username", sender.Username).Msg("user registered or retrieved")
+			} else if u.Created {
+				logger.L().Info().Int64("telegram_id", sender.ID).Str("username", sender.Username).Msg("user registered")
 			}
 		}
 		return c.Send("Welcome! Choose an action:", menu)
 	})
 
 	w.bot.Handle("/save", func(c tb.Context) error {
-		url := strings.TrimSpace(c.Message().Payload)
-		if url == "" {
-			return c.Send("usage: /save <url>")
+		args, tags := splitTags(c.Message().Payload)
+		if len(args) == 0 {
+			return c.Send("usage: /save <url> [#tag ...]")
 		}
+		url := args[0]
 		ctx := context.Background()
-		id, err := w.api.CreateLink(ctx, url)
+		u, err := w.resolveUser(ctx, c)
+		if err != nil {
+			return c.Send("failed to save link")
+		}
+		id, alreadySaved, err := w.api.CreateLink(ctx, u.ID, url, tags)
 		if err != nil {
 			logger.L().Error().Err(err).Str("url", url).Msg("create link failed")
 			return c.Send("failed to save link")
 		}
-		return c.Send("saved ✅ id: " + id)
+		prefix := "saved ✅\n"
+		if alreadySaved {
+			prefix = "already saved 📌\n"
+		}
+		link, err := w.api.GetLink(ctx, u.ID, id)
+		if err != nil {
+			logger.L().Error().Err(err).Str("id", id).Msg("get saved link failed")
+			return c.Send(prefix + "ID: " + id)
+		}
+		return w.sendLink(c, prefix, link)
 	})
 
 	w.bot.Handle("/viewed", func(c tb.Context) error {
@@ -108,7 +153,11 @@ func (w *Wrapper) prepare() {
 			return c.Send("usage: /viewed <id>")
 		}
 		ctx := context.Background()
-		if err := w.api.MarkViewed(ctx, id); err != nil {
+		u, err := w.resolveUser(ctx, c)
+		if err != nil {
+			return c.Send("failed to mark viewed")
+		}
+		if err := w.api.MarkViewed(ctx, u.ID, id); err != nil {
 			logger.L().Error().Err(err).Str("id", id).Msg("mark viewed failed")
 			return c.Send("failed to mark viewed")
 		}
@@ -116,23 +165,25 @@ func (w *Wrapper) prepare() {
 	})
 
 	w.bot.Handle("/random", func(c tb.Context) error {
-		resource := strings.TrimSpace(c.Message().Payload)
-		ctx := context.Background()
-		link, err := w.api.RandomLink(ctx, resource)
-		if err != nil {
-			logger.L().Error().Err(err).Str("resource", resource).Msg("random link failed")
-			return c.Send("failed to get random link")
-		}
-		if link.URL == "" {
-			return c.Send("no links found")
-		}
-		msg := "random ✅\n" + link.URL + "\nID: " + link.ID
-		if link.Resource != "" {
-			msg += "\nResource: " + link.Resource
-		}
-		return c.Send(msg, menu)
+		args, tags := splitTags(c.Message().Payload)
+		return w.sendRandom(c, strings.Join(args, " "), tags, "link")
 	})
 
+	w.bot.Handle("/search", w.handleSearch)
+	w.bot.Handle(&btnSearchPage, w.handleSearchPage)
+	w.bot.Handle("/subscribe", w.handleSubscribe)
+	w.bot.Handle("/unsubscribe", w.handleUnsubscribe)
+	w.bot.Handle(&btnUnsubscribe, w.handleUnsubscribeButton)
+	w.bot.Handle("/token", w.handleToken)
+	w.bot.Handle("/tokens", w.handleTokens)
+	w.bot.Handle("/revoke", w.handleRevoke)
+	w.bot.Handle("/digest", w.handleDigest)
+	w.bot.Handle("/remind", w.handleRemind)
+	w.bot.Handle("/settings", w.handleSettings)
+	w.bot.Handle(&btnSetting, w.handleSettingButton)
+	w.bot.Handle("/deleteme", w.handleDeleteMe)
+	w.bot.Handle(&btnDeleteAccount, w.handleDeleteAccountButton)
+
 	w.bot.Handle(&btnSave, func(c tb.Context) error {
 		return c.Send("Send link: /save <url>", menu)
 	})
@@ -142,64 +193,62 @@ func (w *Wrapper) prepare() {
 	})
 
 	w.bot.Handle(&btnRandom, func(c tb.Context) error {
-		ctx := context.Background()
-		link, err := w.api.RandomLink(ctx, "")
-		if err != nil {
-			logger.L().Error().Err(err).Msg("random link failed")
-			return c.Send("failed to get random link", menu)
-		}
-		if link.URL == "" {
-			return c.Send("no links found", menu)
-		}
-		msg := "random ✅\n" + link.URL + "\nID: " + link.ID
-		if link.Resource != "" {
-			msg += "\nResource: " + link.Resource
-		}
-		return c.Send(msg, menu)
+		return w.sendRandom(c, "", nil, "link")
 	})
 
 	w.bot.Handle(&btnRandomArticle, func(c tb.Context) error {
-		ctx := context.Background()
-		link, err := w.api.RandomLink(ctx, "article")
-		if err != nil {
-			logger.L().Error().Err(err).Msg("random article failed")
-			return c.Send("failed to get random article", menu)
-		}
-		if link.URL == "" {
-			return c.Send("no articles found", menu)
-		}
-		msg := "random ✅\n" + link.URL + "\nID: " + link.ID + "\nResource: article"
-		return c.Send(msg, menu)
+		return w.sendRandom(c, "article", nil, "article")
 	})
 
 	w.bot.Handle(&btnRandomVideo, func(c tb.Context) error {
-		ctx := context.Background()
-		link, err := w.api.RandomLink(ctx, "video")
-		if err != nil {
-			logger.L().Error().Err(err).Msg("random video failed")
-			return c.Send("failed to get random video", menu)
-		}
-		if link.URL == "" {
-			return c.Send("no videos found", menu)
-		}
-		msg := "random ✅\n" + link.URL + "\nID: " + link.ID + "\nResource: video"
-		return c.Send(msg, menu)
+		return w.sendRandom(c, "video", nil, "video")
 	})
 
+	w.bot.Handle(&btnLinkAction, w.handleLinkAction)
+
 	w.bot.Handle(tb.OnText, func(c tb.Context) error {
 		text := strings.TrimSpace(c.Text())
 		if text == "" {
 			return nil
 		}
 		if strings.HasPrefix(text, "/") {
-			return c.Send("unknown command, try /save, /viewed, /random", menu)
+			return c.Send("unknown command, try /save, /viewed, /random, /search, /subscribe, /unsubscribe, /digest, /remind, /settings, /token, /deleteme", menu)
+		}
+		if linkID, ok := w.taggedLinkID(c.Message()); ok {
+			return w.handleTagReply(c, linkID)
 		}
-		return c.Send("commands: /save <url>, /viewed <id>, /random [resource]", menu)
+		return w.handleShared(c)
 	})
 
-	w.bot.Handle(tb.OnPhoto, func(c tb.Context) error {
-		return c.Send("commands: /save <url>, /viewed <id>, /random [resource]", menu)
-	})
+	// Photos and other media share links in their captions.
+	w.bot.Handle(tb.OnPhoto, w.handleShared)
+	w.bot.Handle(tb.OnMedia, w.handleShared)
 
 	// reserved for future middleware
 }
+
+// splitTags separates "#tag" words from the rest of a command payload.
+func splitTags(payload string) (args, tags []string) {
+	for _, field := range strings.Fields(payload) {
+		if strings.HasPrefix(field, "#") && len(field) > 1 {
+			tags = append(tags, strings.TrimPrefix(field, "#"))
+			continue
+		}
+		args = append(args, field)
+	}
+	return args, tags
+}
+
+// resolveUser maps the Telegram sender to a user-service user, registering it on first use.
+func (w *Wrapper) resolveUser(ctx context.Context, c tb.Context) (*user.User, error) {
+	sender := c.Sender()
+	if sender == nil {
+		return nil, errors.New("message has no sender")
+	}
+	u, err := w.userService.GetOrCreateUser(ctx, sender.ID, sender.Username, sender.FirstName, sender.LastName)
+	if err != nil {
+		logger.L().Error().Err(err).Int64("telegram_id", sender.ID).Msg("failed to resolve user")
+		return nil, err
+	}
+	return u, nil
+}
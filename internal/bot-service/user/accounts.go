@@ -0,0 +1,26 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+)
+
+// ErrUserNotFound means user-service knows no user with the given id.
+var ErrUserNotFound = errors.New("user not found")
+
+// ExportAccount returns the JSON document of everything user-service stores
+// about the user: profile, settings, reminders and tokens.
+func (c *Client) ExportAccount(ctx context.Context, userID string) ([]byte, error) {
+	var export json.RawMessage
+	if err := c.call(ctx, "GET", "/api/v1/users/"+url.PathEscape(userID)+"/export", nil, &export, ErrUserNotFound); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// DeleteUser deletes the user with everything stored about them, links included.
+func (c *Client) DeleteUser(ctx context.Context, userID string) error {
+	return c.call(ctx, "DELETE", "/api/v1/users/"+url.PathEscape(userID), nil, nil, ErrUserNotFound)
+}
@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
+
+	"github.com/danilovid/linkkeeper/pkg/auth"
 )
 
+// ErrTokenNotFound means the user has no token with the given id.
+var ErrTokenNotFound = errors.New("token not found")
+
 type Client struct {
 	baseURL string
 	http    *http.Client
@@ -23,6 +30,8 @@ type User struct {
 	LastName   string `json:"last_name,omitempty"`
 	CreatedAt  string `json:"created_at"`
 	UpdatedAt  string `json:"updated_at"`
+	// Created is set by GetOrCreateUser for a user registered by the call.
+	Created bool `json:"created,omitempty"`
 }
 
 type CreateUserRequest struct {
@@ -36,10 +45,22 @@ type ExistsResponse struct {
 	Exists bool `json:"exists"`
 }
 
-func NewClient(baseURL string, timeout time.Duration) *Client {
+// Token is a personal API token; Token itself is only set when it was just issued.
+type Token struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name,omitempty"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Token      string     `json:"token,omitempty"`
+}
+
+// NewClient returns a client of the service at baseURL. Requests are signed
+// with signer unless it is nil.
+func NewClient(baseURL string, timeout time.Duration, signer *auth.Signer) *Client {
 	return &Client{
 		baseURL: strings.TrimRight(baseURL, "/"),
-		http:    &http.Client{Timeout: timeout},
+		http:    &http.Client{Timeout: timeout, Transport: signer.Transport(nil)},
 	}
 }
 
@@ -131,3 +152,82 @@ func (c *Client) UserExists(ctx context.Context, telegramID int64) (bool, error)
 
 	return result.Exists, nil
 }
+
+// IssueToken creates a personal API token for the user.
+func (c *Client) IssueToken(ctx context.Context, userID, name string) (*Token, error) {
+	payload, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/users/"+url.PathEscape(userID)+"/tokens", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("api status: %s", resp.Status)
+	}
+
+	var token Token
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (c *Client) ListTokens(ctx context.Context, userID string) ([]Token, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/v1/users/"+url.PathEscape(userID)+"/tokens", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("api status: %s", resp.Status)
+	}
+
+	var tokens []Token
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// RevokeToken deletes one of the user's tokens. ErrTokenNotFound means the user has no such token.
+func (c *Client) RevokeToken(ctx context.Context, userID, tokenID string) error {
+	requestURL := c.baseURL + "/api/v1/users/" + url.PathEscape(userID) + "/tokens/" + url.PathEscape(tokenID)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", requestURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return ErrTokenNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("api status: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoDigest means the user has no digest scheduled.
+	ErrNoDigest = errors.New("no digest scheduled")
+	// ErrReminderNotFound means the user has no reminder with the given id.
+	ErrReminderNotFound = errors.New("reminder not found")
+	// ErrInvalidInput wraps the reason user-service rejected a request as invalid.
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// DigestRequest sets up a digest. Time is the local time of day as "HH:MM";
+// Weekday is only used by weekly digests.
+type DigestRequest struct {
+	Frequency string `json:"frequency"`
+	Weekday   string `json:"weekday,omitempty"`
+	Time      string `json:"time"`
+	Timezone  string `json:"timezone"`
+	Count     int    `json:"count,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+}
+
+// Digest is a user's digest schedule; TelegramID is only set on due digests.
+type Digest struct {
+	UserID     string    `json:"user_id"`
+	TelegramID int64     `json:"telegram_id,omitempty"`
+	Frequency  string    `json:"frequency"`
+	Weekday    string    `json:"weekday,omitempty"`
+	Time       string    `json:"time"`
+	Timezone   string    `json:"timezone"`
+	Count      int       `json:"count"`
+	Resource   string    `json:"resource,omitempty"`
+	NextRunAt  time.Time `json:"next_run_at"`
+}
+
+// Reminder is a one-off reminder of a link; TelegramID is only set on due reminders.
+type Reminder struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	TelegramID int64     `json:"telegram_id,omitempty"`
+	LinkID     string    `json:"link_id"`
+	RemindAt   time.Time `json:"remind_at"`
+}
+
+func (c *Client) GetDigest(ctx context.Context, userID string) (*Digest, error) {
+	var digest Digest
+	if err := c.call(ctx, "GET", "/api/v1/users/"+url.PathEscape(userID)+"/digest", nil, &digest, ErrNoDigest); err != nil {
+		return nil, err
+	}
+	return &digest, nil
+}
+
+// SetDigest creates or replaces the user's digest schedule.
+func (c *Client) SetDigest(ctx context.Context, userID string, digest DigestRequest) (*Digest, error) {
+	var out Digest
+	if err := c.call(ctx, "PUT", "/api/v1/users/"+url.PathEscape(userID)+"/digest", digest, &out, ErrNoDigest); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DisableDigest(ctx context.Context, userID string) error {
+	return c.call(ctx, "DELETE", "/api/v1/users/"+url.PathEscape(userID)+"/digest", nil, nil, ErrNoDigest)
+}
+
+// DigestSent tells user-service a digest went out, so that it schedules the next one.
+func (c *Client) DigestSent(ctx context.Context, userID string, sentAt time.Time) error {
+	body := map[string]time.Time{"sent_at": sentAt}
+	return c.call(ctx, "POST", "/api/v1/users/"+url.PathEscape(userID)+"/digest/sent", body, nil, ErrNoDigest)
+}
+
+func (c *Client) DueDigests(ctx context.Context, before time.Time) ([]Digest, error) {
+	var due []Digest
+	path := "/api/v1/digests/due?before=" + url.QueryEscape(before.UTC().Format(time.RFC3339))
+	if err := c.call(ctx, "GET", path, nil, &due, nil); err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+func (c *Client) AddReminder(ctx context.Context, userID, linkID string, remindAt time.Time) (*Reminder, error) {
+	body := map[string]any{"link_id": linkID, "remind_at": remindAt}
+	var reminder Reminder
+	if err := c.call(ctx, "POST", "/api/v1/users/"+url.PathEscape(userID)+"/reminders", body, &reminder, nil); err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+func (c *Client) ListReminders(ctx context.Context, userID string) ([]Reminder, error) {
+	var reminders []Reminder
+	if err := c.call(ctx, "GET", "/api/v1/users/"+url.PathEscape(userID)+"/reminders", nil, &reminders, nil); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// CancelReminder deletes one of the user's reminders, also once it was sent.
+func (c *Client) CancelReminder(ctx context.Context, userID, reminderID string) error {
+	path := "/api/v1/users/" + url.PathEscape(userID) + "/reminders/" + url.PathEscape(reminderID)
+	return c.call(ctx, "DELETE", path, nil, nil, ErrReminderNotFound)
+}
+
+func (c *Client) DueReminders(ctx context.Context, before time.Time) ([]Reminder, error) {
+	var due []Reminder
+	path := "/api/v1/reminders/due?before=" + url.QueryEscape(before.UTC().Format(time.RFC3339))
+	if err := c.call(ctx, "GET", path, nil, &due, nil); err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+// call sends in as JSON, unless it is nil, and decodes the response into out,
+// unless it is nil. A 404 response returns notFound when it is set; a 400
+// response wraps ErrInvalidInput with the reason user-service gave.
+func (c *Client) call(ctx context.Context, method, path string, in, out any, notFound error) error {
+	var body io.Reader = http.NoBody
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && notFound != nil {
+		return notFound
+	}
+	if resp.StatusCode == http.StatusBadRequest {
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s", ErrInvalidInput, strings.TrimPrefix(strings.TrimSpace(string(reason)), "invalid input: "))
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("api status: %s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
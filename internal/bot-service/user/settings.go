@@ -0,0 +1,70 @@
+package user
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Settings are a user's preferences.
+type Settings struct {
+	Timezone        string         `json:"timezone"`
+	Language        string         `json:"language"`
+	DefaultResource string         `json:"default_resource"`
+	Privacy         Privacy        `json:"privacy"`
+	Digest          DigestSettings `json:"digest"`
+}
+
+type Privacy struct {
+	AutoSave bool `json:"auto_save"`
+}
+
+type DigestSettings struct {
+	Enabled   bool       `json:"enabled"`
+	Frequency string     `json:"frequency,omitempty"`
+	Weekday   string     `json:"weekday,omitempty"`
+	Time      string     `json:"time,omitempty"`
+	Count     int        `json:"count,omitempty"`
+	Resource  string     `json:"resource,omitempty"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+}
+
+// SettingsPatch lists the settings to change; nil fields are left as they are.
+type SettingsPatch struct {
+	Timezone        *string       `json:"timezone,omitempty"`
+	Language        *string       `json:"language,omitempty"`
+	DefaultResource *string       `json:"default_resource,omitempty"`
+	Privacy         *PrivacyPatch `json:"privacy,omitempty"`
+	Digest          *DigestPatch  `json:"digest,omitempty"`
+}
+
+type PrivacyPatch struct {
+	AutoSave *bool `json:"auto_save,omitempty"`
+}
+
+// DigestPatch changes the digest; any change but Enabled false turns it on.
+type DigestPatch struct {
+	Enabled   *bool   `json:"enabled,omitempty"`
+	Frequency *string `json:"frequency,omitempty"`
+	Weekday   *string `json:"weekday,omitempty"`
+	Time      *string `json:"time,omitempty"`
+	Count     *int    `json:"count,omitempty"`
+}
+
+func (c *Client) GetSettings(ctx context.Context, userID string) (*Settings, error) {
+	var settings Settings
+	if err := c.call(ctx, "GET", "/api/v1/users/"+url.PathEscape(userID)+"/settings", nil, &settings, nil); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateSettings changes the settings patch names. ErrInvalidInput is
+// returned with the reason when user-service rejects them.
+func (c *Client) UpdateSettings(ctx context.Context, userID string, patch SettingsPatch) (*Settings, error) {
+	var settings Settings
+	if err := c.call(ctx, "PATCH", "/api/v1/users/"+url.PathEscape(userID)+"/settings", patch, &settings, nil); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
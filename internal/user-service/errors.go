@@ -0,0 +1,16 @@
+package userservice
+
+import "errors"
+
+// ErrNotFound is returned for users and records that do not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidInput wraps the reason input was rejected.
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrConflict is returned when a write clashes with existing data, such as a
+// second user with the same Telegram ID.
+var ErrConflict = errors.New("conflict")
+
+// ErrUnauthorized is returned when a login or session cannot be verified.
+var ErrUnauthorized = errors.New("unauthorized")
@@ -27,3 +27,154 @@ func (u *UserModel) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// APITokenModel is a personal API token. Only a hash of the token is kept;
+// Prefix is its first few characters, so that users can tell tokens apart.
+type APITokenModel struct {
+	ID         uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:char(36);index;not null" json:"user_id"`
+	Name       string     `gorm:"type:varchar(255)" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+func (APITokenModel) TableName() string {
+	return "api_tokens"
+}
+
+func (t *APITokenModel) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Session is a short-lived token minted after a Telegram login.
+type Session struct {
+	Token     string
+	ExpiresAt time.Time
+	User      *UserModel
+}
+
+// Digest frequencies.
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestModel is a user's digest schedule: Count random unread links, of
+// Resource when set, sent every day or every Weekday at Minute past midnight
+// in Timezone. NextRunAt is when the next digest is due.
+type DigestModel struct {
+	UserID    uuid.UUID    `gorm:"type:char(36);primary_key" json:"user_id"`
+	Frequency string       `gorm:"type:varchar(16);not null" json:"frequency"`
+	Weekday   time.Weekday `gorm:"not null;default:0" json:"weekday"`
+	Minute    int          `gorm:"not null" json:"minute"`
+	Timezone  string       `gorm:"type:varchar(64);not null" json:"timezone"`
+	Count     int          `gorm:"not null" json:"count"`
+	Resource  string       `gorm:"type:varchar(32)" json:"resource,omitempty"`
+	NextRunAt time.Time    `gorm:"index;not null" json:"next_run_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+func (DigestModel) TableName() string {
+	return "digests"
+}
+
+// ReminderModel is a one-off reminder to read a link at RemindAt.
+type ReminderModel struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:char(36);index;not null" json:"user_id"`
+	LinkID    string    `gorm:"type:varchar(36);not null" json:"link_id"`
+	RemindAt  time.Time `gorm:"index;not null" json:"remind_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ReminderModel) TableName() string {
+	return "reminders"
+}
+
+func (r *ReminderModel) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// DueDigest is a digest that is due, with the Telegram chat to send it to.
+type DueDigest struct {
+	DigestModel
+	TelegramID int64 `json:"telegram_id"`
+}
+
+// DueReminder is a reminder that is due, with the Telegram chat to send it to.
+type DueReminder struct {
+	ReminderModel
+	TelegramID int64 `json:"telegram_id"`
+}
+
+// SettingsModel holds a user's preferences. Users without a row have the
+// defaults of DefaultSettings.
+type SettingsModel struct {
+	UserID          uuid.UUID `gorm:"type:char(36);primary_key" json:"user_id"`
+	Timezone        string    `gorm:"type:varchar(64);not null" json:"timezone"`
+	Language        string    `gorm:"type:varchar(8);not null" json:"language"`
+	DefaultResource string    `gorm:"type:varchar(32)" json:"default_resource,omitempty"`
+	// AutoSave saves the links of every message sent or forwarded to the bot;
+	// without it only /save does.
+	AutoSave  bool      `gorm:"not null" json:"auto_save"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (SettingsModel) TableName() string {
+	return "user_settings"
+}
+
+// DefaultSettings are the settings of a user who has not changed any.
+func DefaultSettings(userID uuid.UUID) SettingsModel {
+	return SettingsModel{
+		UserID:   userID,
+		Timezone: "UTC",
+		Language: "en",
+		AutoSave: true,
+	}
+}
+
+// Settings are a user's preferences together with their digest schedule,
+// which is nil when the digest is off.
+type Settings struct {
+	SettingsModel
+	Digest *DigestModel
+}
+
+// SettingsPatch lists the settings to change; nil fields are left as they are.
+type SettingsPatch struct {
+	Timezone        *string
+	Language        *string
+	DefaultResource *string
+	AutoSave        *bool
+	Digest          *DigestPatch
+}
+
+// DigestPatch changes the digest schedule. Enabling a digest that is off
+// starts from a daily digest of 5 links at 09:00.
+type DigestPatch struct {
+	Enabled   *bool
+	Frequency *string
+	Weekday   *time.Weekday
+	Minute    *int
+	Count     *int
+	Resource  *string
+}
+
+// AccountExport is everything user-service stores about a user. Settings are
+// the defaults for users who never changed them; Digest is nil when it is off.
+type AccountExport struct {
+	User       UserModel
+	Settings   Settings
+	Reminders  []ReminderModel
+	Tokens     []APITokenModel
+	ExportedAt time.Time
+}
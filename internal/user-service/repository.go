@@ -1,11 +1,58 @@
 package userservice
 
-import "github.com/google/uuid"
+import (
+	"context"
+	"time"
 
+	"github.com/google/uuid"
+)
+
+// Repository stores users. Lookups return ErrNotFound for unknown users.
 type Repository interface {
-	Create(user *UserModel) error
-	GetByID(id uuid.UUID) (*UserModel, error)
-	GetByTelegramID(telegramID int64) (*UserModel, error)
-	Update(user *UserModel) error
-	Exists(telegramID int64) (bool, error)
+	// Create returns ErrConflict when a user with the Telegram ID exists.
+	Create(ctx context.Context, user *UserModel) error
+	// Upsert creates the user or, when a user with the Telegram ID exists,
+	// updates its username and name. It reports whether the user was created.
+	Upsert(ctx context.Context, user *UserModel) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*UserModel, error)
+	GetByTelegramID(ctx context.Context, telegramID int64) (*UserModel, error)
+	Update(ctx context.Context, user *UserModel) error
+	Exists(ctx context.Context, telegramID int64) (bool, error)
+	// Delete removes the user with their tokens, schedules and settings. The
+	// data other services keep about the user, such as links, goes with it
+	// through ON DELETE CASCADE.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// TokenRepository stores personal API tokens by the hash of the token.
+type TokenRepository interface {
+	CreateToken(ctx context.Context, token *APITokenModel) error
+	ListTokens(ctx context.Context, userID uuid.UUID) ([]APITokenModel, error)
+	DeleteToken(ctx context.Context, userID, tokenID uuid.UUID) error
+	GetTokenByHash(ctx context.Context, tokenHash string) (*APITokenModel, error)
+	TouchToken(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error
+}
+
+// ScheduleRepository stores digest schedules and reminders.
+type ScheduleRepository interface {
+	// SaveDigest creates or replaces the user's digest schedule.
+	SaveDigest(ctx context.Context, digest *DigestModel) error
+	GetDigest(ctx context.Context, userID uuid.UUID) (*DigestModel, error)
+	DeleteDigest(ctx context.Context, userID uuid.UUID) error
+	SetDigestNextRun(ctx context.Context, userID uuid.UUID, next time.Time) error
+	// DueDigests returns up to limit digests due before the given time, earliest first.
+	DueDigests(ctx context.Context, before time.Time, limit int) ([]DueDigest, error)
+
+	CreateReminder(ctx context.Context, reminder *ReminderModel) error
+	ListReminders(ctx context.Context, userID uuid.UUID) ([]ReminderModel, error)
+	DeleteReminder(ctx context.Context, userID, reminderID uuid.UUID) error
+	// DueReminders returns up to limit reminders due before the given time, earliest first.
+	DueReminders(ctx context.Context, before time.Time, limit int) ([]DueReminder, error)
+}
+
+// SettingsRepository stores user settings.
+type SettingsRepository interface {
+	// GetSettings returns ErrNotFound for users who never changed their settings.
+	GetSettings(ctx context.Context, userID uuid.UUID) (*SettingsModel, error)
+	SaveSettings(ctx context.Context, settings *SettingsModel) error
 }
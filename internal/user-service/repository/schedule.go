@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+type scheduleRepo struct {
+	db *gorm.DB
+}
+
+func NewScheduleRepo(db *gorm.DB) userservice.ScheduleRepository {
+	return &scheduleRepo{db: db}
+}
+
+func (r *scheduleRepo) SaveDigest(ctx context.Context, digest *userservice.DigestModel) error {
+	return r.db.WithContext(ctx).Save(digest).Error
+}
+
+func (r *scheduleRepo) GetDigest(ctx context.Context, userID uuid.UUID) (*userservice.DigestModel, error) {
+	var digest userservice.DigestModel
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&digest).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, userservice.ErrNotFound
+		}
+		return nil, err
+	}
+	return &digest, nil
+}
+
+func (r *scheduleRepo) DeleteDigest(ctx context.Context, userID uuid.UUID) error {
+	res := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&userservice.DigestModel{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return userservice.ErrNotFound
+	}
+	return nil
+}
+
+func (r *scheduleRepo) SetDigestNextRun(ctx context.Context, userID uuid.UUID, next time.Time) error {
+	res := r.db.WithContext(ctx).Model(&userservice.DigestModel{}).Where("user_id = ?", userID).Update("next_run_at", next)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return userservice.ErrNotFound
+	}
+	return nil
+}
+
+func (r *scheduleRepo) DueDigests(ctx context.Context, before time.Time, limit int) ([]userservice.DueDigest, error) {
+	var due []userservice.DueDigest
+	err := r.db.WithContext(ctx).Table("digests").
+		Select("digests.*, users.telegram_id").
+		Joins("JOIN users ON users.id = digests.user_id").
+		Where("digests.next_run_at <= ?", before.UTC()).
+		Order("digests.next_run_at").
+		Limit(limit).
+		Scan(&due).Error
+	if err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+func (r *scheduleRepo) CreateReminder(ctx context.Context, reminder *userservice.ReminderModel) error {
+	return r.db.WithContext(ctx).Create(reminder).Error
+}
+
+func (r *scheduleRepo) ListReminders(ctx context.Context, userID uuid.UUID) ([]userservice.ReminderModel, error) {
+	var reminders []userservice.ReminderModel
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("remind_at").Find(&reminders).Error
+	if err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (r *scheduleRepo) DeleteReminder(ctx context.Context, userID, reminderID uuid.UUID) error {
+	res := r.db.WithContext(ctx).Where("user_id = ? AND id = ?", userID, reminderID).Delete(&userservice.ReminderModel{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return userservice.ErrNotFound
+	}
+	return nil
+}
+
+func (r *scheduleRepo) DueReminders(ctx context.Context, before time.Time, limit int) ([]userservice.DueReminder, error) {
+	var due []userservice.DueReminder
+	err := r.db.WithContext(ctx).Table("reminders").
+		Select("reminders.*, users.telegram_id").
+		Joins("JOIN users ON users.id = reminders.user_id").
+		Where("reminders.remind_at <= ?", before.UTC()).
+		Order("reminders.remind_at").
+		Limit(limit).
+		Scan(&due).Error
+	if err != nil {
+		return nil, err
+	}
+	return due, nil
+}
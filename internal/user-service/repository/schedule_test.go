@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+func TestScheduleRepo_Digests(t *testing.T) {
+	db := setupTestDB(t)
+	users := NewUserRepo(db)
+	repo := NewScheduleRepo(db)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	early := &userservice.UserModel{TelegramID: 1}
+	late := &userservice.UserModel{TelegramID: 2}
+	require.NoError(t, users.Create(context.Background(), early))
+	require.NoError(t, users.Create(context.Background(), late))
+	require.NoError(t, repo.SaveDigest(context.Background(), &userservice.DigestModel{
+		UserID: early.ID, Frequency: userservice.DigestDaily, Minute: 9 * 60, Timezone: "UTC", Count: 5,
+		NextRunAt: now.Add(-time.Hour),
+	}))
+	require.NoError(t, repo.SaveDigest(context.Background(), &userservice.DigestModel{
+		UserID: late.ID, Frequency: userservice.DigestDaily, Minute: 18 * 60, Timezone: "UTC", Count: 5,
+		NextRunAt: now.Add(time.Hour),
+	}))
+
+	due, err := repo.DueDigests(context.Background(), now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, early.ID, due[0].UserID)
+	assert.Equal(t, int64(1), due[0].TelegramID)
+	assert.Equal(t, 9*60, due[0].Minute)
+
+	// Saving again replaces the schedule.
+	require.NoError(t, repo.SaveDigest(context.Background(), &userservice.DigestModel{
+		UserID: early.ID, Frequency: userservice.DigestWeekly, Weekday: time.Monday, Minute: 8 * 60, Timezone: "UTC", Count: 3,
+		NextRunAt: now.Add(-time.Minute),
+	}))
+	got, err := repo.GetDigest(context.Background(), early.ID)
+	require.NoError(t, err)
+	assert.Equal(t, userservice.DigestWeekly, got.Frequency)
+	assert.Equal(t, time.Monday, got.Weekday)
+
+	require.NoError(t, repo.SetDigestNextRun(context.Background(), early.ID, now.Add(24*time.Hour)))
+	due, err = repo.DueDigests(context.Background(), now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	require.NoError(t, repo.DeleteDigest(context.Background(), early.ID))
+	_, err = repo.GetDigest(context.Background(), early.ID)
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteDigest(context.Background(), early.ID), userservice.ErrNotFound)
+	assert.ErrorIs(t, repo.SetDigestNextRun(context.Background(), early.ID, now), userservice.ErrNotFound)
+}
+
+func TestScheduleRepo_Reminders(t *testing.T) {
+	db := setupTestDB(t)
+	users := NewUserRepo(db)
+	repo := NewScheduleRepo(db)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	user := &userservice.UserModel{TelegramID: 42}
+	require.NoError(t, users.Create(context.Background(), user))
+	soon := &userservice.ReminderModel{UserID: user.ID, LinkID: uuid.NewString(), RemindAt: now.Add(-time.Minute)}
+	later := &userservice.ReminderModel{UserID: user.ID, LinkID: uuid.NewString(), RemindAt: now.Add(time.Hour)}
+	require.NoError(t, repo.CreateReminder(context.Background(), later))
+	require.NoError(t, repo.CreateReminder(context.Background(), soon))
+	assert.NotEqual(t, uuid.Nil, soon.ID)
+
+	list, err := repo.ListReminders(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, soon.ID, list[0].ID)
+
+	due, err := repo.DueReminders(context.Background(), now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, soon.LinkID, due[0].LinkID)
+	assert.Equal(t, int64(42), due[0].TelegramID)
+
+	assert.ErrorIs(t, repo.DeleteReminder(context.Background(), uuid.New(), soon.ID), userservice.ErrNotFound)
+	require.NoError(t, repo.DeleteReminder(context.Background(), user.ID, soon.ID))
+	due, err = repo.DueReminders(context.Background(), now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
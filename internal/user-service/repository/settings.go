@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+type settingsRepo struct {
+	db *gorm.DB
+}
+
+func NewSettingsRepo(db *gorm.DB) userservice.SettingsRepository {
+	return &settingsRepo{db: db}
+}
+
+func (r *settingsRepo) GetSettings(ctx context.Context, userID uuid.UUID) (*userservice.SettingsModel, error) {
+	var settings userservice.SettingsModel
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settings).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, userservice.ErrNotFound
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *settingsRepo) SaveSettings(ctx context.Context, settings *userservice.SettingsModel) error {
+	return r.db.WithContext(ctx).Save(settings).Error
+}
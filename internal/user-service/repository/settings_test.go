@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+func TestSettingsRepo_SaveAndGet(t *testing.T) {
+	db := setupTestDB(t)
+	users := NewUserRepo(db)
+	repo := NewSettingsRepo(db)
+
+	user := &userservice.UserModel{TelegramID: 1}
+	require.NoError(t, users.Create(context.Background(), user))
+
+	_, err := repo.GetSettings(context.Background(), user.ID)
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+
+	// A new row keeps false, not the column's default.
+	settings := userservice.DefaultSettings(user.ID)
+	settings.AutoSave = false
+	require.NoError(t, repo.SaveSettings(context.Background(), &settings))
+	got, err := repo.GetSettings(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.False(t, got.AutoSave)
+
+	got.Timezone = "Europe/Berlin"
+	got.AutoSave = true
+	require.NoError(t, repo.SaveSettings(context.Background(), got))
+	got, err = repo.GetSettings(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", got.Timezone)
+	assert.True(t, got.AutoSave)
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+type tokenRepo struct {
+	db *gorm.DB
+}
+
+func NewTokenRepo(db *gorm.DB) userservice.TokenRepository {
+	return &tokenRepo{db: db}
+}
+
+func (r *tokenRepo) CreateToken(ctx context.Context, token *userservice.APITokenModel) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *tokenRepo) ListTokens(ctx context.Context, userID uuid.UUID) ([]userservice.APITokenModel, error) {
+	var tokens []userservice.APITokenModel
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at desc").Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *tokenRepo) DeleteToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	res := r.db.WithContext(ctx).Where("user_id = ? AND id = ?", userID, tokenID).Delete(&userservice.APITokenModel{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return userservice.ErrNotFound
+	}
+	return nil
+}
+
+func (r *tokenRepo) GetTokenByHash(ctx context.Context, tokenHash string) (*userservice.APITokenModel, error) {
+	var token userservice.APITokenModel
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, userservice.ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *tokenRepo) TouchToken(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&userservice.APITokenModel{}).Where("id = ?", tokenID).Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+func TestTokenRepo_Lifecycle(t *testing.T) {
+	repo := NewTokenRepo(setupTestDB(t))
+	owner := uuid.New()
+
+	token := &userservice.APITokenModel{UserID: owner, Name: "laptop", Prefix: "lk_abcde", TokenHash: "hash-1"}
+	require.NoError(t, repo.CreateToken(context.Background(), token))
+	assert.NotEqual(t, uuid.Nil, token.ID)
+	require.NoError(t, repo.CreateToken(context.Background(), &userservice.APITokenModel{UserID: uuid.New(), Prefix: "lk_fghij", TokenHash: "hash-2"}))
+
+	found, err := repo.GetTokenByHash(context.Background(), "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.Nil(t, found.LastUsedAt)
+
+	usedAt := time.Now().Truncate(time.Second)
+	require.NoError(t, repo.TouchToken(context.Background(), token.ID, usedAt))
+	found, err = repo.GetTokenByHash(context.Background(), "hash-1")
+	require.NoError(t, err)
+	require.NotNil(t, found.LastUsedAt)
+	assert.True(t, usedAt.Equal(*found.LastUsedAt))
+
+	tokens, err := repo.ListTokens(context.Background(), owner)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "laptop", tokens[0].Name)
+
+	assert.ErrorIs(t, repo.DeleteToken(context.Background(), uuid.New(), token.ID), userservice.ErrNotFound, "only the owner can delete a token")
+	require.NoError(t, repo.DeleteToken(context.Background(), owner, token.ID))
+	_, err = repo.GetTokenByHash(context.Background(), "hash-1")
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+}
+
+func TestTokenRepo_DuplicateHash(t *testing.T) {
+	repo := NewTokenRepo(setupTestDB(t))
+
+	require.NoError(t, repo.CreateToken(context.Background(), &userservice.APITokenModel{UserID: uuid.New(), Prefix: "lk_abcde", TokenHash: "hash"}))
+	err := repo.CreateToken(context.Background(), &userservice.APITokenModel{UserID: uuid.New(), Prefix: "lk_abcde", TokenHash: "hash"})
+
+	assert.Error(t, err)
+}
@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	userservice "github.com/danilovid/linkkeeper/internal/user-service"
 )
@@ -17,43 +20,111 @@ func NewUserRepo(db *gorm.DB) userservice.Repository {
 	return &userRepo{db: db}
 }
 
-func (r *userRepo) Create(user *userservice.UserModel) error {
-	return r.db.Create(user).Error
+func (r *userRepo) Create(ctx context.Context, user *userservice.UserModel) error {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(user)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: telegram user %d is registered already", userservice.ErrConflict, user.TelegramID)
+	}
+	return nil
 }
 
-func (r *userRepo) GetByID(id uuid.UUID) (*userservice.UserModel, error) {
+// profileChanged limits the update of Upsert to users whose profile differs,
+// so that an unchanged user is not written at all.
+const profileChanged = "users.username IS DISTINCT FROM excluded.username OR " +
+	"users.first_name IS DISTINCT FROM excluded.first_name OR " +
+	"users.last_name IS DISTINCT FROM excluded.last_name"
+
+// Upsert inserts the user in a single statement, so that concurrent calls for
+// one Telegram user cannot clash on its unique index. A user that exists
+// already gets the given username and name; user is then filled in with it.
+func (r *userRepo) Upsert(ctx context.Context, user *userservice.UserModel) (bool, error) {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	newID := user.ID
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "telegram_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"username", "first_name", "last_name", "updated_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: profileChanged}}},
+	}, clause.Returning{}).Create(user)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		// The user exists and its profile is unchanged.
+		existing, err := r.GetByTelegramID(ctx, user.TelegramID)
+		if err != nil {
+			return false, err
+		}
+		*user = *existing
+		return false, nil
+	}
+	// An updated row keeps its id; only an inserted one has the new id.
+	return user.ID == newID, nil
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (*userservice.UserModel, error) {
 	var user userservice.UserModel
-	err := r.db.Where("id = ?", id).First(&user).Error
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, userservice.ErrNotFound
 		}
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepo) GetByTelegramID(telegramID int64) (*userservice.UserModel, error) {
+func (r *userRepo) GetByTelegramID(ctx context.Context, telegramID int64) (*userservice.UserModel, error) {
 	var user userservice.UserModel
-	err := r.db.Where("telegram_id = ?", telegramID).First(&user).Error
+	err := r.db.WithContext(ctx).Where("telegram_id = ?", telegramID).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, userservice.ErrNotFound
 		}
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepo) Update(user *userservice.UserModel) error {
-	return r.db.Save(user).Error
+func (r *userRepo) Update(ctx context.Context, user *userservice.UserModel) error {
+	return r.db.WithContext(ctx).Save(user).Error
 }
 
-func (r *userRepo) Exists(telegramID int64) (bool, error) {
+func (r *userRepo) Exists(ctx context.Context, telegramID int64) (bool, error) {
 	var count int64
-	err := r.db.Model(&userservice.UserModel{}).Where("telegram_id = ?", telegramID).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&userservice.UserModel{}).Where("telegram_id = ?", telegramID).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
+
+func (r *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The foreign keys of these tables cascade as well; deleting them
+		// here keeps databases created by AutoMigrate alone clean too.
+		owned := []any{
+			&userservice.APITokenModel{},
+			&userservice.DigestModel{},
+			&userservice.ReminderModel{},
+			&userservice.SettingsModel{},
+		}
+		for _, model := range owned {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		res := tx.Where("id = ?", id).Delete(&userservice.UserModel{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return userservice.ErrNotFound
+		}
+		return nil
+	})
+}
@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"context"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +20,8 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
 
-	err = db.AutoMigrate(&userservice.UserModel{})
+	err = db.AutoMigrate(&userservice.UserModel{}, &userservice.APITokenModel{},
+		&userservice.DigestModel{}, &userservice.ReminderModel{}, &userservice.SettingsModel{})
 	require.NoError(t, err)
 
 	return db
@@ -33,7 +38,7 @@ func TestUserRepo_Create(t *testing.T) {
 		LastName:   "User",
 	}
 
-	err := repo.Create(user)
+	err := repo.Create(context.Background(), user)
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, user.ID)
 	assert.NotZero(t, user.CreatedAt)
@@ -48,15 +53,15 @@ func TestUserRepo_Create_DuplicateTelegramID(t *testing.T) {
 		TelegramID: 123456789,
 		Username:   "user1",
 	}
-	err := repo.Create(user1)
+	err := repo.Create(context.Background(), user1)
 	require.NoError(t, err)
 
 	user2 := &userservice.UserModel{
 		TelegramID: 123456789,
 		Username:   "user2",
 	}
-	err = repo.Create(user2)
-	assert.Error(t, err)
+	err = repo.Create(context.Background(), user2)
+	assert.ErrorIs(t, err, userservice.ErrConflict)
 }
 
 func TestUserRepo_GetByID(t *testing.T) {
@@ -67,10 +72,10 @@ func TestUserRepo_GetByID(t *testing.T) {
 		TelegramID: 123456789,
 		Username:   "testuser",
 	}
-	err := repo.Create(user)
+	err := repo.Create(context.Background(), user)
 	require.NoError(t, err)
 
-	found, err := repo.GetByID(user.ID)
+	found, err := repo.GetByID(context.Background(), user.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, user.ID, found.ID)
 	assert.Equal(t, user.TelegramID, found.TelegramID)
@@ -81,9 +86,19 @@ func TestUserRepo_GetByID_NotFound(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepo(db)
 
-	_, err := repo.GetByID(uuid.New())
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "not found")
+	_, err := repo.GetByID(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+}
+
+func TestUserRepo_GetByID_CanceledContext(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepo(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, context.Canceled)
 }
 
 func TestUserRepo_GetByTelegramID(t *testing.T) {
@@ -95,10 +110,10 @@ func TestUserRepo_GetByTelegramID(t *testing.T) {
 		Username:   "testuser",
 		FirstName:  "Test",
 	}
-	err := repo.Create(user)
+	err := repo.Create(context.Background(), user)
 	require.NoError(t, err)
 
-	found, err := repo.GetByTelegramID(123456789)
+	found, err := repo.GetByTelegramID(context.Background(), 123456789)
 	assert.NoError(t, err)
 	assert.Equal(t, user.ID, found.ID)
 	assert.Equal(t, user.TelegramID, found.TelegramID)
@@ -110,9 +125,8 @@ func TestUserRepo_GetByTelegramID_NotFound(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepo(db)
 
-	_, err := repo.GetByTelegramID(999999999)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "not found")
+	_, err := repo.GetByTelegramID(context.Background(), 999999999)
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
 }
 
 func TestUserRepo_Update(t *testing.T) {
@@ -124,16 +138,16 @@ func TestUserRepo_Update(t *testing.T) {
 		Username:   "oldusername",
 		FirstName:  "Old",
 	}
-	err := repo.Create(user)
+	err := repo.Create(context.Background(), user)
 	require.NoError(t, err)
 
 	user.Username = "newusername"
 	user.FirstName = "New"
 
-	err = repo.Update(user)
+	err = repo.Update(context.Background(), user)
 	assert.NoError(t, err)
 
-	found, err := repo.GetByID(user.ID)
+	found, err := repo.GetByID(context.Background(), user.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "newusername", found.Username)
 	assert.Equal(t, "New", found.FirstName)
@@ -143,7 +157,7 @@ func TestUserRepo_Exists(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepo(db)
 
-	exists, err := repo.Exists(123456789)
+	exists, err := repo.Exists(context.Background(), 123456789)
 	require.NoError(t, err)
 	assert.False(t, exists)
 
@@ -151,10 +165,116 @@ func TestUserRepo_Exists(t *testing.T) {
 		TelegramID: 123456789,
 		Username:   "testuser",
 	}
-	err = repo.Create(user)
+	err = repo.Create(context.Background(), user)
 	require.NoError(t, err)
 
-	exists, err = repo.Exists(123456789)
+	exists, err = repo.Exists(context.Background(), 123456789)
 	require.NoError(t, err)
 	assert.True(t, exists)
 }
+
+func TestUserRepo_Upsert(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepo(db)
+	ctx := context.Background()
+
+	user := &userservice.UserModel{TelegramID: 42, Username: "ann", FirstName: "Ann"}
+	created, err := repo.Upsert(ctx, user)
+	require.NoError(t, err)
+	assert.True(t, created)
+	id := user.ID
+
+	unchanged := &userservice.UserModel{TelegramID: 42, Username: "ann", FirstName: "Ann"}
+	created, err = repo.Upsert(ctx, unchanged)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, id, unchanged.ID)
+	assert.Equal(t, user.UpdatedAt.Unix(), unchanged.UpdatedAt.Unix(), "an unchanged user is not written")
+
+	renamed := &userservice.UserModel{TelegramID: 42, Username: "ann_b", FirstName: "Ann", LastName: "B"}
+	created, err = repo.Upsert(ctx, renamed)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, id, renamed.ID)
+
+	found, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "ann_b", found.Username)
+	assert.Equal(t, "B", found.LastName)
+	assert.Equal(t, user.CreatedAt.Unix(), found.CreatedAt.Unix())
+}
+
+func TestUserRepo_Upsert_Concurrent(t *testing.T) {
+	// Connections to ":memory:" would each see a database of their own.
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "users.db")+"?_busy_timeout=5000&_journal_mode=WAL"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&userservice.UserModel{}))
+	repo := NewUserRepo(db)
+
+	const callers = 20
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+		ids     = make(map[uuid.UUID]bool)
+	)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user := &userservice.UserModel{TelegramID: 42, Username: "ann"}
+			isNew, err := repo.Upsert(context.Background(), user)
+			assert.NoError(t, err)
+			mu.Lock()
+			defer mu.Unlock()
+			if isNew {
+				created++
+			}
+			ids[user.ID] = true
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, created)
+	assert.Len(t, ids, 1)
+	var count int64
+	require.NoError(t, db.Model(&userservice.UserModel{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestUserRepo_Delete(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepo(db)
+	ctx := context.Background()
+
+	user := &userservice.UserModel{TelegramID: 42}
+	require.NoError(t, repo.Create(ctx, user))
+	other := &userservice.UserModel{TelegramID: 43}
+	require.NoError(t, repo.Create(ctx, other))
+	for _, id := range []uuid.UUID{user.ID, other.ID} {
+		require.NoError(t, NewTokenRepo(db).CreateToken(ctx, &userservice.APITokenModel{UserID: id, Prefix: "lk_", TokenHash: id.String()}))
+		require.NoError(t, NewScheduleRepo(db).SaveDigest(ctx, &userservice.DigestModel{UserID: id, Frequency: userservice.DigestDaily, Timezone: "UTC"}))
+		require.NoError(t, NewSettingsRepo(db).SaveSettings(ctx, &userservice.SettingsModel{UserID: id, Timezone: "UTC"}))
+		require.NoError(t, NewScheduleRepo(db).CreateReminder(ctx, &userservice.ReminderModel{UserID: id, LinkID: uuid.NewString(), RemindAt: time.Now()}))
+	}
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+
+	_, err := repo.GetByID(ctx, user.ID)
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+	tokens, err := NewTokenRepo(db).ListTokens(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+	_, err = NewScheduleRepo(db).GetDigest(ctx, user.ID)
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+	_, err = NewSettingsRepo(db).GetSettings(ctx, user.ID)
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+	reminders, err := NewScheduleRepo(db).ListReminders(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, reminders)
+
+	_, err = NewSettingsRepo(db).GetSettings(ctx, other.ID)
+	assert.NoError(t, err, "other users keep their data")
+
+	assert.ErrorIs(t, repo.Delete(ctx, user.ID), userservice.ErrNotFound)
+}
@@ -0,0 +1,145 @@
+// Package telegram verifies the login data Telegram hands to websites (the
+// Login Widget) and to Mini Apps (WebApp initData).
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+// Identity is the Telegram user login data vouches for.
+type Identity struct {
+	ID        int64
+	Username  string
+	FirstName string
+	LastName  string
+	AuthDate  time.Time
+}
+
+// Verifier checks login data against the bot token it was signed with.
+// Errors wrap userservice.ErrUnauthorized.
+type Verifier struct {
+	botToken string
+	maxAge   time.Duration
+	now      func() time.Time
+}
+
+// NewVerifier returns a Verifier that accepts login data signed with
+// botToken and at most maxAge old.
+func NewVerifier(botToken string, maxAge time.Duration) *Verifier {
+	return &Verifier{botToken: botToken, maxAge: maxAge, now: time.Now}
+}
+
+// VerifyLoginWidget checks the fields the Login Widget passes to its
+// callback: id, first_name, last_name, username, photo_url, auth_date and hash.
+// The widget signs them with the SHA-256 of the bot token.
+func (v *Verifier) VerifyLoginWidget(fields map[string]string) (Identity, error) {
+	secret := sha256.Sum256([]byte(v.botToken))
+	if err := v.checkHash(fields, secret[:]); err != nil {
+		return Identity{}, err
+	}
+	id, err := strconv.ParseInt(fields["id"], 10, 64)
+	if err != nil || id == 0 {
+		return Identity{}, fmt.Errorf("%w: login data has no user id", userservice.ErrUnauthorized)
+	}
+	authDate, err := v.checkAge(fields["auth_date"])
+	if err != nil {
+		return Identity{}, err
+	}
+	return Identity{
+		ID:        id,
+		Username:  fields["username"],
+		FirstName: fields["first_name"],
+		LastName:  fields["last_name"],
+		AuthDate:  authDate,
+	}, nil
+}
+
+// webAppUser is the user field of Mini App initData.
+type webAppUser struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// VerifyWebAppInitData checks the query string a Mini App gets as
+// Telegram.WebApp.initData. It is signed with HMAC-SHA256("WebAppData", bot token).
+func (v *Verifier) VerifyWebAppInitData(initData string) (Identity, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: malformed init data", userservice.ErrUnauthorized)
+	}
+	fields := make(map[string]string, len(values))
+	for key := range values {
+		fields[key] = values.Get(key)
+	}
+	mac := hmac.New(sha256.New, []byte("WebAppData"))
+	mac.Write([]byte(v.botToken))
+	if err := v.checkHash(fields, mac.Sum(nil)); err != nil {
+		return Identity{}, err
+	}
+	var user webAppUser
+	if err := json.Unmarshal([]byte(fields["user"]), &user); err != nil || user.ID == 0 {
+		return Identity{}, fmt.Errorf("%w: init data has no user", userservice.ErrUnauthorized)
+	}
+	authDate, err := v.checkAge(fields["auth_date"])
+	if err != nil {
+		return Identity{}, err
+	}
+	return Identity{
+		ID:        user.ID,
+		Username:  user.Username,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		AuthDate:  authDate,
+	}, nil
+}
+
+// checkHash compares the hash field with the HMAC-SHA256, keyed with secret,
+// of the other fields sorted by key and joined as "key=value" lines.
+func (v *Verifier) checkHash(fields map[string]string, secret []byte) error {
+	got, err := hex.DecodeString(fields["hash"])
+	if err != nil || len(got) == 0 {
+		return fmt.Errorf("%w: login data is not signed", userservice.ErrUnauthorized)
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, key+"="+fields[key])
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("%w: login data signature does not match", userservice.ErrUnauthorized)
+	}
+	return nil
+}
+
+func (v *Verifier) checkAge(raw string) (time.Time, error) {
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: login data has no auth_date", userservice.ErrUnauthorized)
+	}
+	authDate := time.Unix(seconds, 0)
+	if v.now().Sub(authDate) > v.maxAge {
+		return time.Time{}, fmt.Errorf("%w: login data expired", userservice.ErrUnauthorized)
+	}
+	return authDate, nil
+}
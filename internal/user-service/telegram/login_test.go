@@ -0,0 +1,132 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+const testBotToken = "123456:test-bot-token"
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestVerifier() *Verifier {
+	v := NewVerifier(testBotToken, time.Hour)
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+// sign sets the hash of fields the way Telegram does.
+func sign(fields map[string]string, secret []byte) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, key+"="+fields[key])
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	fields["hash"] = hex.EncodeToString(mac.Sum(nil))
+}
+
+func widgetFields(authDate time.Time) map[string]string {
+	fields := map[string]string{
+		"id":         "42",
+		"first_name": "Ann",
+		"username":   "ann",
+		"auth_date":  strconv.FormatInt(authDate.Unix(), 10),
+	}
+	secret := sha256.Sum256([]byte(testBotToken))
+	sign(fields, secret[:])
+	return fields
+}
+
+func webAppInitData(authDate time.Time) string {
+	fields := map[string]string{
+		"query_id":  "AAH",
+		"user":      `{"id":42,"first_name":"Ann","username":"ann"}`,
+		"auth_date": strconv.FormatInt(authDate.Unix(), 10),
+	}
+	mac := hmac.New(sha256.New, []byte("WebAppData"))
+	mac.Write([]byte(testBotToken))
+	sign(fields, mac.Sum(nil))
+	values := url.Values{}
+	for key, value := range fields {
+		values.Set(key, value)
+	}
+	return values.Encode()
+}
+
+func TestVerifyLoginWidget(t *testing.T) {
+	identity, err := newTestVerifier().VerifyLoginWidget(widgetFields(testNow.Add(-time.Minute)))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), identity.ID)
+	assert.Equal(t, "ann", identity.Username)
+	assert.Equal(t, "Ann", identity.FirstName)
+}
+
+func TestVerifyLoginWidget_Tampered(t *testing.T) {
+	fields := widgetFields(testNow.Add(-time.Minute))
+	fields["id"] = "43"
+
+	_, err := newTestVerifier().VerifyLoginWidget(fields)
+	assert.ErrorIs(t, err, userservice.ErrUnauthorized)
+}
+
+func TestVerifyLoginWidget_Unsigned(t *testing.T) {
+	fields := widgetFields(testNow.Add(-time.Minute))
+	delete(fields, "hash")
+
+	_, err := newTestVerifier().VerifyLoginWidget(fields)
+	assert.ErrorIs(t, err, userservice.ErrUnauthorized)
+}
+
+func TestVerifyLoginWidget_Expired(t *testing.T) {
+	_, err := newTestVerifier().VerifyLoginWidget(widgetFields(testNow.Add(-2 * time.Hour)))
+	assert.ErrorIs(t, err, userservice.ErrUnauthorized)
+}
+
+func TestVerifyLoginWidget_OtherBot(t *testing.T) {
+	v := NewVerifier("654321:other-bot-token", time.Hour)
+	v.now = func() time.Time { return testNow }
+
+	_, err := v.VerifyLoginWidget(widgetFields(testNow.Add(-time.Minute)))
+	assert.ErrorIs(t, err, userservice.ErrUnauthorized)
+}
+
+func TestVerifyWebAppInitData(t *testing.T) {
+	identity, err := newTestVerifier().VerifyWebAppInitData(webAppInitData(testNow.Add(-time.Minute)))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), identity.ID)
+	assert.Equal(t, "ann", identity.Username)
+}
+
+func TestVerifyWebAppInitData_WidgetSignature(t *testing.T) {
+	// Login Widget data must not pass as initData: the two use different keys.
+	values := url.Values{}
+	for key, value := range widgetFields(testNow.Add(-time.Minute)) {
+		values.Set(key, value)
+	}
+
+	_, err := newTestVerifier().VerifyWebAppInitData(values.Encode())
+	assert.ErrorIs(t, err, userservice.ErrUnauthorized)
+}
+
+func TestVerifyWebAppInitData_Expired(t *testing.T) {
+	_, err := newTestVerifier().VerifyWebAppInitData(webAppInitData(testNow.Add(-2 * time.Hour)))
+	assert.ErrorIs(t, err, userservice.ErrUnauthorized)
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+// AccountExportResponse is everything user-service stores about a user.
+// Tokens are listed without the tokens themselves, which are not stored.
+type AccountExportResponse struct {
+	ExportedAt time.Time          `json:"exported_at"`
+	User       UserResponse       `json:"user"`
+	Settings   SettingsResponse   `json:"settings"`
+	Reminders  []ReminderResponse `json:"reminders"`
+	Tokens     []TokenResponse    `json:"tokens"`
+}
+
+// ExportAccount answers with the user's data as a JSON file to download.
+func (s *Server) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	export, err := s.accounts.ExportAccount(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "failed to export account")
+		return
+	}
+
+	resp := AccountExportResponse{
+		ExportedAt: export.ExportedAt,
+		User:       toUserResponse(&export.User),
+		Settings:   toSettingsResponse(&export.Settings),
+		Reminders:  make([]ReminderResponse, 0, len(export.Reminders)),
+		Tokens:     make([]TokenResponse, 0, len(export.Tokens)),
+	}
+	for _, reminder := range export.Reminders {
+		resp.Reminders = append(resp.Reminders, toReminderResponse(reminder, 0))
+	}
+	for _, token := range export.Tokens {
+		resp.Tokens = append(resp.Tokens, toTokenResponse(token))
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="linkkeeper-account.json"`)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DeleteAccount deletes the user and all their data for good.
+func (s *Server) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	if err := s.accounts.DeleteAccount(r.Context(), id); err != nil {
+		writeError(w, err, "failed to delete account")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
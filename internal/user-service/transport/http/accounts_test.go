@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+type MockAccountUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAccountUsecase) ExportAccount(_ context.Context, userID uuid.UUID) (*userservice.AccountExport, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.AccountExport), args.Error(1)
+}
+
+func (m *MockAccountUsecase) DeleteAccount(_ context.Context, userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func TestExportAccount(t *testing.T) {
+	mockAccounts := new(MockAccountUsecase)
+	server := NewServer(new(MockUsecase), WithAccounts(mockAccounts))
+	userID, tokenID, reminderID, linkID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mockAccounts.On("ExportAccount", userID).Return(&userservice.AccountExport{
+		User:     userservice.UserModel{ID: userID, TelegramID: 42, Username: "ann", CreatedAt: at, UpdatedAt: at},
+		Settings: userservice.Settings{SettingsModel: userservice.DefaultSettings(userID)},
+		Reminders: []userservice.ReminderModel{
+			{ID: reminderID, UserID: userID, LinkID: linkID.String(), RemindAt: at},
+		},
+		Tokens:     []userservice.APITokenModel{{ID: tokenID, UserID: userID, Name: "laptop", Prefix: "lk_ab", TokenHash: "secret", CreatedAt: at}},
+		ExportedAt: at,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/users/"+userID.String()+"/export", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="linkkeeper-account.json"`, w.Header().Get("Content-Disposition"))
+	assert.JSONEq(t, `{
+		"exported_at": "2026-03-01T12:00:00Z",
+		"user": {"id": "`+userID.String()+`", "telegram_id": 42, "username": "ann",
+			"created_at": "2026-03-01T12:00:00Z", "updated_at": "2026-03-01T12:00:00Z"},
+		"settings": {"timezone": "UTC", "language": "en", "default_resource": "",
+			"privacy": {"auto_save": true}, "digest": {"enabled": false}},
+		"reminders": [{"id": "`+reminderID.String()+`", "user_id": "`+userID.String()+`",
+			"link_id": "`+linkID.String()+`", "remind_at": "2026-03-01T12:00:00Z"}],
+		"tokens": [{"id": "`+tokenID.String()+`", "name": "laptop", "prefix": "lk_ab", "created_at": "2026-03-01T12:00:00Z"}]
+	}`, w.Body.String())
+}
+
+func TestDeleteAccount(t *testing.T) {
+	mockAccounts := new(MockAccountUsecase)
+	server := NewServer(new(MockUsecase), WithAccounts(mockAccounts))
+	userID, unknown := uuid.New(), uuid.New()
+	mockAccounts.On("DeleteAccount", userID).Return(nil)
+	mockAccounts.On("DeleteAccount", unknown).Return(userservice.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/users/"+userID.String(), nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/users/"+unknown.String(), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockAccounts.AssertExpectations(t)
+}
+
+func TestDeleteAccount_OnlyOwnAccount(t *testing.T) {
+	mockAccounts := new(MockAccountUsecase)
+	mockTokens := new(MockTokenUsecase)
+	server := NewServer(new(MockUsecase), WithAccounts(mockAccounts), WithTokens(mockTokens), WithAuthRequired(true))
+	userID := uuid.New()
+	mockTokens.On("ResolveToken", "lk_good").Return(&userservice.APITokenModel{UserID: userID}, nil)
+
+	req := httptest.NewRequest("DELETE", "/api/v1/users/"+uuid.NewString(), nil)
+	req.Header.Set("Authorization", "Bearer lk_good")
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockAccounts.AssertNotCalled(t, "DeleteAccount", mock.Anything)
+}
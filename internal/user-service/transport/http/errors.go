@@ -0,0 +1,43 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// writeError answers with the status matching err; msg is logged for errors
+// that are the service's fault.
+func writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, userservice.ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, userservice.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, userservice.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, userservice.ErrUnauthorized):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.L().Warn().Err(err).Msg(msg)
+		http.Error(w, "request timed out", http.StatusGatewayTimeout)
+	case errors.Is(err, context.Canceled):
+		// The client has gone; nobody reads the answer.
+		http.Error(w, "request canceled", http.StatusServiceUnavailable)
+	default:
+		logger.L().Error().Err(err).Msg(msg)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.L().Error().Err(err).Msg("failed to encode response")
+	}
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("user 1: %w", userservice.ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: bad timezone", userservice.ErrInvalidInput), http.StatusBadRequest},
+		{fmt.Errorf("%w: telegram user 1 is registered already", userservice.ErrConflict), http.StatusConflict},
+		{userservice.ErrUnauthorized, http.StatusUnauthorized},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{context.Canceled, http.StatusServiceUnavailable},
+		{errors.New("db error"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeError(w, tt.err, "failed")
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	var deadline time.Time
+	handler := withTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, _ = r.Context().Deadline()
+	}))
+
+	start := time.Now()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+
+	assert.WithinDuration(t, start.Add(requestTimeout), deadline, time.Second)
+}
@@ -9,15 +9,81 @@ import (
 	"github.com/gorilla/mux"
 
 	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+	"github.com/danilovid/linkkeeper/pkg/auth"
 	"github.com/danilovid/linkkeeper/pkg/logger"
 )
 
 type Server struct {
-	uc userservice.Usecase
+	uc           userservice.Usecase
+	tokens       userservice.TokenUsecase
+	sessions     userservice.SessionUsecase
+	schedules    userservice.ScheduleUsecase
+	settings     userservice.SettingsUsecase
+	accounts     userservice.AccountUsecase
+	services     *auth.SignatureVerifier
+	authRequired bool
 }
 
-func NewServer(uc userservice.Usecase) *Server {
-	return &Server{uc: uc}
+// Option configures optional Server behavior.
+type Option func(*Server)
+
+// WithTokens enables the API token endpoints and authentication by API token.
+func WithTokens(tokens userservice.TokenUsecase) Option {
+	return func(s *Server) {
+		s.tokens = tokens
+	}
+}
+
+// WithSessions enables Telegram login and authentication by session token.
+func WithSessions(sessions userservice.SessionUsecase) Option {
+	return func(s *Server) {
+		s.sessions = sessions
+	}
+}
+
+// WithSchedules enables the digest and reminder endpoints.
+func WithSchedules(schedules userservice.ScheduleUsecase) Option {
+	return func(s *Server) {
+		s.schedules = schedules
+	}
+}
+
+// WithSettings enables the settings endpoints.
+func WithSettings(settings userservice.SettingsUsecase) Option {
+	return func(s *Server) {
+		s.settings = settings
+	}
+}
+
+// WithAccounts enables account export and deletion.
+func WithAccounts(accounts userservice.AccountUsecase) Option {
+	return func(s *Server) {
+		s.accounts = accounts
+	}
+}
+
+// WithServiceVerifier accepts requests signed by other services. Once it is
+// set, only they may look up and register users by Telegram ID.
+func WithServiceVerifier(services *auth.SignatureVerifier) Option {
+	return func(s *Server) {
+		s.services = services
+	}
+}
+
+// WithAuthRequired rejects requests that do not authenticate. Without it
+// callers are trusted, as on a private network.
+func WithAuthRequired(required bool) Option {
+	return func(s *Server) {
+		s.authRequired = required
+	}
+}
+
+func NewServer(uc userservice.Usecase, opts ...Option) *Server {
+	s := &Server{uc: uc}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *Server) Handler() http.Handler {
@@ -41,6 +107,13 @@ type UserResponse struct {
 	UpdatedAt  string `json:"updated_at"`
 }
 
+// CreateUserResponse answers POST /users; Created tells a newly registered
+// user from one that existed.
+type CreateUserResponse struct {
+	UserResponse
+	Created bool `json:"created"`
+}
+
 type ExistsResponse struct {
 	Exists bool `json:"exists"`
 }
@@ -58,56 +131,32 @@ func (s *Server) GetOrCreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := s.uc.GetOrCreateUser(req.TelegramID, req.Username, req.FirstName, req.LastName)
+	user, created, err := s.uc.GetOrCreateUser(r.Context(), req.TelegramID, req.Username, req.FirstName, req.LastName)
 	if err != nil {
-		logger.L().Error().Err(err).Msg("failed to get or create user")
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		writeError(w, err, "failed to get or create user")
 		return
 	}
 
-	resp := UserResponse{
-		ID:         user.ID.String(),
-		TelegramID: user.TelegramID,
-		Username:   user.Username,
-		FirstName:  user.FirstName,
-		LastName:   user.LastName,
-		CreatedAt:  user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:  user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		logger.L().Error().Err(err).Msg("failed to encode response")
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
 	}
+	writeJSON(w, status, CreateUserResponse{UserResponse: toUserResponse(user), Created: created})
 }
 
 func (s *Server) GetUserByID(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		http.Error(w, "invalid user id", http.StatusBadRequest)
+	id, ok := s.pathUserID(w, r)
+	if !ok {
 		return
 	}
 
-	user, err := s.uc.GetUserByID(id)
+	user, err := s.uc.GetUserByID(r.Context(), id)
 	if err != nil {
-		logger.L().Error().Err(err).Msg("failed to get user")
-		http.Error(w, "user not found", http.StatusNotFound)
+		writeError(w, err, "failed to get user")
 		return
 	}
 
-	resp := UserResponse{
-		ID:         user.ID.String(),
-		TelegramID: user.TelegramID,
-		Username:   user.Username,
-		FirstName:  user.FirstName,
-		LastName:   user.LastName,
-		CreatedAt:  user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:  user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}
+	resp := toUserResponse(user)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -125,22 +174,13 @@ func (s *Server) GetUserByTelegramID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := s.uc.GetUserByTelegramID(id)
+	user, err := s.uc.GetUserByTelegramID(r.Context(), id)
 	if err != nil {
-		logger.L().Error().Err(err).Msg("failed to get user by telegram id")
-		http.Error(w, "user not found", http.StatusNotFound)
+		writeError(w, err, "failed to get user by telegram id")
 		return
 	}
 
-	resp := UserResponse{
-		ID:         user.ID.String(),
-		TelegramID: user.TelegramID,
-		Username:   user.Username,
-		FirstName:  user.FirstName,
-		LastName:   user.LastName,
-		CreatedAt:  user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:  user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}
+	resp := toUserResponse(user)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -158,10 +198,9 @@ func (s *Server) CheckUserExists(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	exists, err := s.uc.UserExists(id)
+	exists, err := s.uc.UserExists(r.Context(), id)
 	if err != nil {
-		logger.L().Error().Err(err).Msg("failed to check user existence")
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		writeError(w, err, "failed to check user existence")
 		return
 	}
 
@@ -171,3 +210,45 @@ func (s *Server) CheckUserExists(w http.ResponseWriter, r *http.Request) {
 		logger.L().Error().Err(err).Msg("failed to encode response")
 	}
 }
+
+func toUserResponse(user *userservice.UserModel) UserResponse {
+	return UserResponse{
+		ID:         user.ID.String(),
+		TelegramID: user.TelegramID,
+		Username:   user.Username,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		CreatedAt:  user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:  user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// pathUserID parses the {id} of the request path and checks that the caller
+// may act on that user: callers authenticated as a user only on themselves.
+// It answers the request itself when not.
+func (s *Server) pathUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	if caller := auth.UserID(r.Context()); caller != "" && caller != id.String() {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// serviceOnly rejects callers authenticated as a user from endpoints that
+// look up or register users by Telegram ID, and callers that did not sign
+// the request when services sign theirs.
+func (s *Server) serviceOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := auth.FromContext(r.Context())
+		if id.Service == "" && (id.UserID != "" || s.services != nil) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -2,8 +2,8 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,15 +12,17 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+	"github.com/danilovid/linkkeeper/pkg/auth"
 )
 
 type MockUsecase struct {
 	mock.Mock
 }
 
-func (m *MockUsecase) CreateUser(telegramID int64, username, firstName, lastName string) (*userservice.UserModel, error) {
+func (m *MockUsecase) CreateUser(_ context.Context, telegramID int64, username, firstName, lastName string) (*userservice.UserModel, error) {
 	args := m.Called(telegramID, username, firstName, lastName)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -28,7 +30,7 @@ func (m *MockUsecase) CreateUser(telegramID int64, username, firstName, lastName
 	return args.Get(0).(*userservice.UserModel), args.Error(1)
 }
 
-func (m *MockUsecase) GetUserByID(id uuid.UUID) (*userservice.UserModel, error) {
+func (m *MockUsecase) GetUserByID(_ context.Context, id uuid.UUID) (*userservice.UserModel, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,7 +38,7 @@ func (m *MockUsecase) GetUserByID(id uuid.UUID) (*userservice.UserModel, error)
 	return args.Get(0).(*userservice.UserModel), args.Error(1)
 }
 
-func (m *MockUsecase) GetUserByTelegramID(telegramID int64) (*userservice.UserModel, error) {
+func (m *MockUsecase) GetUserByTelegramID(_ context.Context, telegramID int64) (*userservice.UserModel, error) {
 	args := m.Called(telegramID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -44,15 +46,15 @@ func (m *MockUsecase) GetUserByTelegramID(telegramID int64) (*userservice.UserMo
 	return args.Get(0).(*userservice.UserModel), args.Error(1)
 }
 
-func (m *MockUsecase) GetOrCreateUser(telegramID int64, username, firstName, lastName string) (*userservice.UserModel, error) {
+func (m *MockUsecase) GetOrCreateUser(_ context.Context, telegramID int64, username, firstName, lastName string) (*userservice.UserModel, bool, error) {
 	args := m.Called(telegramID, username, firstName, lastName)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, false, args.Error(2)
 	}
-	return args.Get(0).(*userservice.UserModel), args.Error(1)
+	return args.Get(0).(*userservice.UserModel), args.Bool(1), args.Error(2)
 }
 
-func (m *MockUsecase) UserExists(telegramID int64) (bool, error) {
+func (m *MockUsecase) UserExists(_ context.Context, telegramID int64) (bool, error) {
 	args := m.Called(telegramID)
 	return args.Bool(0), args.Error(1)
 }
@@ -69,7 +71,7 @@ func TestGetOrCreateUser_Success(t *testing.T) {
 		LastName:   "User",
 	}
 
-	mockUC.On("GetOrCreateUser", int64(123456789), "testuser", "Test", "User").Return(expectedUser, nil)
+	mockUC.On("GetOrCreateUser", int64(123456789), "testuser", "Test", "User").Return(expectedUser, false, nil)
 
 	reqBody := CreateUserRequest{
 		TelegramID: 123456789,
@@ -87,12 +89,34 @@ func TestGetOrCreateUser_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var resp UserResponse
+	var resp CreateUserResponse
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedUser.ID.String(), resp.ID)
 	assert.Equal(t, expectedUser.TelegramID, resp.TelegramID)
 	assert.Equal(t, expectedUser.Username, resp.Username)
+	assert.False(t, resp.Created)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetOrCreateUser_Created(t *testing.T) {
+	mockUC := new(MockUsecase)
+	server := NewServer(mockUC)
+
+	user := &userservice.UserModel{ID: uuid.New(), TelegramID: 42, Username: "newuser"}
+	mockUC.On("GetOrCreateUser", int64(42), "newuser", "", "").Return(user, true, nil)
+
+	body, _ := json.Marshal(CreateUserRequest{TelegramID: 42, Username: "newuser"})
+	req := httptest.NewRequest("POST", "/api/v1/users", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	server.GetOrCreateUser(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var resp CreateUserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Created)
+	assert.Equal(t, user.ID.String(), resp.ID)
 	mockUC.AssertExpectations(t)
 }
 
@@ -173,7 +197,7 @@ func TestGetUserByID_NotFound(t *testing.T) {
 	server := NewServer(mockUC)
 
 	userID := uuid.New()
-	mockUC.On("GetUserByID", userID).Return(nil, errors.New("user not found"))
+	mockUC.On("GetUserByID", userID).Return(nil, userservice.ErrNotFound)
 
 	req := httptest.NewRequest("GET", "/api/v1/users/"+userID.String(), nil)
 	req = mux.SetURLVars(req, map[string]string{"id": userID.String()})
@@ -226,3 +250,27 @@ func TestCheckUserExists_False(t *testing.T) {
 	assert.False(t, resp.Exists)
 	mockUC.AssertExpectations(t)
 }
+
+func TestGetOrCreateUser_SignedOnly(t *testing.T) {
+	mockUC := new(MockUsecase)
+	keys := []auth.Key{{ID: "bot", Secret: []byte("secret")}}
+	server := NewServer(mockUC, WithServiceVerifier(auth.NewSignatureVerifier(keys)))
+	user := &userservice.UserModel{ID: uuid.New(), TelegramID: 42}
+	mockUC.On("GetOrCreateUser", int64(42), "", "", "").Return(user, false, nil)
+
+	newRequest := func() *http.Request {
+		body, _ := json.Marshal(CreateUserRequest{TelegramID: 42})
+		return httptest.NewRequest("POST", "/api/v1/users", bytes.NewBuffer(body))
+	}
+
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, newRequest())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req := newRequest()
+	require.NoError(t, auth.NewSigner(keys).Sign(req))
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+}
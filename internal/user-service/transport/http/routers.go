@@ -1,34 +1,92 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/justinas/alice"
 	"github.com/rs/cors"
 
+	"github.com/danilovid/linkkeeper/pkg/auth"
 	"github.com/danilovid/linkkeeper/pkg/logger"
 )
 
+// requestTimeout bounds the work done for a request. It is below the write
+// timeout of the server, so that the client still hears about a timeout.
+const requestTimeout = 4 * time.Second
+
 func (s *Server) routes() http.Handler {
 	r := mux.NewRouter()
 
+	authOpts := auth.Options{
+		Services: s.services,
+		Required: s.authRequired,
+		Public: func(r *http.Request) bool {
+			return r.URL.Path == "/health" || strings.HasPrefix(r.URL.Path, "/api/v1/auth/telegram/")
+		},
+	}
+	if s.tokens != nil || s.sessions != nil {
+		authOpts.Tokens = s
+	}
+
 	middleware := alice.New(
 		logRequest,
+		withTimeout,
 		cors.New(cors.Options{
 			AllowedOrigins:   []string{"*"},
-			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 			AllowedHeaders:   []string{"*"},
 			AllowCredentials: true,
 		}).Handler,
+		auth.Middleware(authOpts),
 	)
 
 	api := r.PathPrefix("/api/v1").Subrouter()
 
-	api.HandleFunc("/users", s.GetOrCreateUser).Methods("POST")
+	api.HandleFunc("/users", s.serviceOnly(s.GetOrCreateUser)).Methods("POST")
 	api.HandleFunc("/users/{id}", s.GetUserByID).Methods("GET")
-	api.HandleFunc("/users/telegram/{telegram_id}", s.GetUserByTelegramID).Methods("GET")
-	api.HandleFunc("/users/telegram/{telegram_id}/exists", s.CheckUserExists).Methods("GET")
+	api.HandleFunc("/users/telegram/{telegram_id}", s.serviceOnly(s.GetUserByTelegramID)).Methods("GET")
+	api.HandleFunc("/users/telegram/{telegram_id}/exists", s.serviceOnly(s.CheckUserExists)).Methods("GET")
+
+	if s.tokens != nil {
+		api.HandleFunc("/users/{id}/tokens", s.IssueToken).Methods("POST")
+		api.HandleFunc("/users/{id}/tokens", s.ListTokens).Methods("GET")
+		api.HandleFunc("/users/{id}/tokens/{token_id}", s.RevokeToken).Methods("DELETE")
+	}
+
+	if s.schedules != nil {
+		api.HandleFunc("/users/{id}/digest", s.GetDigest).Methods("GET")
+		api.HandleFunc("/users/{id}/digest", s.SetDigest).Methods("PUT")
+		api.HandleFunc("/users/{id}/digest", s.DisableDigest).Methods("DELETE")
+		api.HandleFunc("/users/{id}/digest/sent", s.serviceOnly(s.DigestSent)).Methods("POST")
+		api.HandleFunc("/users/{id}/reminders", s.AddReminder).Methods("POST")
+		api.HandleFunc("/users/{id}/reminders", s.ListReminders).Methods("GET")
+		api.HandleFunc("/users/{id}/reminders/{reminder_id}", s.CancelReminder).Methods("DELETE")
+		api.HandleFunc("/digests/due", s.serviceOnly(s.DueDigests)).Methods("GET")
+		api.HandleFunc("/reminders/due", s.serviceOnly(s.DueReminders)).Methods("GET")
+	}
+
+	if s.settings != nil {
+		api.HandleFunc("/users/{id}/settings", s.GetSettings).Methods("GET")
+		api.HandleFunc("/users/{id}/settings", s.UpdateSettings).Methods("PATCH")
+	}
+
+	if s.accounts != nil {
+		api.HandleFunc("/users/{id}", s.DeleteAccount).Methods("DELETE")
+		api.HandleFunc("/users/{id}/export", s.ExportAccount).Methods("GET")
+	}
+
+	if s.sessions != nil {
+		api.HandleFunc("/auth/telegram/login", s.LoginWidget).Methods("POST")
+		api.HandleFunc("/auth/telegram/webapp", s.LoginWebApp).Methods("POST")
+	}
+
+	if s.tokens != nil || s.sessions != nil {
+		api.HandleFunc("/auth/whoami", s.WhoAmI).Methods("GET")
+	}
 
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -49,3 +107,13 @@ func logRequest(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// withTimeout cancels the context of a request, and with it the database work
+// done for it, once requestTimeout has passed.
+func withTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,300 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+// DigestRequest sets up a digest. Time is the local time of day as "HH:MM";
+// Weekday is only used by weekly digests.
+type DigestRequest struct {
+	Frequency string `json:"frequency"`
+	Weekday   string `json:"weekday,omitempty"`
+	Time      string `json:"time"`
+	Timezone  string `json:"timezone"`
+	Count     int    `json:"count,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+}
+
+type DigestResponse struct {
+	UserID     string    `json:"user_id"`
+	TelegramID int64     `json:"telegram_id,omitempty"`
+	Frequency  string    `json:"frequency"`
+	Weekday    string    `json:"weekday,omitempty"`
+	Time       string    `json:"time"`
+	Timezone   string    `json:"timezone"`
+	Count      int       `json:"count"`
+	Resource   string    `json:"resource,omitempty"`
+	NextRunAt  time.Time `json:"next_run_at"`
+}
+
+type DigestSentRequest struct {
+	SentAt time.Time `json:"sent_at"`
+}
+
+type ReminderRequest struct {
+	LinkID   string    `json:"link_id"`
+	RemindAt time.Time `json:"remind_at"`
+}
+
+type ReminderResponse struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	TelegramID int64     `json:"telegram_id,omitempty"`
+	LinkID     string    `json:"link_id"`
+	RemindAt   time.Time `json:"remind_at"`
+}
+
+func (s *Server) GetDigest(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	digest, err := s.schedules.GetDigest(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "failed to get digest")
+		return
+	}
+	writeJSON(w, http.StatusOK, toDigestResponse(*digest, 0))
+}
+
+func (s *Server) SetDigest(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	var req DigestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	schedule, err := req.schedule()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	digest, err := s.schedules.SetDigest(r.Context(), id, schedule)
+	if err != nil {
+		writeError(w, err, "failed to set digest")
+		return
+	}
+	writeJSON(w, http.StatusOK, toDigestResponse(*digest, 0))
+}
+
+func (s *Server) DisableDigest(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	if err := s.schedules.DisableDigest(r.Context(), id); err != nil {
+		writeError(w, err, "failed to disable digest")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DigestSent is called by the bot once it sent a digest.
+func (s *Server) DigestSent(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	var req DigestSentRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.SentAt.IsZero() {
+		req.SentAt = time.Now()
+	}
+
+	digest, err := s.schedules.DigestSent(r.Context(), id, req.SentAt)
+	if err != nil {
+		writeError(w, err, "failed to mark digest sent")
+		return
+	}
+	writeJSON(w, http.StatusOK, toDigestResponse(*digest, 0))
+}
+
+func (s *Server) DueDigests(w http.ResponseWriter, r *http.Request) {
+	before, ok := beforeParam(w, r)
+	if !ok {
+		return
+	}
+	due, err := s.schedules.DueDigests(r.Context(), before)
+	if err != nil {
+		writeError(w, err, "failed to list due digests")
+		return
+	}
+	resp := make([]DigestResponse, 0, len(due))
+	for _, d := range due {
+		resp = append(resp, toDigestResponse(d.DigestModel, d.TelegramID))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) AddReminder(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	var req ReminderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	reminder, err := s.schedules.AddReminder(r.Context(), id, req.LinkID, req.RemindAt)
+	if err != nil {
+		writeError(w, err, "failed to add reminder")
+		return
+	}
+	writeJSON(w, http.StatusCreated, toReminderResponse(*reminder, 0))
+}
+
+func (s *Server) ListReminders(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	reminders, err := s.schedules.ListReminders(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "failed to list reminders")
+		return
+	}
+	resp := make([]ReminderResponse, 0, len(reminders))
+	for _, reminder := range reminders {
+		resp = append(resp, toReminderResponse(reminder, 0))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) CancelReminder(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	reminderID, err := uuid.Parse(mux.Vars(r)["reminder_id"])
+	if err != nil {
+		http.Error(w, "invalid reminder id", http.StatusBadRequest)
+		return
+	}
+	if err := s.schedules.CancelReminder(r.Context(), id, reminderID); err != nil {
+		writeError(w, err, "failed to cancel reminder")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) DueReminders(w http.ResponseWriter, r *http.Request) {
+	before, ok := beforeParam(w, r)
+	if !ok {
+		return
+	}
+	due, err := s.schedules.DueReminders(r.Context(), before)
+	if err != nil {
+		writeError(w, err, "failed to list due reminders")
+		return
+	}
+	resp := make([]ReminderResponse, 0, len(due))
+	for _, d := range due {
+		resp = append(resp, toReminderResponse(d.ReminderModel, d.TelegramID))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (req DigestRequest) schedule() (userservice.DigestSchedule, error) {
+	schedule := userservice.DigestSchedule{
+		Frequency: req.Frequency,
+		Timezone:  req.Timezone,
+		Count:     req.Count,
+		Resource:  req.Resource,
+	}
+	minute, err := parseTimeOfDay(req.Time)
+	if err != nil {
+		return schedule, err
+	}
+	schedule.Minute = minute
+	if req.Frequency == userservice.DigestWeekly {
+		weekday, ok := parseWeekday(req.Weekday)
+		if !ok {
+			return schedule, fmt.Errorf("unknown weekday %q", req.Weekday)
+		}
+		schedule.Weekday = weekday
+	}
+	return schedule, nil
+}
+
+// parseTimeOfDay reads "HH:MM" as minutes past midnight.
+func parseTimeOfDay(value string) (int, error) {
+	var hour, minute int
+	if n, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || n != 2 || len(value) != 5 ||
+		hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, errors.New("time must be HH:MM")
+	}
+	return hour*60 + minute, nil
+}
+
+// parseWeekday reads an English weekday name, full or abbreviated to three letters.
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(name)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || (len(name) == 3 && name == full[:3]) {
+			return d, true
+		}
+	}
+	return time.Sunday, false
+}
+
+func beforeParam(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	raw := r.URL.Query().Get("before")
+	if raw == "" {
+		return time.Now(), true
+	}
+	before, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		http.Error(w, "before must be an RFC 3339 time", http.StatusBadRequest)
+		return time.Time{}, false
+	}
+	return before, true
+}
+
+func toDigestResponse(digest userservice.DigestModel, telegramID int64) DigestResponse {
+	resp := DigestResponse{
+		UserID:     digest.UserID.String(),
+		TelegramID: telegramID,
+		Frequency:  digest.Frequency,
+		Time:       fmt.Sprintf("%02d:%02d", digest.Minute/60, digest.Minute%60),
+		Timezone:   digest.Timezone,
+		Count:      digest.Count,
+		Resource:   digest.Resource,
+		NextRunAt:  digest.NextRunAt.UTC(),
+	}
+	if digest.Frequency == userservice.DigestWeekly {
+		resp.Weekday = strings.ToLower(digest.Weekday.String())
+	}
+	return resp
+}
+
+func toReminderResponse(reminder userservice.ReminderModel, telegramID int64) ReminderResponse {
+	return ReminderResponse{
+		ID:         reminder.ID.String(),
+		UserID:     reminder.UserID.String(),
+		TelegramID: telegramID,
+		LinkID:     reminder.LinkID,
+		RemindAt:   reminder.RemindAt.UTC(),
+	}
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+	"github.com/danilovid/linkkeeper/pkg/auth"
+)
+
+type MockScheduleUsecase struct {
+	mock.Mock
+}
+
+func (m *MockScheduleUsecase) SetDigest(_ context.Context, userID uuid.UUID, schedule userservice.DigestSchedule) (*userservice.DigestModel, error) {
+	args := m.Called(userID, schedule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.DigestModel), args.Error(1)
+}
+
+func (m *MockScheduleUsecase) GetDigest(_ context.Context, userID uuid.UUID) (*userservice.DigestModel, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.DigestModel), args.Error(1)
+}
+
+func (m *MockScheduleUsecase) DisableDigest(_ context.Context, userID uuid.UUID) error {
+	return m.Called(userID).Error(0)
+}
+
+func (m *MockScheduleUsecase) DueDigests(_ context.Context, before time.Time) ([]userservice.DueDigest, error) {
+	args := m.Called(before)
+	return args.Get(0).([]userservice.DueDigest), args.Error(1)
+}
+
+func (m *MockScheduleUsecase) DigestSent(_ context.Context, userID uuid.UUID, sentAt time.Time) (*userservice.DigestModel, error) {
+	args := m.Called(userID, sentAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.DigestModel), args.Error(1)
+}
+
+func (m *MockScheduleUsecase) AddReminder(_ context.Context, userID uuid.UUID, linkID string, remindAt time.Time) (*userservice.ReminderModel, error) {
+	args := m.Called(userID, linkID, remindAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.ReminderModel), args.Error(1)
+}
+
+func (m *MockScheduleUsecase) ListReminders(_ context.Context, userID uuid.UUID) ([]userservice.ReminderModel, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]userservice.ReminderModel), args.Error(1)
+}
+
+func (m *MockScheduleUsecase) CancelReminder(_ context.Context, userID, reminderID uuid.UUID) error {
+	return m.Called(userID, reminderID).Error(0)
+}
+
+func (m *MockScheduleUsecase) DueReminders(_ context.Context, before time.Time) ([]userservice.DueReminder, error) {
+	args := m.Called(before)
+	return args.Get(0).([]userservice.DueReminder), args.Error(1)
+}
+
+func TestSetDigest(t *testing.T) {
+	mockSchedules := new(MockScheduleUsecase)
+	server := NewServer(new(MockUsecase), WithSchedules(mockSchedules))
+	userID := uuid.New()
+	next := time.Date(2026, 3, 6, 17, 30, 0, 0, time.UTC)
+	schedule := userservice.DigestSchedule{
+		Frequency: userservice.DigestWeekly, Weekday: time.Friday, Minute: 18*60 + 30, Timezone: "Europe/Berlin", Count: 3,
+	}
+	mockSchedules.On("SetDigest", userID, schedule).Return(&userservice.DigestModel{
+		UserID: userID, Frequency: schedule.Frequency, Weekday: schedule.Weekday, Minute: schedule.Minute,
+		Timezone: schedule.Timezone, Count: schedule.Count, NextRunAt: next,
+	}, nil)
+
+	body, _ := json.Marshal(DigestRequest{Frequency: "weekly", Weekday: "Fri", Time: "18:30", Timezone: "Europe/Berlin", Count: 3})
+	req := httptest.NewRequest("PUT", "/api/v1/users/"+userID.String()+"/digest", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp DigestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "friday", resp.Weekday)
+	assert.Equal(t, "18:30", resp.Time)
+	assert.True(t, next.Equal(resp.NextRunAt))
+	mockSchedules.AssertExpectations(t)
+}
+
+func TestSetDigest_InvalidTime(t *testing.T) {
+	server := NewServer(new(MockUsecase), WithSchedules(new(MockScheduleUsecase)))
+
+	for _, value := range []string{"9:00", "24:00", "09:60", "nine"} {
+		body, _ := json.Marshal(DigestRequest{Frequency: "daily", Time: value, Timezone: "UTC"})
+		req := httptest.NewRequest("PUT", "/api/v1/users/"+uuid.NewString()+"/digest", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, value)
+	}
+}
+
+func TestAddReminder_Invalid(t *testing.T) {
+	mockSchedules := new(MockScheduleUsecase)
+	server := NewServer(new(MockUsecase), WithSchedules(mockSchedules))
+	userID := uuid.New()
+	remindAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockSchedules.On("AddReminder", userID, "link", remindAt).Return(nil, userservice.ErrInvalidInput)
+
+	body, _ := json.Marshal(ReminderRequest{LinkID: "link", RemindAt: remindAt})
+	req := httptest.NewRequest("POST", "/api/v1/users/"+userID.String()+"/reminders", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSchedules.AssertExpectations(t)
+}
+
+func TestDueReminders_SignedOnly(t *testing.T) {
+	mockSchedules := new(MockScheduleUsecase)
+	keys := []auth.Key{{ID: "bot", Secret: []byte("secret")}}
+	server := NewServer(new(MockUsecase), WithSchedules(mockSchedules), WithServiceVerifier(auth.NewSignatureVerifier(keys)))
+	before := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	reminder := userservice.ReminderModel{ID: uuid.New(), UserID: uuid.New(), LinkID: uuid.NewString(), RemindAt: before}
+	mockSchedules.On("DueReminders", before).Return([]userservice.DueReminder{{ReminderModel: reminder, TelegramID: 42}}, nil)
+
+	path := "/api/v1/reminders/due?before=2026-03-02T12:00:00Z"
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req := httptest.NewRequest("GET", path, nil)
+	require.NoError(t, auth.NewSigner(keys).Sign(req))
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp []ReminderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	assert.Equal(t, int64(42), resp[0].TelegramID)
+	assert.Equal(t, reminder.LinkID, resp[0].LinkID)
+	mockSchedules.AssertExpectations(t)
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+type WebAppLoginRequest struct {
+	InitData string `json:"init_data"`
+}
+
+type SessionResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      UserResponse `json:"user"`
+}
+
+// LoginWidget logs in with the fields the Telegram Login Widget passes to
+// its callback, posted as a JSON object.
+func (s *Server) LoginWidget(w http.ResponseWriter, r *http.Request) {
+	var raw map[string]any
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	fields := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			fields[key] = v
+		case json.Number:
+			fields[key] = v.String()
+		default:
+			http.Error(w, "invalid field "+key, http.StatusBadRequest)
+			return
+		}
+	}
+
+	session, err := s.sessions.LoginWithWidget(r.Context(), fields)
+	s.writeSession(w, session, err)
+}
+
+// LoginWebApp logs in with the initData of a Telegram Mini App.
+func (s *Server) LoginWebApp(w http.ResponseWriter, r *http.Request) {
+	var req WebAppLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.InitData == "" {
+		http.Error(w, "init_data is required", http.StatusBadRequest)
+		return
+	}
+
+	session, err := s.sessions.LoginWithWebApp(r.Context(), req.InitData)
+	s.writeSession(w, session, err)
+}
+
+func (s *Server) writeSession(w http.ResponseWriter, session *userservice.Session, err error) {
+	if err != nil {
+		writeError(w, err, "failed to log in")
+		return
+	}
+
+	resp := SessionResponse{
+		Token:     session.Token,
+		ExpiresAt: session.ExpiresAt,
+		User:      toUserResponse(session.User),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.L().Error().Err(err).Msg("failed to encode response")
+	}
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+type MockSessionUsecase struct {
+	mock.Mock
+}
+
+func (m *MockSessionUsecase) LoginWithWidget(_ context.Context, fields map[string]string) (*userservice.Session, error) {
+	args := m.Called(fields)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.Session), args.Error(1)
+}
+
+func (m *MockSessionUsecase) LoginWithWebApp(_ context.Context, initData string) (*userservice.Session, error) {
+	args := m.Called(initData)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.Session), args.Error(1)
+}
+
+func (m *MockSessionUsecase) ResolveSession(_ context.Context, token string) (uuid.UUID, error) {
+	args := m.Called(token)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func TestLoginWidget(t *testing.T) {
+	mockSessions := new(MockSessionUsecase)
+	server := NewServer(new(MockUsecase), WithSessions(mockSessions), WithAuthRequired(true))
+	user := &userservice.UserModel{ID: uuid.New(), TelegramID: 42, Username: "ann"}
+	fields := map[string]string{"id": "42", "username": "ann", "auth_date": "1700000000", "hash": "abc"}
+	mockSessions.On("LoginWithWidget", fields).
+		Return(&userservice.Session{Token: "ls_token", ExpiresAt: time.Now().Add(time.Hour), User: user}, nil)
+
+	// The widget hands id and auth_date over as numbers.
+	body := []byte(`{"id":42,"username":"ann","auth_date":1700000000,"hash":"abc"}`)
+	req := httptest.NewRequest("POST", "/api/v1/auth/telegram/login", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp SessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "ls_token", resp.Token)
+	assert.Equal(t, user.ID.String(), resp.User.ID)
+}
+
+func TestLoginWebApp_Forged(t *testing.T) {
+	mockSessions := new(MockSessionUsecase)
+	server := NewServer(new(MockUsecase), WithSessions(mockSessions), WithAuthRequired(true))
+	mockSessions.On("LoginWithWebApp", "user=x&hash=y").Return(nil, userservice.ErrUnauthorized)
+
+	body, _ := json.Marshal(WebAppLoginRequest{InitData: "user=x&hash=y"})
+	req := httptest.NewRequest("POST", "/api/v1/auth/telegram/webapp", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestWhoAmI_Session(t *testing.T) {
+	mockSessions := new(MockSessionUsecase)
+	mockTokens := new(MockTokenUsecase)
+	server := NewServer(new(MockUsecase), WithTokens(mockTokens), WithSessions(mockSessions), WithAuthRequired(true))
+	userID := uuid.New()
+	mockSessions.On("ResolveSession", "ls_token").Return(userID, nil)
+	mockSessions.On("ResolveSession", "lk_token").Return(uuid.Nil, userservice.ErrNotFound)
+	mockTokens.On("ResolveToken", "lk_token").Return(nil, userservice.ErrNotFound)
+
+	req := httptest.NewRequest("GET", "/api/v1/auth/whoami", nil)
+	req.Header.Set("Authorization", "Bearer ls_token")
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp WhoAmIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, userID.String(), resp.UserID)
+
+	req = httptest.NewRequest("GET", "/api/v1/auth/whoami", nil)
+	req.Header.Set("Authorization", "Bearer lk_token")
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
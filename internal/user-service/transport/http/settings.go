@@ -0,0 +1,160 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+type PrivacySettings struct {
+	AutoSave bool `json:"auto_save"`
+}
+
+// DigestSettings describes the digest; the other fields are only set when it is enabled.
+type DigestSettings struct {
+	Enabled   bool       `json:"enabled"`
+	Frequency string     `json:"frequency,omitempty"`
+	Weekday   string     `json:"weekday,omitempty"`
+	Time      string     `json:"time,omitempty"`
+	Count     int        `json:"count,omitempty"`
+	Resource  string     `json:"resource,omitempty"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+}
+
+type SettingsResponse struct {
+	Timezone        string          `json:"timezone"`
+	Language        string          `json:"language"`
+	DefaultResource string          `json:"default_resource"`
+	Privacy         PrivacySettings `json:"privacy"`
+	Digest          DigestSettings  `json:"digest"`
+	UpdatedAt       *time.Time      `json:"updated_at,omitempty"`
+}
+
+// SettingsPatchRequest changes the settings it names and leaves the others alone.
+type SettingsPatchRequest struct {
+	Timezone        *string             `json:"timezone"`
+	Language        *string             `json:"language"`
+	DefaultResource *string             `json:"default_resource"`
+	Privacy         *PrivacyPatch       `json:"privacy"`
+	Digest          *DigestPatchRequest `json:"digest"`
+}
+
+type PrivacyPatch struct {
+	AutoSave *bool `json:"auto_save"`
+}
+
+// DigestPatchRequest changes the digest; Time is "HH:MM" and Weekday an
+// English day name. Any change but "enabled": false turns the digest on.
+type DigestPatchRequest struct {
+	Enabled   *bool   `json:"enabled"`
+	Frequency *string `json:"frequency"`
+	Weekday   *string `json:"weekday"`
+	Time      *string `json:"time"`
+	Count     *int    `json:"count"`
+	Resource  *string `json:"resource"`
+}
+
+func (s *Server) GetSettings(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	settings, err := s.settings.GetSettings(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "failed to get settings")
+		return
+	}
+	writeJSON(w, http.StatusOK, toSettingsResponse(settings))
+}
+
+func (s *Server) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	var req SettingsPatchRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	patch, err := req.patch()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	settings, err := s.settings.UpdateSettings(r.Context(), id, patch)
+	if err != nil {
+		writeError(w, err, "failed to update settings")
+		return
+	}
+	writeJSON(w, http.StatusOK, toSettingsResponse(settings))
+}
+
+func (req SettingsPatchRequest) patch() (userservice.SettingsPatch, error) {
+	patch := userservice.SettingsPatch{
+		Timezone:        req.Timezone,
+		Language:        req.Language,
+		DefaultResource: req.DefaultResource,
+	}
+	if req.Privacy != nil {
+		patch.AutoSave = req.Privacy.AutoSave
+	}
+	if req.Digest == nil {
+		return patch, nil
+	}
+
+	d := req.Digest
+	digest := &userservice.DigestPatch{
+		Enabled:   d.Enabled,
+		Frequency: d.Frequency,
+		Count:     d.Count,
+		Resource:  d.Resource,
+	}
+	if d.Weekday != nil {
+		weekday, ok := parseWeekday(*d.Weekday)
+		if !ok {
+			return patch, fmt.Errorf("unknown weekday %q", *d.Weekday)
+		}
+		digest.Weekday = &weekday
+	}
+	if d.Time != nil {
+		minute, err := parseTimeOfDay(*d.Time)
+		if err != nil {
+			return patch, err
+		}
+		digest.Minute = &minute
+	}
+	patch.Digest = digest
+	return patch, nil
+}
+
+func toSettingsResponse(settings *userservice.Settings) SettingsResponse {
+	resp := SettingsResponse{
+		Timezone:        settings.Timezone,
+		Language:        settings.Language,
+		DefaultResource: settings.DefaultResource,
+		Privacy:         PrivacySettings{AutoSave: settings.AutoSave},
+	}
+	if !settings.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &settings.UpdatedAt
+	}
+	if settings.Digest != nil {
+		digest := toDigestResponse(*settings.Digest, 0)
+		resp.Digest = DigestSettings{
+			Enabled:   true,
+			Frequency: digest.Frequency,
+			Weekday:   digest.Weekday,
+			Time:      digest.Time,
+			Count:     digest.Count,
+			Resource:  digest.Resource,
+			NextRunAt: &digest.NextRunAt,
+		}
+	}
+	return resp
+}
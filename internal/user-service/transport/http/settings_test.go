@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+type MockSettingsUsecase struct {
+	mock.Mock
+}
+
+func (m *MockSettingsUsecase) GetSettings(_ context.Context, userID uuid.UUID) (*userservice.Settings, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.Settings), args.Error(1)
+}
+
+func (m *MockSettingsUsecase) UpdateSettings(_ context.Context, userID uuid.UUID, patch userservice.SettingsPatch) (*userservice.Settings, error) {
+	args := m.Called(userID, patch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.Settings), args.Error(1)
+}
+
+func TestGetSettings(t *testing.T) {
+	mockSettings := new(MockSettingsUsecase)
+	server := NewServer(new(MockUsecase), WithSettings(mockSettings))
+	userID := uuid.New()
+	mockSettings.On("GetSettings", userID).Return(&userservice.Settings{
+		SettingsModel: userservice.DefaultSettings(userID),
+		Digest: &userservice.DigestModel{
+			UserID: userID, Frequency: userservice.DigestWeekly, Weekday: time.Saturday, Minute: 10 * 60,
+			Timezone: "UTC", Count: 3, NextRunAt: time.Date(2026, 3, 7, 10, 0, 0, 0, time.UTC),
+		},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/users/"+userID.String()+"/settings", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"timezone": "UTC", "language": "en", "default_resource": "",
+		"privacy": {"auto_save": true},
+		"digest": {"enabled": true, "frequency": "weekly", "weekday": "saturday", "time": "10:00", "count": 3,
+			"next_run_at": "2026-03-07T10:00:00Z"}
+	}`, w.Body.String())
+}
+
+func TestUpdateSettings(t *testing.T) {
+	mockSettings := new(MockSettingsUsecase)
+	server := NewServer(new(MockUsecase), WithSettings(mockSettings))
+	userID := uuid.New()
+	resource, autoSave, minute := "video", false, 7*60+30
+	mockSettings.On("UpdateSettings", userID, userservice.SettingsPatch{
+		DefaultResource: &resource,
+		AutoSave:        &autoSave,
+		Digest:          &userservice.DigestPatch{Minute: &minute},
+	}).Return(&userservice.Settings{SettingsModel: userservice.DefaultSettings(userID)}, nil)
+
+	body := `{"default_resource": "video", "privacy": {"auto_save": false}, "digest": {"time": "07:30"}}`
+	req := httptest.NewRequest("PATCH", "/api/v1/users/"+userID.String()+"/settings", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSettings.AssertExpectations(t)
+}
+
+func TestUpdateSettings_Invalid(t *testing.T) {
+	mockSettings := new(MockSettingsUsecase)
+	server := NewServer(new(MockUsecase), WithSettings(mockSettings))
+	userID := uuid.New()
+	mockSettings.On("UpdateSettings", userID, mock.Anything).Return(nil, userservice.ErrInvalidInput)
+
+	for _, body := range []string{
+		`{"theme": "dark"}`,
+		`{"digest": {"time": "7:30"}}`,
+		`{"digest": {"weekday": "someday"}}`,
+		`{"timezone": "Mars/Olympus"}`,
+	} {
+		req := httptest.NewRequest("PATCH", "/api/v1/users/"+userID.String()+"/settings", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+	"github.com/danilovid/linkkeeper/pkg/auth"
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+type IssueTokenRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+type TokenResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name,omitempty"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// IssuedTokenResponse is the only response that carries the token itself.
+type IssuedTokenResponse struct {
+	TokenResponse
+	Token string `json:"token"`
+}
+
+type WhoAmIResponse struct {
+	UserID string `json:"user_id"`
+}
+
+// ResolveToken lets the auth middleware resolve the service's own tokens:
+// session tokens minted at login and personal API tokens.
+func (s *Server) ResolveToken(ctx context.Context, token string) (string, error) {
+	if s.sessions != nil {
+		userID, err := s.sessions.ResolveSession(ctx, token)
+		if err == nil {
+			return userID.String(), nil
+		}
+		if !errors.Is(err, userservice.ErrNotFound) {
+			return "", err
+		}
+	}
+	if s.tokens == nil {
+		return "", auth.ErrInvalidToken
+	}
+	record, err := s.tokens.ResolveToken(ctx, token)
+	if errors.Is(err, userservice.ErrNotFound) {
+		return "", auth.ErrInvalidToken
+	}
+	if err != nil {
+		return "", err
+	}
+	return record.UserID.String(), nil
+}
+
+func (s *Server) IssueToken(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	var req IssueTokenRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	record, token, err := s.tokens.IssueToken(r.Context(), id, req.Name)
+	if err != nil {
+		writeError(w, err, "failed to issue token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(IssuedTokenResponse{TokenResponse: toTokenResponse(*record), Token: token}); err != nil {
+		logger.L().Error().Err(err).Msg("failed to encode response")
+	}
+}
+
+func (s *Server) ListTokens(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := s.tokens.ListTokens(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "failed to list tokens")
+		return
+	}
+
+	resp := make([]TokenResponse, 0, len(records))
+	for _, record := range records {
+		resp = append(resp, toTokenResponse(record))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.L().Error().Err(err).Msg("failed to encode response")
+	}
+}
+
+func (s *Server) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.pathUserID(w, r)
+	if !ok {
+		return
+	}
+	tokenID, err := uuid.Parse(mux.Vars(r)["token_id"])
+	if err != nil {
+		http.Error(w, "invalid token id", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.tokens.RevokeToken(r.Context(), id, tokenID); err != nil {
+		writeError(w, err, "failed to revoke token")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WhoAmI tells which user the request's token belongs to. Other services
+// resolve tokens through it.
+func (s *Server) WhoAmI(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(WhoAmIResponse{UserID: userID}); err != nil {
+		logger.L().Error().Err(err).Msg("failed to encode response")
+	}
+}
+
+func toTokenResponse(record userservice.APITokenModel) TokenResponse {
+	return TokenResponse{
+		ID:         record.ID.String(),
+		Name:       record.Name,
+		Prefix:     record.Prefix,
+		CreatedAt:  record.CreatedAt,
+		LastUsedAt: record.LastUsedAt,
+	}
+}
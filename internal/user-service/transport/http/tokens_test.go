@@ -0,0 +1,102 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+type MockTokenUsecase struct {
+	mock.Mock
+}
+
+func (m *MockTokenUsecase) IssueToken(_ context.Context, userID uuid.UUID, name string) (*userservice.APITokenModel, string, error) {
+	args := m.Called(userID, name)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*userservice.APITokenModel), args.String(1), args.Error(2)
+}
+
+func (m *MockTokenUsecase) ListTokens(_ context.Context, userID uuid.UUID) ([]userservice.APITokenModel, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]userservice.APITokenModel), args.Error(1)
+}
+
+func (m *MockTokenUsecase) RevokeToken(_ context.Context, userID, tokenID uuid.UUID) error {
+	args := m.Called(userID, tokenID)
+	return args.Error(0)
+}
+
+func (m *MockTokenUsecase) ResolveToken(_ context.Context, token string) (*userservice.APITokenModel, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userservice.APITokenModel), args.Error(1)
+}
+
+func TestIssueToken_Trusted(t *testing.T) {
+	mockTokens := new(MockTokenUsecase)
+	server := NewServer(new(MockUsecase), WithTokens(mockTokens))
+	userID := uuid.New()
+	record := &userservice.APITokenModel{ID: uuid.New(), UserID: userID, Name: "laptop", Prefix: "lk_abcde"}
+	mockTokens.On("IssueToken", userID, "laptop").Return(record, "lk_abcdefgh", nil)
+
+	body, _ := json.Marshal(IssueTokenRequest{Name: "laptop"})
+	req := httptest.NewRequest("POST", "/api/v1/users/"+userID.String()+"/tokens", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp IssuedTokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "lk_abcdefgh", resp.Token)
+	assert.Equal(t, record.ID.String(), resp.ID)
+	mockTokens.AssertExpectations(t)
+}
+
+func TestTokens_AuthRequired(t *testing.T) {
+	mockTokens := new(MockTokenUsecase)
+	server := NewServer(new(MockUsecase), WithTokens(mockTokens), WithAuthRequired(true))
+	handler := server.Handler()
+	userID := uuid.New()
+	mockTokens.On("ResolveToken", "lk_good").Return(&userservice.APITokenModel{UserID: userID}, nil)
+	mockTokens.On("ResolveToken", "lk_bad").Return(nil, userservice.ErrNotFound)
+	mockTokens.On("ListTokens", userID).Return([]userservice.APITokenModel{}, nil)
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, do("GET", "/health", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/api/v1/users/"+userID.String()+"/tokens", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/api/v1/auth/whoami", "lk_bad").Code)
+
+	w := do("GET", "/api/v1/auth/whoami", "lk_good")
+	require.Equal(t, http.StatusOK, w.Code)
+	var who WhoAmIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &who))
+	assert.Equal(t, userID.String(), who.UserID)
+
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/users/"+userID.String()+"/tokens", "lk_good").Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/users/"+uuid.NewString()+"/tokens", "lk_good").Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/users/telegram/42", "lk_good").Code)
+	mockTokens.AssertExpectations(t)
+}
@@ -1,11 +1,81 @@
 package userservice
 
-import "github.com/google/uuid"
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Usecase interface {
-	CreateUser(telegramID int64, username, firstName, lastName string) (*UserModel, error)
-	GetUserByID(id uuid.UUID) (*UserModel, error)
-	GetUserByTelegramID(telegramID int64) (*UserModel, error)
-	GetOrCreateUser(telegramID int64, username, firstName, lastName string) (*UserModel, error)
-	UserExists(telegramID int64) (bool, error)
+	CreateUser(ctx context.Context, telegramID int64, username, firstName, lastName string) (*UserModel, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*UserModel, error)
+	GetUserByTelegramID(ctx context.Context, telegramID int64) (*UserModel, error)
+	// GetOrCreateUser registers the Telegram user on first use and otherwise
+	// refreshes a changed username or name. It reports whether the user is new.
+	GetOrCreateUser(ctx context.Context, telegramID int64, username, firstName, lastName string) (*UserModel, bool, error)
+	UserExists(ctx context.Context, telegramID int64) (bool, error)
+}
+
+// TokenUsecase issues and resolves personal API tokens.
+type TokenUsecase interface {
+	// IssueToken creates a token for the user and returns it together with
+	// the token itself, which cannot be read back later.
+	IssueToken(ctx context.Context, userID uuid.UUID, name string) (*APITokenModel, string, error)
+	ListTokens(ctx context.Context, userID uuid.UUID) ([]APITokenModel, error)
+	RevokeToken(ctx context.Context, userID, tokenID uuid.UUID) error
+	// ResolveToken returns the token record for a token, or ErrNotFound.
+	ResolveToken(ctx context.Context, token string) (*APITokenModel, error)
+}
+
+// SessionUsecase logs users in with data signed by Telegram and resolves
+// the session tokens it mints. Login errors wrap ErrUnauthorized.
+type SessionUsecase interface {
+	LoginWithWidget(ctx context.Context, fields map[string]string) (*Session, error)
+	LoginWithWebApp(ctx context.Context, initData string) (*Session, error)
+	// ResolveSession returns the id of the session's user, or ErrNotFound
+	// for invalid and expired sessions.
+	ResolveSession(ctx context.Context, token string) (uuid.UUID, error)
+}
+
+// DigestSchedule is what a user asks for when setting up a digest.
+type DigestSchedule struct {
+	Frequency string
+	Weekday   time.Weekday
+	Minute    int
+	Timezone  string
+	Count     int
+	Resource  string
+}
+
+// ScheduleUsecase keeps the digest schedules and reminders the bot sends.
+// Invalid schedules and reminders are rejected with ErrInvalidInput.
+type ScheduleUsecase interface {
+	SetDigest(ctx context.Context, userID uuid.UUID, schedule DigestSchedule) (*DigestModel, error)
+	GetDigest(ctx context.Context, userID uuid.UUID) (*DigestModel, error)
+	DisableDigest(ctx context.Context, userID uuid.UUID) error
+	DueDigests(ctx context.Context, before time.Time) ([]DueDigest, error)
+	// DigestSent schedules the user's next digest after sentAt.
+	DigestSent(ctx context.Context, userID uuid.UUID, sentAt time.Time) (*DigestModel, error)
+
+	AddReminder(ctx context.Context, userID uuid.UUID, linkID string, remindAt time.Time) (*ReminderModel, error)
+	ListReminders(ctx context.Context, userID uuid.UUID) ([]ReminderModel, error)
+	// CancelReminder also removes reminders once they were sent.
+	CancelReminder(ctx context.Context, userID, reminderID uuid.UUID) error
+	DueReminders(ctx context.Context, before time.Time) ([]DueReminder, error)
+}
+
+// SettingsUsecase reads and updates user settings. Invalid settings are
+// rejected with ErrInvalidInput and nothing is changed.
+type SettingsUsecase interface {
+	GetSettings(ctx context.Context, userID uuid.UUID) (*Settings, error)
+	UpdateSettings(ctx context.Context, userID uuid.UUID, patch SettingsPatch) (*Settings, error)
+}
+
+// AccountUsecase lets users take their data with them and leave.
+type AccountUsecase interface {
+	ExportAccount(ctx context.Context, userID uuid.UUID) (*AccountExport, error)
+	// DeleteAccount deletes the user and everything stored about them, here
+	// and in the services sharing the database.
+	DeleteAccount(ctx context.Context, userID uuid.UUID) error
 }
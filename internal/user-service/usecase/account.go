@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+type accountUsecase struct {
+	users     userservice.Repository
+	tokens    userservice.TokenRepository
+	schedules userservice.ScheduleRepository
+	settings  userservice.SettingsRepository
+	now       func() time.Time
+}
+
+func NewAccountService(users userservice.Repository, tokens userservice.TokenRepository, schedules userservice.ScheduleRepository, settings userservice.SettingsRepository) userservice.AccountUsecase {
+	return &accountUsecase{users: users, tokens: tokens, schedules: schedules, settings: settings, now: time.Now}
+}
+
+func (u *accountUsecase) ExportAccount(ctx context.Context, userID uuid.UUID) (*userservice.AccountExport, error) {
+	user, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user %s: %w", userID, err)
+	}
+	export := &userservice.AccountExport{User: *user, ExportedAt: u.now().UTC()}
+
+	settings, err := u.settings.GetSettings(ctx, userID)
+	if errors.Is(err, userservice.ErrNotFound) {
+		defaults := userservice.DefaultSettings(userID)
+		settings = &defaults
+	} else if err != nil {
+		return nil, err
+	}
+	export.Settings.SettingsModel = *settings
+
+	digest, err := u.schedules.GetDigest(ctx, userID)
+	if err != nil && !errors.Is(err, userservice.ErrNotFound) {
+		return nil, err
+	}
+	export.Settings.Digest = digest
+
+	if export.Reminders, err = u.schedules.ListReminders(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Tokens, err = u.tokens.ListTokens(ctx, userID); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+func (u *accountUsecase) DeleteAccount(ctx context.Context, userID uuid.UUID) error {
+	if err := u.users.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("user %s: %w", userID, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+func TestAccountUsecase_ExportAccount(t *testing.T) {
+	mockRepo := new(MockRepository)
+	tokens := newMemTokens()
+	schedules := newMemSchedules()
+	settings := memSettings{}
+	accounts := NewAccountService(mockRepo, tokens, schedules, settings).(*accountUsecase)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	accounts.now = func() time.Time { return now }
+
+	userID := uuid.New()
+	mockRepo.On("GetByID", userID).Return(&userservice.UserModel{ID: userID, TelegramID: 42, Username: "ann"}, nil)
+	require.NoError(t, tokens.CreateToken(context.Background(), &userservice.APITokenModel{UserID: userID, Name: "laptop"}))
+	require.NoError(t, schedules.SaveDigest(context.Background(), &userservice.DigestModel{UserID: userID, Frequency: userservice.DigestDaily}))
+	require.NoError(t, schedules.CreateReminder(context.Background(), &userservice.ReminderModel{UserID: userID, LinkID: uuid.NewString()}))
+
+	export, err := accounts.ExportAccount(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, "ann", export.User.Username)
+	assert.Equal(t, now, export.ExportedAt)
+	assert.Equal(t, userservice.DefaultSettings(userID), export.Settings.SettingsModel, "defaults for users who never changed them")
+	require.NotNil(t, export.Settings.Digest)
+	assert.Len(t, export.Reminders, 1)
+	require.Len(t, export.Tokens, 1)
+	assert.Equal(t, "laptop", export.Tokens[0].Name)
+}
+
+func TestAccountUsecase_ExportAccount_UnknownUser(t *testing.T) {
+	mockRepo := new(MockRepository)
+	accounts := NewAccountService(mockRepo, newMemTokens(), newMemSchedules(), memSettings{})
+	userID := uuid.New()
+	mockRepo.On("GetByID", userID).Return(nil, userservice.ErrNotFound)
+
+	_, err := accounts.ExportAccount(context.Background(), userID)
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+}
+
+func TestAccountUsecase_DeleteAccount(t *testing.T) {
+	mockRepo := new(MockRepository)
+	accounts := NewAccountService(mockRepo, newMemTokens(), newMemSchedules(), memSettings{})
+	userID, unknown := uuid.New(), uuid.New()
+	mockRepo.On("Delete", userID).Return(nil)
+	mockRepo.On("Delete", unknown).Return(userservice.ErrNotFound)
+
+	assert.NoError(t, accounts.DeleteAccount(context.Background(), userID))
+	assert.ErrorIs(t, accounts.DeleteAccount(context.Background(), unknown), userservice.ErrNotFound)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+const (
+	// defaultDigestCount is the number of links in a digest unless asked otherwise.
+	defaultDigestCount = 5
+	// maxDigestCount bounds the links in a digest.
+	maxDigestCount = 20
+	// maxResourceLen matches the resource column of links.
+	maxResourceLen = 32
+	// dueBatch bounds the digests and reminders handed out at once.
+	dueBatch = 100
+)
+
+type scheduleUsecase struct {
+	schedules userservice.ScheduleRepository
+	users     userservice.Repository
+	now       func() time.Time
+}
+
+func NewScheduleService(schedules userservice.ScheduleRepository, users userservice.Repository) userservice.ScheduleUsecase {
+	return &scheduleUsecase{schedules: schedules, users: users, now: time.Now}
+}
+
+func (u *scheduleUsecase) SetDigest(ctx context.Context, userID uuid.UUID, schedule userservice.DigestSchedule) (*userservice.DigestModel, error) {
+	if schedule.Frequency != userservice.DigestDaily && schedule.Frequency != userservice.DigestWeekly {
+		return nil, fmt.Errorf("%w: frequency must be %q or %q", userservice.ErrInvalidInput, userservice.DigestDaily, userservice.DigestWeekly)
+	}
+	if schedule.Weekday < time.Sunday || schedule.Weekday > time.Saturday {
+		return nil, fmt.Errorf("%w: weekday out of range", userservice.ErrInvalidInput)
+	}
+	if schedule.Minute < 0 || schedule.Minute >= 24*60 {
+		return nil, fmt.Errorf("%w: time of day out of range", userservice.ErrInvalidInput)
+	}
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil || schedule.Timezone == "" || schedule.Timezone == "Local" {
+		return nil, fmt.Errorf("%w: unknown timezone %q", userservice.ErrInvalidInput, schedule.Timezone)
+	}
+	if schedule.Count == 0 {
+		schedule.Count = defaultDigestCount
+	}
+	if schedule.Count < 1 || schedule.Count > maxDigestCount {
+		return nil, fmt.Errorf("%w: a digest has 1 to %d links", userservice.ErrInvalidInput, maxDigestCount)
+	}
+	if len(schedule.Resource) > maxResourceLen {
+		return nil, fmt.Errorf("%w: resource is longer than %d characters", userservice.ErrInvalidInput, maxResourceLen)
+	}
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("user %s: %w", userID, err)
+	}
+
+	digest := &userservice.DigestModel{
+		UserID:    userID,
+		Frequency: schedule.Frequency,
+		Minute:    schedule.Minute,
+		Timezone:  schedule.Timezone,
+		Count:     schedule.Count,
+		Resource:  schedule.Resource,
+	}
+	if schedule.Frequency == userservice.DigestWeekly {
+		digest.Weekday = schedule.Weekday
+	}
+	digest.NextRunAt = nextDigestRun(digest, u.now())
+	if err := u.schedules.SaveDigest(ctx, digest); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+func (u *scheduleUsecase) GetDigest(ctx context.Context, userID uuid.UUID) (*userservice.DigestModel, error) {
+	return u.schedules.GetDigest(ctx, userID)
+}
+
+func (u *scheduleUsecase) DisableDigest(ctx context.Context, userID uuid.UUID) error {
+	return u.schedules.DeleteDigest(ctx, userID)
+}
+
+func (u *scheduleUsecase) DueDigests(ctx context.Context, before time.Time) ([]userservice.DueDigest, error) {
+	return u.schedules.DueDigests(ctx, before, dueBatch)
+}
+
+func (u *scheduleUsecase) DigestSent(ctx context.Context, userID uuid.UUID, sentAt time.Time) (*userservice.DigestModel, error) {
+	digest, err := u.schedules.GetDigest(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// A digest that was late, e.g. because the bot was down, is sent once and
+	// the schedule picks up again from the next regular time.
+	digest.NextRunAt = nextDigestRun(digest, sentAt)
+	if err := u.schedules.SetDigestNextRun(ctx, userID, digest.NextRunAt); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+func (u *scheduleUsecase) AddReminder(ctx context.Context, userID uuid.UUID, linkID string, remindAt time.Time) (*userservice.ReminderModel, error) {
+	if _, err := uuid.Parse(linkID); err != nil {
+		return nil, fmt.Errorf("%w: invalid link id", userservice.ErrInvalidInput)
+	}
+	if !remindAt.After(u.now()) {
+		return nil, fmt.Errorf("%w: reminder is in the past", userservice.ErrInvalidInput)
+	}
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("user %s: %w", userID, err)
+	}
+	reminder := &userservice.ReminderModel{UserID: userID, LinkID: linkID, RemindAt: remindAt.UTC()}
+	if err := u.schedules.CreateReminder(ctx, reminder); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+func (u *scheduleUsecase) ListReminders(ctx context.Context, userID uuid.UUID) ([]userservice.ReminderModel, error) {
+	return u.schedules.ListReminders(ctx, userID)
+}
+
+func (u *scheduleUsecase) CancelReminder(ctx context.Context, userID, reminderID uuid.UUID) error {
+	return u.schedules.DeleteReminder(ctx, userID, reminderID)
+}
+
+func (u *scheduleUsecase) DueReminders(ctx context.Context, before time.Time) ([]userservice.DueReminder, error) {
+	return u.schedules.DueReminders(ctx, before, dueBatch)
+}
+
+// nextDigestRun returns the first time after after that the digest is due,
+// in the digest's timezone: days with a daylight saving change keep the
+// wall-clock time.
+func nextDigestRun(digest *userservice.DigestModel, after time.Time) time.Time {
+	loc, err := time.LoadLocation(digest.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := after.In(loc)
+	for day := 0; ; day++ {
+		run := time.Date(local.Year(), local.Month(), local.Day()+day, digest.Minute/60, digest.Minute%60, 0, 0, loc)
+		if !run.After(after) {
+			continue
+		}
+		if digest.Frequency == userservice.DigestWeekly && run.Weekday() != digest.Weekday {
+			continue
+		}
+		return run.UTC()
+	}
+}
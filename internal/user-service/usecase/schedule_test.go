@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+// memSchedules keeps digests by user; reminders are not kept.
+type memSchedules struct {
+	digests   map[uuid.UUID]userservice.DigestModel
+	reminders []userservice.ReminderModel
+}
+
+func newMemSchedules() *memSchedules {
+	return &memSchedules{digests: map[uuid.UUID]userservice.DigestModel{}}
+}
+
+func (m *memSchedules) SaveDigest(_ context.Context, digest *userservice.DigestModel) error {
+	m.digests[digest.UserID] = *digest
+	return nil
+}
+
+func (m *memSchedules) GetDigest(_ context.Context, userID uuid.UUID) (*userservice.DigestModel, error) {
+	digest, ok := m.digests[userID]
+	if !ok {
+		return nil, userservice.ErrNotFound
+	}
+	return &digest, nil
+}
+
+func (m *memSchedules) DeleteDigest(_ context.Context, userID uuid.UUID) error {
+	delete(m.digests, userID)
+	return nil
+}
+
+func (m *memSchedules) SetDigestNextRun(_ context.Context, userID uuid.UUID, next time.Time) error {
+	digest := m.digests[userID]
+	digest.NextRunAt = next
+	m.digests[userID] = digest
+	return nil
+}
+
+func (m *memSchedules) DueDigests(context.Context, time.Time, int) ([]userservice.DueDigest, error) {
+	return nil, nil
+}
+
+func (m *memSchedules) CreateReminder(_ context.Context, reminder *userservice.ReminderModel) error {
+	m.reminders = append(m.reminders, *reminder)
+	return nil
+}
+
+func (m *memSchedules) ListReminders(context.Context, uuid.UUID) ([]userservice.ReminderModel, error) {
+	return m.reminders, nil
+}
+
+func (m *memSchedules) DeleteReminder(context.Context, uuid.UUID, uuid.UUID) error {
+	return nil
+}
+
+func (m *memSchedules) DueReminders(context.Context, time.Time, int) ([]userservice.DueReminder, error) {
+	return nil, nil
+}
+
+func TestNextDigestRun(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		digest userservice.DigestModel
+		after  time.Time
+		want   time.Time
+	}{
+		{
+			name:   "later today",
+			digest: userservice.DigestModel{Frequency: userservice.DigestDaily, Minute: 9 * 60, Timezone: "Europe/Berlin"},
+			after:  time.Date(2026, 3, 2, 7, 0, 0, 0, berlin),
+			want:   time.Date(2026, 3, 2, 9, 0, 0, 0, berlin),
+		},
+		{
+			name:   "exactly due moves to tomorrow",
+			digest: userservice.DigestModel{Frequency: userservice.DigestDaily, Minute: 9 * 60, Timezone: "Europe/Berlin"},
+			after:  time.Date(2026, 3, 2, 9, 0, 0, 0, berlin),
+			want:   time.Date(2026, 3, 3, 9, 0, 0, 0, berlin),
+		},
+		{
+			name:   "keeps wall clock across daylight saving",
+			digest: userservice.DigestModel{Frequency: userservice.DigestDaily, Minute: 9 * 60, Timezone: "Europe/Berlin"},
+			after:  time.Date(2026, 3, 28, 10, 0, 0, 0, berlin),
+			want:   time.Date(2026, 3, 29, 9, 0, 0, 0, berlin),
+		},
+		{
+			name:   "weekly",
+			digest: userservice.DigestModel{Frequency: userservice.DigestWeekly, Weekday: time.Friday, Minute: 18*60 + 30, Timezone: "UTC"},
+			after:  time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), // a Monday
+			want:   time.Date(2026, 3, 6, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			name:   "weekly after this week's run",
+			digest: userservice.DigestModel{Frequency: userservice.DigestWeekly, Weekday: time.Monday, Minute: 8 * 60, Timezone: "UTC"},
+			after:  time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextDigestRun(&tt.digest, tt.after)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
+
+func TestScheduleUsecase_SetDigest(t *testing.T) {
+	mockRepo := new(MockRepository)
+	schedules := newMemSchedules()
+	uc := NewScheduleService(schedules, mockRepo).(*scheduleUsecase)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	userID := uuid.New()
+	mockRepo.On("GetByID", userID).Return(&userservice.UserModel{ID: userID}, nil)
+
+	digest, err := uc.SetDigest(context.Background(), userID, userservice.DigestSchedule{
+		Frequency: userservice.DigestDaily, Weekday: time.Friday, Minute: 9 * 60, Timezone: "UTC",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, digest.Count)
+	assert.Equal(t, time.Sunday, digest.Weekday, "weekday is only kept for weekly digests")
+	assert.Equal(t, time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC), digest.NextRunAt)
+
+	sent, err := uc.DigestSent(context.Background(), userID, time.Date(2026, 3, 5, 9, 1, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 6, 9, 0, 0, 0, time.UTC), sent.NextRunAt)
+	assert.Equal(t, sent.NextRunAt, schedules.digests[userID].NextRunAt)
+}
+
+func TestScheduleUsecase_SetDigest_Invalid(t *testing.T) {
+	uc := NewScheduleService(newMemSchedules(), new(MockRepository))
+	valid := userservice.DigestSchedule{Frequency: userservice.DigestDaily, Minute: 60, Timezone: "UTC", Count: 3}
+
+	for name, change := range map[string]func(*userservice.DigestSchedule){
+		"frequency": func(s *userservice.DigestSchedule) { s.Frequency = "hourly" },
+		"minute":    func(s *userservice.DigestSchedule) { s.Minute = 24 * 60 },
+		"timezone":  func(s *userservice.DigestSchedule) { s.Timezone = "Mars/Olympus" },
+		"count":     func(s *userservice.DigestSchedule) { s.Count = 21 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			schedule := valid
+			change(&schedule)
+			_, err := uc.SetDigest(context.Background(), uuid.New(), schedule)
+			assert.ErrorIs(t, err, userservice.ErrInvalidInput)
+		})
+	}
+}
+
+func TestScheduleUsecase_AddReminder(t *testing.T) {
+	mockRepo := new(MockRepository)
+	schedules := newMemSchedules()
+	uc := NewScheduleService(schedules, mockRepo).(*scheduleUsecase)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	userID := uuid.New()
+	linkID := uuid.NewString()
+	mockRepo.On("GetByID", userID).Return(&userservice.UserModel{ID: userID}, nil)
+
+	_, err := uc.AddReminder(context.Background(), userID, linkID, now.Add(-time.Minute))
+	assert.ErrorIs(t, err, userservice.ErrInvalidInput)
+	_, err = uc.AddReminder(context.Background(), userID, "not-a-link", now.Add(time.Hour))
+	assert.ErrorIs(t, err, userservice.ErrInvalidInput)
+
+	reminder, err := uc.AddReminder(context.Background(), userID, linkID, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, linkID, reminder.LinkID)
+	assert.Len(t, schedules.reminders, 1)
+}
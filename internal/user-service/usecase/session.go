@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+	"github.com/danilovid/linkkeeper/internal/user-service/telegram"
+)
+
+// sessionPrefix marks session tokens, telling them apart from API tokens.
+const sessionPrefix = "ls_"
+
+// SessionConfig configures session tokens.
+type SessionConfig struct {
+	// Secret signs session tokens; every instance of the service needs the same one.
+	Secret []byte
+	// TTL is how long a session token is valid.
+	TTL time.Duration
+}
+
+type sessionUsecase struct {
+	users    userservice.Usecase
+	verifier *telegram.Verifier
+	cfg      SessionConfig
+	now      func() time.Time
+}
+
+func NewSessionService(users userservice.Usecase, verifier *telegram.Verifier, cfg SessionConfig) userservice.SessionUsecase {
+	return &sessionUsecase{users: users, verifier: verifier, cfg: cfg, now: time.Now}
+}
+
+func (u *sessionUsecase) LoginWithWidget(ctx context.Context, fields map[string]string) (*userservice.Session, error) {
+	identity, err := u.verifier.VerifyLoginWidget(fields)
+	if err != nil {
+		return nil, err
+	}
+	return u.login(ctx, identity)
+}
+
+func (u *sessionUsecase) LoginWithWebApp(ctx context.Context, initData string) (*userservice.Session, error) {
+	identity, err := u.verifier.VerifyWebAppInitData(initData)
+	if err != nil {
+		return nil, err
+	}
+	return u.login(ctx, identity)
+}
+
+// login registers the Telegram user on first login, the same way the bot does,
+// so that the app and the bot share the user's links.
+func (u *sessionUsecase) login(ctx context.Context, identity telegram.Identity) (*userservice.Session, error) {
+	user, _, err := u.users.GetOrCreateUser(ctx, identity.ID, identity.Username, identity.FirstName, identity.LastName)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := u.now().Add(u.cfg.TTL).Truncate(time.Second)
+	return &userservice.Session{
+		Token:     u.sign(user.ID, expiresAt),
+		ExpiresAt: expiresAt,
+		User:      user,
+	}, nil
+}
+
+// A session token is "ls_" followed by the user id and expiry and their
+// HMAC-SHA256, base64url-encoded and separated by a dot.
+func (u *sessionUsecase) sign(userID uuid.UUID, expiresAt time.Time) string {
+	payload := make([]byte, 0, 24)
+	payload = append(payload, userID[:]...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(expiresAt.Unix()))
+	return sessionPrefix + base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(u.mac(payload))
+}
+
+func (u *sessionUsecase) mac(payload []byte) []byte {
+	mac := hmac.New(sha256.New, u.cfg.Secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// ResolveSession also checks that the user still exists.
+func (u *sessionUsecase) ResolveSession(ctx context.Context, token string) (uuid.UUID, error) {
+	rest, ok := strings.CutPrefix(token, sessionPrefix)
+	if !ok {
+		return uuid.Nil, userservice.ErrNotFound
+	}
+	rawPayload, rawMAC, ok := strings.Cut(rest, ".")
+	if !ok {
+		return uuid.Nil, userservice.ErrNotFound
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(rawPayload)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, userservice.ErrNotFound
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(rawMAC)
+	if err != nil || !hmac.Equal(sig, u.mac(payload)) {
+		return uuid.Nil, userservice.ErrNotFound
+	}
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if !u.now().Before(expiresAt) {
+		return uuid.Nil, userservice.ErrNotFound
+	}
+	userID, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return uuid.Nil, userservice.ErrNotFound
+	}
+	if _, err := u.users.GetUserByID(ctx, userID); err != nil {
+		// ErrNotFound for deleted users; a failing database is not an invalid session.
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
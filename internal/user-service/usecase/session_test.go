@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+	"github.com/danilovid/linkkeeper/internal/user-service/telegram"
+)
+
+const testBotToken = "123456:test-bot-token"
+
+// signedInitData returns Mini App initData for the Telegram user 42, signed
+// with testBotToken.
+func signedInitData(authDate time.Time) string {
+	values := url.Values{}
+	values.Set("user", `{"id":42,"first_name":"Ann","username":"ann"}`)
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, key+"="+values.Get(key))
+	}
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(testBotToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return values.Encode()
+}
+
+func newTestSessions(repo *MockRepository, secret string) *sessionUsecase {
+	return NewSessionService(
+		NewUserService(repo),
+		telegram.NewVerifier(testBotToken, time.Hour),
+		SessionConfig{Secret: []byte(secret), TTL: time.Hour},
+	).(*sessionUsecase)
+}
+
+func TestSessionUsecase_LoginAndResolve(t *testing.T) {
+	mockRepo := new(MockRepository)
+	sessions := newTestSessions(mockRepo, "secret")
+	userID := uuid.New()
+	mockRepo.On("Upsert", mock.AnythingOfType("*userservice.UserModel")).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*userservice.UserModel).ID = userID
+		}).
+		Return(false, nil)
+	mockRepo.On("GetByID", userID).Return(&userservice.UserModel{ID: userID, TelegramID: 42}, nil)
+
+	session, err := sessions.LoginWithWebApp(context.Background(), signedInitData(time.Now()))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(session.Token, sessionPrefix))
+	assert.Equal(t, userID, session.User.ID)
+	assert.Equal(t, "ann", session.User.Username)
+
+	resolved, err := sessions.ResolveSession(context.Background(), session.Token)
+	require.NoError(t, err)
+	assert.Equal(t, userID, resolved)
+}
+
+func TestSessionUsecase_LoginRegistersNewUser(t *testing.T) {
+	mockRepo := new(MockRepository)
+	sessions := newTestSessions(mockRepo, "secret")
+	mockRepo.On("Upsert", mock.MatchedBy(func(u *userservice.UserModel) bool {
+		return u.TelegramID == 42 && u.Username == "ann"
+	})).Return(true, nil)
+
+	_, err := sessions.LoginWithWebApp(context.Background(), signedInitData(time.Now()))
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSessionUsecase_LoginRejectsForgedData(t *testing.T) {
+	mockRepo := new(MockRepository)
+	sessions := newTestSessions(mockRepo, "secret")
+
+	_, err := sessions.LoginWithWebApp(context.Background(), strings.Replace(signedInitData(time.Now()), "ann", "bob", 1))
+	assert.ErrorIs(t, err, userservice.ErrUnauthorized)
+	mockRepo.AssertNotCalled(t, "Upsert", mock.Anything)
+}
+
+func TestSessionUsecase_ResolveSession_Invalid(t *testing.T) {
+	mockRepo := new(MockRepository)
+	sessions := newTestSessions(mockRepo, "secret")
+	user := &userservice.UserModel{ID: uuid.New()}
+	mockRepo.On("GetByID", user.ID).Return(user, nil)
+	token := sessions.sign(user.ID, time.Now().Add(time.Hour))
+
+	other := newTestSessions(mockRepo, "other secret")
+	_, err := other.ResolveSession(context.Background(), token)
+	assert.ErrorIs(t, err, userservice.ErrNotFound, "signed with another secret")
+
+	expired := sessions.sign(user.ID, time.Now().Add(-time.Second))
+	_, err = sessions.ResolveSession(context.Background(), expired)
+	assert.ErrorIs(t, err, userservice.ErrNotFound, "expired")
+
+	_, err = sessions.ResolveSession(context.Background(), token[:len(token)-2])
+	assert.ErrorIs(t, err, userservice.ErrNotFound, "truncated")
+
+	_, err = sessions.ResolveSession(context.Background(), "lk_not-a-session")
+	assert.ErrorIs(t, err, userservice.ErrNotFound, "api token")
+}
+
+func TestSessionUsecase_ResolveSession_DeletedUser(t *testing.T) {
+	mockRepo := new(MockRepository)
+	sessions := newTestSessions(mockRepo, "secret")
+	userID := uuid.New()
+	mockRepo.On("GetByID", userID).Return(nil, userservice.ErrNotFound)
+
+	_, err := sessions.ResolveSession(context.Background(), sessions.sign(userID, time.Now().Add(time.Hour)))
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+var (
+	// languagePattern accepts ISO 639-1 language codes.
+	languagePattern = regexp.MustCompile(`^[a-z]{2}$`)
+	// resourcePattern accepts resource names as the api-service stores them.
+	resourcePattern = regexp.MustCompile(`^[a-z]{1,32}$`)
+)
+
+// defaultDigest is the digest a user gets when turning it on in the settings.
+var defaultDigest = userservice.DigestSchedule{
+	Frequency: userservice.DigestDaily,
+	Minute:    9 * 60,
+	Count:     defaultDigestCount,
+}
+
+type settingsUsecase struct {
+	settings  userservice.SettingsRepository
+	users     userservice.Repository
+	schedules userservice.ScheduleUsecase
+}
+
+func NewSettingsService(settings userservice.SettingsRepository, users userservice.Repository, schedules userservice.ScheduleUsecase) userservice.SettingsUsecase {
+	return &settingsUsecase{settings: settings, users: users, schedules: schedules}
+}
+
+func (u *settingsUsecase) GetSettings(ctx context.Context, userID uuid.UUID) (*userservice.Settings, error) {
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("user %s: %w", userID, err)
+	}
+	stored, err := u.settings.GetSettings(ctx, userID)
+	if errors.Is(err, userservice.ErrNotFound) {
+		defaults := userservice.DefaultSettings(userID)
+		stored = &defaults
+	} else if err != nil {
+		return nil, err
+	}
+
+	settings := &userservice.Settings{SettingsModel: *stored}
+	digest, err := u.schedules.GetDigest(ctx, userID)
+	if err != nil && !errors.Is(err, userservice.ErrNotFound) {
+		return nil, err
+	}
+	settings.Digest = digest
+	return settings, nil
+}
+
+// UpdateSettings applies patch. The digest follows the settings' timezone:
+// changing the timezone moves an existing digest along with it.
+func (u *settingsUsecase) UpdateSettings(ctx context.Context, userID uuid.UUID, patch userservice.SettingsPatch) (*userservice.Settings, error) {
+	current, err := u.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := current.SettingsModel
+	if patch.Timezone != nil {
+		next.Timezone = *patch.Timezone
+	}
+	if patch.Language != nil {
+		next.Language = *patch.Language
+	}
+	if patch.DefaultResource != nil {
+		next.DefaultResource = *patch.DefaultResource
+	}
+	if patch.AutoSave != nil {
+		next.AutoSave = *patch.AutoSave
+	}
+	if err := validateSettings(next); err != nil {
+		return nil, err
+	}
+
+	// The digest is validated when it is saved, so it goes first: a rejected
+	// digest leaves the other settings unchanged as well.
+	digest := current.Digest
+	switch {
+	case patch.Digest != nil && patch.Digest.Enabled != nil && !*patch.Digest.Enabled:
+		if digest != nil {
+			if err := u.schedules.DisableDigest(ctx, userID); err != nil && !errors.Is(err, userservice.ErrNotFound) {
+				return nil, err
+			}
+		}
+		digest = nil
+	case patch.Digest != nil || (digest != nil && next.Timezone != digest.Timezone && patch.Timezone != nil):
+		schedule := applyDigestPatch(digest, patch.Digest)
+		schedule.Timezone = next.Timezone
+		digest, err = u.schedules.SetDigest(ctx, userID, schedule)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.settings.SaveSettings(ctx, &next); err != nil {
+		return nil, err
+	}
+	return &userservice.Settings{SettingsModel: next, Digest: digest}, nil
+}
+
+func validateSettings(s userservice.SettingsModel) error {
+	if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "" || s.Timezone == "Local" {
+		return fmt.Errorf("%w: unknown timezone %q", userservice.ErrInvalidInput, s.Timezone)
+	}
+	if !languagePattern.MatchString(s.Language) {
+		return fmt.Errorf("%w: language must be a two-letter code", userservice.ErrInvalidInput)
+	}
+	if s.DefaultResource != "" && !resourcePattern.MatchString(s.DefaultResource) {
+		return fmt.Errorf("%w: invalid default resource %q", userservice.ErrInvalidInput, s.DefaultResource)
+	}
+	return nil
+}
+
+// applyDigestPatch returns the schedule of digest, or of the default digest
+// when it is off, with the fields of patch applied.
+func applyDigestPatch(digest *userservice.DigestModel, patch *userservice.DigestPatch) userservice.DigestSchedule {
+	schedule := defaultDigest
+	if digest != nil {
+		schedule = userservice.DigestSchedule{
+			Frequency: digest.Frequency,
+			Weekday:   digest.Weekday,
+			Minute:    digest.Minute,
+			Count:     digest.Count,
+			Resource:  digest.Resource,
+		}
+	}
+	if patch == nil {
+		return schedule
+	}
+	if patch.Frequency != nil {
+		schedule.Frequency = *patch.Frequency
+	}
+	if patch.Weekday != nil {
+		schedule.Weekday = *patch.Weekday
+	}
+	if patch.Minute != nil {
+		schedule.Minute = *patch.Minute
+	}
+	if patch.Count != nil {
+		schedule.Count = *patch.Count
+	}
+	if patch.Resource != nil {
+		schedule.Resource = *patch.Resource
+	}
+	return schedule
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+// memSettings keeps settings by user.
+type memSettings map[uuid.UUID]userservice.SettingsModel
+
+func (m memSettings) GetSettings(_ context.Context, userID uuid.UUID) (*userservice.SettingsModel, error) {
+	settings, ok := m[userID]
+	if !ok {
+		return nil, userservice.ErrNotFound
+	}
+	return &settings, nil
+}
+
+func (m memSettings) SaveSettings(_ context.Context, settings *userservice.SettingsModel) error {
+	m[settings.UserID] = *settings
+	return nil
+}
+
+func newTestSettings(t *testing.T) (*settingsUsecase, memSettings, *memSchedules, uuid.UUID) {
+	mockRepo := new(MockRepository)
+	userID := uuid.New()
+	mockRepo.On("GetByID", userID).Return(&userservice.UserModel{ID: userID}, nil)
+	schedules := newMemSchedules()
+	scheduleUC := NewScheduleService(schedules, mockRepo).(*scheduleUsecase)
+	scheduleUC.now = func() time.Time { return time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC) }
+	settings := memSettings{}
+	return NewSettingsService(settings, mockRepo, scheduleUC).(*settingsUsecase), settings, schedules, userID
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSettingsUsecase_Defaults(t *testing.T) {
+	uc, _, _, userID := newTestSettings(t)
+
+	settings, err := uc.GetSettings(context.Background(), userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, userservice.DefaultSettings(userID), settings.SettingsModel)
+	assert.Nil(t, settings.Digest)
+}
+
+func TestSettingsUsecase_PartialUpdate(t *testing.T) {
+	uc, stored, _, userID := newTestSettings(t)
+
+	_, err := uc.UpdateSettings(context.Background(), userID, userservice.SettingsPatch{Language: ptr("ru"), AutoSave: ptr(false)})
+	require.NoError(t, err)
+	settings, err := uc.UpdateSettings(context.Background(), userID, userservice.SettingsPatch{DefaultResource: ptr("video")})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ru", settings.Language)
+	assert.Equal(t, "video", settings.DefaultResource)
+	assert.False(t, settings.AutoSave)
+	assert.Equal(t, "UTC", settings.Timezone)
+	assert.Equal(t, settings.SettingsModel, stored[userID])
+}
+
+func TestSettingsUsecase_Invalid(t *testing.T) {
+	uc, stored, schedules, userID := newTestSettings(t)
+
+	for name, patch := range map[string]userservice.SettingsPatch{
+		"timezone": {Timezone: ptr("Mars/Olympus")},
+		"language": {Language: ptr("english")},
+		"resource": {DefaultResource: ptr("Video!")},
+		"digest":   {Language: ptr("de"), Digest: &userservice.DigestPatch{Count: ptr(100)}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := uc.UpdateSettings(context.Background(), userID, patch)
+			assert.ErrorIs(t, err, userservice.ErrInvalidInput)
+		})
+	}
+	assert.Empty(t, stored, "nothing is saved")
+	assert.Empty(t, schedules.digests)
+}
+
+func TestSettingsUsecase_Digest(t *testing.T) {
+	uc, _, schedules, userID := newTestSettings(t)
+
+	settings, err := uc.UpdateSettings(context.Background(), userID, userservice.SettingsPatch{
+		Timezone: ptr("Europe/Berlin"),
+		Digest:   &userservice.DigestPatch{Count: ptr(3)},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, settings.Digest)
+	assert.Equal(t, userservice.DigestDaily, settings.Digest.Frequency)
+	assert.Equal(t, 9*60, settings.Digest.Minute)
+	assert.Equal(t, 3, settings.Digest.Count)
+	assert.Equal(t, "Europe/Berlin", settings.Digest.Timezone)
+
+	// The digest moves along with the timezone.
+	settings, err = uc.UpdateSettings(context.Background(), userID, userservice.SettingsPatch{Timezone: ptr("Asia/Tokyo")})
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", schedules.digests[userID].Timezone)
+	assert.Equal(t, 3, settings.Digest.Count)
+
+	settings, err = uc.UpdateSettings(context.Background(), userID, userservice.SettingsPatch{Digest: &userservice.DigestPatch{Enabled: ptr(false)}})
+	require.NoError(t, err)
+	assert.Nil(t, settings.Digest)
+	assert.Empty(t, schedules.digests)
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userservice "github.com/danilovid/linkkeeper/internal/user-service"
+)
+
+// memTokens keeps tokens by id and counts writes of their last use.
+type memTokens struct {
+	tokens  map[uuid.UUID]userservice.APITokenModel
+	touches int
+}
+
+func newMemTokens() *memTokens {
+	return &memTokens{tokens: map[uuid.UUID]userservice.APITokenModel{}}
+}
+
+func (m *memTokens) CreateToken(_ context.Context, token *userservice.APITokenModel) error {
+	token.ID = uuid.New()
+	token.CreatedAt = time.Now()
+	m.tokens[token.ID] = *token
+	return nil
+}
+
+func (m *memTokens) ListTokens(_ context.Context, userID uuid.UUID) ([]userservice.APITokenModel, error) {
+	var out []userservice.APITokenModel
+	for _, token := range m.tokens {
+		if token.UserID == userID {
+			out = append(out, token)
+		}
+	}
+	return out, nil
+}
+
+func (m *memTokens) DeleteToken(_ context.Context, userID, tokenID uuid.UUID) error {
+	token, ok := m.tokens[tokenID]
+	if !ok || token.UserID != userID {
+		return userservice.ErrNotFound
+	}
+	delete(m.tokens, tokenID)
+	return nil
+}
+
+func (m *memTokens) GetTokenByHash(_ context.Context, tokenHash string) (*userservice.APITokenModel, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, userservice.ErrNotFound
+}
+
+func (m *memTokens) TouchToken(_ context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	token := m.tokens[tokenID]
+	token.LastUsedAt = &usedAt
+	m.tokens[tokenID] = token
+	m.touches++
+	return nil
+}
+
+func TestTokenUsecase_IssueAndResolve(t *testing.T) {
+	mockRepo := new(MockRepository)
+	tokens := newMemTokens()
+	uc := NewTokenService(tokens, mockRepo).(*tokenUsecase)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	userID := uuid.New()
+	mockRepo.On("GetByID", userID).Return(&userservice.UserModel{ID: userID}, nil)
+
+	record, token, err := uc.IssueToken(context.Background(), userID, " laptop ")
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "lk_"))
+	assert.Equal(t, "laptop", record.Name)
+	assert.Equal(t, token[:8], record.Prefix)
+	assert.NotContains(t, record.TokenHash, token, "only a hash of the token is stored")
+
+	resolved, err := uc.ResolveToken(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, userID, resolved.UserID)
+	assert.Equal(t, now, *resolved.LastUsedAt)
+
+	now = now.Add(10 * time.Second)
+	_, err = uc.ResolveToken(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, 1, tokens.touches, "last use is written at most once a minute")
+
+	_, err = uc.ResolveToken(context.Background(), token+"x")
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+	_, err = uc.ResolveToken(context.Background(), "not-a-token")
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+
+	require.NoError(t, uc.RevokeToken(context.Background(), userID, record.ID))
+	_, err = uc.ResolveToken(context.Background(), token)
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTokenUsecase_IssueToken_UnknownUser(t *testing.T) {
+	mockRepo := new(MockRepository)
+	uc := NewTokenService(newMemTokens(), mockRepo)
+	userID := uuid.New()
+	mockRepo.On("GetByID", userID).Return(nil, userservice.ErrNotFound)
+
+	_, _, err := uc.IssueToken(context.Background(), userID, "")
+
+	assert.ErrorIs(t, err, userservice.ErrNotFound)
+}
+
+func TestTokenUsecase_IssueToken_LongName(t *testing.T) {
+	uc := NewTokenService(newMemTokens(), new(MockRepository))
+
+	_, _, err := uc.IssueToken(context.Background(), uuid.New(), strings.Repeat("n", 256))
+
+	assert.ErrorIs(t, err, userservice.ErrInvalidInput)
+}
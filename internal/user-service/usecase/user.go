@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 
 	userservice "github.com/danilovid/linkkeeper/internal/user-service"
@@ -14,7 +16,7 @@ func NewUserService(repo userservice.Repository) userservice.Usecase {
 	return &userUsecase{repo: repo}
 }
 
-func (u *userUsecase) CreateUser(telegramID int64, username, firstName, lastName string) (*userservice.UserModel, error) {
+func (u *userUsecase) CreateUser(ctx context.Context, telegramID int64, username, firstName, lastName string) (*userservice.UserModel, error) {
 	user := &userservice.UserModel{
 		TelegramID: telegramID,
 		Username:   username,
@@ -22,30 +24,35 @@ func (u *userUsecase) CreateUser(telegramID int64, username, firstName, lastName
 		LastName:   lastName,
 	}
 
-	if err := u.repo.Create(user); err != nil {
+	if err := u.repo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
-func (u *userUsecase) GetUserByID(id uuid.UUID) (*userservice.UserModel, error) {
-	return u.repo.GetByID(id)
+func (u *userUsecase) GetUserByID(ctx context.Context, id uuid.UUID) (*userservice.UserModel, error) {
+	return u.repo.GetByID(ctx, id)
 }
 
-func (u *userUsecase) GetUserByTelegramID(telegramID int64) (*userservice.UserModel, error) {
-	return u.repo.GetByTelegramID(telegramID)
+func (u *userUsecase) GetUserByTelegramID(ctx context.Context, telegramID int64) (*userservice.UserModel, error) {
+	return u.repo.GetByTelegramID(ctx, telegramID)
 }
 
-func (u *userUsecase) GetOrCreateUser(telegramID int64, username, firstName, lastName string) (*userservice.UserModel, error) {
-	user, err := u.repo.GetByTelegramID(telegramID)
-	if err == nil {
-		return user, nil
+func (u *userUsecase) GetOrCreateUser(ctx context.Context, telegramID int64, username, firstName, lastName string) (*userservice.UserModel, bool, error) {
+	user := &userservice.UserModel{
+		TelegramID: telegramID,
+		Username:   username,
+		FirstName:  firstName,
+		LastName:   lastName,
 	}
-
-	return u.CreateUser(telegramID, username, firstName, lastName)
+	created, err := u.repo.Upsert(ctx, user)
+	if err != nil {
+		return nil, false, err
+	}
+	return user, created, nil
 }
 
-func (u *userUsecase) UserExists(telegramID int64) (bool, error) {
-	return u.repo.Exists(telegramID)
+func (u *userUsecase) UserExists(ctx context.Context, telegramID int64) (bool, error) {
+	return u.repo.Exists(ctx, telegramID)
 }
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -15,12 +16,22 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) Create(user *userservice.UserModel) error {
+func (m *MockRepository) Create(_ context.Context, user *userservice.UserModel) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockRepository) GetByID(id uuid.UUID) (*userservice.UserModel, error) {
+func (m *MockRepository) Upsert(_ context.Context, user *userservice.UserModel) (bool, error) {
+	args := m.Called(user)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) Delete(_ context.Context, id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetByID(_ context.Context, id uuid.UUID) (*userservice.UserModel, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -28,7 +39,7 @@ func (m *MockRepository) GetByID(id uuid.UUID) (*userservice.UserModel, error) {
 	return args.Get(0).(*userservice.UserModel), args.Error(1)
 }
 
-func (m *MockRepository) GetByTelegramID(telegramID int64) (*userservice.UserModel, error) {
+func (m *MockRepository) GetByTelegramID(_ context.Context, telegramID int64) (*userservice.UserModel, error) {
 	args := m.Called(telegramID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,12 +47,12 @@ func (m *MockRepository) GetByTelegramID(telegramID int64) (*userservice.UserMod
 	return args.Get(0).(*userservice.UserModel), args.Error(1)
 }
 
-func (m *MockRepository) Update(user *userservice.UserModel) error {
+func (m *MockRepository) Update(_ context.Context, user *userservice.UserModel) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockRepository) Exists(telegramID int64) (bool, error) {
+func (m *MockRepository) Exists(_ context.Context, telegramID int64) (bool, error) {
 	args := m.Called(telegramID)
 	return args.Bool(0), args.Error(1)
 }
@@ -52,7 +63,7 @@ func TestUserUsecase_CreateUser(t *testing.T) {
 
 	mockRepo.On("Create", mock.AnythingOfType("*userservice.UserModel")).Return(nil)
 
-	user, err := uc.CreateUser(123456789, "testuser", "Test", "User")
+	user, err := uc.CreateUser(context.Background(), 123456789, "testuser", "Test", "User")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
@@ -69,7 +80,7 @@ func TestUserUsecase_CreateUser_Error(t *testing.T) {
 
 	mockRepo.On("Create", mock.AnythingOfType("*userservice.UserModel")).Return(errors.New("db error"))
 
-	user, err := uc.CreateUser(123456789, "testuser", "Test", "User")
+	user, err := uc.CreateUser(context.Background(), 123456789, "testuser", "Test", "User")
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
@@ -88,7 +99,7 @@ func TestUserUsecase_GetUserByID(t *testing.T) {
 
 	mockRepo.On("GetByID", expectedUser.ID).Return(expectedUser, nil)
 
-	user, err := uc.GetUserByID(expectedUser.ID)
+	user, err := uc.GetUserByID(context.Background(), expectedUser.ID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedUser, user)
@@ -107,7 +118,7 @@ func TestUserUsecase_GetUserByTelegramID(t *testing.T) {
 
 	mockRepo.On("GetByTelegramID", int64(123456789)).Return(expectedUser, nil)
 
-	user, err := uc.GetUserByTelegramID(123456789)
+	user, err := uc.GetUserByTelegramID(context.Background(), 123456789)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedUser, user)
@@ -118,19 +129,19 @@ func TestUserUsecase_GetOrCreateUser_Existing(t *testing.T) {
 	mockRepo := new(MockRepository)
 	uc := NewUserService(mockRepo)
 
-	existingUser := &userservice.UserModel{
-		ID:         uuid.New(),
-		TelegramID: 123456789,
-		Username:   "existinguser",
-	}
-
-	mockRepo.On("GetByTelegramID", int64(123456789)).Return(existingUser, nil)
+	existingID := uuid.New()
+	mockRepo.On("Upsert", mock.AnythingOfType("*userservice.UserModel")).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*userservice.UserModel).ID = existingID
+		}).
+		Return(false, nil)
 
-	user, err := uc.GetOrCreateUser(123456789, "newusername", "New", "User")
+	user, created, err := uc.GetOrCreateUser(context.Background(), 123456789, "newusername", "New", "User")
 
 	assert.NoError(t, err)
-	assert.Equal(t, existingUser, user)
-	assert.Equal(t, "existinguser", user.Username)
+	assert.False(t, created)
+	assert.Equal(t, existingID, user.ID)
+	assert.Equal(t, "newusername", user.Username)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -138,12 +149,12 @@ func TestUserUsecase_GetOrCreateUser_New(t *testing.T) {
 	mockRepo := new(MockRepository)
 	uc := NewUserService(mockRepo)
 
-	mockRepo.On("GetByTelegramID", int64(123456789)).Return(nil, errors.New("not found"))
-	mockRepo.On("Create", mock.AnythingOfType("*userservice.UserModel")).Return(nil)
+	mockRepo.On("Upsert", mock.AnythingOfType("*userservice.UserModel")).Return(true, nil)
 
-	user, err := uc.GetOrCreateUser(123456789, "newuser", "New", "User")
+	user, created, err := uc.GetOrCreateUser(context.Background(), 123456789, "newuser", "New", "User")
 
 	assert.NoError(t, err)
+	assert.True(t, created)
 	assert.NotNil(t, user)
 	assert.Equal(t, int64(123456789), user.TelegramID)
 	assert.Equal(t, "newuser", user.Username)
@@ -151,13 +162,27 @@ func TestUserUsecase_GetOrCreateUser_New(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUserUsecase_GetOrCreateUser_Error(t *testing.T) {
+	mockRepo := new(MockRepository)
+	uc := NewUserService(mockRepo)
+
+	mockRepo.On("Upsert", mock.AnythingOfType("*userservice.UserModel")).Return(false, errors.New("db error"))
+
+	user, created, err := uc.GetOrCreateUser(context.Background(), 123456789, "newuser", "New", "User")
+
+	assert.Error(t, err)
+	assert.False(t, created)
+	assert.Nil(t, user)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestUserUsecase_UserExists(t *testing.T) {
 	mockRepo := new(MockRepository)
 	uc := NewUserService(mockRepo)
 
 	mockRepo.On("Exists", int64(123456789)).Return(true, nil)
 
-	exists, err := uc.UserExists(123456789)
+	exists, err := uc.UserExists(context.Background(), 123456789)
 
 	assert.NoError(t, err)
 	assert.True(t, exists)
@@ -170,7 +195,7 @@ func TestUserUsecase_UserExists_NotFound(t *testing.T) {
 
 	mockRepo.On("Exists", int64(999999999)).Return(false, nil)
 
-	exists, err := uc.UserExists(999999999)
+	exists, err := uc.UserExists(context.Background(), 999999999)
 
 	assert.NoError(t, err)
 	assert.False(t, exists)
@@ -0,0 +1,18 @@
+-- link_models.user_id may already exist without a foreign key when the
+-- api-service auto-migrated the column before 002_users.sql ran.
+DO $$
+BEGIN
+  IF to_regclass('link_models') IS NOT NULL THEN
+    IF NOT EXISTS (
+      SELECT 1
+      FROM pg_constraint
+      WHERE conrelid = to_regclass('link_models')
+        AND confrelid = to_regclass('users')
+        AND contype = 'f'
+    ) THEN
+      ALTER TABLE link_models
+        ADD CONSTRAINT fk_link_models_user
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+    END IF;
+  END IF;
+END $$;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS link_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    link_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    viewed_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_views_link_id ON link_views(link_id);
+CREATE INDEX IF NOT EXISTS idx_link_views_user_viewed_at ON link_views(user_id, viewed_at);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1
+    FROM pg_constraint
+    WHERE conrelid = to_regclass('link_views')
+      AND confrelid = to_regclass('link_models')
+      AND contype = 'f'
+  ) THEN
+    ALTER TABLE link_views
+      ADD CONSTRAINT fk_link_views_link
+      FOREIGN KEY (link_id) REFERENCES link_models(id) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+-- Only the most recent view of each link survived before view events existed;
+-- keep it so that the history does not start empty.
+INSERT INTO link_views (id, link_id, user_id, viewed_at)
+SELECT gen_random_uuid(), id, user_id, viewed_at
+FROM link_models
+WHERE viewed_at IS NOT NULL
+  AND user_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM link_views WHERE link_views.link_id = link_models.id);
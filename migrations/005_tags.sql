@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tags_user_name ON tags(user_id, name);
+
+CREATE TABLE IF NOT EXISTS link_tags (
+    link_id UUID NOT NULL REFERENCES link_models(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (link_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_tags_tag_id ON link_tags(tag_id);
+
+-- The tables may have been auto-migrated before this file ran; add the
+-- cascading foreign keys they would be missing in that case.
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint
+    WHERE conrelid = to_regclass('tags') AND confrelid = to_regclass('users') AND contype = 'f'
+  ) THEN
+    ALTER TABLE tags
+      ADD CONSTRAINT fk_tags_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint
+    WHERE conrelid = to_regclass('link_tags') AND confrelid = to_regclass('link_models') AND contype = 'f'
+  ) THEN
+    ALTER TABLE link_tags
+      ADD CONSTRAINT fk_link_tags_link FOREIGN KEY (link_id) REFERENCES link_models(id) ON DELETE CASCADE;
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint
+    WHERE conrelid = to_regclass('link_tags') AND confrelid = to_regclass('tags') AND contype = 'f'
+  ) THEN
+    ALTER TABLE link_tags
+      ADD CONSTRAINT fk_link_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE;
+  END IF;
+END $$;
@@ -0,0 +1,13 @@
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+
+-- Weighted document for full-text search: title ranks above notes above the URL.
+-- Tags live in another table and are folded in at query time.
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS search_vector tsvector
+  GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(notes, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(url, '')), 'C')
+  ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_link_models_search_vector ON link_models USING GIN (search_vector);
@@ -0,0 +1,10 @@
+-- Page metadata filled in by the enricher after a link is saved.
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS meta_description TEXT NOT NULL DEFAULT '';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS meta_site_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS meta_image_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS meta_canonical_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS meta_language TEXT NOT NULL DEFAULT '';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS meta_favicon_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS meta_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS meta_content_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS meta_fetched_at TIMESTAMPTZ;
@@ -0,0 +1,7 @@
+-- Canonical form of the URL used to detect duplicates. Links saved before this
+-- migration keep an empty value until duplicates are merged for their owner.
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS canonical_url TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_link_models_user_canonical_url
+  ON link_models (user_id, canonical_url)
+  WHERE canonical_url <> '';
@@ -0,0 +1,11 @@
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'unread';
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS reading_at TIMESTAMPTZ;
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS done_at TIMESTAMPTZ;
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_link_models_user_status ON link_models (user_id, status);
+
+-- Links that were marked viewed before statuses existed count as done.
+UPDATE link_models
+SET status = 'done', done_at = viewed_at
+WHERE views > 0 AND status = 'unread' AND done_at IS NULL;
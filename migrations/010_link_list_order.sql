@@ -0,0 +1,4 @@
+-- Keyset pagination orders links by a sort key and then by id.
+CREATE INDEX IF NOT EXISTS idx_link_models_user_created ON link_models (user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_link_models_user_updated ON link_models (user_id, updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_link_models_user_title ON link_models (user_id, lower(title), id);
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    format TEXT NOT NULL,
+    status TEXT NOT NULL,
+    payload BYTEA,
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    created INTEGER NOT NULL DEFAULT 0,
+    duplicates INTEGER NOT NULL DEFAULT 0,
+    invalid INTEGER NOT NULL DEFAULT 0,
+    errors TEXT,
+    error TEXT NOT NULL DEFAULT '',
+    attempt INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_user_id ON import_jobs (user_id, created_at);
+-- Workers look for queued jobs and for running ones that stopped making progress.
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs (status, created_at)
+    WHERE status IN ('queued', 'running');
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1
+    FROM pg_constraint
+    WHERE conrelid = to_regclass('import_jobs')
+      AND confrelid = to_regclass('users')
+      AND contype = 'f'
+  ) THEN
+    ALTER TABLE import_jobs
+      ADD CONSTRAINT fk_import_jobs_user
+      FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+  END IF;
+END $$;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS feed_tokens (
+    user_id UUID PRIMARY KEY,
+    token_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feed_tokens_token_hash ON feed_tokens (token_hash);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1
+    FROM pg_constraint
+    WHERE conrelid = to_regclass('feed_tokens')
+      AND confrelid = to_regclass('users')
+      AND contype = 'f'
+  ) THEN
+    ALTER TABLE feed_tokens
+      ADD CONSTRAINT fk_feed_tokens_user
+      FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+  END IF;
+END $$;
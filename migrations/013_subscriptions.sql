@@ -0,0 +1,57 @@
+ALTER TABLE link_models ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    feed_url TEXT NOT NULL,
+    site_url TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    tags TEXT,
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    last_polled_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    error_count INTEGER NOT NULL DEFAULT 0,
+    next_poll_at TIMESTAMPTZ NOT NULL,
+    attempt INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_subscriptions_user_feed_url ON subscriptions (user_id, feed_url);
+-- Pollers pick the feed that has been due the longest.
+CREATE INDEX IF NOT EXISTS idx_subscriptions_next_poll_at ON subscriptions (next_poll_at);
+
+-- Keys of the entries each feed had at its last poll; entries not listed are new.
+CREATE TABLE IF NOT EXISTS subscription_entries (
+    subscription_id UUID NOT NULL,
+    entry_key TEXT NOT NULL,
+    PRIMARY KEY (subscription_id, entry_key)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1
+    FROM pg_constraint
+    WHERE conrelid = to_regclass('subscriptions')
+      AND confrelid = to_regclass('users')
+      AND contype = 'f'
+  ) THEN
+    ALTER TABLE subscriptions
+      ADD CONSTRAINT fk_subscriptions_user
+      FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1
+    FROM pg_constraint
+    WHERE conrelid = to_regclass('subscription_entries')
+      AND confrelid = to_regclass('subscriptions')
+      AND contype = 'f'
+  ) THEN
+    ALTER TABLE subscription_entries
+      ADD CONSTRAINT fk_subscription_entries_subscription
+      FOREIGN KEY (subscription_id) REFERENCES subscriptions(id) ON DELETE CASCADE;
+  END IF;
+END $$;
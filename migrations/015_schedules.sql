@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS digests (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    frequency VARCHAR(16) NOT NULL,
+    weekday INTEGER NOT NULL DEFAULT 0,
+    minute INTEGER NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    count INTEGER NOT NULL,
+    resource VARCHAR(32),
+    next_run_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_digests_next_run_at ON digests(next_run_at);
+
+CREATE TABLE IF NOT EXISTS reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    link_id VARCHAR(36) NOT NULL,
+    remind_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_user_id ON reminders(user_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_remind_at ON reminders(remind_at);
@@ -0,0 +1,150 @@
+// Package auth identifies the user an HTTP request acts for.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/danilovid/linkkeeper/pkg/logger"
+)
+
+// UserIDHeader carries the id of the user (as issued by user-service) a
+// trusted caller acts for.
+const UserIDHeader = "X-User-ID"
+
+// authKeyHeader is an alternative to "Authorization: Bearer <token>" for
+// clients that cannot set the Authorization header.
+const authKeyHeader = "X-Auth-Key"
+
+// ErrInvalidToken is returned by a TokenResolver for unknown, revoked or expired tokens.
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenResolver maps a bearer token to the id of the user it belongs to.
+type TokenResolver interface {
+	ResolveToken(ctx context.Context, token string) (string, error)
+}
+
+// Identity is who a request was authenticated as.
+type Identity struct {
+	UserID string
+	// Service is the id of the key a service signed the request with, or "".
+	Service string
+}
+
+type identityKey struct{}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the identity stored in ctx, if any.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// UserID returns the id of the user ctx was authenticated as, or "".
+func UserID(ctx context.Context) string {
+	id, _ := FromContext(ctx)
+	return id.UserID
+}
+
+// Options configure Middleware.
+type Options struct {
+	// Tokens resolves bearer tokens. Without it every token is rejected.
+	Tokens TokenResolver
+	// Services verifies requests signed by other services, which may name the
+	// user they act for in UserIDHeader. Without it every signed request is
+	// rejected; with it only signed requests may name a user.
+	Services *SignatureVerifier
+	// Required rejects requests that carry neither a token nor a service
+	// signature. When false and no Services are set, the caller is trusted,
+	// as on a private network, and may name the user it acts for in UserIDHeader.
+	Required bool
+	// Public marks requests that authenticate in some other way, or not at
+	// all, and are let through without a token.
+	Public func(*http.Request) bool
+}
+
+// Middleware resolves the request's bearer token and stores the user it
+// belongs to in the request context. Invalid tokens and signatures are
+// rejected with 401 whatever the options say; signed requests that cannot be
+// checked for replays get 503.
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var service string
+			if Signed(r) {
+				var err error
+				if service, err = opts.Services.Verify(r); err != nil {
+					logger.L().Warn().Err(err).Str("path", r.URL.Path).Msg("rejected signed request")
+					if errors.Is(err, ErrTooManyNonces) {
+						http.Error(w, "authentication unavailable", http.StatusServiceUnavailable)
+						return
+					}
+					unauthorized(w)
+					return
+				}
+			}
+			token := Token(r)
+			switch {
+			case token != "":
+				userID, err := resolve(r.Context(), opts.Tokens, token)
+				if errors.Is(err, ErrInvalidToken) {
+					unauthorized(w)
+					return
+				}
+				if err != nil {
+					logger.L().Error().Err(err).Msg("resolve token")
+					http.Error(w, "authentication unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				if claimed := strings.TrimSpace(r.Header.Get(UserIDHeader)); claimed != "" && claimed != userID {
+					http.Error(w, "forbidden", http.StatusForbidden)
+					return
+				}
+				r = r.WithContext(NewContext(r.Context(), Identity{UserID: userID, Service: service}))
+			case service != "" || (opts.Services == nil && !opts.Required):
+				userID := strings.TrimSpace(r.Header.Get(UserIDHeader))
+				if userID != "" || service != "" {
+					r = r.WithContext(NewContext(r.Context(), Identity{UserID: userID, Service: service}))
+				}
+			case !opts.Required && r.Header.Get(UserIDHeader) == "":
+				// An anonymous caller; handlers decide what it may do.
+			case opts.Public == nil || !opts.Public(r):
+				unauthorized(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func resolve(ctx context.Context, tokens TokenResolver, token string) (string, error) {
+	if tokens == nil {
+		return "", ErrInvalidToken
+	}
+	return tokens.ResolveToken(ctx, token)
+}
+
+// Service returns the id of the key ctx's request was signed with, or "".
+func Service(ctx context.Context) string {
+	id, _ := FromContext(ctx)
+	return id.Service
+}
+
+// Token returns the bearer token of a request, or "".
+func Token(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get(authKeyHeader))
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticTokens resolves the tokens in the map and counts the lookups.
+type staticTokens struct {
+	users map[string]string
+	calls int
+	err   error
+}
+
+func (s *staticTokens) ResolveToken(_ context.Context, token string) (string, error) {
+	s.calls++
+	if s.err != nil {
+		return "", s.err
+	}
+	userID, ok := s.users[token]
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	return userID, nil
+}
+
+func serve(t *testing.T, opts Options, req *http.Request) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	var seen string
+	handler := Middleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = UserID(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, seen
+}
+
+func TestMiddleware_Token(t *testing.T) {
+	tokens := &staticTokens{users: map[string]string{"good": "user-1"}}
+	opts := Options{Tokens: tokens, Required: true}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/links", nil)
+	req.Header.Set("Authorization", "Bearer good")
+	rec, seen := serve(t, opts, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "user-1", seen)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/links", nil)
+	req.Header.Set("X-Auth-Key", "good")
+	_, seen = serve(t, opts, req)
+	assert.Equal(t, "user-1", seen)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/links", nil)
+	req.Header.Set("Authorization", "Bearer bad")
+	rec, _ = serve(t, Options{Tokens: tokens}, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "invalid tokens are rejected even when auth is optional")
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/links", nil)
+	req.Header.Set("Authorization", "Bearer good")
+	req.Header.Set(UserIDHeader, "user-2")
+	rec, _ = serve(t, opts, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "a token cannot act for another user")
+}
+
+func TestMiddleware_ResolverDown(t *testing.T) {
+	tokens := &staticTokens{err: errors.New("connection refused")}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/links", nil)
+	req.Header.Set("Authorization", "Bearer good")
+
+	rec, _ := serve(t, Options{Tokens: tokens}, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestMiddleware_Anonymous(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/links", nil)
+	req.Header.Set(UserIDHeader, "user-1")
+
+	rec, seen := serve(t, Options{}, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "user-1", seen, "trusted callers name their user")
+
+	rec, seen = serve(t, Options{Required: true}, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, seen)
+
+	public := func(r *http.Request) bool { return r.URL.Path == "/health" }
+	rec, seen = serve(t, Options{Required: true, Public: public}, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, seen)
+}
+
+func TestCachedResolver(t *testing.T) {
+	tokens := &staticTokens{users: map[string]string{"good": "user-1"}}
+	cache := NewCachedResolver(tokens, time.Minute)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 3 {
+		userID, err := cache.ResolveToken(ctx, "good")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", userID)
+	}
+	assert.Equal(t, 1, tokens.calls)
+
+	_, err := cache.ResolveToken(ctx, "bad")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = cache.ResolveToken(ctx, "bad")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.Equal(t, 3, tokens.calls, "failures are not cached")
+
+	now = now.Add(2 * time.Minute)
+	delete(tokens.users, "good")
+	_, err = cache.ResolveToken(ctx, "good")
+	assert.ErrorIs(t, err, ErrInvalidToken, "expired entries are resolved again")
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// maxCachedTokens bounds the cache; expired entries are dropped once it is reached.
+const maxCachedTokens = 1024
+
+// CachedResolver remembers resolved tokens for a while, so that a service
+// asking another one to resolve tokens does not do so on every request.
+// Revoked tokens keep working until their entry expires.
+type CachedResolver struct {
+	next TokenResolver
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]cachedToken
+}
+
+type cachedToken struct {
+	userID  string
+	expires time.Time
+}
+
+var _ TokenResolver = (*CachedResolver)(nil)
+
+func NewCachedResolver(next TokenResolver, ttl time.Duration) *CachedResolver {
+	return &CachedResolver{
+		next:    next,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[[sha256.Size]byte]cachedToken),
+	}
+}
+
+// ResolveToken answers from the cache when it can. Only successful
+// resolutions are cached.
+func (c *CachedResolver) ResolveToken(ctx context.Context, token string) (string, error) {
+	key := sha256.Sum256([]byte(token))
+	now := c.now()
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.userID, nil
+	}
+
+	userID, err := c.next.ResolveToken(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedTokens {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) < maxCachedTokens {
+		c.entries[key] = cachedToken{userID: userID, expires: now.Add(c.ttl)}
+	}
+	return userID, nil
+}
@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"bytes"
+	"container/heap"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers of a request signed by another service.
+const (
+	signatureKeyHeader       = "X-Signature-Key"
+	signatureTimestampHeader = "X-Signature-Timestamp"
+	signatureNonceHeader     = "X-Signature-Nonce"
+	signatureHeader          = "X-Signature"
+)
+
+// MaxClockSkew is how far the timestamp of a signed request may be off.
+const MaxClockSkew = 5 * time.Minute
+
+// maxNonces bounds the nonces remembered against replays. Expired ones are
+// dropped as they expire; while it is reached by live ones, signed requests
+// are turned away with ErrTooManyNonces.
+const maxNonces = 100_000
+
+// ErrInvalidSignature is returned for signed requests that do not verify.
+var ErrInvalidSignature = errors.New("invalid request signature")
+
+// ErrTooManyNonces is returned for genuine signed requests that cannot be
+// told apart from replays because too many nonces are remembered already.
+var ErrTooManyNonces = errors.New("too many signed requests")
+
+// Key is a secret shared by the services, named so that it can be rotated.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// ParseKeys reads keys from a comma-separated list of "id:secret" pairs.
+// The first key signs requests; all of them are accepted, so a new key can be
+// rolled out to the verifying services before anyone signs with it.
+func ParseKeys(raw string) ([]Key, error) {
+	var keys []Key
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("service key %q: want id:secret", id)
+		}
+		keys = append(keys, Key{ID: id, Secret: []byte(secret)})
+	}
+	return keys, nil
+}
+
+// Signer signs outgoing requests with a key shared with the receiving service.
+type Signer struct {
+	key Key
+	now func() time.Time
+}
+
+// NewSigner returns a Signer that signs with the first of keys, or nil when
+// there are no keys.
+func NewSigner(keys []Key) *Signer {
+	if len(keys) == 0 {
+		return nil
+	}
+	return &Signer{key: keys[0], now: time.Now}
+}
+
+// Sign adds the signature headers to req. The signature covers the method,
+// URL, body, the user the request acts for and a fresh nonce.
+func (s *Signer) Sign(req *http.Request) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set(signatureKeyHeader, s.key.ID)
+	req.Header.Set(signatureTimestampHeader, timestamp)
+	req.Header.Set(signatureNonceHeader, hex.EncodeToString(nonce))
+	req.Header.Set(signatureHeader, hex.EncodeToString(signature(s.key.Secret, req, body)))
+	return nil
+}
+
+// Transport wraps base so that every request it sends is signed. A nil
+// Signer returns base unchanged.
+func (s *Signer) Transport(base http.RoundTripper) http.RoundTripper {
+	if s == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return signingTransport{signer: s, base: base}
+}
+
+type signingTransport struct {
+	signer *Signer
+	base   http.RoundTripper
+}
+
+func (t signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it is given.
+	req = req.Clone(req.Context())
+	if err := t.signer.Sign(req); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// SignatureVerifier checks requests signed by other services and rejects
+// replays of requests it has already seen.
+type SignatureVerifier struct {
+	keys map[string][]byte
+	now  func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]struct{}
+	// expiries holds the remembered nonces, the one expiring first in front.
+	expiries nonceHeap
+}
+
+// NewSignatureVerifier returns a verifier accepting any of keys, or nil when
+// there are no keys.
+func NewSignatureVerifier(keys []Key) *SignatureVerifier {
+	if len(keys) == 0 {
+		return nil
+	}
+	v := &SignatureVerifier{
+		keys:   make(map[string][]byte, len(keys)),
+		now:    time.Now,
+		nonces: make(map[string]struct{}),
+	}
+	for _, key := range keys {
+		v.keys[key.ID] = key.Secret
+	}
+	return v
+}
+
+// Signed reports whether r carries a service signature.
+func Signed(r *http.Request) bool {
+	return r.Header.Get(signatureHeader) != ""
+}
+
+// Verify checks the signature of r and returns the id of the key it was
+// signed with. Errors wrap ErrInvalidSignature, except ErrTooManyNonces.
+func (v *SignatureVerifier) Verify(r *http.Request) (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("%w: no service keys configured", ErrInvalidSignature)
+	}
+	keyID := r.Header.Get(signatureKeyHeader)
+	secret, ok := v.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown key %q", ErrInvalidSignature, keyID)
+	}
+	seconds, err := strconv.ParseInt(r.Header.Get(signatureTimestampHeader), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad timestamp", ErrInvalidSignature)
+	}
+	now := v.now()
+	signedAt := time.Unix(seconds, 0)
+	if signedAt.Before(now.Add(-MaxClockSkew)) || signedAt.After(now.Add(MaxClockSkew)) {
+		return "", fmt.Errorf("%w: timestamp out of range", ErrInvalidSignature)
+	}
+	nonce := r.Header.Get(signatureNonceHeader)
+	if nonce == "" {
+		return "", fmt.Errorf("%w: missing nonce", ErrInvalidSignature)
+	}
+	got, err := hex.DecodeString(r.Header.Get(signatureHeader))
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return "", err
+	}
+	if !hmac.Equal(got, signature(secret, r, body)) {
+		return "", fmt.Errorf("%w: signature does not match", ErrInvalidSignature)
+	}
+	// Checked last, so that only genuine requests use up their nonce.
+	if err := v.remember(keyID+":"+nonce, signedAt.Add(MaxClockSkew), now); err != nil {
+		return "", err
+	}
+	return keyID, nil
+}
+
+// remember records a nonce until expires, failing for nonces already seen.
+func (v *SignatureVerifier) remember(nonce string, expires, now time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for len(v.expiries) > 0 && now.After(v.expiries[0].expires) {
+		delete(v.nonces, heap.Pop(&v.expiries).(nonceExpiry).nonce)
+	}
+	if _, seen := v.nonces[nonce]; seen {
+		return fmt.Errorf("%w: replayed request", ErrInvalidSignature)
+	}
+	if len(v.nonces) >= maxNonces {
+		return ErrTooManyNonces
+	}
+	v.nonces[nonce] = struct{}{}
+	heap.Push(&v.expiries, nonceExpiry{nonce: nonce, expires: expires})
+	return nil
+}
+
+type nonceExpiry struct {
+	nonce   string
+	expires time.Time
+}
+
+// nonceHeap is a min-heap of nonces by expiry, for container/heap.
+type nonceHeap []nonceExpiry
+
+func (h nonceHeap) Len() int           { return len(h) }
+func (h nonceHeap) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+func (h nonceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *nonceHeap) Push(x any)        { *h = append(*h, x.(nonceExpiry)) }
+
+func (h *nonceHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// signature is the HMAC-SHA256 of the parts of a request a signature covers,
+// one per line.
+func signature(secret []byte, r *http.Request, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join([]string{
+		r.Method,
+		r.URL.RequestURI(),
+		r.Header.Get(signatureTimestampHeader),
+		r.Header.Get(signatureNonceHeader),
+		r.Header.Get(UserIDHeader),
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")))
+	return mac.Sum(nil)
+}
+
+// readBody returns the body of r and leaves r with an unread copy of it.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
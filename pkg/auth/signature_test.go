@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oldKey = Key{ID: "k1", Secret: []byte("old secret")}
+	newKey = Key{ID: "k2", Secret: []byte("new secret")}
+)
+
+func signedRequest(t *testing.T, signer *Signer, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/links?x=1", strings.NewReader(body))
+	req.Header.Set(UserIDHeader, "user-1")
+	require.NoError(t, signer.Sign(req))
+	return req
+}
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys(" k2:new secret, k1:old secret ")
+	require.NoError(t, err)
+	assert.Equal(t, []Key{newKey, oldKey}, keys)
+
+	keys, err = ParseKeys("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = ParseKeys("k1")
+	assert.Error(t, err)
+}
+
+func TestSignatureVerifier(t *testing.T) {
+	verifier := NewSignatureVerifier([]Key{oldKey})
+	req := signedRequest(t, NewSigner([]Key{oldKey}), `{"url":"https://go.dev"}`)
+
+	keyID, err := verifier.Verify(req)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keyID)
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"url":"https://go.dev"}`, string(body), "the body is left for the handler")
+}
+
+func TestSignatureVerifier_Tampered(t *testing.T) {
+	verifier := NewSignatureVerifier([]Key{oldKey})
+	signer := NewSigner([]Key{oldKey})
+
+	req := signedRequest(t, signer, `{"url":"https://go.dev"}`)
+	req.Body = io.NopCloser(strings.NewReader(`{"url":"https://evil.example"}`))
+	_, err := verifier.Verify(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "body")
+
+	req = signedRequest(t, signer, "")
+	req.Header.Set(UserIDHeader, "user-2")
+	_, err = verifier.Verify(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "user")
+
+	req = signedRequest(t, signer, "")
+	req.URL.RawQuery = "x=2"
+	_, err = verifier.Verify(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "query")
+
+	req = signedRequest(t, NewSigner([]Key{{ID: "k1", Secret: []byte("guess")}}), "")
+	_, err = verifier.Verify(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "secret")
+}
+
+func TestSignatureVerifier_Replay(t *testing.T) {
+	verifier := NewSignatureVerifier([]Key{oldKey})
+	req := signedRequest(t, NewSigner([]Key{oldKey}), "")
+
+	_, err := verifier.Verify(req)
+	require.NoError(t, err)
+	_, err = verifier.Verify(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestSignatureVerifier_Stale(t *testing.T) {
+	verifier := NewSignatureVerifier([]Key{oldKey})
+	signer := NewSigner([]Key{oldKey})
+	signer.now = func() time.Time { return time.Now().Add(-MaxClockSkew - time.Minute) }
+
+	_, err := verifier.Verify(signedRequest(t, signer, ""))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestSignatureVerifier_NoncesFull(t *testing.T) {
+	verifier := NewSignatureVerifier([]Key{oldKey})
+	now := time.Now()
+	later := now.Add(MaxClockSkew + 3*time.Second + time.Millisecond)
+	live := 0
+	for i := 0; i < maxNonces; i++ {
+		// Expiries out of order, as with signers whose clocks differ.
+		expires := now.Add(MaxClockSkew + time.Duration(i%7)*time.Second)
+		require.NoError(t, verifier.remember(fmt.Sprint("k1:", i), expires, now))
+		if expires.After(later) {
+			live++
+		}
+	}
+
+	err := verifier.remember("k1:new", now.Add(MaxClockSkew), now)
+	assert.ErrorIs(t, err, ErrTooManyNonces, "live nonces are not evicted")
+	assert.ErrorIs(t, verifier.remember("k1:0", now.Add(MaxClockSkew), now), ErrInvalidSignature, "replays are still told apart")
+	assert.Len(t, verifier.nonces, maxNonces)
+
+	require.NoError(t, verifier.remember("k1:new", later.Add(MaxClockSkew), later))
+	assert.Len(t, verifier.nonces, live+1, "expired nonces are dropped")
+	assert.Len(t, verifier.expiries, len(verifier.nonces))
+}
+
+func TestMiddleware_SignedRequest_NoncesFull(t *testing.T) {
+	verifier := NewSignatureVerifier([]Key{oldKey})
+	for i := 0; i < maxNonces; i++ {
+		require.NoError(t, verifier.remember(fmt.Sprint("k1:", i), time.Now().Add(time.Hour), time.Now()))
+	}
+
+	rec, _ := serve(t, Options{Services: verifier, Required: true}, signedRequest(t, NewSigner([]Key{oldKey}), ""))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestSignatureVerifier_Rotation(t *testing.T) {
+	// While a key is rotated, services verify with both keys.
+	verifier := NewSignatureVerifier([]Key{newKey, oldKey})
+
+	keyID, err := verifier.Verify(signedRequest(t, NewSigner([]Key{oldKey}), ""))
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keyID)
+
+	keyID, err = verifier.Verify(signedRequest(t, NewSigner([]Key{newKey, oldKey}), ""))
+	require.NoError(t, err)
+	assert.Equal(t, "k2", keyID)
+
+	// Once the old key is dropped, requests signed with it are rejected.
+	_, err = NewSignatureVerifier([]Key{newKey}).Verify(signedRequest(t, NewSigner([]Key{oldKey}), ""))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestSigner_Transport(t *testing.T) {
+	verifier := NewSignatureVerifier([]Key{oldKey})
+	var keyID, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		keyID, err = verifier.Verify(r)
+		assert.NoError(t, err)
+		raw, _ := io.ReadAll(r.Body)
+		body = string(raw)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewSigner([]Key{oldKey}).Transport(nil)}
+	resp, err := client.Post(srv.URL+"/api/v1/links", "application/json", strings.NewReader(`{"url":"https://go.dev"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "k1", keyID)
+	assert.Equal(t, `{"url":"https://go.dev"}`, body)
+
+	assert.Nil(t, (*Signer)(nil).Transport(nil), "a nil signer leaves requests unsigned")
+}
+
+func TestMiddleware_SignedRequest(t *testing.T) {
+	signer := NewSigner([]Key{oldKey})
+	opts := Options{Services: NewSignatureVerifier([]Key{oldKey}), Required: true}
+
+	rec, seen := serve(t, opts, signedRequest(t, signer, ""))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "user-1", seen)
+
+	req := signedRequest(t, signer, "")
+	req.Header.Set(UserIDHeader, "user-2")
+	rec, _ = serve(t, opts, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestMiddleware_UnsignedUserID(t *testing.T) {
+	// Once services sign their requests, nobody else may name a user.
+	opts := Options{Services: NewSignatureVerifier([]Key{oldKey})}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/links", nil)
+	req.Header.Set(UserIDHeader, "user-1")
+	rec, _ := serve(t, opts, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/links", nil)
+	rec, seen := serve(t, opts, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, seen)
+}
+
+func TestMiddleware_SignedWithoutVerifier(t *testing.T) {
+	req := signedRequest(t, NewSigner([]Key{oldKey}), "")
+	rec, _ := serve(t, Options{}, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"flag"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config represents system configuration.
@@ -10,6 +12,18 @@ type Config struct {
 	Env         string // runtime environment
 	HTTPAddr    string // address "[host]:port" for HTTP server
 	PostgresDSN string // Postgres DSN
+
+	AuthRequired     bool          // reject requests without an API token instead of trusting the network
+	ServiceKeys      string        // "id:secret" keys services sign their requests to each other with, first one signing
+	AdminToken       string        // token for admin endpoints; they are disabled when empty
+	MetadataTimeout  time.Duration // per-attempt timeout when fetching page metadata
+	MetadataAttempts int           // attempts to fetch page metadata of a link
+	StripURLParams   string        // comma-separated query parameters dropped when canonicalizing URLs, on top of the defaults
+	UserServiceURL   string        // base URL of user-service, used to include profiles in backups
+	FeedRefresh      time.Duration // time between two polls of a subscribed feed
+	TelegramToken    string        // bot token Telegram login data is signed with; Telegram login is disabled when empty
+	SessionSecret    string        // key session tokens are signed with
+	SessionTTL       time.Duration // lifetime of a session token
 }
 
 // New reads config from environment/flags and returns pointer to a new Config.
@@ -25,6 +39,52 @@ func New() *Config {
 		"PostgreSQL DSN.",
 	)
 
+	flag.BoolVar(
+		&c.AuthRequired,
+		"authRequired",
+		lookupEnvBool("AUTH_REQUIRED", false),
+		"Reject requests without an API token; when false callers are trusted and may name their user in X-User-ID.",
+	)
+	flag.StringVar(
+		&c.ServiceKeys,
+		"serviceKeys",
+		os.Getenv("SERVICE_KEYS"),
+		`Comma-separated "id:secret" keys other services sign requests with; the first one signs this service's requests.`,
+	)
+	flag.StringVar(&c.AdminToken, "adminToken", os.Getenv("ADMIN_TOKEN"), "Token for admin endpoints; leave empty to disable them.")
+	flag.DurationVar(
+		&c.MetadataTimeout,
+		"metadataTimeout",
+		lookupEnvDuration("METADATA_TIMEOUT", 10*time.Second),
+		"Timeout of a single page metadata fetch.",
+	)
+	flag.IntVar(&c.MetadataAttempts, "metadataAttempts", lookupEnvInt("METADATA_ATTEMPTS", 3), "Attempts to fetch page metadata of a link.")
+
+	flag.StringVar(
+		&c.StripURLParams,
+		"stripUrlParams",
+		os.Getenv("STRIP_URL_PARAMS"),
+		`Comma-separated query parameters to ignore when detecting duplicate links; "name*" matches a prefix.`,
+	)
+
+	flag.StringVar(&c.UserServiceURL, "userServiceUrl", os.Getenv("USER_SERVICE_URL"), "Base URL of user-service; backups carry no profile when empty.")
+
+	flag.DurationVar(
+		&c.FeedRefresh,
+		"feedRefresh",
+		lookupEnvDuration("FEED_REFRESH_INTERVAL", 30*time.Minute),
+		"Time between two polls of a subscribed feed.",
+	)
+
+	flag.StringVar(&c.TelegramToken, "telegramToken", os.Getenv("TELEGRAM_TOKEN"), "Bot token to verify Telegram login data with; leave empty to disable Telegram login.")
+	flag.StringVar(
+		&c.SessionSecret,
+		"sessionSecret",
+		os.Getenv("SESSION_SECRET"),
+		"Key to sign session tokens with; when empty a random one is used and sessions do not survive a restart.",
+	)
+	flag.DurationVar(&c.SessionTTL, "sessionTtl", lookupEnvDuration("SESSION_TTL", 12*time.Hour), "Lifetime of a session token.")
+
 	flag.Parse()
 
 	return c
@@ -36,3 +96,24 @@ func lookupEnvString(k, def string) string {
 	}
 	return def
 }
+
+func lookupEnvDuration(k string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(k)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+func lookupEnvInt(k string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(k)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+func lookupEnvBool(k string, def bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(k)); err == nil {
+		return v
+	}
+	return def
+}
@@ -61,7 +61,7 @@ func TestIntegration_CreateAndGetUser(t *testing.T) {
 
 	userServer.GetOrCreateUser(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusCreated, w.Code)
 
 	var userResp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &userResp)
@@ -80,6 +80,8 @@ func TestIntegration_CreateAndGetUser(t *testing.T) {
 	err = json.Unmarshal(w2.Body.Bytes(), &userResp2)
 	require.NoError(t, err)
 	assert.Equal(t, userResp["id"], userResp2["id"])
+	assert.Equal(t, true, userResp["created"])
+	assert.Equal(t, false, userResp2["created"])
 }
 
 func TestIntegration_UserExists(t *testing.T) {